@@ -23,7 +23,7 @@ import (
 type UnsupportedWitnessVerError byte
 
 func (e UnsupportedWitnessVerError) Error() string {
-	return fmt.Sprintf("unsupported witness version: %#x", e)
+	return fmt.Sprintf("unsupported witness version: %#x", byte(e))
 }
 
 // UnsupportedWitnessProgLenError describes an error where a segwit address
@@ -79,7 +79,16 @@ func encodeSegWitAddress(hrp string, witnessVersion byte, witnessProgram []byte)
 	combined := make([]byte, len(converted)+1)
 	combined[0] = witnessVersion
 	copy(combined[1:], converted)
-	bech, err := bech32.Encode(hrp, combined)
+
+	// Witness version 0 addresses use the original bech32 checksum, while
+	// all later versions use bech32m as defined in BIP 350.
+	var bech string
+	switch witnessVersion {
+	case 0:
+		bech, err = bech32.Encode(hrp, combined)
+	default:
+		bech, err = bech32.EncodeM(hrp, combined)
+	}
 	if err != nil {
 		return "", err
 	}
@@ -99,7 +108,8 @@ func encodeSegWitAddress(hrp string, witnessVersion byte, witnessProgram []byte)
 
 // Address is an interface type for any type of destination a transaction
 // output may spend to.  This includes pay-to-pubkey (P2PK), pay-to-pubkey-hash
-// (P2PKH), and pay-to-script-hash (P2SH).  Address is designed to be generic
+// (P2PKH), pay-to-script-hash (P2SH) and the native segwit outputs (P2WPKH,
+// P2WSH, P2TR and future witness versions).  Address is designed to be generic
 // enough that other kinds of addresses may be added in the future without
 // changing the decoding and encoding API.
 type Address interface {
@@ -135,8 +145,8 @@ type Address interface {
 // public key, the address will be associated with the passed defaultNet.
 func DecodeAddress(addr string, defaultNet *chaincfg.Params) (Address, error) {
 	// Bech32 encoded segwit addresses start with a human-readable part
-	// (hrp) followed by '1'. For Bitcoin mainnet the hrp is "bc", and for
-	// testnet it is "tb". If the address string has a prefix that matches
+	// (hrp) followed by '1'. For Monacoin mainnet the hrp is "mona", and for
+	// testnet it is "tmona". If the address string has a prefix that matches
 	// one of the prefixes for the known networks, we try to decode it as
 	// a segwit address.
 	oneIndex := strings.LastIndexByte(addr, '1')
@@ -149,19 +159,23 @@ func DecodeAddress(addr string, defaultNet *chaincfg.Params) (Address, error) {
 				return nil, err
 			}
 
-			// We currently only support P2WPKH and P2WSH, which is
-			// witness version 0.
-			if witnessVer != 0 {
-				return nil, UnsupportedWitnessVerError(witnessVer)
-			}
-
-			switch len(witnessProg) {
-			case 20:
+			// Witness version 0 is either P2WPKH or P2WSH depending
+			// on the program length, a version 1 program of 32
+			// bytes is a taproot output, and anything else is a
+			// witness program without a dedicated address type.
+			switch {
+			case witnessVer == 0 && len(witnessProg) == 20:
 				return newAddressWitnessPubKeyHash(hrp, witnessProg)
-			case 32:
+			case witnessVer == 0 && len(witnessProg) == 32:
 				return newAddressWitnessScriptHash(hrp, witnessProg)
-			default:
+			case witnessVer == 0:
 				return nil, UnsupportedWitnessProgLenError(len(witnessProg))
+			case witnessVer == 1 && len(witnessProg) == 32:
+				return newAddressTaproot(hrp, witnessProg)
+			default:
+				return newAddressWitnessProgram(
+					hrp, witnessVer, witnessProg,
+				)
 			}
 		}
 	}
@@ -208,7 +222,7 @@ func DecodeAddress(addr string, defaultNet *chaincfg.Params) (Address, error) {
 // returns the witness version and witness program byte representation.
 func decodeSegWitAddress(address string) (byte, []byte, error) {
 	// Decode the bech32 encoded address.
-	_, data, err := bech32.Decode(address)
+	_, data, bech32version, err := bech32.DecodeGeneric(address)
 	if err != nil {
 		return 0, nil, err
 	}
@@ -222,7 +236,7 @@ func decodeSegWitAddress(address string) (byte, []byte, error) {
 	// ...and be <= 16.
 	version := data[0]
 	if version > 16 {
-		return 0, nil, fmt.Errorf("invalid witness version: %v", version)
+		return 0, nil, UnsupportedWitnessVerError(version)
 	}
 
 	// The remaining characters of the address returned are grouped into
@@ -244,6 +258,17 @@ func decodeSegWitAddress(address string) (byte, []byte, error) {
 			"version 0: %v", len(regrouped))
 	}
 
+	// For witness version 0, the bech32 encoding must be used, while all
+	// later versions must use bech32m (BIP 350).
+	if version == 0 && bech32version != bech32.Version0 {
+		return 0, nil, fmt.Errorf("invalid checksum expected bech32 " +
+			"encoding for address with witness version 0")
+	}
+	if version >= 1 && bech32version != bech32.VersionM {
+		return 0, nil, fmt.Errorf("invalid checksum expected bech32m " +
+			"encoding for address with witness version 1 or higher")
+	}
+
 	return version, regrouped, nil
 }
 
@@ -679,3 +704,186 @@ func (a *AddressWitnessScriptHash) WitnessVersion() byte {
 func (a *AddressWitnessScriptHash) WitnessProgram() []byte {
 	return a.witnessProgram[:]
 }
+
+// AddressTaproot is an Address for a pay-to-taproot (P2TR) output.  Taproot
+// outputs are witness version 1 programs of 32 bytes holding the tweaked
+// output key, and are encoded using bech32m.  See BIP 341 and BIP 350 for
+// further details:
+// https://github.com/bitcoin/bips/blob/master/bip-0341.mediawiki
+type AddressTaproot struct {
+	hrp            string
+	witnessVersion byte
+	witnessProgram [32]byte
+}
+
+// NewAddressTaproot returns a new AddressTaproot.  The witness program must be
+// the 32-byte x-only serialization of the taproot output key.
+func NewAddressTaproot(witnessProg []byte, net *chaincfg.Params) (*AddressTaproot, error) {
+	return newAddressTaproot(net.Bech32HRPSegwit, witnessProg)
+}
+
+// newAddressTaproot is an internal helper function to create an AddressTaproot
+// with a known human-readable part, rather than looking it up through its
+// parameters.
+func newAddressTaproot(hrp string, witnessProg []byte) (*AddressTaproot, error) {
+	// Check for valid program length for witness version 1, which is 32
+	// for P2TR.
+	if len(witnessProg) != 32 {
+		return nil, errors.New("witness program must be 32 " +
+			"bytes for p2tr")
+	}
+
+	addr := &AddressTaproot{
+		hrp:            strings.ToLower(hrp),
+		witnessVersion: 0x01,
+	}
+
+	copy(addr.witnessProgram[:], witnessProg)
+
+	return addr, nil
+}
+
+// EncodeAddress returns the bech32m string encoding of an AddressTaproot.
+// Part of the Address interface.
+func (a *AddressTaproot) EncodeAddress() string {
+	str, err := encodeSegWitAddress(a.hrp, a.witnessVersion,
+		a.witnessProgram[:])
+	if err != nil {
+		return ""
+	}
+	return str
+}
+
+// ScriptAddress returns the witness program for this address.
+// Part of the Address interface.
+func (a *AddressTaproot) ScriptAddress() []byte {
+	return a.witnessProgram[:]
+}
+
+// IsForNet returns whether or not the AddressTaproot is associated with the
+// passed monacoin network.
+// Part of the Address interface.
+func (a *AddressTaproot) IsForNet(net *chaincfg.Params) bool {
+	return a.hrp == net.Bech32HRPSegwit
+}
+
+// String returns a human-readable string for the AddressTaproot.
+// This is equivalent to calling EncodeAddress, but is provided so the type
+// can be used as a fmt.Stringer.
+// Part of the Address interface.
+func (a *AddressTaproot) String() string {
+	return a.EncodeAddress()
+}
+
+// Hrp returns the human-readable part of the bech32m encoded AddressTaproot.
+func (a *AddressTaproot) Hrp() string {
+	return a.hrp
+}
+
+// WitnessVersion returns the witness version of the AddressTaproot.
+func (a *AddressTaproot) WitnessVersion() byte {
+	return a.witnessVersion
+}
+
+// WitnessProgram returns the witness program of the AddressTaproot.
+func (a *AddressTaproot) WitnessProgram() []byte {
+	return a.witnessProgram[:]
+}
+
+// AddressWitnessProgram is an Address for a native segwit output of witness
+// version 1 through 16 that has no dedicated address type, such as programs
+// for future soft forks.  These addresses are encoded using bech32m.  See
+// BIP 350 for further details.
+type AddressWitnessProgram struct {
+	hrp            string
+	witnessVersion byte
+	witnessProgram []byte
+}
+
+// NewAddressWitnessProgram returns a new AddressWitnessProgram for the given
+// witness version and program.  The version must be between 1 and 16 and the
+// program between 2 and 40 bytes.  Version 0 programs must be created with
+// NewAddressWitnessPubKeyHash or NewAddressWitnessScriptHash instead.
+func NewAddressWitnessProgram(witnessVersion byte, witnessProg []byte,
+	net *chaincfg.Params) (*AddressWitnessProgram, error) {
+
+	return newAddressWitnessProgram(
+		net.Bech32HRPSegwit, witnessVersion, witnessProg,
+	)
+}
+
+// newAddressWitnessProgram is an internal helper function to create an
+// AddressWitnessProgram with a known human-readable part, rather than looking
+// it up through its parameters.
+func newAddressWitnessProgram(hrp string, witnessVersion byte,
+	witnessProg []byte) (*AddressWitnessProgram, error) {
+
+	if witnessVersion < 1 || witnessVersion > 16 {
+		return nil, UnsupportedWitnessVerError(witnessVersion)
+	}
+	if len(witnessProg) < 2 || len(witnessProg) > 40 {
+		return nil, UnsupportedWitnessProgLenError(len(witnessProg))
+	}
+
+	addr := &AddressWitnessProgram{
+		hrp:            strings.ToLower(hrp),
+		witnessVersion: witnessVersion,
+		witnessProgram: make([]byte, len(witnessProg)),
+	}
+
+	copy(addr.witnessProgram, witnessProg)
+
+	return addr, nil
+}
+
+// EncodeAddress returns the bech32m string encoding of an
+// AddressWitnessProgram.
+// Part of the Address interface.
+func (a *AddressWitnessProgram) EncodeAddress() string {
+	str, err := encodeSegWitAddress(a.hrp, a.witnessVersion,
+		a.witnessProgram)
+	if err != nil {
+		return ""
+	}
+	return str
+}
+
+// ScriptAddress returns a copy of the witness program for this address.
+// Part of the Address interface.
+func (a *AddressWitnessProgram) ScriptAddress() []byte {
+	return a.WitnessProgram()
+}
+
+// IsForNet returns whether or not the AddressWitnessProgram is associated
+// with the passed monacoin network.
+// Part of the Address interface.
+func (a *AddressWitnessProgram) IsForNet(net *chaincfg.Params) bool {
+	return a.hrp == net.Bech32HRPSegwit
+}
+
+// String returns a human-readable string for the AddressWitnessProgram.
+// This is equivalent to calling EncodeAddress, but is provided so the type
+// can be used as a fmt.Stringer.
+// Part of the Address interface.
+func (a *AddressWitnessProgram) String() string {
+	return a.EncodeAddress()
+}
+
+// Hrp returns the human-readable part of the bech32m encoded
+// AddressWitnessProgram.
+func (a *AddressWitnessProgram) Hrp() string {
+	return a.hrp
+}
+
+// WitnessVersion returns the witness version of the AddressWitnessProgram.
+func (a *AddressWitnessProgram) WitnessVersion() byte {
+	return a.witnessVersion
+}
+
+// WitnessProgram returns a copy of the witness program of the
+// AddressWitnessProgram.
+func (a *AddressWitnessProgram) WitnessProgram() []byte {
+	prog := make([]byte, len(a.witnessProgram))
+	copy(prog, a.witnessProgram)
+	return prog
+}
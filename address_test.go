@@ -655,25 +655,141 @@ func TestAddresses(t *testing.T) {
 			},
 			net: &customParams,
 		},
-		// Unsupported witness versions (version 0 only supported at this point)
+		// Taproot and other witness v1+ addresses.
 		{
-			name:  "segwit mainnet witness v1",
+			name:    "segwit mainnet p2tr v1",
+			addr:    "mona1p0xlxvlhemja6c4dqv22uapctqupfhlxm9h8z3k2e72q4k9hcz7vqtsd8k8",
+			encoded: "mona1p0xlxvlhemja6c4dqv22uapctqupfhlxm9h8z3k2e72q4k9hcz7vqtsd8k8",
+			valid:   true,
+			result: monautil.TstAddressTaproot(
+				1,
+				[32]byte{
+					0x79, 0xbe, 0x66, 0x7e, 0xf9, 0xdc, 0xbb, 0xac,
+					0x55, 0xa0, 0x62, 0x95, 0xce, 0x87, 0x0b, 0x07,
+					0x02, 0x9b, 0xfc, 0xdb, 0x2d, 0xce, 0x28, 0xd9,
+					0x59, 0xf2, 0x81, 0x5b, 0x16, 0xf8, 0x17, 0x98},
+				chaincfg.MainNetParams.Bech32HRPSegwit),
+			f: func() (monautil.Address, error) {
+				outputKey := []byte{
+					0x79, 0xbe, 0x66, 0x7e, 0xf9, 0xdc, 0xbb, 0xac,
+					0x55, 0xa0, 0x62, 0x95, 0xce, 0x87, 0x0b, 0x07,
+					0x02, 0x9b, 0xfc, 0xdb, 0x2d, 0xce, 0x28, 0xd9,
+					0x59, 0xf2, 0x81, 0x5b, 0x16, 0xf8, 0x17, 0x98}
+				return monautil.NewAddressTaproot(outputKey, &chaincfg.MainNetParams)
+			},
+			net: &chaincfg.MainNetParams,
+		},
+		{
+			name:    "segwit testnet p2tr v1",
+			addr:    "TMONA1P0XLXVLHEMJA6C4DQV22UAPCTQUPFHLXM9H8Z3K2E72Q4K9HCZ7VQ53EY3V",
+			encoded: "tmona1p0xlxvlhemja6c4dqv22uapctqupfhlxm9h8z3k2e72q4k9hcz7vq53ey3v",
+			valid:   true,
+			result: monautil.TstAddressTaproot(
+				1,
+				[32]byte{
+					0x79, 0xbe, 0x66, 0x7e, 0xf9, 0xdc, 0xbb, 0xac,
+					0x55, 0xa0, 0x62, 0x95, 0xce, 0x87, 0x0b, 0x07,
+					0x02, 0x9b, 0xfc, 0xdb, 0x2d, 0xce, 0x28, 0xd9,
+					0x59, 0xf2, 0x81, 0x5b, 0x16, 0xf8, 0x17, 0x98},
+				chaincfg.TestNet4Params.Bech32HRPSegwit),
+			f: func() (monautil.Address, error) {
+				outputKey := []byte{
+					0x79, 0xbe, 0x66, 0x7e, 0xf9, 0xdc, 0xbb, 0xac,
+					0x55, 0xa0, 0x62, 0x95, 0xce, 0x87, 0x0b, 0x07,
+					0x02, 0x9b, 0xfc, 0xdb, 0x2d, 0xce, 0x28, 0xd9,
+					0x59, 0xf2, 0x81, 0x5b, 0x16, 0xf8, 0x17, 0x98}
+				return monautil.NewAddressTaproot(outputKey, &chaincfg.TestNet4Params)
+			},
+			net: &chaincfg.TestNet4Params,
+		},
+		{
+			name:    "segwit mainnet witness v1 non-taproot length",
+			addr:    "mona1p0xlxvlhemja6c4dqv22uapctqupfhlxmutufdq",
+			encoded: "mona1p0xlxvlhemja6c4dqv22uapctqupfhlxmutufdq",
+			valid:   true,
+			result: monautil.TstAddressWitnessProgram(
+				1,
+				[]byte{
+					0x79, 0xbe, 0x66, 0x7e, 0xf9, 0xdc, 0xbb, 0xac,
+					0x55, 0xa0, 0x62, 0x95, 0xce, 0x87, 0x0b, 0x07,
+					0x02, 0x9b, 0xfc, 0xdb},
+				chaincfg.MainNetParams.Bech32HRPSegwit),
+			f: func() (monautil.Address, error) {
+				program := []byte{
+					0x79, 0xbe, 0x66, 0x7e, 0xf9, 0xdc, 0xbb, 0xac,
+					0x55, 0xa0, 0x62, 0x95, 0xce, 0x87, 0x0b, 0x07,
+					0x02, 0x9b, 0xfc, 0xdb}
+				return monautil.NewAddressWitnessProgram(1, program, &chaincfg.MainNetParams)
+			},
+			net: &chaincfg.MainNetParams,
+		},
+		{
+			name:    "segwit mainnet witness v2",
+			addr:    "mona1zw50qwz5afh",
+			encoded: "mona1zw50qwz5afh",
+			valid:   true,
+			result: monautil.TstAddressWitnessProgram(
+				2, []byte{0x75, 0x1e},
+				chaincfg.MainNetParams.Bech32HRPSegwit),
+			f: func() (monautil.Address, error) {
+				return monautil.NewAddressWitnessProgram(
+					2, []byte{0x75, 0x1e}, &chaincfg.MainNetParams)
+			},
+			net: &chaincfg.MainNetParams,
+		},
+		{
+			name:    "segwit mainnet witness v16",
+			addr:    "mona1sw50q5sr2p9",
+			encoded: "mona1sw50q5sr2p9",
+			valid:   true,
+			result: monautil.TstAddressWitnessProgram(
+				16, []byte{0x75, 0x1e},
+				chaincfg.MainNetParams.Bech32HRPSegwit),
+			f: func() (monautil.Address, error) {
+				return monautil.NewAddressWitnessProgram(
+					16, []byte{0x75, 0x1e}, &chaincfg.MainNetParams)
+			},
+			net: &chaincfg.MainNetParams,
+		},
+		// Witness v1+ addresses using the original bech32 checksum are
+		// invalid per BIP 350.
+		{
+			name:  "segwit mainnet witness v1 bitcoin hrp",
 			addr:  "bc1pw508d6qejxtdg4y5r3zarvary0c5xw7kw508d6qejxtdg4y5r3zarvary0c5xw7k7grplx",
 			valid: false,
 			net:   &chaincfg.MainNetParams,
 		},
 		{
-			name:  "segwit mainnet witness v16",
+			name:  "segwit mainnet witness v16 bech32 checksum",
 			addr:  "mona1sw50qpvnxy8",
 			valid: false,
 			net:   &chaincfg.MainNetParams,
 		},
 		{
-			name:  "segwit mainnet witness v2",
+			name:  "segwit mainnet witness v2 bech32 checksum",
 			addr:  "mona1zw508d6qejxtdg4y5r3zarvaryvhm3vz7",
 			valid: false,
 			net:   &chaincfg.MainNetParams,
 		},
+		{
+			name:  "segwit mainnet p2wpkh v0 bech32m checksum",
+			addr:  "mona1qvzvkjn4q3nszqxrv3nraga2r822xjty34e2c5d",
+			valid: false,
+			net:   &chaincfg.MainNetParams,
+		},
+		{
+			name:  "segwit mainnet witness v1 program too long",
+			addr:  "mona1p09a8klra0elcpqvzswzgtp583zyc4zuv3k8glyy3j2fef9vkj7vfnx5mnjwea8aq5ydmsjkn",
+			valid: false,
+			f: func() (monautil.Address, error) {
+				program := make([]byte, 41)
+				for i := range program {
+					program[i] = byte(0x79 + i)
+				}
+				return monautil.NewAddressWitnessProgram(1, program, &chaincfg.MainNetParams)
+			},
+			net: &chaincfg.MainNetParams,
+		},
 		// Invalid segwit addresses
 		{
 			name:  "segwit invalid hrp",
@@ -788,6 +904,10 @@ func TestAddresses(t *testing.T) {
 				saddr = monautil.TstAddressSegwitSAddr(encoded)
 			case *monautil.AddressWitnessScriptHash:
 				saddr = monautil.TstAddressSegwitSAddr(encoded)
+			case *monautil.AddressTaproot:
+				saddr = monautil.TstAddressSegwitSAddr(encoded)
+			case *monautil.AddressWitnessProgram:
+				saddr = monautil.TstAddressSegwitSAddr(encoded)
 			}
 
 			// Check script address, as well as the Hash160 method for P2PKH and
@@ -846,6 +966,46 @@ func TestAddresses(t *testing.T) {
 					return
 				}
 
+				if p := a.WitnessProgram(); !bytes.Equal(saddr, p) {
+					t.Errorf("%v: witness programs do not match:\n%x != \n%x",
+						test.name, saddr, p)
+					return
+				}
+
+			case *monautil.AddressTaproot:
+				if hrp := a.Hrp(); test.net.Bech32HRPSegwit != hrp {
+					t.Errorf("%v: hrps do not match:\n%x != \n%x",
+						test.name, test.net.Bech32HRPSegwit, hrp)
+					return
+				}
+
+				expVer := test.result.(*monautil.AddressTaproot).WitnessVersion()
+				if v := a.WitnessVersion(); v != expVer {
+					t.Errorf("%v: witness versions do not match:\n%x != \n%x",
+						test.name, expVer, v)
+					return
+				}
+
+				if p := a.WitnessProgram(); !bytes.Equal(saddr, p) {
+					t.Errorf("%v: witness programs do not match:\n%x != \n%x",
+						test.name, saddr, p)
+					return
+				}
+
+			case *monautil.AddressWitnessProgram:
+				if hrp := a.Hrp(); test.net.Bech32HRPSegwit != hrp {
+					t.Errorf("%v: hrps do not match:\n%x != \n%x",
+						test.name, test.net.Bech32HRPSegwit, hrp)
+					return
+				}
+
+				expVer := test.result.(*monautil.AddressWitnessProgram).WitnessVersion()
+				if v := a.WitnessVersion(); v != expVer {
+					t.Errorf("%v: witness versions do not match:\n%x != \n%x",
+						test.name, expVer, v)
+					return
+				}
+
 				if p := a.WitnessProgram(); !bytes.Equal(saddr, p) {
 					t.Errorf("%v: witness programs do not match:\n%x != \n%x",
 						test.name, saddr, p)
@@ -890,3 +1050,35 @@ func TestAddresses(t *testing.T) {
 		}
 	}
 }
+
+// TestWitnessProgramCopy ensures the witness program of an
+// AddressWitnessProgram can't be modified through the returned slices.
+func TestWitnessProgramCopy(t *testing.T) {
+	program := []byte{0x75, 0x1e, 0x76, 0xe8, 0x19, 0x91, 0x96, 0xd4}
+	addr, err := monautil.NewAddressWitnessProgram(2, program,
+		&chaincfg.MainNetParams)
+	if err != nil {
+		t.Fatalf("NewAddressWitnessProgram: %v", err)
+	}
+	encoded := addr.EncodeAddress()
+
+	addr.WitnessProgram()[0] ^= 0xff
+	addr.ScriptAddress()[1] ^= 0xff
+	program[2] ^= 0xff
+
+	if got := addr.EncodeAddress(); got != encoded {
+		t.Errorf("address changed from %v to %v", encoded, got)
+	}
+}
+
+// TestNewAddressTaprootLength ensures taproot addresses are only created from
+// 32-byte witness programs.
+func TestNewAddressTaprootLength(t *testing.T) {
+	for _, size := range []int{20, 31, 33} {
+		_, err := monautil.NewAddressTaproot(make([]byte, size),
+			&chaincfg.MainNetParams)
+		if err == nil {
+			t.Errorf("created taproot address of %d bytes", size)
+		}
+	}
+}
@@ -5,9 +5,11 @@
 package monautil
 
 import (
+	"encoding/json"
 	"errors"
 	"math"
 	"strconv"
+	"strings"
 
 	"github.com/shopspring/decimal"
 )
@@ -51,6 +53,42 @@ func (u AmountUnit) String() string {
 	}
 }
 
+var (
+	// ErrMalformedAmount describes an error in which a string passed to
+	// ParseAmount is not a plain decimal number optionally followed by a
+	// known unit.
+	ErrMalformedAmount = errors.New("malformed monacoin amount")
+
+	// ErrAmountPrecision describes an error in which a parsed amount is
+	// more precise than a watanabe, the base unit.
+	ErrAmountPrecision = errors.New("monacoin amount is more precise " +
+		"than a watanabe")
+
+	// ErrAmountOutOfRange describes an error in which an amount exceeds
+	// MaxSatoshi in magnitude.
+	ErrAmountOutOfRange = errors.New("monacoin amount out of range")
+
+	// ErrAmountDivideByZero describes an error in which an amount is
+	// divided by zero.
+	ErrAmountDivideByZero = errors.New("monacoin amount divided by zero")
+)
+
+// parseAmountUnit returns the unit whose String is the passed suffix.  The
+// ASCII spelling uMONA is accepted for μMONA.
+func parseAmountUnit(s string) (AmountUnit, bool) {
+	if s == "uMONA" {
+		return AmountMicroBTC, true
+	}
+	for _, u := range []AmountUnit{AmountMegaBTC, AmountKiloBTC,
+		AmountBTC, AmountMilliBTC, AmountMicroBTC, AmountSatoshi} {
+
+		if u.String() == s {
+			return u, true
+		}
+	}
+	return 0, false
+}
+
 // Amount represents the base monacoin monetary unit (colloquially referred
 // to as a `Satoshi').  A single Amount is equal to 1e-8 of a monacoin.
 type Amount int64
@@ -101,6 +139,60 @@ func NewAmount(d decimal.Decimal) (Amount, error) {
 	return Amount(d3.IntPart()), nil
 }
 
+// ParseAmount parses a decimal number of monacoin, such as "1.23456789", or
+// of any known unit when followed by its suffix, such as "150 mMONA" or
+// "100 Watanabe".  The number is converted without rounding, so
+// ErrAmountPrecision is returned for amounts more precise than a watanabe.
+// Exponents are not accepted.
+func ParseAmount(s string) (Amount, error) {
+	s = strings.TrimSpace(s)
+	num, suffix := s, ""
+	if i := strings.IndexFunc(s, func(r rune) bool {
+		return (r < '0' || r > '9') && r != '.' && r != '-' && r != '+'
+	}); i >= 0 {
+		num, suffix = s[:i], strings.TrimSpace(s[i:])
+	}
+
+	unit := AmountBTC
+	if suffix != "" {
+		var ok bool
+		unit, ok = parseAmountUnit(suffix)
+		if !ok {
+			return 0, ErrMalformedAmount
+		}
+	}
+
+	// Only plain decimal numbers with an optional leading sign are
+	// accepted.
+	digits := strings.TrimLeft(num, "+-")
+	if len(num)-len(digits) > 1 || digits == "" || digits == "." ||
+		strings.Count(digits, ".") > 1 ||
+		strings.ContainsAny(digits, "+-") {
+
+		return 0, ErrMalformedAmount
+	}
+	d, err := decimal.NewFromString(num)
+	if err != nil {
+		return 0, ErrMalformedAmount
+	}
+
+	return amountFromDecimal(d, unit)
+}
+
+// amountFromDecimal converts a decimal number of the passed unit to an Amount
+// without rounding.
+func amountFromDecimal(d decimal.Decimal, u AmountUnit) (Amount, error) {
+	d = d.Shift(int32(u) + 8)
+	if !d.Equal(d.Truncate(0)) {
+		return 0, ErrAmountPrecision
+	}
+	if d.Abs().Cmp(decimal.NewFromInt(MaxSatoshi)) > 0 {
+		return 0, ErrAmountOutOfRange
+	}
+
+	return Amount(d.IntPart()), nil
+}
+
 // ToDecimalUnit converts a monetary amount counted in monacoin base units to a
 // Decimal value representing an amount of monacoin.
 func (a *Amount) ToDecimalUnit(u AmountUnit) decimal.Decimal {
@@ -145,3 +237,128 @@ func (a Amount) MulF64(f float64) Amount {
 	return Amount(decimal.NewFromInt(int64(a)).Mul(decimal.NewFromFloat(f)).Round(0).IntPart())
 	//return round(float64(a) * f)
 }
+
+// MarshalText implements the encoding.TextMarshaler interface by returning
+// the amount as formatted by String, such as "1.5 MONA".
+func (a Amount) MarshalText() ([]byte, error) {
+	return []byte(a.String()), nil
+}
+
+// UnmarshalText implements the encoding.TextUnmarshaler interface by parsing
+// the text with ParseAmount.
+func (a *Amount) UnmarshalText(text []byte) error {
+	amount, err := ParseAmount(string(text))
+	if err != nil {
+		return err
+	}
+	*a = amount
+	return nil
+}
+
+// MarshalJSON implements the json.Marshaler interface by encoding the amount
+// as an exact JSON number of monacoin, such as 1.5, as done by the RPC
+// server.
+func (a Amount) MarshalJSON() ([]byte, error) {
+	f := NewAmountFormatter(AmountBTC)
+	f.OmitUnit = true
+	return []byte(f.Format(a)), nil
+}
+
+// UnmarshalJSON implements the json.Unmarshaler interface.  The amount may be
+// a JSON number of monacoin, which is converted without rounding, or a string
+// accepted by ParseAmount.
+func (a *Amount) UnmarshalJSON(data []byte) error {
+	if string(data) == "null" {
+		return nil
+	}
+
+	if len(data) > 0 && data[0] == '"' {
+		var s string
+		if err := json.Unmarshal(data, &s); err != nil {
+			return err
+		}
+		return a.UnmarshalText([]byte(s))
+	}
+
+	d, err := decimal.NewFromString(string(data))
+	if err != nil {
+		return ErrMalformedAmount
+	}
+	amount, err := amountFromDecimal(d, AmountBTC)
+	if err != nil {
+		return err
+	}
+	*a = amount
+	return nil
+}
+
+// inRange returns whether the amount is within the money range, which is
+// MaxSatoshi in magnitude.
+func (a Amount) inRange() bool {
+	return a >= -MaxSatoshi && a <= MaxSatoshi
+}
+
+// Add returns the sum of the amounts.  ErrAmountOutOfRange is returned if
+// either amount or the sum exceeds MaxSatoshi in magnitude, so sums of
+// amounts never overflow.
+func (a Amount) Add(b Amount) (Amount, error) {
+	if !a.inRange() || !b.inRange() {
+		return 0, ErrAmountOutOfRange
+	}
+	sum := a + b
+	if !sum.inRange() {
+		return 0, ErrAmountOutOfRange
+	}
+	return sum, nil
+}
+
+// Sub returns the difference of the amounts.  ErrAmountOutOfRange is returned
+// if either amount or the difference exceeds MaxSatoshi in magnitude.  The
+// difference may be negative, so callers subtracting a fee from a sum of
+// inputs must check the sign of the result themselves.
+func (a Amount) Sub(b Amount) (Amount, error) {
+	if !a.inRange() || !b.inRange() {
+		return 0, ErrAmountOutOfRange
+	}
+	diff := a - b
+	if !diff.inRange() {
+		return 0, ErrAmountOutOfRange
+	}
+	return diff, nil
+}
+
+// MulInt returns the amount multiplied by n.  ErrAmountOutOfRange is returned
+// if the amount or the product exceeds MaxSatoshi in magnitude.
+func (a Amount) MulInt(n int64) (Amount, error) {
+	if !a.inRange() {
+		return 0, ErrAmountOutOfRange
+	}
+
+	// Check the magnitude of the product by division so that the
+	// multiplication can't overflow.
+	absA, absN := int64(a), n
+	if absA < 0 {
+		absA = -absA
+	}
+	if absN < 0 {
+		absN = -absN
+	}
+	if absA != 0 && (absN < 0 || absN > MaxSatoshi/absA) {
+		return 0, ErrAmountOutOfRange
+	}
+
+	return a * Amount(n), nil
+}
+
+// Div returns the amount divided by n, truncated toward zero.
+// ErrAmountDivideByZero is returned if n is zero, and ErrAmountOutOfRange if
+// the amount exceeds MaxSatoshi in magnitude.
+func (a Amount) Div(n int64) (Amount, error) {
+	if n == 0 {
+		return 0, ErrAmountDivideByZero
+	}
+	if !a.inRange() {
+		return 0, ErrAmountOutOfRange
+	}
+	return a / Amount(n), nil
+}
@@ -5,7 +5,8 @@
 package monautil_test
 
 import (
-	//"math"
+	"encoding/json"
+	"math"
 	"testing"
 
 	. "github.com/monasuite/monautil"
@@ -324,3 +325,372 @@ func TestAmountMulF64(t *testing.T) {
 		}
 	}
 }
+
+func TestParseAmount(t *testing.T) {
+	tests := []struct {
+		name     string
+		s        string
+		expected Amount
+		err      error
+	}{
+		{name: "plain monacoin", s: "1.23456789", expected: 123456789},
+		{name: "monacoin suffix", s: "1.23456789 MONA", expected: 123456789},
+		{name: "no space", s: "150mMONA", expected: 15000000},
+		{name: "milli", s: "150 mMONA", expected: 15000000},
+		{name: "kilo", s: "0.001 kMONA", expected: 1e8},
+		{name: "mega", s: "105.12 MMONA", expected: MaxSatoshi},
+		{name: "micro", s: "1.5 μMONA", expected: 150},
+		{name: "micro ascii", s: "1.5 uMONA", expected: 150},
+		{name: "watanabe", s: "100 Watanabe", expected: 100},
+		{name: "negative", s: "-0.5", expected: -5e7},
+		{name: "plus sign", s: "+2", expected: 2e8},
+		{name: "leading dot", s: ".5", expected: 5e7},
+		{name: "surrounding space", s: " 1 MONA ", expected: 1e8},
+		{name: "trailing zeros", s: "1.000000000000", expected: 1e8},
+		{name: "sub-watanabe", s: "0.000000001", err: ErrAmountPrecision},
+		{name: "fractional watanabe", s: "0.5 Watanabe", err: ErrAmountPrecision},
+		{name: "exceeds max", s: "105120000.00000001", err: ErrAmountOutOfRange},
+		{name: "exceeds min", s: "-105.13 MMONA", err: ErrAmountOutOfRange},
+		{name: "unknown unit", s: "1 BTC", err: ErrMalformedAmount},
+		{name: "exponent", s: "1e8", err: ErrMalformedAmount},
+		{name: "two dots", s: "1.2.3", err: ErrMalformedAmount},
+		{name: "two signs", s: "--1", err: ErrMalformedAmount},
+		{name: "inner sign", s: "1-2", err: ErrMalformedAmount},
+		{name: "only dot", s: ".", err: ErrMalformedAmount},
+		{name: "empty", s: "", err: ErrMalformedAmount},
+		{name: "unit only", s: "MONA", err: ErrMalformedAmount},
+	}
+
+	for _, test := range tests {
+		a, err := ParseAmount(test.s)
+		if err != test.err {
+			t.Errorf("%v: got error %v, want %v", test.name, err, test.err)
+			continue
+		}
+		if a != test.expected {
+			t.Errorf("%v: got amount %d, want %d", test.name, int64(a),
+				int64(test.expected))
+		}
+	}
+}
+
+func TestAmountFormatter(t *testing.T) {
+	tests := []struct {
+		name      string
+		amount    Amount
+		formatter AmountFormatter
+		s         string
+	}{
+		{
+			name:      "exact like Format",
+			amount:    44433322211100,
+			formatter: *NewAmountFormatter(AmountBTC),
+			s:         "444333.222111 MONA",
+		},
+		{
+			name:   "fixed decimals",
+			amount: 150000000,
+			formatter: AmountFormatter{
+				Unit:     AmountBTC,
+				Decimals: ExactDecimals,
+			},
+			s: "1.50000000 MONA",
+		},
+		{
+			name:   "two decimals rounded up",
+			amount: 1005000000,
+			formatter: AmountFormatter{
+				Unit:     AmountBTC,
+				Decimals: 1,
+			},
+			s: "10.1 MONA",
+		},
+		{
+			name:   "negative rounded away from zero",
+			amount: -125,
+			formatter: AmountFormatter{
+				Unit:     AmountMicroBTC,
+				Decimals: 1,
+			},
+			s: "-1.3 μMONA",
+		},
+		{
+			name:   "negative rounded to zero",
+			amount: -1,
+			formatter: AmountFormatter{
+				Unit:     AmountBTC,
+				Decimals: 2,
+				OmitUnit: true,
+			},
+			s: "0.00",
+		},
+		{
+			name:   "padded",
+			amount: 1,
+			formatter: AmountFormatter{
+				Unit:     AmountSatoshi,
+				Decimals: 2,
+			},
+			s: "1.00 Watanabe",
+		},
+		{
+			name:   "trimmed to integer",
+			amount: 2e8,
+			formatter: AmountFormatter{
+				Unit:              AmountBTC,
+				Decimals:          4,
+				TrimTrailingZeros: true,
+			},
+			s: "2 MONA",
+		},
+		{
+			name:   "locale separators",
+			amount: MaxSatoshi - 1,
+			formatter: AmountFormatter{
+				Unit:               AmountBTC,
+				Decimals:           ExactDecimals,
+				DecimalSeparator:   ",",
+				ThousandsSeparator: ".",
+			},
+			s: "105.119.999,99999999 MONA",
+		},
+		{
+			name:   "grouped watanabe",
+			amount: 123456,
+			formatter: AmountFormatter{
+				Unit:               AmountSatoshi,
+				Decimals:           ExactDecimals,
+				ThousandsSeparator: ",",
+				OmitUnit:           true,
+			},
+			s: "123,456",
+		},
+		{
+			name:   "most negative amount",
+			amount: math.MinInt64,
+			formatter: AmountFormatter{
+				Unit:     AmountBTC,
+				Decimals: ExactDecimals,
+			},
+			s: "-92233720368.54775808 MONA",
+		},
+		{
+			name:   "unit below watanabe",
+			amount: 12,
+			formatter: AmountFormatter{
+				Unit:     AmountUnit(-10),
+				Decimals: ExactDecimals,
+			},
+			s: "1200 1e-10 MONA",
+		},
+		{
+			name:   "unit far above amount",
+			amount: math.MaxInt64,
+			formatter: AmountFormatter{
+				Unit: AmountUnit(20),
+			},
+			s: "0 1e20 MONA",
+		},
+	}
+
+	for _, test := range tests {
+		s := test.formatter.Format(test.amount)
+		if s != test.s {
+			t.Errorf("%v: got %q, want %q", test.name, s, test.s)
+		}
+	}
+
+	// The default formatter matches Format for every unit.
+	for _, u := range []AmountUnit{AmountMegaBTC, AmountKiloBTC, AmountBTC,
+		AmountMilliBTC, AmountMicroBTC, AmountSatoshi} {
+
+		a := Amount(-44433322211100)
+		if s := NewAmountFormatter(u).Format(a); s != a.Format(u) {
+			t.Errorf("%v: got %q, want %q", u, s, a.Format(u))
+		}
+	}
+}
+
+func TestAmountMarshal(t *testing.T) {
+	type wrapper struct {
+		Amount Amount `json:"amount"`
+	}
+
+	tests := []struct {
+		name   string
+		amount Amount
+		json   string
+		text   string
+	}{
+		{"zero", 0, `{"amount":0}`, "0 MONA"},
+		{"one watanabe", 1, `{"amount":0.00000001}`, "0.00000001 MONA"},
+		{"fraction", 150000000, `{"amount":1.5}`, "1.5 MONA"},
+		{"negative", -MaxSatoshi, `{"amount":-105120000}`, "-105120000 MONA"},
+	}
+
+	for _, test := range tests {
+		b, err := json.Marshal(wrapper{test.amount})
+		if err != nil {
+			t.Errorf("%v: Marshal: unexpected error: %v", test.name, err)
+			continue
+		}
+		if string(b) != test.json {
+			t.Errorf("%v: got JSON %s, want %s", test.name, b, test.json)
+		}
+		var w wrapper
+		if err := json.Unmarshal(b, &w); err != nil {
+			t.Errorf("%v: Unmarshal: unexpected error: %v", test.name, err)
+			continue
+		}
+		if w.Amount != test.amount {
+			t.Errorf("%v: JSON round trip got %v, want %v", test.name,
+				w.Amount, test.amount)
+		}
+
+		text, err := test.amount.MarshalText()
+		if err != nil {
+			t.Errorf("%v: MarshalText: unexpected error: %v", test.name, err)
+			continue
+		}
+		if string(text) != test.text {
+			t.Errorf("%v: got text %s, want %s", test.name, text, test.text)
+		}
+		var a Amount
+		if err := a.UnmarshalText(text); err != nil {
+			t.Errorf("%v: UnmarshalText: unexpected error: %v", test.name, err)
+			continue
+		}
+		if a != test.amount {
+			t.Errorf("%v: text round trip got %v, want %v", test.name, a,
+				test.amount)
+		}
+	}
+
+	unmarshalTests := []struct {
+		name   string
+		json   string
+		amount Amount
+		err    error
+	}{
+		{"exponent", `{"amount":1e-8}`, 1, nil},
+		{"string", `{"amount":"150 mMONA"}`, 15000000, nil},
+		{"null", `{"amount":null}`, 0, nil},
+		{"sub-watanabe", `{"amount":0.000000001}`, 0, ErrAmountPrecision},
+		{"out of range", `{"amount":"105120001"}`, 0, ErrAmountOutOfRange},
+	}
+
+	for _, test := range unmarshalTests {
+		var w wrapper
+		err := json.Unmarshal([]byte(test.json), &w)
+		if err != test.err {
+			t.Errorf("%v: got error %v, want %v", test.name, err, test.err)
+			continue
+		}
+		if w.Amount != test.amount {
+			t.Errorf("%v: got %v, want %v", test.name, w.Amount,
+				test.amount)
+		}
+	}
+}
+
+func TestAmountCheckedArithmetic(t *testing.T) {
+	tests := []struct {
+		name string
+		op   func() (Amount, error)
+		res  Amount
+		err  error
+	}{
+		{
+			name: "add",
+			op:   func() (Amount, error) { return Amount(1e8).Add(5e7) },
+			res:  15e7,
+		},
+		{
+			name: "add to max",
+			op:   func() (Amount, error) { return Amount(MaxSatoshi - 1).Add(1) },
+			res:  MaxSatoshi,
+		},
+		{
+			name: "add beyond max",
+			op:   func() (Amount, error) { return Amount(MaxSatoshi).Add(1) },
+			err:  ErrAmountOutOfRange,
+		},
+		{
+			name: "add overflowing int64",
+			op:   func() (Amount, error) { return Amount(math.MaxInt64).Add(1) },
+			err:  ErrAmountOutOfRange,
+		},
+		{
+			name: "sub to negative",
+			op:   func() (Amount, error) { return Amount(1000).Sub(1500) },
+			res:  -500,
+		},
+		{
+			name: "sub beyond min",
+			op:   func() (Amount, error) { return Amount(-MaxSatoshi).Sub(1) },
+			err:  ErrAmountOutOfRange,
+		},
+		{
+			name: "sub underflowing int64",
+			op:   func() (Amount, error) { return Amount(0).Sub(math.MinInt64) },
+			err:  ErrAmountOutOfRange,
+		},
+		{
+			name: "mul",
+			op:   func() (Amount, error) { return Amount(-3).MulInt(4) },
+			res:  -12,
+		},
+		{
+			name: "mul to max",
+			op:   func() (Amount, error) { return Amount(MaxSatoshi / 2).MulInt(2) },
+			res:  MaxSatoshi,
+		},
+		{
+			name: "mul beyond max",
+			op:   func() (Amount, error) { return Amount(MaxSatoshi/2 + 1).MulInt(-2) },
+			err:  ErrAmountOutOfRange,
+		},
+		{
+			name: "mul overflowing int64",
+			op:   func() (Amount, error) { return Amount(1 << 32).MulInt(1 << 32) },
+			err:  ErrAmountOutOfRange,
+		},
+		{
+			name: "mul by min int64",
+			op:   func() (Amount, error) { return Amount(1).MulInt(math.MinInt64) },
+			err:  ErrAmountOutOfRange,
+		},
+		{
+			name: "mul zero by min int64",
+			op:   func() (Amount, error) { return Amount(0).MulInt(math.MinInt64) },
+			res:  0,
+		},
+		{
+			name: "div truncated",
+			op:   func() (Amount, error) { return Amount(-7).Div(2) },
+			res:  -3,
+		},
+		{
+			name: "div by zero",
+			op:   func() (Amount, error) { return Amount(7).Div(0) },
+			err:  ErrAmountDivideByZero,
+		},
+		{
+			name: "div out of range",
+			op:   func() (Amount, error) { return Amount(math.MaxInt64).Div(2) },
+			err:  ErrAmountOutOfRange,
+		},
+	}
+
+	for _, test := range tests {
+		res, err := test.op()
+		if err != test.err {
+			t.Errorf("%v: got error %v, want %v", test.name, err, test.err)
+			continue
+		}
+		if res != test.res {
+			t.Errorf("%v: got %d, want %d", test.name, int64(res),
+				int64(test.res))
+		}
+	}
+}
@@ -0,0 +1,154 @@
+// Copyright (c) 2026 The monasuite developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package monautil
+
+import (
+	"strconv"
+	"strings"
+)
+
+// ExactDecimals is the value of AmountFormatter.Decimals which formats
+// amounts with as many decimal places as the unit needs to represent a
+// watanabe, so amounts are never rounded.
+const ExactDecimals = -1
+
+// AmountFormatter formats amounts using exact integer math, with the
+// separators and number of decimal places configured for a locale or
+// display.
+type AmountFormatter struct {
+	// Unit is the unit the amount is formatted in.
+	Unit AmountUnit
+
+	// Decimals is the number of decimal places shown.  Amounts that are
+	// more precise are rounded half away from zero, and amounts that are
+	// less precise are padded with zeros.  ExactDecimals shows as many
+	// decimal places as the unit needs.
+	Decimals int
+
+	// TrimTrailingZeros removes the zeros at the end of the decimal places,
+	// along with the decimal separator if no decimal places are left.
+	TrimTrailingZeros bool
+
+	// DecimalSeparator separates the integer part from the decimal places.
+	// A period is used if it is empty.
+	DecimalSeparator string
+
+	// ThousandsSeparator is inserted between each group of three digits
+	// of the integer part.  Digits are not grouped if it is empty.
+	ThousandsSeparator string
+
+	// OmitUnit leaves out the unit, which is otherwise appended after a
+	// space.
+	OmitUnit bool
+}
+
+// NewAmountFormatter returns a formatter for the passed unit which formats
+// amounts the same way as Amount.Format: exactly, without trailing zeros and
+// without grouping digits.
+func NewAmountFormatter(u AmountUnit) *AmountFormatter {
+	return &AmountFormatter{
+		Unit:              u,
+		Decimals:          ExactDecimals,
+		TrimTrailingZeros: true,
+		DecimalSeparator:  ".",
+	}
+}
+
+// Format formats the amount according to the formatter's options.
+func (f *AmountFormatter) Format(a Amount) string {
+	// The magnitude is kept as an unsigned integer so the most negative
+	// amount doesn't overflow.
+	abs := uint64(a)
+	if a < 0 {
+		abs = -abs
+	}
+
+	// prec is the number of decimal places of abs when counted in the
+	// formatter's unit.
+	prec := int(f.Unit) + 8
+	decimals := f.Decimals
+	if decimals < 0 {
+		decimals = prec
+		if decimals < 0 {
+			decimals = 0
+		}
+	}
+
+	// Round off the decimal places that aren't shown.
+	if prec > decimals {
+		if prec-decimals > 19 {
+			// The divisor exceeds twice any uint64, so the amount
+			// always rounds to zero.
+			abs = 0
+		} else {
+			div := uint64(1)
+			for i := 0; i < prec-decimals; i++ {
+				div *= 10
+			}
+			rem := abs % div
+			abs /= div
+			if rem >= div-rem {
+				abs++
+			}
+		}
+		prec = decimals
+	}
+
+	digits := strconv.FormatUint(abs, 10)
+	if prec < 0 {
+		digits += strings.Repeat("0", -prec)
+		prec = 0
+	}
+	if len(digits) <= prec {
+		digits = strings.Repeat("0", prec-len(digits)+1) + digits
+	}
+	intPart := digits[:len(digits)-prec]
+	fraction := digits[len(digits)-prec:] +
+		strings.Repeat("0", decimals-prec)
+	if f.TrimTrailingZeros {
+		fraction = strings.TrimRight(fraction, "0")
+	}
+
+	var b strings.Builder
+	if a < 0 && abs != 0 {
+		b.WriteByte('-')
+	}
+	b.WriteString(groupThousands(intPart, f.ThousandsSeparator))
+	if fraction != "" {
+		if f.DecimalSeparator == "" {
+			b.WriteByte('.')
+		} else {
+			b.WriteString(f.DecimalSeparator)
+		}
+		b.WriteString(fraction)
+	}
+	if !f.OmitUnit {
+		b.WriteByte(' ')
+		b.WriteString(f.Unit.String())
+	}
+
+	return b.String()
+}
+
+// groupThousands inserts the separator between each group of three digits,
+// counted from the right.
+func groupThousands(digits, sep string) string {
+	if sep == "" || len(digits) <= 3 {
+		return digits
+	}
+
+	var b strings.Builder
+	first := len(digits) % 3
+	if first == 0 {
+		first = 3
+	}
+	b.WriteString(digits[:first])
+	for i := first; i < len(digits); i += 3 {
+		b.WriteString(sep)
+		b.WriteString(digits[i : i+3])
+	}
+
+	return b.String()
+}
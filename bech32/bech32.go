@@ -116,15 +116,19 @@ func bech32Polymod(hrp string, values, checksum []byte) int {
 
 // writeBech32Checksum calculates the checksum data expected for a string that
 // will have the given hrp and payload data and writes it to the provided string
-// builder.
+// builder.  The version determines which checksum constant is used.
 //
 // The payload data MUST be encoded as a base 32 (5 bits per element) byte slice
 // and the hrp MUST only use the allowed character set (ascii chars between 33
 // and 126), otherwise the results are undefined.
 //
-// For more details on the checksum calculation, please refer to BIP 173.
-func writeBech32Checksum(hrp string, data []byte, bldr *strings.Builder) {
-	polymod := bech32Polymod(hrp, data, nil) ^ 1
+// For more details on the checksum calculation, please refer to BIP 173 and
+// BIP 350.
+func writeBech32Checksum(hrp string, data []byte, bldr *strings.Builder,
+	version Version) {
+
+	bech32Const := VersionToConsts[version]
+	polymod := bech32Polymod(hrp, data, nil) ^ bech32Const
 	for i := 0; i < 6; i++ {
 		b := byte((polymod >> uint(5*(5-i))) & 31)
 
@@ -137,38 +141,42 @@ func writeBech32Checksum(hrp string, data []byte, bldr *strings.Builder) {
 
 // bech32VerifyChecksum verifies whether the bech32 string specified by the
 // provided hrp and payload data (encoded as 5 bits per element byte slice) has
-// the correct checksum suffix.
+// a correct checksum suffix for either the original bech32 or the bech32m
+// variant.  The version of the matching checksum is returned along with a
+// flag indicating whether any checksum matched.
 //
 // Data MUST have more than 6 elements, otherwise this function panics.
 //
-// For more details on the checksum verification, please refer to BIP 173.
-func bech32VerifyChecksum(hrp string, data []byte) bool {
+// For more details on the checksum verification, please refer to BIP 173 and
+// BIP 350.
+func bech32VerifyChecksum(hrp string, data []byte) (Version, bool) {
 	checksum := data[len(data)-6:]
 	values := data[:len(data)-6]
 	polymod := bech32Polymod(hrp, values, checksum)
-	return polymod == 1
+
+	version, ok := ConstsToVersion[polymod]
+	if !ok {
+		return VersionUnknown, false
+	}
+	return version, true
 }
 
-// DecodeNoLimit decodes a bech32 encoded string, returning the human-readable
-// part and the data part excluding the checksum.  This function does NOT
-// validate against the BIP-173 maximum length allowed for bech32 strings and
-// is meant for use in custom applications (such as lightning network payment
-// requests), NOT on-chain addresses.
-//
-// Note that the returned data is 5-bit (base32) encoded and the human-readable
-// part will be lowercase.
-func DecodeNoLimit(bech string) (string, []byte, error) {
+// decodeNoLimit is the internal version of DecodeNoLimit that also returns
+// the bech32 version of the checksum that matched.  Bech32m checksums are only
+// accepted when allowM is set.  When the checksum is invalid, the returned
+// error describes the expected checksum for the original bech32 version.
+func decodeNoLimit(bech string, allowM bool) (string, []byte, Version, error) {
 	// The minimum allowed size of a bech32 string is 8 characters, since it
 	// needs a non-empty HRP, a separator, and a 6 character checksum.
 	if len(bech) < 8 {
-		return "", nil, ErrInvalidLength(len(bech))
+		return "", nil, VersionUnknown, ErrInvalidLength(len(bech))
 	}
 
 	// Only	ASCII characters between 33 and 126 are allowed.
 	var hasLower, hasUpper bool
 	for i := 0; i < len(bech); i++ {
 		if bech[i] < 33 || bech[i] > 126 {
-			return "", nil, ErrInvalidCharacter(bech[i])
+			return "", nil, VersionUnknown, ErrInvalidCharacter(bech[i])
 		}
 
 		// The characters must be either all lowercase or all uppercase. Testing
@@ -176,7 +184,7 @@ func DecodeNoLimit(bech string) (string, []byte, error) {
 		hasLower = hasLower || (bech[i] >= 97 && bech[i] <= 122)
 		hasUpper = hasUpper || (bech[i] >= 65 && bech[i] <= 90)
 		if hasLower && hasUpper {
-			return "", nil, ErrMixedCase{}
+			return "", nil, VersionUnknown, ErrMixedCase{}
 		}
 	}
 
@@ -191,7 +199,7 @@ func DecodeNoLimit(bech string) (string, []byte, error) {
 	// last 6 characters of the string (since checksum cannot contain '1').
 	one := strings.LastIndexByte(bech, '1')
 	if one < 1 || one+7 > len(bech) {
-		return "", nil, ErrInvalidSeparatorIndex(one)
+		return "", nil, VersionUnknown, ErrInvalidSeparatorIndex(one)
 	}
 
 	// The human-readable part is everything before the last '1'.
@@ -202,12 +210,13 @@ func DecodeNoLimit(bech string) (string, []byte, error) {
 	// 'charset'.
 	decoded, err := toBytes(data)
 	if err != nil {
-		return "", nil, err
+		return "", nil, VersionUnknown, err
 	}
 
 	// Verify if the checksum (stored inside decoded[:]) is valid, given the
 	// previously decoded hrp.
-	if !bech32VerifyChecksum(hrp, decoded) {
+	bechVersion, ok := bech32VerifyChecksum(hrp, decoded)
+	if !ok || (bechVersion != Version0 && !allowM) {
 		// Invalid checksum. Calculate what it should have been, so that the
 		// error contains this information.
 
@@ -218,22 +227,39 @@ func DecodeNoLimit(bech string) (string, []byte, error) {
 		// Calculate the expected checksum, given the hrp and payload data.
 		var expectedBldr strings.Builder
 		expectedBldr.Grow(6)
-		writeBech32Checksum(hrp, payload, &expectedBldr)
+		writeBech32Checksum(hrp, payload, &expectedBldr, Version0)
 		expected := expectedBldr.String()
 
 		err = ErrInvalidChecksum{
 			Expected: expected,
 			Actual:   actual,
 		}
-		return "", nil, err
+		return "", nil, VersionUnknown, err
 	}
 
 	// We exclude the last 6 bytes, which is the checksum.
-	return hrp, decoded[:len(decoded)-6], nil
+	return hrp, decoded[:len(decoded)-6], bechVersion, nil
+}
+
+// DecodeNoLimit decodes a bech32 encoded string, returning the human-readable
+// part and the data part excluding the checksum.  This function does NOT
+// validate against the BIP-173 maximum length allowed for bech32 strings and
+// is meant for use in custom applications (such as lightning network payment
+// requests), NOT on-chain addresses.
+//
+// Only strings using the original BIP-173 checksum are accepted.  Use
+// DecodeGeneric to also accept bech32m strings.
+//
+// Note that the returned data is 5-bit (base32) encoded and the human-readable
+// part will be lowercase.
+func DecodeNoLimit(bech string) (string, []byte, error) {
+	hrp, data, _, err := decodeNoLimit(bech, false)
+	return hrp, data, err
 }
 
 // Decode decodes a bech32 encoded string, returning the human-readable part and
-// the data part excluding the checksum.
+// the data part excluding the checksum.  Only strings using the original
+// BIP-173 checksum are accepted.
 //
 // Note that the returned data is 5-bit (base32) encoded and the human-readable
 // part will be lowercase.
@@ -246,11 +272,25 @@ func Decode(bech string) (string, []byte, error) {
 	return DecodeNoLimit(bech)
 }
 
-// Encode encodes a byte slice into a bech32 string with the given
-// human-readable part (HRP).  The HRP will be converted to lowercase if needed
-// since mixed cased encodings are not permitted and lowercase is used for
-// checksum purposes.  Note that the bytes must each encode 5 bits (base32).
-func Encode(hrp string, data []byte) (string, error) {
+// DecodeGeneric is identical to the existing Decode method, but will also
+// accept strings using the bech32m checksum defined in BIP 350.  The version
+// of the checksum that matched is returned along with the decoded data.
+//
+// Note that the returned data is 5-bit (base32) encoded and the human-readable
+// part will be lowercase.
+func DecodeGeneric(bech string) (string, []byte, Version, error) {
+	// The maximum allowed length for a bech32 string is 90.
+	if len(bech) > 90 {
+		return "", nil, VersionUnknown, ErrInvalidLength(len(bech))
+	}
+
+	return decodeNoLimit(bech, true)
+}
+
+// encodeGeneric is the base bech32 encoding function that is aware of the
+// existence of the checksum versions.  This method is private, as the Encode
+// and EncodeM methods are intended to be used instead.
+func encodeGeneric(hrp string, data []byte, version Version) (string, error) {
 	// The resulting bech32 string is the concatenation of the lowercase hrp,
 	// the separator 1, data and the 6-byte checksum.
 	hrp = strings.ToLower(hrp)
@@ -268,11 +308,25 @@ func Encode(hrp string, data []byte) (string, error) {
 	}
 
 	// Calculate and write the checksum of the data.
-	writeBech32Checksum(hrp, data, &bldr)
+	writeBech32Checksum(hrp, data, &bldr, version)
 
 	return bldr.String(), nil
 }
 
+// Encode encodes a byte slice into a bech32 string with the given
+// human-readable part (HRP).  The HRP will be converted to lowercase if needed
+// since mixed cased encodings are not permitted and lowercase is used for
+// checksum purposes.  Note that the bytes must each encode 5 bits (base32).
+func Encode(hrp string, data []byte) (string, error) {
+	return encodeGeneric(hrp, data, Version0)
+}
+
+// EncodeM is the exactly same as the Encode method, but it uses the new
+// bech32m constant defined in BIP 350 when calculating the checksum.
+func EncodeM(hrp string, data []byte) (string, error) {
+	return encodeGeneric(hrp, data, VersionM)
+}
+
 // ConvertBits converts a byte slice where each byte is encoding fromBits bits,
 // to a byte slice where each byte is encoding toBits bits.
 func ConvertBits(data []byte, fromBits, toBits uint8, pad bool) ([]byte, error) {
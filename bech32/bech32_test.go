@@ -86,6 +86,101 @@ func TestBech32(t *testing.T) {
 	}
 }
 
+// TestBech32M tests that the following set of strings, based on the test
+// vectors in BIP-350 are either valid or invalid using the new bech32m
+// checksum algo.  Those that are valid should be decoded and re-encoded to the
+// same string, and reported as the bech32m version.
+func TestBech32M(t *testing.T) {
+	tests := []struct {
+		str           string
+		expectedError error
+	}{
+		{"A1LQFN3A", nil},
+		{"a1lqfn3a", nil},
+		{"an83characterlonghumanreadablepartthatcontainsthetheexcludedcharactersbioandnumber11sg7hg6", nil},
+		{"abcdef1l7aum6echk45nj3s0wdvt2fg8x9yrzpqzd3ryx", nil},
+		{"11llllllllllllllllllllllllllllllllllllllllllllllllllllllllllllllllllllllllllllllllllludsr8", nil},
+		{"split1checkupstagehandshakeupstreamerranterredcaperredlc445v", nil},
+		{"?1v759aa", nil},
+
+		// Additional test vectors used in bitcoin core
+		{"\x201xj0phk", ErrInvalidCharacter(' ')},
+		{"\x7f1g6xzxy", ErrInvalidCharacter(0x7f)},
+		{"\x801vctc34", ErrInvalidCharacter(0x80)},
+		{"an84characterslonghumanreadablepartthatcontainsthetheexcludedcharactersbioandnumber11d6pts4", ErrInvalidLength(91)},
+		{"qyrz8wqd2c9m", ErrInvalidSeparatorIndex(-1)},
+		{"1qyrz8wqd2c9m", ErrInvalidSeparatorIndex(0)},
+		{"y1b0jsk6g", ErrNonCharsetChar(98)},
+		{"lt1igcx5c0", ErrNonCharsetChar(105)},
+		{"in1muywd", ErrInvalidSeparatorIndex(2)},
+		{"mm1crxm3i", ErrNonCharsetChar(105)},
+		{"au1s5cgom", ErrNonCharsetChar(111)},
+		{"M1VUXWEZ", ErrInvalidChecksum{"mzl49c", "vuxwez"}},
+		{"16plkw9", ErrInvalidLength(7)},
+		{"1p2gdwpf", ErrInvalidSeparatorIndex(0)},
+	}
+
+	for i, test := range tests {
+		str := test.str
+		hrp, decoded, version, err := DecodeGeneric(str)
+		if test.expectedError != err {
+			t.Errorf("%d: (%v) expected decoding error %v "+
+				"instead got %v", i, str, test.expectedError, err)
+			continue
+		}
+
+		if err != nil {
+			// End test case here if a decoding error was expected.
+			continue
+		}
+
+		if version != VersionM {
+			t.Errorf("%d: expected version %v, got %v", i, VersionM,
+				version)
+			continue
+		}
+
+		// The original bech32 decoder must reject bech32m strings.
+		if _, _, err := Decode(str); err == nil {
+			t.Errorf("%d: expected bech32 decoding of bech32m "+
+				"string to fail", i)
+			continue
+		}
+
+		// Check that it encodes to the same string, using bech32 m.
+		encoded, err := EncodeM(hrp, decoded)
+		if err != nil {
+			t.Errorf("encoding failed: %v", err)
+		}
+
+		if encoded != strings.ToLower(str) {
+			t.Errorf("expected data to encode to %v, but got %v",
+				str, encoded)
+		}
+
+		// Flip a bit in the string an make sure it is caught.
+		pos := strings.LastIndexAny(str, "1")
+		flipped := str[:pos+1] + string((str[pos+1] ^ 1)) + str[pos+2:]
+		_, _, _, err = DecodeGeneric(flipped)
+		if err == nil {
+			t.Error("expected decoding to fail")
+		}
+	}
+}
+
+// TestDecodeGenericVersion ensures DecodeGeneric reports the original bech32
+// version for strings using the BIP-173 checksum.
+func TestDecodeGenericVersion(t *testing.T) {
+	const str = "split1checkupstagehandshakeupstreamerranterredcaperred2y9e3w"
+	_, _, version, err := DecodeGeneric(str)
+	if err != nil {
+		t.Fatalf("unexpected decode error: %v", err)
+	}
+	if version != Version0 {
+		t.Fatalf("expected version %v, got %v", Version0, version)
+	}
+}
+
 // TestMixedCaseEncode ensures mixed case HRPs are converted to lowercase as
 // expected when encoding and that decoding the produced encoding when converted
 // to all uppercase produces the lowercase HRP and original data.
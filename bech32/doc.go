@@ -4,12 +4,18 @@
 
 /*
 Package bech32 provides a Go implementation of the bech32 format specified in
-BIP 173.
+BIP 173 and of its bech32m variant specified in BIP 350.
 
 Bech32 strings consist of a human-readable part (hrp), followed by the
 separator 1, then a checksummed data part encoded using the 32 characters
 "qpzry9x8gf2tvdw0s3jn54khce6mua7l".
 
+The bech32m variant only differs in the constant used when computing the
+checksum.  Encode and Decode operate on the original format, EncodeM produces
+bech32m strings and DecodeGeneric accepts either, reporting which Version the
+checksum matched.
+
 More info: https://github.com/monacoin/bips/blob/master/bip-0173.mediawiki
+and https://github.com/bitcoin/bips/blob/master/bip-0350.mediawiki
 */
 package bech32
@@ -0,0 +1,55 @@
+// Copyright (c) 2026 The monasuite developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package bech32
+
+// Version defines the current set of bech32 versions.
+type Version uint8
+
+const (
+	// Version0 defines the original bech32 version specified in BIP 173.
+	Version0 Version = iota
+
+	// VersionM is the new bech32 version defined in BIP 350, also known as
+	// bech32m.
+	VersionM
+
+	// VersionUnknown denotes an unknown bech version.
+	VersionUnknown
+)
+
+const (
+	// bech32ChecksumConst is the constant the polymod of a valid original
+	// bech32 string must equal.
+	bech32ChecksumConst = 1
+
+	// bech32mChecksumConst is the constant the polymod of a valid bech32m
+	// string must equal.
+	bech32mChecksumConst = 0x2bc830a3
+)
+
+// VersionToConsts maps bech32 versions to the checksum constant to be used
+// when encoding, and asserted to match when decoding.
+var VersionToConsts = map[Version]int{
+	Version0: bech32ChecksumConst,
+	VersionM: bech32mChecksumConst,
+}
+
+// ConstsToVersion maps a bech32 constant to the version it's associated with.
+var ConstsToVersion = map[int]Version{
+	bech32ChecksumConst:  Version0,
+	bech32mChecksumConst: VersionM,
+}
+
+// String returns a human-readable name for the bech32 version.
+func (v Version) String() string {
+	switch v {
+	case Version0:
+		return "bech32"
+	case VersionM:
+		return "bech32m"
+	default:
+		return "unknown"
+	}
+}
@@ -0,0 +1,278 @@
+// Copyright (c) 2026 The monasuite developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package bip38
+
+// References:
+//   [BIP38]: Passphrase-protected private key
+//   https://github.com/bitcoin/bips/blob/master/bip-0038.mediawiki
+
+import (
+	"crypto/aes"
+	"errors"
+	"math/big"
+
+	"github.com/monasuite/monad/btcec"
+	"github.com/monasuite/monad/chaincfg"
+	"github.com/monasuite/monad/chaincfg/chainhash"
+	"github.com/monasuite/monautil"
+	"github.com/monasuite/monautil/base58"
+	"golang.org/x/crypto/scrypt"
+	"golang.org/x/text/unicode/norm"
+)
+
+const (
+	// encryptedKeyLen is the length of a decoded encrypted key, including
+	// its two byte prefix.
+	encryptedKeyLen = 39
+
+	// prefixNonECMultiplied is the second byte of the prefix of keys
+	// encrypted without EC multiplication.  The first byte of both
+	// prefixes is 0x01, which is passed to base58 as the version.
+	prefixNonECMultiplied = 0x42
+
+	// prefixECMultiplied is the second byte of the prefix of keys
+	// encrypted with EC multiplication.
+	prefixECMultiplied = 0x43
+
+	// prefixVersion is the first byte of the prefix of encrypted keys.
+	prefixVersion = 0x01
+
+	// flagNonECMultiplied is set in the flag byte of keys encrypted
+	// without EC multiplication.
+	flagNonECMultiplied = 0xc0
+
+	// flagCompressed is set in the flag byte of keys whose address is
+	// made from the compressed public key.
+	flagCompressed = 0x20
+
+	// flagLotSequence is set in the flag byte of EC multiplied keys whose
+	// owner entropy includes a lot and sequence number.
+	flagLotSequence = 0x04
+
+	// The scrypt parameters used to derive the encryption key from the
+	// passphrase, for keys encrypted without EC multiplication and for the
+	// passfactor of EC multiplied keys.
+	scryptN = 16384
+	scryptR = 8
+	scryptP = 8
+
+	// The scrypt parameters used to derive the encryption key of EC
+	// multiplied keys from the passpoint.
+	scryptPointN = 1024
+	scryptPointR = 1
+	scryptPointP = 1
+)
+
+var (
+	// ErrMalformedKey describes an error in which a string is not a BIP38
+	// encrypted private key.
+	ErrMalformedKey = errors.New("malformed encrypted private key")
+
+	// ErrWrongPassphrase describes an error in which a key decrypted with
+	// the passphrase doesn't belong to the address the encrypted key
+	// commits to, which means the passphrase is wrong.
+	ErrWrongPassphrase = errors.New("wrong passphrase")
+)
+
+// normalizePassphrase returns the passphrase in Unicode normalization form C,
+// as required by [BIP38].
+func normalizePassphrase(passphrase string) []byte {
+	return []byte(norm.NFC.String(passphrase))
+}
+
+// addressHash returns the first four bytes of the double SHA256 of the P2PKH
+// address of the passed public key on the passed network, which salts the
+// encryption and lets the passphrase be checked on decryption.
+func addressHash(pubKey []byte, net *chaincfg.Params) ([]byte,
+	*monautil.AddressPubKeyHash, error) {
+
+	addr, err := monautil.NewAddressPubKeyHash(monautil.Hash160(pubKey), net)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return chainhash.DoubleHashB([]byte(addr.EncodeAddress()))[:4], addr, nil
+}
+
+// serializePubKey returns the serialization of the public key in the passed
+// form.
+func serializePubKey(pubKey *btcec.PublicKey, compressed bool) []byte {
+	if compressed {
+		return pubKey.SerializeCompressed()
+	}
+	return pubKey.SerializeUncompressed()
+}
+
+// encryptBlock returns the 16 byte block src xored with mask and encrypted
+// with AES-256 under key.
+func encryptBlock(key, src, mask []byte) []byte {
+	block, _ := aes.NewCipher(key)
+	buf := make([]byte, aes.BlockSize)
+	for i := range buf {
+		buf[i] = src[i] ^ mask[i]
+	}
+	block.Encrypt(buf, buf)
+	return buf
+}
+
+// decryptBlock returns the 16 byte block src decrypted with AES-256 under key
+// and xored with mask.
+func decryptBlock(key, src, mask []byte) []byte {
+	block, _ := aes.NewCipher(key)
+	buf := make([]byte, aes.BlockSize)
+	block.Decrypt(buf, src)
+	for i := range buf {
+		buf[i] ^= mask[i]
+	}
+	return buf
+}
+
+// encode returns the base58check encoding of the passed bytes, whose first
+// byte is used as the version.
+func encode(b []byte) string {
+	return base58.CheckEncode(b[1:], b[0])
+}
+
+// decode decodes a base58check encoded string of the passed length, including
+// its version byte, and returns the bytes with the version prepended.
+func decode(s string, length int) ([]byte, bool) {
+	payload, version, err := base58.CheckDecode(s)
+	if err != nil || len(payload)+1 != length {
+		return nil, false
+	}
+
+	return append([]byte{version}, payload...), true
+}
+
+// Encrypt encrypts the private key of the WIF with the passphrase, without EC
+// multiplication, and returns the encrypted key, which starts with 6P.  The
+// passed network determines the address whose hash salts the encryption, so
+// the key must be decrypted for the same network.
+func Encrypt(wif *monautil.WIF, passphrase string,
+	net *chaincfg.Params) (string, error) {
+
+	hash, _, err := addressHash(wif.SerializePubKey(), net)
+	if err != nil {
+		return "", err
+	}
+
+	derived, err := scrypt.Key(
+		normalizePassphrase(passphrase), hash, scryptN, scryptR,
+		scryptP, 64,
+	)
+	if err != nil {
+		return "", err
+	}
+	derivedHalf1, derivedHalf2 := derived[:32], derived[32:]
+
+	privKey := wif.PrivKey.Serialize()
+	flag := byte(flagNonECMultiplied)
+	if wif.CompressPubKey {
+		flag |= flagCompressed
+	}
+
+	b := make([]byte, 0, encryptedKeyLen)
+	b = append(b, prefixVersion, prefixNonECMultiplied, flag)
+	b = append(b, hash...)
+	b = append(b, encryptBlock(
+		derivedHalf2, privKey[:16], derivedHalf1[:16],
+	)...)
+	b = append(b, encryptBlock(
+		derivedHalf2, privKey[16:], derivedHalf1[16:],
+	)...)
+
+	return encode(b), nil
+}
+
+// Decrypt decrypts the passed encrypted key, which may have been encrypted
+// with or without EC multiplication, with the passphrase and returns the WIF
+// for the passed network.  ErrWrongPassphrase is returned if the decrypted
+// key doesn't match the address hash of the encrypted key, which also happens
+// if the key was encrypted for another network.
+func Decrypt(encrypted, passphrase string,
+	net *chaincfg.Params) (*monautil.WIF, error) {
+
+	b, ok := decode(encrypted, encryptedKeyLen)
+	if !ok || b[0] != prefixVersion {
+		return nil, ErrMalformedKey
+	}
+
+	var (
+		privKey *btcec.PrivateKey
+		err     error
+	)
+	flag := b[2]
+	switch b[1] {
+	case prefixNonECMultiplied:
+		if flag&^flagCompressed != flagNonECMultiplied {
+			return nil, ErrMalformedKey
+		}
+		privKey, err = decryptNonECMultiplied(b, passphrase)
+
+	case prefixECMultiplied:
+		if flag&^(flagCompressed|flagLotSequence) != 0 {
+			return nil, ErrMalformedKey
+		}
+		privKey, err = decryptECMultiplied(b, passphrase)
+
+	default:
+		return nil, ErrMalformedKey
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	compressed := flag&flagCompressed != 0
+	hash, _, err := addressHash(
+		serializePubKey(privKey.PubKey(), compressed), net,
+	)
+	if err != nil {
+		return nil, err
+	}
+	if string(hash) != string(b[3:7]) {
+		return nil, ErrWrongPassphrase
+	}
+
+	return monautil.NewWIF(privKey, net, compressed)
+}
+
+// decryptNonECMultiplied returns the private key of the passed decoded key
+// encrypted without EC multiplication.
+func decryptNonECMultiplied(b []byte,
+	passphrase string) (*btcec.PrivateKey, error) {
+
+	hash := b[3:7]
+	derived, err := scrypt.Key(
+		normalizePassphrase(passphrase), hash, scryptN, scryptR,
+		scryptP, 64,
+	)
+	if err != nil {
+		return nil, err
+	}
+	derivedHalf1, derivedHalf2 := derived[:32], derived[32:]
+
+	privKey := make([]byte, 0, 32)
+	privKey = append(privKey, decryptBlock(
+		derivedHalf2, b[7:23], derivedHalf1[:16],
+	)...)
+	privKey = append(privKey, decryptBlock(
+		derivedHalf2, b[23:39], derivedHalf1[16:],
+	)...)
+
+	return privKeyFromBytes(privKey)
+}
+
+// privKeyFromBytes returns the private key with the passed big endian
+// representation, or ErrWrongPassphrase if it is out of range, which only
+// happens for keys decrypted with the wrong passphrase.
+func privKeyFromBytes(b []byte) (*btcec.PrivateKey, error) {
+	d := new(big.Int).SetBytes(b)
+	if d.Sign() == 0 || d.Cmp(btcec.S256().N) >= 0 {
+		return nil, ErrWrongPassphrase
+	}
+
+	privKey, _ := btcec.PrivKeyFromBytes(btcec.S256(), b)
+	return privKey, nil
+}
@@ -0,0 +1,284 @@
+// Copyright (c) 2026 The monasuite developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package bip38_test
+
+import (
+	"testing"
+
+	"github.com/monasuite/monad/chaincfg"
+	"github.com/monasuite/monautil"
+	"github.com/monasuite/monautil/bip38"
+)
+
+// vectorParams returns network parameters with the address and private key
+// versions of the bitcoin main network, so the address hashes match the
+// BIP 38 test vectors.
+func vectorParams() *chaincfg.Params {
+	params := chaincfg.MainNetParams
+	params.PubKeyHashAddrID = 0x00
+	params.PrivateKeyID = 0x80
+	return &params
+}
+
+// TestVectors ensures the BIP 38 test vectors decrypt to the expected keys,
+// and that keys encrypted without EC multiplication encrypt to the expected
+// strings.
+func TestVectors(t *testing.T) {
+	tests := []struct {
+		name         string
+		passphrase   string
+		encrypted    string
+		wif          string
+		ecMultiplied bool
+	}{{
+		name:       "no compression 1",
+		passphrase: "TestingOneTwoThree",
+		encrypted:  "6PRVWUbkzzsbcVac2qwfssoUJAN1Xhrg6bNk8J7Nzm5H7kxEbn2Nh2ZoGg",
+		wif:        "5KN7MzqK5wt2TP1fQCYyHBtDrXdJuXbUzm4A9rKAteGu3Qi5CVR",
+	}, {
+		name:       "no compression 2",
+		passphrase: "Satoshi",
+		encrypted:  "6PRNFFkZc2NZ6dJqFfhRoFNMR9Lnyj7dYGrzdgXXVMXcxoKTePPX1dWByq",
+		wif:        "5HtasZ6ofTHP6HCwTqTkLDuLQisYPah7aUnSKfC7h4hMUVw2gi5",
+	}, {
+		name:       "no compression, unicode passphrase",
+		passphrase: "ϓ\u0000\U00010400\U0001f4a9",
+		encrypted:  "6PRW5o9FLp4gJDDVqJQKJFTpMvdsSGJxMYHtHaQBF3ooa8mwD69bapcDQn",
+		wif:        "5Jajm8eQ22H3pGWLEVCXyvND8dQZhiQhoLJNKjYXk9roUFTMSZ4",
+	}, {
+		name:       "compression 1",
+		passphrase: "TestingOneTwoThree",
+		encrypted:  "6PYNKZ1EAgYgmQfmNVamxyXVWHzK5s6DGhwP4J5o44cvXdoY7sRzhtpUeo",
+		wif:        "L44B5gGEpqEDRS9vVPz7QT35jcBG2r3CZwSwQ4fCewXAhAhqGVpP",
+	}, {
+		name:       "compression 2",
+		passphrase: "Satoshi",
+		encrypted:  "6PYLtMnXvfG3oJde97zRyLYFZCYizPU5T3LwgdYJz1fRhh16bU7u6PPmY7",
+		wif:        "KwYgW8gcxj1JWJXhPSu4Fqwzfhp5Yfi42mdYmMa4XqK7NJxXUSK7",
+	}, {
+		name:         "ec multiplied 1",
+		passphrase:   "TestingOneTwoThree",
+		encrypted:    "6PfQu77ygVyJLZjfvMLyhLMQbYnu5uguoJJ4kMCLqWwPEdfpwANVS76gTX",
+		wif:          "5K4caxezwjGCGfnoPTZ8tMcJBLB7Jvyjv4xxeacadhq8nLisLR2",
+		ecMultiplied: true,
+	}, {
+		name:         "ec multiplied 2",
+		passphrase:   "Satoshi",
+		encrypted:    "6PfLGnQs6VZnrNpmVKfjotbnQuaJK4KZoPFrAjx1JMJUa1Ft8gnf5WxfKd",
+		wif:          "5KJ51SgxWaAYR13zd9ReMhJpwrcX47xTJh2D3fGPG9CM8vkv5sH",
+		ecMultiplied: true,
+	}, {
+		name:         "ec multiplied with lot 1",
+		passphrase:   "MOLON LABE",
+		encrypted:    "6PgNBNNzDkKdhkT6uJntUXwwzQV8Rr2tZcbkDcuC9DZRsS6AtHts4Ypo1j",
+		wif:          "5JLdxTtcTHcfYcmJsNVy1v2PMDx432JPoYcBTVVRHpPaxUrdtf8",
+		ecMultiplied: true,
+	}, {
+		name:         "ec multiplied with lot 2",
+		passphrase:   "ΜΟΛΩΝ ΛΑΒΕ",
+		encrypted:    "6PgGWtx25kUg8QWvwuJAgorN6k9FbE25rv5dMRwu5SKMnfpfVe5mar2ngH",
+		wif:          "5KMKKuUmAkiNbA3DazMQiLfDq47qs8MAEThm4yL8R2PhV1ov33D",
+		ecMultiplied: true,
+	}}
+
+	net := vectorParams()
+	for _, test := range tests {
+		wif, err := bip38.Decrypt(test.encrypted, test.passphrase, net)
+		if err != nil {
+			t.Errorf("%s: Decrypt: unexpected error: %v", test.name,
+				err)
+			continue
+		}
+		if wif.String() != test.wif {
+			t.Errorf("%s: Decrypt: got %s, want %s", test.name, wif,
+				test.wif)
+		}
+
+		if test.ecMultiplied {
+			continue
+		}
+		encrypted, err := bip38.Encrypt(wif, test.passphrase, net)
+		if err != nil {
+			t.Errorf("%s: Encrypt: unexpected error: %v", test.name,
+				err)
+			continue
+		}
+		if encrypted != test.encrypted {
+			t.Errorf("%s: Encrypt: got %s, want %s", test.name,
+				encrypted, test.encrypted)
+		}
+	}
+}
+
+// TestConfirmationCodeVectors ensures the confirmation codes of the BIP 38
+// test vectors verify with their passphrases.
+func TestConfirmationCodeVectors(t *testing.T) {
+	tests := []struct {
+		passphrase string
+		code       string
+		addr       string
+	}{{
+		passphrase: "MOLON LABE",
+		code:       "cfrm38V8aXBn7JWA1ESmFMUn6erxeBGZGAxJPY4e36S9QWkzZKtaVqLNMgnifETYw7BPwWC9aPD",
+		addr:       "1Jscj8ALrYu2y9TD8NrpvDBugPedmbj4Yh",
+	}, {
+		passphrase: "ΜΟΛΩΝ ΛΑΒΕ",
+		code:       "cfrm38V8G4qq2ywYEFfWLD5Cc6msj9UwsG2Mj4Z6QdGJAFQpdatZLavkgRd1i4iBMdRngDqDs51",
+		addr:       "1Lurmih3KruL4xDB5FmHof38yawNtP9oGf",
+	}}
+
+	net := vectorParams()
+	for _, test := range tests {
+		addr, err := bip38.VerifyConfirmationCode(
+			test.code, test.passphrase, net,
+		)
+		if err != nil {
+			t.Errorf("%s: unexpected error: %v", test.code, err)
+			continue
+		}
+		if addr.EncodeAddress() != test.addr {
+			t.Errorf("%s: got address %s, want %s", test.code,
+				addr.EncodeAddress(), test.addr)
+		}
+
+		_, err = bip38.VerifyConfirmationCode(test.code, "wrong", net)
+		if err != bip38.ErrWrongPassphrase {
+			t.Errorf("%s: got error %v, want %v", test.code, err,
+				bip38.ErrWrongPassphrase)
+		}
+	}
+}
+
+// TestECMultiply ensures keys generated from intermediate codes decrypt with
+// the passphrase to keys of the generated address, and that their
+// confirmation codes verify.
+func TestECMultiply(t *testing.T) {
+	const passphrase = "モナコイン"
+	net := &chaincfg.MainNetParams
+
+	noLot, err := bip38.NewIntermediateCode(passphrase)
+	if err != nil {
+		t.Fatalf("NewIntermediateCode: unexpected error: %v", err)
+	}
+	withLot, err := bip38.NewIntermediateCodeWithLot(passphrase, 1024, 7)
+	if err != nil {
+		t.Fatalf("NewIntermediateCodeWithLot: unexpected error: %v", err)
+	}
+
+	// Each intermediate code is used with one of the key forms only, as
+	// scrypt makes each round slow.
+	tests := []struct {
+		intermediate string
+		compressed   bool
+	}{
+		{noLot, false},
+		{withLot, true},
+	}
+
+	for _, test := range tests {
+		if test.intermediate[:10] != "passphrase" {
+			t.Errorf("intermediate code %s doesn't start with "+
+				"passphrase", test.intermediate)
+		}
+
+		gen, err := bip38.GenerateEncryptedKey(
+			test.intermediate, test.compressed, net,
+		)
+		if err != nil {
+			t.Errorf("GenerateEncryptedKey: unexpected "+
+				"error: %v", err)
+			continue
+		}
+		if gen.EncryptedKey[:2] != "6P" ||
+			gen.ConfirmationCode[:6] != "cfrm38" {
+
+			t.Errorf("unexpected encodings %s, %s",
+				gen.EncryptedKey, gen.ConfirmationCode)
+		}
+
+		wif, err := bip38.Decrypt(gen.EncryptedKey, passphrase, net)
+		if err != nil {
+			t.Errorf("Decrypt: unexpected error: %v", err)
+			continue
+		}
+		if wif.CompressPubKey != test.compressed {
+			t.Errorf("Decrypt: got compressed %v, want %v",
+				wif.CompressPubKey, test.compressed)
+		}
+		addr, err := monautil.NewAddressPubKeyHash(
+			monautil.Hash160(wif.SerializePubKey()), net,
+		)
+		if err != nil {
+			t.Fatalf("NewAddressPubKeyHash: unexpected "+
+				"error: %v", err)
+		}
+		if addr.EncodeAddress() != gen.Address.EncodeAddress() {
+			t.Errorf("Decrypt: got key of %s, want %s", addr,
+				gen.Address)
+		}
+
+		confirmed, err := bip38.VerifyConfirmationCode(
+			gen.ConfirmationCode, passphrase, net,
+		)
+		if err != nil {
+			t.Errorf("VerifyConfirmationCode: unexpected "+
+				"error: %v", err)
+			continue
+		}
+		if confirmed.EncodeAddress() != gen.Address.EncodeAddress() {
+			t.Errorf("VerifyConfirmationCode: got %s, want %s",
+				confirmed, gen.Address)
+		}
+	}
+
+	if _, err := bip38.NewIntermediateCodeWithLot(passphrase, bip38.MaxLot+1, 0); err != bip38.ErrInvalidLotSequence {
+		t.Errorf("NewIntermediateCodeWithLot: got error %v, want %v",
+			err, bip38.ErrInvalidLotSequence)
+	}
+}
+
+// TestDecryptErrors ensures wrong passphrases, wrong networks and malformed
+// keys are detected.
+func TestDecryptErrors(t *testing.T) {
+	wif, err := monautil.DecodeWIF(
+		"T4ff2X9NeYy292nC39BjDDGaMBoXg3JzPXXcuYce8KgVmZ9RD9U1",
+	)
+	if err != nil {
+		t.Fatalf("DecodeWIF: unexpected error: %v", err)
+	}
+	encrypted, err := bip38.Encrypt(wif, "passphrase", &chaincfg.MainNetParams)
+	if err != nil {
+		t.Fatalf("Encrypt: unexpected error: %v", err)
+	}
+
+	decrypted, err := bip38.Decrypt(encrypted, "passphrase", &chaincfg.MainNetParams)
+	if err != nil {
+		t.Fatalf("Decrypt: unexpected error: %v", err)
+	}
+	if decrypted.String() != wif.String() {
+		t.Errorf("Decrypt: got %s, want %s", decrypted, wif)
+	}
+
+	tests := []struct {
+		name       string
+		encrypted  string
+		passphrase string
+		net        *chaincfg.Params
+		err        error
+	}{
+		{"wrong passphrase", encrypted, "wrong", &chaincfg.MainNetParams, bip38.ErrWrongPassphrase},
+		{"wrong network", encrypted, "passphrase", &chaincfg.TestNet4Params, bip38.ErrWrongPassphrase},
+		{"bad checksum", encrypted[:len(encrypted)-1] + "1", "passphrase", &chaincfg.MainNetParams, bip38.ErrMalformedKey},
+		{"wif", wif.String(), "passphrase", &chaincfg.MainNetParams, bip38.ErrMalformedKey},
+	}
+
+	for _, test := range tests {
+		_, err := bip38.Decrypt(test.encrypted, test.passphrase, test.net)
+		if err != test.err {
+			t.Errorf("%s: got error %v, want %v", test.name, err,
+				test.err)
+		}
+	}
+}
@@ -0,0 +1,28 @@
+// Copyright (c) 2026 The monasuite developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+/*
+Package bip38 implements passphrase-protected private keys as specified by
+BIP 38.
+
+Overview
+
+A BIP 38 encrypted key is a private key encrypted with a passphrase, encoded
+as a base58 string starting with 6P.  It is commonly printed on paper
+wallets.  The encryption key is derived from the passphrase with scrypt,
+salted with a hash of the P2PKH address of the key, which also lets the
+passphrase be checked on decryption.  Since the address depends on the
+network, keys must be decrypted with the network they were encrypted for.
+
+Keys can be encrypted in two modes.  Encrypt turns an existing WIF into an
+encrypted key.  With EC multiplication, the owner of the passphrase instead
+creates an intermediate code, starting with passphrase, and hands it to a
+party such as a paper wallet printer.  GenerateEncryptedKey then creates new
+encrypted keys from the intermediate code without learning the passphrase or
+the private keys, along with a confirmation code, starting with cfrm38, that
+proves to the owner that an encrypted key belongs to an address.
+
+Decrypt handles keys encrypted in either mode.
+*/
+package bip38
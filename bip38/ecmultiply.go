@@ -0,0 +1,373 @@
+// Copyright (c) 2026 The monasuite developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package bip38
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/binary"
+	"errors"
+	"math/big"
+
+	"github.com/monasuite/monad/btcec"
+	"github.com/monasuite/monad/chaincfg"
+	"github.com/monasuite/monad/chaincfg/chainhash"
+	"github.com/monasuite/monautil"
+	"golang.org/x/crypto/scrypt"
+)
+
+const (
+	// MaxLot is the highest lot number of an intermediate code.
+	MaxLot = 1048575
+
+	// MaxSequence is the highest sequence number of an intermediate code.
+	MaxSequence = 4095
+
+	// intermediateCodeLen is the length of a decoded intermediate code.
+	intermediateCodeLen = 49
+
+	// confirmationCodeLen is the length of a decoded confirmation code.
+	confirmationCodeLen = 51
+)
+
+var (
+	// intermediateMagic is the prefix of intermediate codes without a lot
+	// and sequence number, which makes them start with passphrase.
+	intermediateMagic = []byte{0x2c, 0xe9, 0xb3, 0xe1, 0xff, 0x39, 0xe2, 0x53}
+
+	// intermediateLotMagic is the prefix of intermediate codes with a lot
+	// and sequence number.
+	intermediateLotMagic = []byte{0x2c, 0xe9, 0xb3, 0xe1, 0xff, 0x39, 0xe2, 0x51}
+
+	// confirmationMagic is the prefix of confirmation codes, which makes
+	// them start with cfrm38.
+	confirmationMagic = []byte{0x64, 0x3b, 0xf6, 0xa8, 0x9a}
+)
+
+var (
+	// ErrMalformedIntermediateCode describes an error in which a string is
+	// not a BIP38 intermediate code.
+	ErrMalformedIntermediateCode = errors.New("malformed intermediate code")
+
+	// ErrMalformedConfirmationCode describes an error in which a string is
+	// not a BIP38 confirmation code.
+	ErrMalformedConfirmationCode = errors.New("malformed confirmation code")
+
+	// ErrInvalidLotSequence describes an error in which a lot number
+	// exceeds MaxLot or a sequence number exceeds MaxSequence.
+	ErrInvalidLotSequence = errors.New("lot or sequence number out of range")
+)
+
+// passFactor derives the passfactor of an EC multiplied key from the
+// passphrase and the owner entropy, whose last four bytes are the lot and
+// sequence number if hasLot is set.
+func passFactor(passphrase string, ownerEntropy []byte,
+	hasLot bool) ([]byte, error) {
+
+	if !hasLot {
+		return scrypt.Key(
+			normalizePassphrase(passphrase), ownerEntropy, scryptN,
+			scryptR, scryptP, 32,
+		)
+	}
+
+	preFactor, err := scrypt.Key(
+		normalizePassphrase(passphrase), ownerEntropy[:4], scryptN,
+		scryptR, scryptP, 32,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return chainhash.DoubleHashB(append(preFactor, ownerEntropy...)), nil
+}
+
+// passPoint returns the compressed public key of the passfactor.
+func passPoint(passFactor []byte) ([]byte, error) {
+	privKey, err := privKeyFromBytes(passFactor)
+	if err != nil {
+		return nil, err
+	}
+
+	return privKey.PubKey().SerializeCompressed(), nil
+}
+
+// newIntermediateCode returns the intermediate code for the passphrase and
+// owner entropy.
+func newIntermediateCode(passphrase string, ownerEntropy []byte,
+	hasLot bool) (string, error) {
+
+	factor, err := passFactor(passphrase, ownerEntropy, hasLot)
+	if err != nil {
+		return "", err
+	}
+	point, err := passPoint(factor)
+	if err != nil {
+		return "", err
+	}
+
+	b := make([]byte, 0, intermediateCodeLen)
+	if hasLot {
+		b = append(b, intermediateLotMagic...)
+	} else {
+		b = append(b, intermediateMagic...)
+	}
+	b = append(b, ownerEntropy...)
+	b = append(b, point...)
+
+	return encode(b), nil
+}
+
+// NewIntermediateCode returns a new intermediate code for the passphrase,
+// which starts with passphrase.  The owner of the passphrase hands it to a
+// party generating encrypted keys with GenerateEncryptedKey, who learns
+// neither the passphrase nor the private keys.
+func NewIntermediateCode(passphrase string) (string, error) {
+	ownerSalt := make([]byte, 8)
+	if _, err := rand.Read(ownerSalt); err != nil {
+		return "", err
+	}
+
+	return newIntermediateCode(passphrase, ownerSalt, false)
+}
+
+// NewIntermediateCodeWithLot is like NewIntermediateCode, but embeds the
+// passed lot and sequence number in the intermediate code, so they are
+// recorded in every key generated from it.
+func NewIntermediateCodeWithLot(passphrase string, lot,
+	sequence uint32) (string, error) {
+
+	if lot > MaxLot || sequence > MaxSequence {
+		return "", ErrInvalidLotSequence
+	}
+
+	ownerEntropy := make([]byte, 8)
+	if _, err := rand.Read(ownerEntropy[:4]); err != nil {
+		return "", err
+	}
+	binary.BigEndian.PutUint32(ownerEntropy[4:], lot*4096+sequence)
+
+	return newIntermediateCode(passphrase, ownerEntropy, true)
+}
+
+// GeneratedKey is an encrypted key generated from an intermediate code.
+type GeneratedKey struct {
+	// EncryptedKey is the encrypted private key, which can only be
+	// decrypted with the passphrase of the intermediate code.
+	EncryptedKey string
+
+	// ConfirmationCode proves to the owner of the passphrase that
+	// EncryptedKey belongs to Address, without decrypting it.
+	ConfirmationCode string
+
+	// Address is the P2PKH address of the key.
+	Address *monautil.AddressPubKeyHash
+}
+
+// GenerateEncryptedKey generates a new private key that can only be decrypted
+// with the passphrase of the passed intermediate code, and returns it
+// encrypted along with its address and confirmation code.  The compressed
+// argument specifies whether the address is made from the compressed public
+// key.
+func GenerateEncryptedKey(intermediate string, compressed bool,
+	net *chaincfg.Params) (*GeneratedKey, error) {
+
+	b, ok := decode(intermediate, intermediateCodeLen)
+	if !ok {
+		return nil, ErrMalformedIntermediateCode
+	}
+	hasLot := bytes.Equal(b[:8], intermediateLotMagic)
+	if !hasLot && !bytes.Equal(b[:8], intermediateMagic) {
+		return nil, ErrMalformedIntermediateCode
+	}
+	ownerEntropy, point := b[8:16], b[16:]
+	passPoint, err := btcec.ParsePubKey(point, btcec.S256())
+	if err != nil {
+		return nil, ErrMalformedIntermediateCode
+	}
+
+	seedB := make([]byte, 24)
+	if _, err := rand.Read(seedB); err != nil {
+		return nil, err
+	}
+	factorB := chainhash.DoubleHashB(seedB)
+
+	// The key is the product of passfactor and factorb, so its public key
+	// is the passpoint multiplied by factorb.
+	curve := btcec.S256()
+	x, y := curve.ScalarMult(passPoint.X, passPoint.Y, factorB)
+	pubKey := &btcec.PublicKey{Curve: curve, X: x, Y: y}
+	hash, addr, err := addressHash(serializePubKey(pubKey, compressed), net)
+	if err != nil {
+		return nil, err
+	}
+
+	flag := byte(0)
+	if compressed {
+		flag |= flagCompressed
+	}
+	if hasLot {
+		flag |= flagLotSequence
+	}
+
+	derived, err := pointDerivedKey(point, hash, ownerEntropy)
+	if err != nil {
+		return nil, err
+	}
+	derivedHalf1, derivedHalf2 := derived[:32], derived[32:]
+
+	encryptedPart1 := encryptBlock(
+		derivedHalf2, seedB[:16], derivedHalf1[:16],
+	)
+	encryptedPart2 := encryptBlock(
+		derivedHalf2, append(encryptedPart1[8:16:16], seedB[16:]...),
+		derivedHalf1[16:],
+	)
+
+	key := make([]byte, 0, encryptedKeyLen)
+	key = append(key, prefixVersion, prefixECMultiplied, flag)
+	key = append(key, hash...)
+	key = append(key, ownerEntropy...)
+	key = append(key, encryptedPart1[:8]...)
+	key = append(key, encryptedPart2...)
+
+	// The confirmation code holds pointb, the public key of factorb,
+	// encrypted the same way.
+	_, pointBPub := btcec.PrivKeyFromBytes(curve, factorB)
+	pointB := pointBPub.SerializeCompressed()
+
+	code := make([]byte, 0, confirmationCodeLen)
+	code = append(code, confirmationMagic...)
+	code = append(code, flag)
+	code = append(code, hash...)
+	code = append(code, ownerEntropy...)
+	code = append(code, pointB[0]^(derivedHalf2[31]&1))
+	code = append(code, encryptBlock(
+		derivedHalf2, pointB[1:17], derivedHalf1[:16],
+	)...)
+	code = append(code, encryptBlock(
+		derivedHalf2, pointB[17:33], derivedHalf1[16:],
+	)...)
+
+	return &GeneratedKey{
+		EncryptedKey:     encode(key),
+		ConfirmationCode: encode(code),
+		Address:          addr,
+	}, nil
+}
+
+// pointDerivedKey derives the encryption key of an EC multiplied key from the
+// passpoint, address hash and owner entropy.
+func pointDerivedKey(passPoint, hash, ownerEntropy []byte) ([]byte, error) {
+	salt := make([]byte, 0, len(hash)+len(ownerEntropy))
+	salt = append(salt, hash...)
+	salt = append(salt, ownerEntropy...)
+
+	return scrypt.Key(
+		passPoint, salt, scryptPointN, scryptPointR, scryptPointP, 64,
+	)
+}
+
+// decryptECMultiplied returns the private key of the passed decoded key
+// encrypted with EC multiplication.
+func decryptECMultiplied(b []byte,
+	passphrase string) (*btcec.PrivateKey, error) {
+
+	flag, hash, ownerEntropy := b[2], b[3:7], b[7:15]
+	factor, err := passFactor(
+		passphrase, ownerEntropy, flag&flagLotSequence != 0,
+	)
+	if err != nil {
+		return nil, err
+	}
+	point, err := passPoint(factor)
+	if err != nil {
+		return nil, err
+	}
+
+	derived, err := pointDerivedKey(point, hash, ownerEntropy)
+	if err != nil {
+		return nil, err
+	}
+	derivedHalf1, derivedHalf2 := derived[:32], derived[32:]
+
+	// The second encrypted part holds the second half of the first one,
+	// followed by the end of seedb.
+	part2 := decryptBlock(derivedHalf2, b[23:39], derivedHalf1[16:])
+	encryptedPart1 := append(b[15:23:23], part2[:8]...)
+	part1 := decryptBlock(derivedHalf2, encryptedPart1, derivedHalf1[:16])
+
+	seedB := append(part1, part2[8:]...)
+	factorB := new(big.Int).SetBytes(chainhash.DoubleHashB(seedB))
+
+	d := new(big.Int).SetBytes(factor)
+	d.Mul(d, factorB)
+	d.Mod(d, btcec.S256().N)
+
+	return privKeyFromBytes(d.Bytes())
+}
+
+// VerifyConfirmationCode checks the passed confirmation code with the
+// passphrase and returns the P2PKH address of the encrypted key it was
+// generated with, which the owner of the passphrase can compare against the
+// address they were given.  ErrWrongPassphrase is returned if the code
+// doesn't match the passphrase.
+func VerifyConfirmationCode(code, passphrase string,
+	net *chaincfg.Params) (*monautil.AddressPubKeyHash, error) {
+
+	b, ok := decode(code, confirmationCodeLen)
+	if !ok || !bytes.Equal(b[:5], confirmationMagic) {
+		return nil, ErrMalformedConfirmationCode
+	}
+	flag, hash, ownerEntropy := b[5], b[6:10], b[10:18]
+	if flag&^(flagCompressed|flagLotSequence) != 0 {
+		return nil, ErrMalformedConfirmationCode
+	}
+
+	factor, err := passFactor(
+		passphrase, ownerEntropy, flag&flagLotSequence != 0,
+	)
+	if err != nil {
+		return nil, err
+	}
+	point, err := passPoint(factor)
+	if err != nil {
+		return nil, err
+	}
+
+	derived, err := pointDerivedKey(point, hash, ownerEntropy)
+	if err != nil {
+		return nil, err
+	}
+	derivedHalf1, derivedHalf2 := derived[:32], derived[32:]
+
+	pointB := make([]byte, 0, 33)
+	pointB = append(pointB, b[18]^(derivedHalf2[31]&1))
+	pointB = append(pointB, decryptBlock(
+		derivedHalf2, b[19:35], derivedHalf1[:16],
+	)...)
+	pointB = append(pointB, decryptBlock(
+		derivedHalf2, b[35:51], derivedHalf1[16:],
+	)...)
+	pointBPub, err := btcec.ParsePubKey(pointB, btcec.S256())
+	if err != nil {
+		return nil, ErrWrongPassphrase
+	}
+
+	curve := btcec.S256()
+	x, y := curve.ScalarMult(pointBPub.X, pointBPub.Y, factor)
+	pubKey := &btcec.PublicKey{Curve: curve, X: x, Y: y}
+	addrHash, addr, err := addressHash(
+		serializePubKey(pubKey, flag&flagCompressed != 0), net,
+	)
+	if err != nil {
+		return nil, err
+	}
+	if !bytes.Equal(addrHash, hash) {
+		return nil, ErrWrongPassphrase
+	}
+
+	return addr, nil
+}
@@ -0,0 +1,44 @@
+// Copyright (c) 2026 The monasuite developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package bip38_test
+
+import (
+	"fmt"
+
+	"github.com/monasuite/monad/chaincfg"
+	"github.com/monasuite/monautil"
+	"github.com/monasuite/monautil/bip38"
+)
+
+// This example demonstrates how to encrypt a private key with a passphrase
+// and decrypt it again.
+func ExampleEncrypt() {
+	net := &chaincfg.MainNetParams
+	wif, err := monautil.DecodeWIF(
+		"T4ff2X9NeYy292nC39BjDDGaMBoXg3JzPXXcuYce8KgVmZ9RD9U1",
+	)
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+
+	encrypted, err := bip38.Encrypt(wif, "TestingOneTwoThree", net)
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	fmt.Println("Encrypted:", encrypted)
+
+	decrypted, err := bip38.Decrypt(encrypted, "TestingOneTwoThree", net)
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	fmt.Println("Decrypted:", decrypted)
+
+	// Output:
+	// Encrypted: 6PYXHjaTnM4i3qsdBBp6mEj2Vc6kaTTVvrcDK4MB17n9D9MtLro68Zp6B4
+	// Decrypted: T4ff2X9NeYy292nC39BjDDGaMBoXg3JzPXXcuYce8KgVmZ9RD9U1
+}
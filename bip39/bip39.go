@@ -0,0 +1,200 @@
+// Copyright (c) 2026 The monasuite developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package bip39
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/sha512"
+	"errors"
+	"fmt"
+	"strings"
+
+	"golang.org/x/crypto/pbkdf2"
+	"golang.org/x/text/unicode/norm"
+)
+
+const (
+	// MinEntropyBits is the minimum number of bits of entropy a mnemonic
+	// encodes.
+	MinEntropyBits = 128
+
+	// MaxEntropyBits is the maximum number of bits of entropy a mnemonic
+	// encodes.
+	MaxEntropyBits = 256
+
+	// SeedBytes is the length in bytes of the seeds derived from a
+	// mnemonic.
+	SeedBytes = 64
+
+	// seedIterations is the number of PBKDF2 iterations used to derive a
+	// seed.
+	seedIterations = 2048
+
+	// bitsPerWord is the number of bits each word of a mnemonic encodes.
+	bitsPerWord = 11
+)
+
+var (
+	// ErrInvalidEntropyLen describes an error in which the provided entropy
+	// or entropy length is not supported.
+	ErrInvalidEntropyLen = fmt.Errorf("entropy length must be a multiple "+
+		"of 32 bits between %d and %d bits", MinEntropyBits,
+		MaxEntropyBits)
+
+	// ErrInvalidWordCount describes an error in which a mnemonic doesn't
+	// consist of 12, 15, 18, 21 or 24 words.
+	ErrInvalidWordCount = errors.New("mnemonic must consist of 12, 15, " +
+		"18, 21 or 24 words")
+
+	// ErrChecksumMismatch describes an error in which the checksum encoded
+	// in a mnemonic doesn't match its entropy.
+	ErrChecksumMismatch = errors.New("mnemonic checksum mismatch")
+
+	// ErrUnknownWordList describes an error in which the words of a
+	// mnemonic don't all belong to one of the known wordlists.
+	ErrUnknownWordList = errors.New("mnemonic words don't belong to a " +
+		"known wordlist")
+)
+
+// UnknownWordError describes an error in which a mnemonic contains a word
+// that isn't part of the wordlist.
+type UnknownWordError struct {
+	Word     string
+	WordList *WordList
+}
+
+// Error returns the error string.
+func (e *UnknownWordError) Error() string {
+	return fmt.Sprintf("word %q is not part of the %s wordlist", e.Word,
+		e.WordList.Name())
+}
+
+// validateEntropyLen returns an error if entropy of the passed length in bits
+// can't be encoded in a mnemonic.
+func validateEntropyLen(bits int) error {
+	if bits < MinEntropyBits || bits > MaxEntropyBits || bits%32 != 0 {
+		return ErrInvalidEntropyLen
+	}
+
+	return nil
+}
+
+// NewEntropy returns the given number of bits of random entropy, suitable for
+// creating a mnemonic.  The number of bits must be a multiple of 32 between
+// MinEntropyBits and MaxEntropyBits.
+func NewEntropy(bits int) ([]byte, error) {
+	if err := validateEntropyLen(bits); err != nil {
+		return nil, err
+	}
+
+	entropy := make([]byte, bits/8)
+	if _, err := rand.Read(entropy); err != nil {
+		return nil, err
+	}
+
+	return entropy, nil
+}
+
+// NewMnemonic returns the mnemonic encoding the passed entropy with words of
+// the passed wordlist.  The entropy is followed by a checksum of one bit per
+// 32 bits of entropy, taken from its SHA256 hash, and every 11 bits select a
+// word.
+func NewMnemonic(entropy []byte, list *WordList) (string, error) {
+	if err := validateEntropyLen(len(entropy) * 8); err != nil {
+		return "", err
+	}
+
+	hash := sha256.Sum256(entropy)
+	data := append(append([]byte(nil), entropy...), hash[0])
+	numWords := (len(entropy)*8 + len(entropy)/4) / bitsPerWord
+
+	words := make([]string, numWords)
+	for i := range words {
+		index := 0
+		for bit := i * bitsPerWord; bit < (i+1)*bitsPerWord; bit++ {
+			index = index<<1 | int(data[bit/8]>>(7-uint(bit%8))&1)
+		}
+		words[i] = list.Word(index)
+	}
+
+	return strings.Join(words, list.Separator()), nil
+}
+
+// EntropyFromMnemonic returns the entropy encoded in the passed mnemonic after
+// validating its words and checksum.  If list is nil, the wordlist is detected
+// from the words of the mnemonic.
+func EntropyFromMnemonic(mnemonic string, list *WordList) ([]byte, error) {
+	words := splitMnemonic(mnemonic)
+	if len(words) < 12 || len(words) > 24 || len(words)%3 != 0 {
+		return nil, ErrInvalidWordCount
+	}
+
+	if list == nil {
+		var err error
+		list, err = DetectWordList(mnemonic)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	// Every 3 words encode 32 bits of entropy and one checksum bit.
+	checksumBits := len(words) / 3
+	data := make([]byte, (len(words)*bitsPerWord+7)/8)
+	for i, word := range words {
+		index, ok := list.Index(word)
+		if !ok {
+			return nil, &UnknownWordError{Word: word, WordList: list}
+		}
+
+		for j := 0; j < bitsPerWord; j++ {
+			if index>>(bitsPerWord-1-uint(j))&1 == 0 {
+				continue
+			}
+			bit := i*bitsPerWord + j
+			data[bit/8] |= 1 << (7 - uint(bit%8))
+		}
+	}
+
+	entropy := data[:checksumBits*4]
+	hash := sha256.Sum256(entropy)
+	mask := byte(0xff) << (8 - uint(checksumBits))
+	if data[len(entropy)]&mask != hash[0]&mask {
+		return nil, ErrChecksumMismatch
+	}
+
+	return entropy, nil
+}
+
+// ValidateMnemonic returns an error if the passed mnemonic has the wrong
+// number of words, contains words that aren't part of the wordlist or has an
+// invalid checksum.  If list is nil, the wordlist is detected from the words
+// of the mnemonic.
+func ValidateMnemonic(mnemonic string, list *WordList) error {
+	_, err := EntropyFromMnemonic(mnemonic, list)
+	return err
+}
+
+// NewSeed validates the passed mnemonic and derives the seed it encodes
+// together with the passed passphrase, which may be empty.  The seed is the
+// result of PBKDF2-HMAC-SHA512 over the NFKD normalized mnemonic, salted with
+// "mnemonic" followed by the NFKD normalized passphrase, and can be used with
+// hdkeychain.NewMaster.  If list is nil, the wordlist is detected from the
+// words of the mnemonic.
+func NewSeed(mnemonic, passphrase string, list *WordList) ([]byte, error) {
+	if err := ValidateMnemonic(mnemonic, list); err != nil {
+		return nil, err
+	}
+
+	// The words are joined by plain spaces, which is what the separators
+	// of all wordlists normalize to.
+	normalized := strings.Join(splitMnemonic(mnemonic), " ")
+	salt := "mnemonic" + norm.NFKD.String(passphrase)
+
+	return pbkdf2.Key(
+		[]byte(normalized), []byte(salt), seedIterations, SeedBytes,
+		sha512.New,
+	), nil
+}
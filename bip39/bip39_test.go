@@ -0,0 +1,189 @@
+// Copyright (c) 2026 The monasuite developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package bip39_test
+
+import (
+	"bytes"
+	"encoding/hex"
+	"strings"
+	"testing"
+
+	"github.com/monasuite/monautil/bip39"
+	"golang.org/x/text/unicode/norm"
+)
+
+// TestVectors ensures mnemonics and seeds are derived from entropy as in the
+// BIP 39 test vectors and the Japanese test vectors referenced by it.
+func TestVectors(t *testing.T) {
+	const japanesePassphrase = "㍍ガバヴァぱばぐゞちぢ十人十色"
+
+	tests := []struct {
+		entropy    string
+		list       *bip39.WordList
+		mnemonic   string
+		passphrase string
+		seed       string
+	}{{
+		entropy:    "00000000000000000000000000000000",
+		list:       bip39.English,
+		mnemonic:   "abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon about",
+		passphrase: "TREZOR",
+		seed:       "c55257c360c07c72029aebc1b53c05ed0362ada38ead3e3e9efa3708e53495531f09a6987599d18264c1e1c92f2cf141630c7a3c4ab7c81b2f001698e7463b04",
+	}, {
+		entropy:    "7f7f7f7f7f7f7f7f7f7f7f7f7f7f7f7f",
+		list:       bip39.English,
+		mnemonic:   "legal winner thank year wave sausage worth useful legal winner thank yellow",
+		passphrase: "TREZOR",
+		seed:       "2e8905819b8723fe2c1d161860e5ee1830318dbf49a83bd451cfb8440c28bd6fa457fe1296106559a3c80937a1c1069be3a3a5bd381ee6260e8d9739fce1f607",
+	}, {
+		entropy:    "80808080808080808080808080808080",
+		list:       bip39.English,
+		mnemonic:   "letter advice cage absurd amount doctor acoustic avoid letter advice cage above",
+		passphrase: "TREZOR",
+		seed:       "d71de856f81a8acc65e6fc851a38d4d7ec216fd0796d0a6827a3ad6ed5511a30fa280f12eb2e47ed2ac03b5c462a0358d18d69fe4f985ec81778c1b370b652a8",
+	}, {
+		entropy:    "ffffffffffffffffffffffffffffffff",
+		list:       bip39.English,
+		mnemonic:   "zoo zoo zoo zoo zoo zoo zoo zoo zoo zoo zoo wrong",
+		passphrase: "TREZOR",
+		seed:       "ac27495480225222079d7be181583751e86f571027b0497b5b5d11218e0a8a13332572917f0f8e5a589620c6f15b11c61dee327651a14c34e18231052e48c069",
+	}, {
+		entropy:    "9e885d952ad362caeb4efe34a8e91bd2",
+		list:       bip39.English,
+		mnemonic:   "ozone drill grab fiber curtain grace pudding thank cruise elder eight picnic",
+		passphrase: "TREZOR",
+		seed:       "274ddc525802f7c828d8ef7ddbcdc5304e87ac3535913611fbbfa986d0c9e5476c91689f9c8a54fd55bd38606aa6a8595ad213d4c9c9f9aca3fb217069a41028",
+	}, {
+		entropy:    "00000000000000000000000000000000",
+		list:       bip39.Japanese,
+		mnemonic:   "あいこくしん　あいこくしん　あいこくしん　あいこくしん　あいこくしん　あいこくしん　あいこくしん　あいこくしん　あいこくしん　あいこくしん　あいこくしん　あおぞら",
+		passphrase: japanesePassphrase,
+		seed:       "a262d6fb6122ecf45be09c50492b31f92e9beb7d9a845987a02cefda57a15f9c467a17872029a9e92299b5cbdf306e3a0ee620245cbd508959b6cb7ca637bd55",
+	}, {
+		entropy:    "8080808080808080808080808080808080808080808080808080808080808080",
+		list:       bip39.Japanese,
+		mnemonic:   "そとづら　あまど　おおう　あこがれる　いくぶん　けいけん　あたえる　いよく　そとづら　あまど　おおう　あこがれる　いくぶん　けいけん　あたえる　いよく　そとづら　あまど　おおう　あこがれる　いくぶん　けいけん　あたえる　うめる",
+		passphrase: japanesePassphrase,
+		seed:       "6b7cd1b2cdfeeef8615077cadd6a0625f417f287652991c80206dbd82db17bf317d5c50a80bd9edd836b39daa1b6973359944c46d3fcc0129198dc7dc5cd0e68",
+	}}
+
+	for i, test := range tests {
+		entropy, _ := hex.DecodeString(test.entropy)
+		mnemonic, err := bip39.NewMnemonic(entropy, test.list)
+		if err != nil {
+			t.Errorf("#%d: NewMnemonic: unexpected error: %v", i, err)
+			continue
+		}
+
+		// The test vectors are written in composed form, while the
+		// Japanese wordlist is decomposed.
+		if norm.NFC.String(mnemonic) != test.mnemonic {
+			t.Errorf("#%d: got mnemonic %q, want %q", i, mnemonic,
+				test.mnemonic)
+		}
+
+		// The wordlist is detected from either form of the mnemonic.
+		for _, m := range []string{mnemonic, test.mnemonic} {
+			decoded, err := bip39.EntropyFromMnemonic(m, nil)
+			if err != nil {
+				t.Errorf("#%d: EntropyFromMnemonic: unexpected "+
+					"error: %v", i, err)
+				continue
+			}
+			if !bytes.Equal(decoded, entropy) {
+				t.Errorf("#%d: got entropy %x, want %x", i,
+					decoded, entropy)
+			}
+
+			seed, err := bip39.NewSeed(m, test.passphrase, test.list)
+			if err != nil {
+				t.Errorf("#%d: NewSeed: unexpected error: %v", i,
+					err)
+				continue
+			}
+			if hex.EncodeToString(seed) != test.seed {
+				t.Errorf("#%d: got seed %x, want %s", i, seed,
+					test.seed)
+			}
+		}
+	}
+}
+
+// TestInvalidMnemonics ensures invalid mnemonics are rejected with the
+// expected errors.
+func TestInvalidMnemonics(t *testing.T) {
+	tests := []struct {
+		name     string
+		mnemonic string
+		list     *bip39.WordList
+		err      error
+	}{{
+		name:     "too few words",
+		mnemonic: strings.Repeat("abandon ", 10) + "about",
+		err:      bip39.ErrInvalidWordCount,
+	}, {
+		name:     "word count not a multiple of 3",
+		mnemonic: strings.Repeat("abandon ", 12) + "about",
+		err:      bip39.ErrInvalidWordCount,
+	}, {
+		name:     "checksum mismatch",
+		mnemonic: strings.Repeat("abandon ", 11) + "abandon",
+		err:      bip39.ErrChecksumMismatch,
+	}, {
+		name:     "mixed wordlists",
+		mnemonic: strings.Repeat("abandon ", 11) + "あおぞら",
+		err:      bip39.ErrUnknownWordList,
+	}, {
+		name:     "wrong wordlist",
+		mnemonic: strings.Repeat("abandon ", 11) + "about",
+		list:     bip39.Japanese,
+	}}
+
+	for _, test := range tests {
+		err := bip39.ValidateMnemonic(test.mnemonic, test.list)
+		if test.err == nil {
+			if _, ok := err.(*bip39.UnknownWordError); !ok {
+				t.Errorf("%s: got error %v, want UnknownWordError",
+					test.name, err)
+			}
+			continue
+		}
+		if err != test.err {
+			t.Errorf("%s: got error %v, want %v", test.name, err,
+				test.err)
+		}
+	}
+}
+
+// TestEntropyLength ensures only supported entropy lengths are accepted.
+func TestEntropyLength(t *testing.T) {
+	for bits := 0; bits <= 320; bits += 8 {
+		valid := bits >= 128 && bits <= 256 && bits%32 == 0
+
+		entropy, err := bip39.NewEntropy(bits)
+		if valid != (err == nil) {
+			t.Errorf("NewEntropy(%d): unexpected error %v", bits, err)
+			continue
+		}
+		if !valid {
+			continue
+		}
+
+		mnemonic, err := bip39.NewMnemonic(entropy, bip39.English)
+		if err != nil {
+			t.Errorf("NewMnemonic: unexpected error: %v", err)
+			continue
+		}
+		if n := len(strings.Fields(mnemonic)); n != (bits+bits/32)/11 {
+			t.Errorf("%d bits encoded in %d words", bits, n)
+		}
+	}
+
+	_, err := bip39.NewMnemonic(make([]byte, 15), bip39.English)
+	if err != bip39.ErrInvalidEntropyLen {
+		t.Errorf("NewMnemonic: got error %v, want %v", err,
+			bip39.ErrInvalidEntropyLen)
+	}
+}
@@ -0,0 +1,22 @@
+// Copyright (c) 2026 The monasuite developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+/*
+Package bip39 implements mnemonic phrases for the generation of deterministic
+keys as specified by BIP 39.
+
+Overview
+
+A mnemonic encodes 128 to 256 bits of entropy, followed by a checksum taken
+from the SHA256 hash of the entropy, as a sentence of 12 to 24 words from a
+wordlist.  Together with an optional passphrase, the mnemonic is turned into a
+64 byte seed using PBKDF2-HMAC-SHA512, which can be passed to
+hdkeychain.NewMaster to create the master extended key of a wallet.
+
+The English and Japanese wordlists are provided.  Mnemonics and passphrases
+are normalized to Unicode NFKD before they are used, so words may be given in
+either composed or decomposed form.  Japanese mnemonics are written with
+ideographic spaces between the words, which normalize to plain spaces.
+*/
+package bip39
@@ -0,0 +1,35 @@
+// Copyright (c) 2026 The monasuite developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package bip39_test
+
+import (
+	"fmt"
+
+	"github.com/monasuite/monad/chaincfg"
+	"github.com/monasuite/monautil/bip39"
+	"github.com/monasuite/monautil/hdkeychain"
+)
+
+// This example demonstrates how to create a master extended key from a
+// mnemonic and passphrase.
+func ExampleNewSeed() {
+	mnemonic := "legal winner thank year wave sausage worth useful legal " +
+		"winner thank yellow"
+	seed, err := bip39.NewSeed(mnemonic, "TREZOR", bip39.English)
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+
+	master, err := hdkeychain.NewMaster(seed, &chaincfg.MainNetParams)
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	fmt.Println(master)
+
+	// Output:
+	// xprv9s21ZrQH143K2gA81bYFHqU68xz1cX2APaSq5tt6MFSLeXnCKV1RVUJt9FWNTbrrryem4ZckN8k4Ls1H6nwdvDTvnV7zEXs2HgPezuVccsq
+}
@@ -0,0 +1,115 @@
+// Copyright (c) 2026 The monasuite developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package bip39
+
+import (
+	"strings"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+// wordListLen is the number of words every wordlist consists of, so that each
+// word encodes 11 bits.
+const wordListLen = 2048
+
+// WordList is a BIP 39 wordlist together with the rules for writing a
+// mnemonic in its language.
+type WordList struct {
+	name      string
+	separator string
+	words     []string
+	index     map[string]int
+}
+
+var (
+	// English is the English wordlist.  Words of an English mnemonic are
+	// separated by spaces.
+	English = newWordList("english", " ", englishWords)
+
+	// Japanese is the Japanese wordlist.  Words of a Japanese mnemonic are
+	// separated by ideographic spaces (U+3000), which NFKD normalization
+	// turns into plain spaces when the seed is derived.
+	Japanese = newWordList("japanese", "\u3000", japaneseWords)
+
+	// wordLists holds all known wordlists, for detecting the wordlist of a
+	// mnemonic.
+	wordLists = []*WordList{English, Japanese}
+)
+
+// newWordList returns the wordlist consisting of the passed words, one per
+// line.  The words are looked up in their NFKD normalized form, so that a
+// mnemonic may be written with either composed or decomposed characters.
+func newWordList(name, separator, data string) *WordList {
+	words := strings.Split(data, "\n")
+	if len(words) != wordListLen {
+		panic("bip39: " + name + " wordlist has wrong length")
+	}
+
+	index := make(map[string]int, len(words))
+	for i, word := range words {
+		index[norm.NFKD.String(word)] = i
+	}
+
+	return &WordList{
+		name:      name,
+		separator: separator,
+		words:     words,
+		index:     index,
+	}
+}
+
+// Name returns the name of the wordlist's language in lowercase.
+func (l *WordList) Name() string {
+	return l.name
+}
+
+// Separator returns the string that separates the words of a mnemonic
+// generated from the wordlist.
+func (l *WordList) Separator() string {
+	return l.separator
+}
+
+// Word returns the word at the passed index, which must be less than 2048.
+func (l *WordList) Word(index int) string {
+	return l.words[index]
+}
+
+// Index returns the index of the passed word in the wordlist, and whether it
+// was found.  The word is NFKD normalized before it is looked up.
+func (l *WordList) Index(word string) (int, bool) {
+	i, ok := l.index[norm.NFKD.String(word)]
+	return i, ok
+}
+
+// DetectWordList returns the wordlist that contains all words of the passed
+// mnemonic.  ErrUnknownWordList is returned if there is none.
+func DetectWordList(mnemonic string) (*WordList, error) {
+	words := splitMnemonic(mnemonic)
+	if len(words) == 0 {
+		return nil, ErrUnknownWordList
+	}
+
+	for _, list := range wordLists {
+		found := true
+		for _, word := range words {
+			if _, ok := list.index[word]; !ok {
+				found = false
+				break
+			}
+		}
+		if found {
+			return list, nil
+		}
+	}
+
+	return nil, ErrUnknownWordList
+}
+
+// splitMnemonic returns the NFKD normalized words of the passed mnemonic.
+// Normalization turns ideographic spaces into plain ones, so words may be
+// separated by any whitespace.
+func splitMnemonic(mnemonic string) []string {
+	return strings.Fields(norm.NFKD.String(mnemonic))
+}
@@ -36,6 +36,8 @@ type Block struct {
 	serializedBlock          []byte          // Serialized bytes for the block
 	serializedBlockNoWitness []byte          // Serialized bytes for block w/o witness data
 	blockHash                *chainhash.Hash // Cached block hash
+	strippedSize             *int64          // Cached size w/o witness data
+	weight                   *int64          // Cached block weight
 	blockHeight              int32           // Height in the main block chain
 	transactions             []*Tx           // Transactions
 	txnsGenerated            bool            // ALL wrapped transactions generated
@@ -105,6 +107,43 @@ func (b *Block) Hash() *chainhash.Hash {
 	return &hash
 }
 
+// StrippedSize returns the serialized size of the block with transactions
+// encoded without any witness data.  This is equivalent to calling
+// SerializeSizeStripped on the underlying wire.MsgBlock, however it caches the
+// result so subsequent calls are more efficient.
+func (b *Block) StrippedSize() int64 {
+	if b.strippedSize != nil {
+		return *b.strippedSize
+	}
+
+	size := int64(b.msgBlock.SerializeSizeStripped())
+	b.strippedSize = &size
+	return size
+}
+
+// Weight returns the weight of the block, which is its stripped size scaled
+// by the witness scale factor plus the size of its witness data.  The result
+// is cached so subsequent calls are more efficient.
+func (b *Block) Weight() int64 {
+	if b.weight != nil {
+		return *b.weight
+	}
+
+	size := int64(len(b.serializedBlock))
+	if size == 0 {
+		size = int64(b.msgBlock.SerializeSize())
+	}
+	weight := b.StrippedSize()*(WitnessScaleFactor-1) + size
+	b.weight = &weight
+	return weight
+}
+
+// VirtualSize returns the virtual size of the block, which is its weight
+// divided by the witness scale factor and rounded up.
+func (b *Block) VirtualSize() int64 {
+	return WeightToVSize(b.Weight())
+}
+
 // Tx returns a wrapped transaction (monautil.Tx) for the transaction at the
 // specified index in the Block.  The supplied index is 0 based.  That is to
 // say, the first transaction in the block is txNum 0.  This is nearly
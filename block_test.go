@@ -231,6 +231,40 @@ func TestNewBlockFromBlockAndBytes(t *testing.T) {
 	}
 }
 
+// TestBlockSizes tests the StrippedSize(), Weight() and VirtualSize() methods
+// of blocks created with and without their serialized bytes.
+func TestBlockSizes(t *testing.T) {
+	var block100000Buf bytes.Buffer
+	err := Block100000.Serialize(&block100000Buf)
+	if err != nil {
+		t.Fatalf("Serialize: %v", err)
+	}
+
+	blocks := []*monautil.Block{
+		monautil.NewBlock(&Block100000),
+		monautil.NewBlockFromBlockAndBytes(&Block100000,
+			block100000Buf.Bytes()),
+	}
+	for i, b := range blocks {
+		// Request the sizes multiple times to ensure the cached values
+		// are consistent.
+		for j := 0; j < 2; j++ {
+			if size := b.StrippedSize(); size != 957 {
+				t.Errorf("block %d: StrippedSize: got %d, want %d",
+					i, size, 957)
+			}
+			if weight := b.Weight(); weight != 3837 {
+				t.Errorf("block %d: Weight: got %d, want %d", i,
+					weight, 3837)
+			}
+			if vsize := b.VirtualSize(); vsize != 960 {
+				t.Errorf("block %d: VirtualSize: got %d, want %d",
+					i, vsize, 960)
+			}
+		}
+	}
+}
+
 // TestBlockErrors tests the error paths for the Block API.
 func TestBlockErrors(t *testing.T) {
 	// Ensure out of range errors are as expected.
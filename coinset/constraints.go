@@ -0,0 +1,498 @@
+// Copyright (c) 2026 The monasuite developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package coinset
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/monasuite/monad/chaincfg/chainhash"
+	"github.com/monasuite/monad/wire"
+	"github.com/monasuite/monautil"
+)
+
+// ExclusionReason describes why a coin was left out of a constrained
+// selection.
+type ExclusionReason uint8
+
+// These constants define the reasons for leaving coins out of a constrained
+// selection.
+const (
+	// ExcludedByRequest is the reason for coins listed in Exclude.
+	ExcludedByRequest ExclusionReason = iota
+
+	// ExcludedUnconfirmed is the reason for coins with fewer than
+	// MinConfs confirmations.
+	ExcludedUnconfirmed
+
+	// ExcludedOtherGroup is the reason for coins of another address group
+	// than the coins that must be included.
+	ExcludedOtherGroup
+
+	// ExcludedUneconomical is the reason for coins whose value doesn't pay
+	// the fee to spend them, when selecting with a selector based on
+	// effective values.
+	ExcludedUneconomical
+)
+
+// String returns the ExclusionReason as a human-readable description.
+func (r ExclusionReason) String() string {
+	switch r {
+	case ExcludedByRequest:
+		return "excluded by request"
+	case ExcludedUnconfirmed:
+		return "too few confirmations"
+	case ExcludedOtherGroup:
+		return "other address group"
+	case ExcludedUneconomical:
+		return "uneconomical"
+	default:
+		return fmt.Sprintf("unknown reason %d", uint8(r))
+	}
+}
+
+// ExcludedCoin is a coin left out of a constrained selection.
+type ExcludedCoin struct {
+	Coin   Coin
+	Reason ExclusionReason
+}
+
+// SelectionRequest describes a coin selection along with the coin control
+// constraints it must honor.
+type SelectionRequest struct {
+	// TargetValue is the value to select coins for.
+	TargetValue monautil.Amount
+
+	// Coins are the coins to select from.
+	Coins []Coin
+
+	// MustInclude are the outpoints of coins that must be spent.  They
+	// are spent even if they would be excluded by MinConfs, and every one
+	// of them must be in Coins.
+	MustInclude []wire.OutPoint
+
+	// Exclude are the outpoints of coins that must never be spent.
+	Exclude []wire.OutPoint
+
+	// MinConfs is the smallest number of confirmations of the coins that
+	// may be spent.
+	MinConfs int64
+
+	// AddressGroup returns the address group of a coin, such as a group
+	// of addresses known to belong together.  When it is set, coins of
+	// different groups are never spent together.
+	AddressGroup func(Coin) string
+
+	// GroupByScript prefers selections which spend all the coins paying
+	// to the same public key script together, so that no coins are left
+	// behind at an address that was revealed by spending.  Selections
+	// ignoring the preference are only made if none honoring it exists.
+	// The coins of a script count as one input toward the input limit of
+	// the wrapped selector.
+	GroupByScript bool
+}
+
+// SelectionError explains why no selection honoring the constraints of a
+// SelectionRequest was found.  It unwraps to the error returned by the
+// wrapped selector, which is usually ErrCoinsNoSelectionAvailable.
+type SelectionError struct {
+	// TargetValue is the value of the request.
+	TargetValue monautil.Amount
+
+	// Available is the total value of the coins the constraints allowed
+	// to be spent together.  When coins of different address groups may
+	// not be mixed, it is the value of the most valuable group.
+	Available monautil.Amount
+
+	// Excluded are the coins left out because of the constraints.
+	Excluded []ExcludedCoin
+
+	// Missing are the outpoints that must be included but are not among
+	// the coins.
+	Missing []wire.OutPoint
+
+	// Conflicting are the outpoints that are both required and excluded.
+	Conflicting []wire.OutPoint
+
+	// MixedGroups is set when the coins that must be included belong to
+	// different address groups.
+	MixedGroups bool
+
+	// Err is the error returned by the wrapped selector, if it was run.
+	Err error
+}
+
+// Error satisfies the error interface and summarizes the reasons.
+func (e *SelectionError) Error() string {
+	var reasons []string
+	if len(e.Conflicting) > 0 {
+		reasons = append(reasons, fmt.Sprintf("%d outpoints both "+
+			"required and excluded", len(e.Conflicting)))
+	}
+	if len(e.Missing) > 0 {
+		reasons = append(reasons, fmt.Sprintf("%d required outpoints "+
+			"missing", len(e.Missing)))
+	}
+	if e.MixedGroups {
+		reasons = append(reasons, "required coins in different "+
+			"address groups")
+	}
+	if len(e.Excluded) > 0 {
+		counts := make(map[ExclusionReason]int)
+		var order []ExclusionReason
+		for _, excluded := range e.Excluded {
+			if counts[excluded.Reason] == 0 {
+				order = append(order, excluded.Reason)
+			}
+			counts[excluded.Reason]++
+		}
+		for _, reason := range order {
+			reasons = append(reasons, fmt.Sprintf("%d coins %v",
+				counts[reason], reason))
+		}
+	}
+
+	msg := fmt.Sprintf("no coin selection possible for %v with %v "+
+		"available", e.TargetValue, e.Available)
+	if len(reasons) > 0 {
+		msg += " (" + strings.Join(reasons, ", ") + ")"
+	}
+	return msg
+}
+
+// Unwrap returns the error of the wrapped selector, or
+// ErrCoinsNoSelectionAvailable if it wasn't run.
+func (e *SelectionError) Unwrap() error {
+	if e.Err == nil {
+		return ErrCoinsNoSelectionAvailable
+	}
+	return e.Err
+}
+
+// feeAwareSelector is implemented by the selectors based on effective values,
+// so the constrained selector can account for the fees of the coins that
+// must be included.
+type feeAwareSelector interface {
+	feeParams() *FeeParams
+	Select(monautil.Amount, []Coin) (*Selection, error)
+}
+
+// feeParams returns the fee parameters, which makes the selectors embedding
+// FeeParams implement feeAwareSelector.
+func (p *FeeParams) feeParams() *FeeParams {
+	return p
+}
+
+// ConstrainedCoinSelector applies the constraints of a SelectionRequest on
+// top of another CoinSelector.  Excluded coins are removed before the wrapped
+// selector is run, coins that must be included are spent along with the
+// coins it selects for the rest of the target value, and the selector is run
+// once for each address group when groups may not be mixed.
+//
+// When the wrapped selector is one of the selectors based on effective
+// values, the fees of the coins that must be included are accounted for and
+// the result is a *Selection.  Otherwise the result is a *CoinSet.
+type ConstrainedCoinSelector struct {
+	Selector CoinSelector
+}
+
+// outPoint returns the outpoint of the coin.
+func outPoint(c Coin) wire.OutPoint {
+	return wire.OutPoint{Hash: *c.Hash(), Index: c.Index()}
+}
+
+// Select returns a selection of coins honoring the constraints of the
+// request.  A *SelectionError explaining the failure is returned when there
+// is none.
+func (s *ConstrainedCoinSelector) Select(req *SelectionRequest) (Coins, error) {
+	selErr := &SelectionError{TargetValue: req.TargetValue}
+
+	excluded := make(map[wire.OutPoint]bool, len(req.Exclude))
+	for _, op := range req.Exclude {
+		excluded[op] = true
+	}
+	required := make(map[wire.OutPoint]bool, len(req.MustInclude))
+	for _, op := range req.MustInclude {
+		if excluded[op] {
+			selErr.Conflicting = append(selErr.Conflicting, op)
+		}
+		required[op] = true
+	}
+	if len(selErr.Conflicting) > 0 {
+		return nil, selErr
+	}
+
+	// Split the coins into those that must be included, those that may
+	// be selected and those that are excluded.
+	var presets, candidates []Coin
+	found := make(map[wire.OutPoint]bool, len(required))
+	for _, c := range req.Coins {
+		op := outPoint(c)
+		switch {
+		case required[op]:
+			if !found[op] {
+				presets = append(presets, c)
+				found[op] = true
+			}
+		case excluded[op]:
+			selErr.Excluded = append(selErr.Excluded,
+				ExcludedCoin{c, ExcludedByRequest})
+		case c.NumConfs() < req.MinConfs:
+			selErr.Excluded = append(selErr.Excluded,
+				ExcludedCoin{c, ExcludedUnconfirmed})
+		default:
+			candidates = append(candidates, c)
+		}
+	}
+	for _, op := range req.MustInclude {
+		if !found[op] {
+			selErr.Missing = append(selErr.Missing, op)
+			found[op] = true
+		}
+	}
+	if len(selErr.Missing) > 0 {
+		return nil, selErr
+	}
+
+	if fa, ok := s.Selector.(feeAwareSelector); ok {
+		params := fa.feeParams()
+		economical := candidates[:0:0]
+		for _, c := range candidates {
+			if len(params.effectiveCoins([]Coin{c})) == 0 {
+				selErr.Excluded = append(selErr.Excluded,
+					ExcludedCoin{c, ExcludedUneconomical})
+				continue
+			}
+			economical = append(economical, c)
+		}
+		candidates = economical
+	}
+
+	// Partition the candidates by address group.  When coins must be
+	// included, only their group may be selected from.
+	groups := [][]Coin{candidates}
+	if req.AddressGroup != nil {
+		groups = nil
+		presetGroup := ""
+		for i, c := range presets {
+			group := req.AddressGroup(c)
+			if i > 0 && group != presetGroup {
+				selErr.MixedGroups = true
+				return nil, selErr
+			}
+			presetGroup = group
+		}
+
+		index := make(map[string]int)
+		for _, c := range candidates {
+			group := req.AddressGroup(c)
+			if len(presets) > 0 && group != presetGroup {
+				selErr.Excluded = append(selErr.Excluded,
+					ExcludedCoin{c, ExcludedOtherGroup})
+				continue
+			}
+			i, ok := index[group]
+			if !ok {
+				i = len(groups)
+				index[group] = i
+				groups = append(groups, nil)
+			}
+			groups[i] = append(groups[i], c)
+		}
+		if len(groups) == 0 {
+			groups = [][]Coin{nil}
+		}
+	}
+
+	var best Coins
+	for _, group := range groups {
+		var available monautil.Amount
+		for _, c := range presets {
+			available += c.Value()
+		}
+		for _, c := range group {
+			available += c.Value()
+		}
+		if available > selErr.Available {
+			selErr.Available = available
+		}
+
+		var (
+			selected Coins
+			err      error
+		)
+		if req.GroupByScript {
+			selected, err = s.selectWithPresets(
+				req.TargetValue, presets, bundleByScript(group),
+			)
+		}
+		if !req.GroupByScript || err != nil {
+			selected, err = s.selectWithPresets(
+				req.TargetValue, presets, group,
+			)
+		}
+		if err != nil {
+			selErr.Err = err
+			continue
+		}
+		if best == nil || betterSelection(selected, best) {
+			best = selected
+		}
+	}
+	if best == nil {
+		return nil, selErr
+	}
+
+	return best, nil
+}
+
+// betterSelection returns whether selection a is preferred over b: the one
+// with less waste when both report it, and otherwise the one spending less
+// value.
+func betterSelection(a, b Coins) bool {
+	sa, okA := a.(*Selection)
+	sb, okB := b.(*Selection)
+	if okA && okB {
+		return sa.Waste < sb.Waste
+	}
+	return NewCoinSet(a.Coins()).TotalValue() <
+		NewCoinSet(b.Coins()).TotalValue()
+}
+
+// selectWithPresets selects coins for the target value which spend all the
+// presets along with any other coins the wrapped selector picks.
+func (s *ConstrainedCoinSelector) selectWithPresets(
+	targetValue monautil.Amount, presets, coins []Coin) (Coins, error) {
+
+	fa, ok := s.Selector.(feeAwareSelector)
+	if !ok {
+		var presetValue monautil.Amount
+		for _, c := range presets {
+			presetValue += c.Value()
+		}
+		if len(presets) > 0 && presetValue >= targetValue {
+			return NewCoinSet(presets), nil
+		}
+
+		selected, err := s.Selector.CoinSelect(
+			targetValue-presetValue, coins,
+		)
+		if err != nil {
+			return nil, err
+		}
+		set := NewCoinSet(presets)
+		for _, c := range expandBundles(selected.Coins()) {
+			set.PushCoin(c)
+		}
+		return set, nil
+	}
+
+	// The effective value of the presets reduces the target value of the
+	// wrapped selector, even if it is negative.
+	params := fa.feeParams()
+	presetCoins := params.spendCoins(presets)
+	var presetValue monautil.Amount
+	for _, c := range presetCoins {
+		presetValue += c.value
+	}
+	if len(presets) > 0 && presetValue >= targetValue+params.baseFee() {
+		return params.newSelection(targetValue, presetCoins, true), nil
+	}
+
+	selected, err := fa.Select(targetValue-presetValue, coins)
+	if err != nil {
+		return nil, err
+	}
+	all := append(presetCoins, params.spendCoins(selected.Coins())...)
+	return params.newSelection(targetValue, all, selected.NeedsChange()), nil
+}
+
+// scriptBundle is a Coin standing for all the coins paying to one public key
+// script, so that selectors spend them together.  The fee selectors account
+// for the input of every coin in the bundle.
+type scriptBundle struct {
+	coins []Coin
+}
+
+// Ensure that scriptBundle is a Coin.
+var _ Coin = (*scriptBundle)(nil)
+
+// Hash returns the hash of the first coin of the bundle.
+func (b *scriptBundle) Hash() *chainhash.Hash { return b.coins[0].Hash() }
+
+// Index returns the index of the first coin of the bundle.
+func (b *scriptBundle) Index() uint32 { return b.coins[0].Index() }
+
+// PkScript returns the public key script all coins of the bundle pay to.
+func (b *scriptBundle) PkScript() []byte { return b.coins[0].PkScript() }
+
+// Value returns the total value of the coins of the bundle.
+func (b *scriptBundle) Value() monautil.Amount {
+	var value monautil.Amount
+	for _, c := range b.coins {
+		value += c.Value()
+	}
+	return value
+}
+
+// NumConfs returns the fewest confirmations of the coins of the bundle.
+func (b *scriptBundle) NumConfs() int64 {
+	numConfs := b.coins[0].NumConfs()
+	for _, c := range b.coins[1:] {
+		if c.NumConfs() < numConfs {
+			numConfs = c.NumConfs()
+		}
+	}
+	return numConfs
+}
+
+// ValueAge returns the total value age of the coins of the bundle.
+func (b *scriptBundle) ValueAge() int64 {
+	var valueAge int64
+	for _, c := range b.coins {
+		valueAge += c.ValueAge()
+	}
+	return valueAge
+}
+
+// bundleByScript returns the coins with those paying to the same public key
+// script replaced by a single bundle, in the order of their first coin.
+func bundleByScript(coins []Coin) []Coin {
+	index := make(map[string]int)
+	var bundles []*scriptBundle
+	for _, c := range coins {
+		script := string(c.PkScript())
+		i, ok := index[script]
+		if !ok {
+			i = len(bundles)
+			index[script] = i
+			bundles = append(bundles, &scriptBundle{})
+		}
+		bundles[i].coins = append(bundles[i].coins, c)
+	}
+
+	bundled := make([]Coin, len(bundles))
+	for i, b := range bundles {
+		if len(b.coins) == 1 {
+			bundled[i] = b.coins[0]
+		} else {
+			bundled[i] = b
+		}
+	}
+	return bundled
+}
+
+// expandBundles returns the coins with every bundle replaced by its coins.
+func expandBundles(coins []Coin) []Coin {
+	expanded := make([]Coin, 0, len(coins))
+	for _, c := range coins {
+		if b, ok := c.(*scriptBundle); ok {
+			expanded = append(expanded, b.coins...)
+			continue
+		}
+		expanded = append(expanded, c)
+	}
+	return expanded
+}
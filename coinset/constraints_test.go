@@ -0,0 +1,330 @@
+// Copyright (c) 2026 The monasuite developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package coinset_test
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/monasuite/monad/wire"
+	"github.com/monasuite/monautil/coinset"
+)
+
+// outPoint returns the outpoint of the coin.
+func outPoint(c coinset.Coin) wire.OutPoint {
+	return wire.OutPoint{Hash: *c.Hash(), Index: c.Index()}
+}
+
+func TestConstrainedSelector(t *testing.T) {
+	a := NewCoin(1, 1e8, 0)
+	b := NewCoin(2, 2e8, 6)
+	c := NewCoin(3, 3e8, 6)
+	d := NewCoin(4, 26e7, 6)
+	unknown := NewCoin(5, 1e8, 6)
+	coins := []coinset.Coin{a, b, c}
+
+	// Coins a and b are in group x, and c and d in group y.
+	groups := map[coinset.Coin]string{a: "x", b: "x", c: "y", d: "y"}
+	addressGroup := func(c coinset.Coin) string { return groups[c] }
+
+	// Coins e and f pay to the same script, and g to another.
+	e := &scriptCoin{NewCoin(6, 1e8, 6), []byte{0x51}}
+	f := &scriptCoin{NewCoin(7, 1e8, 6), []byte{0x51}}
+	g := &scriptCoin{NewCoin(8, 1e8, 6), []byte{0x52}}
+
+	minIndex := coinset.MinIndexCoinSelector{MaxInputs: 10}
+
+	tests := []struct {
+		name     string
+		selector coinset.CoinSelector
+		req      coinset.SelectionRequest
+		want     []coinset.Coin
+		err      *coinset.SelectionError
+	}{
+		{
+			name:     "no constraints",
+			selector: minIndex,
+			req:      coinset.SelectionRequest{TargetValue: 2e8, Coins: coins},
+			want:     []coinset.Coin{a, b},
+		},
+		{
+			name:     "excluded coin",
+			selector: minIndex,
+			req: coinset.SelectionRequest{
+				TargetValue: 1e8,
+				Coins:       coins,
+				Exclude:     []wire.OutPoint{outPoint(a)},
+			},
+			want: []coinset.Coin{b},
+		},
+		{
+			name:     "too few confirmations",
+			selector: minIndex,
+			req: coinset.SelectionRequest{
+				TargetValue: 6e8,
+				Coins:       coins,
+				MinConfs:    1,
+			},
+			err: &coinset.SelectionError{
+				TargetValue: 6e8,
+				Available:   5e8,
+				Excluded: []coinset.ExcludedCoin{
+					{Coin: a, Reason: coinset.ExcludedUnconfirmed},
+				},
+				Err: coinset.ErrCoinsNoSelectionAvailable,
+			},
+		},
+		{
+			name:     "required coin pays target",
+			selector: minIndex,
+			req: coinset.SelectionRequest{
+				TargetValue: 1e8,
+				Coins:       coins,
+				MustInclude: []wire.OutPoint{outPoint(c)},
+			},
+			want: []coinset.Coin{c},
+		},
+		{
+			name:     "required unconfirmed coin",
+			selector: minIndex,
+			req: coinset.SelectionRequest{
+				TargetValue: 4e8,
+				Coins:       coins,
+				MustInclude: []wire.OutPoint{outPoint(a)},
+				MinConfs:    1,
+			},
+			want: []coinset.Coin{a, b, c},
+		},
+		{
+			name:     "conflicting outpoint",
+			selector: minIndex,
+			req: coinset.SelectionRequest{
+				TargetValue: 1e8,
+				Coins:       coins,
+				MustInclude: []wire.OutPoint{outPoint(a)},
+				Exclude:     []wire.OutPoint{outPoint(a)},
+			},
+			err: &coinset.SelectionError{
+				TargetValue: 1e8,
+				Conflicting: []wire.OutPoint{outPoint(a)},
+			},
+		},
+		{
+			name:     "missing outpoint",
+			selector: minIndex,
+			req: coinset.SelectionRequest{
+				TargetValue: 1e8,
+				Coins:       coins,
+				MustInclude: []wire.OutPoint{outPoint(unknown)},
+			},
+			err: &coinset.SelectionError{
+				TargetValue: 1e8,
+				Missing:     []wire.OutPoint{outPoint(unknown)},
+			},
+		},
+		{
+			name:     "group spending least value",
+			selector: minIndex,
+			req: coinset.SelectionRequest{
+				TargetValue:  25e7,
+				Coins:        []coinset.Coin{a, b, d},
+				AddressGroup: addressGroup,
+			},
+			want: []coinset.Coin{d},
+		},
+		{
+			name:     "groups not mixed",
+			selector: minIndex,
+			req: coinset.SelectionRequest{
+				TargetValue:  5e8,
+				Coins:        []coinset.Coin{a, b, d},
+				AddressGroup: addressGroup,
+			},
+			err: &coinset.SelectionError{
+				TargetValue: 5e8,
+				Available:   3e8,
+				Err:         coinset.ErrCoinsNoSelectionAvailable,
+			},
+		},
+		{
+			name:     "group of required coin",
+			selector: minIndex,
+			req: coinset.SelectionRequest{
+				TargetValue:  4e8,
+				Coins:        []coinset.Coin{a, b, c, d},
+				MustInclude:  []wire.OutPoint{outPoint(c)},
+				AddressGroup: addressGroup,
+			},
+			want: []coinset.Coin{c, d},
+		},
+		{
+			name:     "required coins of mixed groups",
+			selector: minIndex,
+			req: coinset.SelectionRequest{
+				TargetValue:  1e8,
+				Coins:        coins,
+				MustInclude:  []wire.OutPoint{outPoint(a), outPoint(c)},
+				AddressGroup: addressGroup,
+			},
+			err: &coinset.SelectionError{
+				TargetValue: 1e8,
+				MixedGroups: true,
+			},
+		},
+		{
+			name:     "coins of a script spent together",
+			selector: minIndex,
+			req: coinset.SelectionRequest{
+				TargetValue:   15e7,
+				Coins:         []coinset.Coin{g, e, f},
+				GroupByScript: true,
+			},
+			want: []coinset.Coin{g, e, f},
+		},
+		{
+			name: "coins of a script split if needed",
+			selector: coinset.MinIndexCoinSelector{
+				MaxInputs:       10,
+				MinChangeAmount: 5e8,
+			},
+			req: coinset.SelectionRequest{
+				TargetValue:   1e8,
+				Coins:         []coinset.Coin{e, g, f},
+				GroupByScript: true,
+			},
+			want: []coinset.Coin{e},
+		},
+	}
+
+	for _, test := range tests {
+		selector := &coinset.ConstrainedCoinSelector{
+			Selector: test.selector,
+		}
+		selected, err := selector.Select(&test.req)
+		if test.err != nil {
+			selErr, ok := err.(*coinset.SelectionError)
+			if !ok {
+				t.Errorf("%s: got error %v, want *SelectionError",
+					test.name, err)
+				continue
+			}
+			if !reflect.DeepEqual(selErr, test.err) {
+				t.Errorf("%s: got error %+v, want %+v", test.name,
+					selErr, test.err)
+			}
+			if !errors.Is(err, coinset.ErrCoinsNoSelectionAvailable) {
+				t.Errorf("%s: error doesn't unwrap to %v",
+					test.name, coinset.ErrCoinsNoSelectionAvailable)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s: unexpected error: %v", test.name, err)
+			continue
+		}
+
+		if !reflect.DeepEqual(selected.Coins(), test.want) {
+			t.Errorf("%s: got %d coins %v, want %d coins %v",
+				test.name, len(selected.Coins()),
+				selected.Coins(), len(test.want), test.want)
+		}
+	}
+}
+
+func TestConstrainedFeeSelector(t *testing.T) {
+	coins := effectiveCoins(1e5, 2e5, 3e5, 5e5)
+	uneconomical := NewCoin(10, testInputFee, 6)
+	params := testFeeParams()
+	selector := &coinset.ConstrainedCoinSelector{
+		Selector: &coinset.BranchAndBoundCoinSelector{FeeParams: params},
+	}
+
+	// The required coin pays part of the target, and the rest is
+	// selected without change.
+	req := &coinset.SelectionRequest{
+		TargetValue: 599500,
+		Coins:       coins,
+		MustInclude: []wire.OutPoint{outPoint(coins[0])},
+	}
+	selected, err := selector.Select(req)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	s, ok := selected.(*coinset.Selection)
+	if !ok {
+		t.Fatalf("got %T, want *coinset.Selection", selected)
+	}
+	checkSelection(t, "required coin", params, req.TargetValue, s)
+	want := []coinset.Coin{coins[0], coins[2], coins[1]}
+	if !reflect.DeepEqual(s.Coins(), want) {
+		t.Errorf("got coins %v, want %v", s.Coins(), want)
+	}
+	if s.NeedsChange() || s.Waste != 500 {
+		t.Errorf("got change %v and waste %v, want none and 500",
+			s.Change, int64(s.Waste))
+	}
+
+	// Bundled coins pay the fee of every input.
+	e := &scriptCoin{NewCoin(6, 1e5+testInputFee, 6), []byte{0x51}}
+	f := &scriptCoin{NewCoin(7, 2e5+testInputFee, 6), []byte{0x51}}
+	req = &coinset.SelectionRequest{
+		TargetValue:   3e5,
+		Coins:         []coinset.Coin{e, f, coins[2]},
+		GroupByScript: true,
+	}
+	params.LongTermFeeRate = 20000
+	selector.Selector = &coinset.BranchAndBoundCoinSelector{FeeParams: params}
+	selected, err = selector.Select(req)
+	if err != nil {
+		t.Fatalf("bundle: unexpected error: %v", err)
+	}
+	s = selected.(*coinset.Selection)
+	checkSelection(t, "bundle", params, req.TargetValue, s)
+	want = []coinset.Coin{e, f}
+	if !reflect.DeepEqual(s.Coins(), want) || s.Waste != -1360 {
+		t.Errorf("bundle: got coins %v and waste %v, want %v and -1360",
+			s.Coins(), int64(s.Waste), want)
+	}
+
+	// Uneconomical coins are explained.
+	req = &coinset.SelectionRequest{
+		TargetValue: 2e6,
+		Coins:       append([]coinset.Coin{uneconomical}, coins...),
+	}
+	_, err = selector.Select(req)
+	selErr, ok := err.(*coinset.SelectionError)
+	if !ok {
+		t.Fatalf("got error %v, want *SelectionError", err)
+	}
+	wantExcluded := []coinset.ExcludedCoin{
+		{Coin: uneconomical, Reason: coinset.ExcludedUneconomical},
+	}
+	if !reflect.DeepEqual(selErr.Excluded, wantExcluded) {
+		t.Errorf("got excluded %v, want %v", selErr.Excluded,
+			wantExcluded)
+	}
+	if !strings.Contains(selErr.Error(), "1 coins uneconomical") {
+		t.Errorf("error %q doesn't explain the excluded coin", selErr)
+	}
+}
+
+func TestSelectionErrorString(t *testing.T) {
+	err := &coinset.SelectionError{
+		TargetValue: 6e8,
+		Available:   5e8,
+		Excluded: []coinset.ExcludedCoin{
+			{Reason: coinset.ExcludedUnconfirmed},
+			{Reason: coinset.ExcludedByRequest},
+			{Reason: coinset.ExcludedUnconfirmed},
+		},
+	}
+	want := "no coin selection possible for 6 MONA with 5 MONA available " +
+		"(2 coins too few confirmations, 1 coins excluded by request)"
+	if err.Error() != want {
+		t.Errorf("got %q, want %q", err.Error(), want)
+	}
+}
@@ -0,0 +1,307 @@
+// Copyright (c) 2026 The monasuite developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package coinset
+
+import (
+	"math/rand"
+	"sort"
+
+	"github.com/monasuite/monautil"
+)
+
+// knapsackIterations is the number of random passes made by the knapsack
+// selector to approximate the best subset of the coins.
+const knapsackIterations = 1000
+
+// shuffle shuffles the coins with the passed source of randomness, or the
+// default source if it is nil.
+func shuffle(r *rand.Rand, coins []effectiveCoin) {
+	swap := func(i, j int) { coins[i], coins[j] = coins[j], coins[i] }
+	if r == nil {
+		rand.Shuffle(len(coins), swap)
+		return
+	}
+	r.Shuffle(len(coins), swap)
+}
+
+// randBool returns a random boolean from the passed source of randomness, or
+// the default source if it is nil.
+func randBool(r *rand.Rand) bool {
+	if r == nil {
+		return rand.Intn(2) == 0
+	}
+	return r.Intn(2) == 0
+}
+
+// KnapsackCoinSelector is a CoinSelector modeled on the knapsack algorithm of
+// Bitcoin Core, working on effective values.  A coin whose effective value
+// pays exactly the target value and the base fee is selected alone.
+// Otherwise, random subsets of the smaller coins are tried to find the one
+// paying closest to that amount, or to that amount plus a change output of at
+// least MinChange, and the smallest coin larger than both is used if it comes
+// closer.
+type KnapsackCoinSelector struct {
+	FeeParams
+
+	// Rand is the source of randomness, or nil for the default source.
+	Rand *rand.Rand
+}
+
+// CoinSelect will attempt to select coins using the algorithm described
+// in the KnapsackCoinSelector struct.  The returned Coins is a *Selection.
+func (s *KnapsackCoinSelector) CoinSelect(targetValue monautil.Amount,
+	coins []Coin) (Coins, error) {
+
+	return s.Select(targetValue, coins)
+}
+
+// Select is the equivalent of CoinSelect returning the *Selection.
+func (s *KnapsackCoinSelector) Select(targetValue monautil.Amount,
+	coins []Coin) (*Selection, error) {
+
+	pool := s.effectiveCoins(coins)
+	shuffle(s.Rand, pool)
+
+	target := targetValue + s.baseFee()
+	changeTarget := s.changeFee() + s.MinChange
+
+	var (
+		applicable   []effectiveCoin
+		totalLower   monautil.Amount
+		lowestLarger *effectiveCoin
+	)
+	for i := range pool {
+		c := &pool[i]
+		switch {
+		case c.value == target:
+			return s.newSelection(
+				targetValue, []effectiveCoin{*c}, true,
+			), nil
+
+		case c.value < target+changeTarget:
+			applicable = append(applicable, *c)
+			totalLower += c.value
+
+		case lowestLarger == nil || c.value < lowestLarger.value:
+			lowestLarger = c
+		}
+	}
+
+	// useLowestLarger returns the selection of the smallest larger coin.
+	useLowestLarger := func() (*Selection, error) {
+		if lowestLarger == nil {
+			return nil, ErrCoinsNoSelectionAvailable
+		}
+		return s.newSelection(
+			targetValue, []effectiveCoin{*lowestLarger}, true,
+		), nil
+	}
+
+	if totalLower == target && len(applicable) > 0 &&
+		!s.tooManyInputs(len(applicable)) {
+
+		return s.newSelection(targetValue, applicable, true), nil
+	}
+	if totalLower < target {
+		return useLowestLarger()
+	}
+
+	sort.SliceStable(applicable, func(i, j int) bool {
+		return applicable[i].value > applicable[j].value
+	})
+	best, bestValue := s.approximateBestSubset(applicable, totalLower, target)
+	if bestValue != target && totalLower >= target+changeTarget {
+		best, bestValue = s.approximateBestSubset(
+			applicable, totalLower, target+changeTarget,
+		)
+	}
+
+	// Prefer the smallest larger coin when the subset pays neither the
+	// exact target nor enough for change, or when it is no smaller.
+	if best == nil || lowestLarger != nil &&
+		((bestValue != target && bestValue < target+changeTarget) ||
+			lowestLarger.value <= bestValue) {
+
+		return useLowestLarger()
+	}
+
+	return s.newSelection(targetValue, best, true), nil
+}
+
+// approximateBestSubset randomly includes coins to find the subset whose
+// effective value is the smallest at or above the target, returning the
+// subset and its value, or a nil subset if no nonempty subset within the
+// input limit was found.
+func (s *KnapsackCoinSelector) approximateBestSubset(coins []effectiveCoin,
+	totalLower, target monautil.Amount) ([]effectiveCoin, monautil.Amount) {
+
+	best := make([]bool, len(coins))
+	for i := range best {
+		best[i] = true
+	}
+	bestValue := totalLower
+	found := !s.tooManyInputs(len(coins))
+
+	included := make([]bool, len(coins))
+	for rep := 0; rep < knapsackIterations && bestValue != target; rep++ {
+		for i := range included {
+			included[i] = false
+		}
+		var total monautil.Amount
+		numIncluded := 0
+		reachedTarget := false
+
+		// The first pass includes coins at random, and the second
+		// includes the coins the first left out until the target is
+		// reached.
+		for pass := 0; pass < 2 && !reachedTarget; pass++ {
+			for i, c := range coins {
+				var include bool
+				if pass == 0 {
+					include = randBool(s.Rand)
+				} else {
+					include = !included[i]
+				}
+				if !include {
+					continue
+				}
+
+				total += c.value
+				numIncluded++
+				included[i] = true
+				if total < target {
+					continue
+				}
+
+				reachedTarget = true
+				if (!found || total < bestValue) &&
+					!s.tooManyInputs(numIncluded) {
+
+					bestValue = total
+					copy(best, included)
+					found = true
+				}
+				total -= c.value
+				numIncluded--
+				included[i] = false
+			}
+		}
+	}
+
+	if !found || bestValue == 0 {
+		return nil, 0
+	}
+	subset := make([]effectiveCoin, 0, len(coins))
+	for i, include := range best {
+		if include {
+			subset = append(subset, coins[i])
+		}
+	}
+	return subset, bestValue
+}
+
+// SingleRandomDrawCoinSelector is a CoinSelector modeled on the single random
+// draw algorithm of Bitcoin Core.  It selects coins in random order until
+// their effective value pays the target value, the base fee and a change
+// output of at least MinChange.  When the input limit is reached, the coin
+// with the smallest effective value is dropped for the next one.
+type SingleRandomDrawCoinSelector struct {
+	FeeParams
+
+	// Rand is the source of randomness, or nil for the default source.
+	Rand *rand.Rand
+}
+
+// CoinSelect will attempt to select coins using the algorithm described
+// in the SingleRandomDrawCoinSelector struct.  The returned Coins is a
+// *Selection.
+func (s *SingleRandomDrawCoinSelector) CoinSelect(targetValue monautil.Amount,
+	coins []Coin) (Coins, error) {
+
+	return s.Select(targetValue, coins)
+}
+
+// Select is the equivalent of CoinSelect returning the *Selection.
+func (s *SingleRandomDrawCoinSelector) Select(targetValue monautil.Amount,
+	coins []Coin) (*Selection, error) {
+
+	pool := s.effectiveCoins(coins)
+	shuffle(s.Rand, pool)
+
+	target := targetValue + s.baseFee() + s.changeFee() + s.MinChange
+	var (
+		selected []effectiveCoin
+		value    monautil.Amount
+	)
+	for _, c := range pool {
+		selected = append(selected, c)
+		value += c.value
+
+		if s.tooManyInputs(len(selected)) {
+			smallest := 0
+			for i := range selected {
+				if selected[i].value < selected[smallest].value {
+					smallest = i
+				}
+			}
+			value -= selected[smallest].value
+			selected = append(selected[:smallest],
+				selected[smallest+1:]...)
+		}
+
+		if value >= target {
+			return s.newSelection(targetValue, selected, true), nil
+		}
+	}
+
+	return nil, ErrCoinsNoSelectionAvailable
+}
+
+// MinWasteCoinSelector is a CoinSelector which runs the branch and bound,
+// knapsack and single random draw selectors, and returns the selection with
+// the least waste, preferring them in that order when their waste is equal.
+// Like Bitcoin Core, it thereby avoids change outputs when a changeless
+// selection is found and falls back to selections with change otherwise.
+type MinWasteCoinSelector struct {
+	FeeParams
+
+	// Rand is the source of randomness, or nil for the default source.
+	Rand *rand.Rand
+}
+
+// CoinSelect will attempt to select coins using the algorithm described
+// in the MinWasteCoinSelector struct.  The returned Coins is a *Selection.
+func (s *MinWasteCoinSelector) CoinSelect(targetValue monautil.Amount,
+	coins []Coin) (Coins, error) {
+
+	return s.Select(targetValue, coins)
+}
+
+// Select is the equivalent of CoinSelect returning the *Selection.
+func (s *MinWasteCoinSelector) Select(targetValue monautil.Amount,
+	coins []Coin) (*Selection, error) {
+
+	selectors := []func(monautil.Amount, []Coin) (*Selection, error){
+		(&BranchAndBoundCoinSelector{s.FeeParams}).Select,
+		(&KnapsackCoinSelector{s.FeeParams, s.Rand}).Select,
+		(&SingleRandomDrawCoinSelector{s.FeeParams, s.Rand}).Select,
+	}
+
+	var best *Selection
+	for _, selectFn := range selectors {
+		selection, err := selectFn(targetValue, coins)
+		if err != nil {
+			continue
+		}
+		if best == nil || selection.Waste < best.Waste {
+			best = selection
+		}
+	}
+	if best == nil {
+		return nil, ErrCoinsNoSelectionAvailable
+	}
+
+	return best, nil
+}
@@ -0,0 +1,357 @@
+// Copyright (c) 2026 The monasuite developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package coinset
+
+import (
+	"sort"
+
+	"github.com/monasuite/monautil"
+	"github.com/monasuite/monautil/txsizes"
+)
+
+// InputWeightFunc returns the weight a coin adds to a transaction spending
+// it.
+type InputWeightFunc func(Coin) int64
+
+// ScriptInputWeight is an InputWeightFunc which estimates the weight from the
+// public key script of the coin.  P2SH coins are assumed to be P2SH-P2WPKH,
+// and coins of other non-witness-key-hash types are assumed to cost as much as
+// P2PKH coins, so a custom InputWeightFunc should be used for wallets holding
+// other script types.
+func ScriptInputWeight(c Coin) int64 {
+	switch monautil.GetScriptClass(c.PkScript()) {
+	case monautil.WitnessV0PubKeyHashTy:
+		return txsizes.Input{Type: txsizes.P2WPKH}.Weight()
+	case monautil.ScriptHashTy:
+		return txsizes.Input{Type: txsizes.NestedP2WPKH}.Weight()
+	default:
+		return txsizes.Input{Type: txsizes.P2PKH}.Weight()
+	}
+}
+
+// FeeParams describes the fees the selectors based on effective values
+// account for.  The effective value of a coin is its value minus the fee to
+// spend it at FeeRate, and coins whose effective value isn't positive are
+// never selected.
+type FeeParams struct {
+	// FeeRate is the fee rate of the transaction.
+	FeeRate monautil.FeeRatePerKVByte
+
+	// LongTermFeeRate is the fee rate expected to be paid to spend coins
+	// in the future.  It is used to compute the waste of selections, and
+	// FeeRate is used when it is zero.
+	LongTermFeeRate monautil.FeeRatePerKVByte
+
+	// BaseWeight is the weight of the transaction without any inputs or
+	// change output, which includes the payment outputs.
+	BaseWeight int64
+
+	// ChangeWeight is the weight a change output adds to the transaction.
+	ChangeWeight int64
+
+	// ChangeSpendWeight is the weight of an input spending the change
+	// output later.
+	ChangeSpendWeight int64
+
+	// MinChange is the smallest change output worth creating.  Smaller
+	// change is added to the fee instead.
+	MinChange monautil.Amount
+
+	// MaxInputs is the largest number of coins selected, or zero for no
+	// limit.
+	MaxInputs int
+
+	// InputWeight returns the weight of the input spending a coin.
+	// ScriptInputWeight is used when it is nil.
+	InputWeight InputWeightFunc
+}
+
+// longTermFeeRate returns the long term fee rate, defaulting to the fee rate.
+func (p *FeeParams) longTermFeeRate() monautil.FeeRatePerKVByte {
+	if p.LongTermFeeRate == 0 {
+		return p.FeeRate
+	}
+	return p.LongTermFeeRate
+}
+
+// baseFee returns the fee of the transaction without inputs or change.
+func (p *FeeParams) baseFee() monautil.Amount {
+	return p.FeeRate.FeeForWeight(p.BaseWeight)
+}
+
+// changeFee returns the fee of the change output.
+func (p *FeeParams) changeFee() monautil.Amount {
+	return p.FeeRate.FeeForWeight(p.ChangeWeight)
+}
+
+// costOfChange returns the fee of creating the change output now and
+// spending it later.
+func (p *FeeParams) costOfChange() monautil.Amount {
+	return p.changeFee() +
+		p.longTermFeeRate().FeeForWeight(p.ChangeSpendWeight)
+}
+
+// tooManyInputs returns whether n coins exceed the input limit.
+func (p *FeeParams) tooManyInputs(n int) bool {
+	return p.MaxInputs > 0 && n > p.MaxInputs
+}
+
+// effectiveCoin is a coin with the fees to spend it.
+type effectiveCoin struct {
+	coin        Coin
+	value       monautil.Amount // effective value
+	fee         monautil.Amount // fee to spend at the fee rate
+	longTermFee monautil.Amount // fee to spend at the long term fee rate
+}
+
+// spendCoin returns the coin with the fees to spend it.  The fees of bundles
+// are the sum of the fees of their coins.
+func (p *FeeParams) spendCoin(c Coin) effectiveCoin {
+	inputWeight := p.InputWeight
+	if inputWeight == nil {
+		inputWeight = ScriptInputWeight
+	}
+
+	longTermFeeRate := p.longTermFeeRate()
+	ec := effectiveCoin{coin: c}
+	for _, member := range expandBundles([]Coin{c}) {
+		weight := inputWeight(member)
+		ec.fee += p.FeeRate.FeeForWeight(weight)
+		ec.longTermFee += longTermFeeRate.FeeForWeight(weight)
+	}
+	ec.value = c.Value() - ec.fee
+	return ec
+}
+
+// spendCoins returns the coins with the fees to spend them, in the same
+// order.
+func (p *FeeParams) spendCoins(coins []Coin) []effectiveCoin {
+	spent := make([]effectiveCoin, len(coins))
+	for i, c := range coins {
+		spent[i] = p.spendCoin(c)
+	}
+	return spent
+}
+
+// effectiveCoins returns the coins with positive effective values, in the
+// same order.
+func (p *FeeParams) effectiveCoins(coins []Coin) []effectiveCoin {
+	effective := make([]effectiveCoin, 0, len(coins))
+	for _, c := range coins {
+		ec := p.spendCoin(c)
+		if ec.value <= 0 {
+			continue
+		}
+		effective = append(effective, ec)
+	}
+	return effective
+}
+
+// Selection is a selection of coins made by the selectors based on effective
+// values, along with the fee, change and waste of the transaction spending
+// them.  It implements the Coins interface.
+type Selection struct {
+	coins []Coin
+
+	// InputValue is the total value of the selected coins.
+	InputValue monautil.Amount
+
+	// Fee is the fee paid by the transaction, including the fee of the
+	// change output and any excess value that isn't worth a change output.
+	Fee monautil.Amount
+
+	// Change is the value of the change output, or zero if the selection
+	// needs no change output.
+	Change monautil.Amount
+
+	// Waste is the waste metric of the selection: the fees paid for the
+	// inputs above their cost at the long term fee rate, plus the cost of
+	// the change output, or the excess value dropped to the fee when there
+	// is no change output.  Selections with less waste are preferred.
+	Waste monautil.Amount
+}
+
+// Ensure that Selection is a Coins.
+var _ Coins = (*Selection)(nil)
+
+// Coins returns the selected coins.
+func (s *Selection) Coins() []Coin {
+	return s.coins
+}
+
+// NeedsChange returns whether the transaction needs a change output.
+func (s *Selection) NeedsChange() bool {
+	return s.Change > 0
+}
+
+// newSelection returns the selection of the passed coins paying the target
+// value.  A change output is added when allowChange is set and the change is
+// at least MinChange.  The coins must have enough effective value to pay the
+// target value and the base fee.
+func (p *FeeParams) newSelection(targetValue monautil.Amount,
+	selected []effectiveCoin, allowChange bool) *Selection {
+
+	s := &Selection{coins: make([]Coin, 0, len(selected))}
+	var effectiveValue monautil.Amount
+	for _, c := range selected {
+		s.coins = append(s.coins, expandBundles([]Coin{c.coin})...)
+		s.InputValue += c.coin.Value()
+		effectiveValue += c.value
+		s.Waste += c.fee - c.longTermFee
+	}
+
+	excess := effectiveValue - targetValue - p.baseFee()
+	change := excess - p.changeFee()
+	if allowChange && change > 0 && change >= p.MinChange {
+		s.Change = change
+		s.Waste += p.costOfChange()
+	} else {
+		s.Waste += excess
+	}
+	s.Fee = s.InputValue - targetValue - s.Change
+
+	return s
+}
+
+// bnbTotalTries is the number of steps of the branch and bound search after
+// which the best selection found so far is returned.
+const bnbTotalTries = 100000
+
+// BranchAndBoundCoinSelector is a CoinSelector that searches for a selection
+// of coins which needs no change output, modeled on the branch and bound
+// algorithm of Bitcoin Core.  The effective value of the selection must cover
+// the target value and the base fee, and may exceed them by at most the cost
+// of creating and later spending a change output, which is dropped to the
+// fee.  Among such selections, the one with the least waste is returned.
+//
+// The search gives up after a fixed number of steps and returns
+// ErrCoinsNoSelectionAvailable when no changeless selection exists, so it is
+// usually followed by a selector that creates change, as done by
+// MinWasteCoinSelector.
+type BranchAndBoundCoinSelector struct {
+	FeeParams
+}
+
+// CoinSelect will attempt to select coins using the algorithm described
+// in the BranchAndBoundCoinSelector struct.  The returned Coins is a
+// *Selection.
+func (s *BranchAndBoundCoinSelector) CoinSelect(targetValue monautil.Amount,
+	coins []Coin) (Coins, error) {
+
+	return s.Select(targetValue, coins)
+}
+
+// Select is the equivalent of CoinSelect returning the *Selection.
+func (s *BranchAndBoundCoinSelector) Select(targetValue monautil.Amount,
+	coins []Coin) (*Selection, error) {
+
+	pool := s.effectiveCoins(coins)
+	sort.SliceStable(pool, func(i, j int) bool {
+		return pool[i].value > pool[j].value
+	})
+
+	var available monautil.Amount
+	for _, c := range pool {
+		available += c.value
+	}
+	selectionTarget := targetValue + s.baseFee()
+	costOfChange := s.costOfChange()
+	if available < selectionTarget {
+		return nil, ErrCoinsNoSelectionAvailable
+	}
+
+	// Spending coins now rather than later only wastes fees when the fee
+	// rate is above the long term fee rate, so only then can selections
+	// with more waste than the best be abandoned early.
+	wasteGrows := len(pool) > 0 && pool[0].fee > pool[0].longTermFee
+
+	var (
+		value, waste monautil.Amount
+		numSelected  int
+		included     []bool // inclusion of each coin considered so far
+		best         []bool
+		bestWaste    monautil.Amount
+		found        bool
+	)
+	for try := 0; try < bnbTotalTries; try++ {
+		backtrack := false
+		switch {
+		case value+available < selectionTarget,
+			value > selectionTarget+costOfChange,
+			found && waste > bestWaste && wasteGrows,
+			s.tooManyInputs(numSelected):
+
+			backtrack = true
+
+		case value >= selectionTarget && numSelected > 0:
+			// The excess is dropped to the fee, so it is waste.
+			excess := value - selectionTarget
+			if !found || waste+excess <= bestWaste {
+				best = append(best[:0], included...)
+				bestWaste = waste + excess
+				found = true
+			}
+			backtrack = true
+
+		case len(included) == len(pool):
+			// Every coin was considered without reaching the target,
+			// which only happens for targets that aren't positive.
+			backtrack = true
+		}
+
+		if backtrack {
+			if found && bestWaste == 0 {
+				break
+			}
+
+			// Walk back to the last included coin, making the coins
+			// omitted after it available again.
+			for len(included) > 0 && !included[len(included)-1] {
+				included = included[:len(included)-1]
+				available += pool[len(included)].value
+			}
+			if len(included) == 0 {
+				break
+			}
+
+			// Omit the last included coin instead.
+			last := pool[len(included)-1]
+			included[len(included)-1] = false
+			value -= last.value
+			waste -= last.fee - last.longTermFee
+			numSelected--
+			continue
+		}
+
+		// Consider the next coin.  It is omitted without trying when an
+		// identical previous coin was omitted, as including it would
+		// only repeat that branch.
+		c := pool[len(included)]
+		available -= c.value
+		if len(included) > 0 && !included[len(included)-1] &&
+			c.value == pool[len(included)-1].value &&
+			c.fee == pool[len(included)-1].fee {
+
+			included = append(included, false)
+			continue
+		}
+		included = append(included, true)
+		value += c.value
+		waste += c.fee - c.longTermFee
+		numSelected++
+	}
+
+	if !found {
+		return nil, ErrCoinsNoSelectionAvailable
+	}
+
+	selected := make([]effectiveCoin, 0, len(best))
+	for i, include := range best {
+		if include {
+			selected = append(selected, pool[i])
+		}
+	}
+	return s.newSelection(targetValue, selected, false), nil
+}
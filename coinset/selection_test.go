@@ -0,0 +1,440 @@
+// Copyright (c) 2026 The monasuite developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package coinset_test
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/monasuite/monautil"
+	"github.com/monasuite/monautil/coinset"
+	"github.com/monasuite/monautil/txsizes"
+)
+
+const (
+	// testInputWeight is the weight of every test coin's input, which
+	// costs testInputFee at testFeeRate.
+	testInputWeight = 272
+	testInputFee    = 680
+	testFeeRate     = 10000
+
+	// testChangeFee is the fee of the change output at testFeeRate, and
+	// testCostOfChange adds the fee to spend it later.
+	testChangeFee    = 310
+	testCostOfChange = testChangeFee + testInputFee
+)
+
+// testFeeParams returns the fee parameters used by the selector tests, with
+// inputs of testInputWeight and no base weight.
+func testFeeParams() coinset.FeeParams {
+	return coinset.FeeParams{
+		FeeRate:           testFeeRate,
+		ChangeWeight:      int64(txsizes.OutputSize(txsizes.P2WPKHPkScriptSize) * 4),
+		ChangeSpendWeight: testInputWeight,
+		InputWeight: func(coinset.Coin) int64 {
+			return testInputWeight
+		},
+	}
+}
+
+// effectiveCoins returns test coins whose effective values at testFeeRate
+// are the passed amounts.
+func effectiveCoins(values ...monautil.Amount) []coinset.Coin {
+	coins := make([]coinset.Coin, len(values))
+	for i, v := range values {
+		coins[i] = NewCoin(int64(i), v+testInputFee, 1)
+	}
+	return coins
+}
+
+// checkSelection ensures the fee, change and input value of a selection are
+// consistent and that the fee pays for the transaction at the fee rate.
+func checkSelection(t *testing.T, name string, params coinset.FeeParams,
+	targetValue monautil.Amount, s *coinset.Selection) {
+
+	var inputValue monautil.Amount
+	weight := params.BaseWeight
+	for _, c := range s.Coins() {
+		inputValue += c.Value()
+		weight += params.InputWeight(c)
+	}
+	if s.NeedsChange() {
+		weight += params.ChangeWeight
+		if s.Change < params.MinChange {
+			t.Errorf("%s: change %v below minimum %v", name,
+				s.Change, params.MinChange)
+		}
+	}
+
+	if s.InputValue != inputValue {
+		t.Errorf("%s: got input value %v, want %v", name,
+			s.InputValue, inputValue)
+	}
+	if s.Fee != inputValue-targetValue-s.Change {
+		t.Errorf("%s: fee %v doesn't balance input value %v, target "+
+			"%v and change %v", name, s.Fee, inputValue,
+			targetValue, s.Change)
+	}
+	if min := params.FeeRate.FeeForWeight(weight); s.Fee < min {
+		t.Errorf("%s: fee %v below %v", name, s.Fee, min)
+	}
+}
+
+func TestBranchAndBoundSelector(t *testing.T) {
+	coins := effectiveCoins(1e5, 2e5, 3e5, 5e5)
+
+	manyCoins := make([]monautil.Amount, 50)
+	for i := range manyCoins {
+		manyCoins[i] = 1e5
+	}
+
+	tests := []struct {
+		name    string
+		coins   []coinset.Coin
+		target  monautil.Amount
+		modify  func(*coinset.FeeParams)
+		indexes []int // indexes of the expected coins
+		waste   monautil.Amount
+		err     error
+	}{
+		{
+			name:    "exact match",
+			coins:   coins,
+			target:  5e5,
+			indexes: []int{3},
+		},
+		{
+			name:    "excess within cost of change",
+			coins:   coins,
+			target:  599500,
+			indexes: []int{2, 1, 0},
+			waste:   500,
+		},
+		{
+			name:   "excess above cost of change",
+			coins:  coins,
+			target: 6e5 - testCostOfChange - 1,
+			err:    coinset.ErrCoinsNoSelectionAvailable,
+		},
+		{
+			name:   "no changeless selection",
+			coins:  coins,
+			target: 55e4,
+			err:    coinset.ErrCoinsNoSelectionAvailable,
+		},
+		{
+			name:   "base fee",
+			coins:  coins,
+			target: 5e5 - testChangeFee,
+			modify: func(p *coinset.FeeParams) {
+				p.BaseWeight = 124
+			},
+			indexes: []int{3},
+		},
+		{
+			name:   "fewer inputs at high fee rate",
+			coins:  coins,
+			target: 3e5,
+			modify: func(p *coinset.FeeParams) {
+				p.LongTermFeeRate = 1000
+			},
+			indexes: []int{2},
+			waste:   testInputFee - 68,
+		},
+		{
+			name:   "more inputs at low fee rate",
+			coins:  coins,
+			target: 3e5,
+			modify: func(p *coinset.FeeParams) {
+				p.LongTermFeeRate = 20000
+			},
+			indexes: []int{1, 0},
+			waste:   2 * (testInputFee - 1360),
+		},
+		{
+			name:   "max inputs",
+			coins:  coins[:2],
+			target: 3e5,
+			modify: func(p *coinset.FeeParams) {
+				p.MaxInputs = 1
+			},
+			err: coinset.ErrCoinsNoSelectionAvailable,
+		},
+		{
+			name:   "insufficient funds",
+			coins:  coins,
+			target: 11e5 + 1,
+			err:    coinset.ErrCoinsNoSelectionAvailable,
+		},
+		{
+			name:   "uneconomical coins",
+			coins:  []coinset.Coin{NewCoin(0, testInputFee, 1)},
+			target: 0,
+			err:    coinset.ErrCoinsNoSelectionAvailable,
+		},
+		{
+			name:    "many identical coins",
+			coins:   effectiveCoins(manyCoins...),
+			target:  25e5,
+			indexes: []int{0, 1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12, 13, 14, 15, 16, 17, 18, 19, 20, 21, 22, 23, 24},
+		},
+	}
+
+	for _, test := range tests {
+		params := testFeeParams()
+		if test.modify != nil {
+			test.modify(&params)
+		}
+		selector := &coinset.BranchAndBoundCoinSelector{FeeParams: params}
+		s, err := selector.Select(test.target, test.coins)
+		if err != test.err {
+			t.Errorf("%s: got error %v, want %v", test.name, err,
+				test.err)
+			continue
+		}
+		if err != nil {
+			continue
+		}
+
+		checkSelection(t, test.name, params, test.target, s)
+		if s.NeedsChange() {
+			t.Errorf("%s: got change %v, want none", test.name,
+				s.Change)
+		}
+		if s.Waste != test.waste {
+			t.Errorf("%s: got waste %v, want %v", test.name,
+				int64(s.Waste), int64(test.waste))
+		}
+		selected := s.Coins()
+		if len(selected) != len(test.indexes) {
+			t.Errorf("%s: got %d coins, want %d", test.name,
+				len(selected), len(test.indexes))
+			continue
+		}
+		for i, index := range test.indexes {
+			if selected[i] != test.coins[index] {
+				t.Errorf("%s: coin %d is not input coin %d",
+					test.name, i, index)
+			}
+		}
+	}
+}
+
+func TestKnapsackSelector(t *testing.T) {
+	tests := []struct {
+		name   string
+		coins  []coinset.Coin
+		target monautil.Amount
+		change monautil.Amount
+		waste  monautil.Amount
+		num    int
+		err    error
+	}{
+		{
+			name:   "exact single coin",
+			coins:  effectiveCoins(1e5, 2e5, 3e5, 1e7),
+			target: 2e5,
+			num:    1,
+		},
+		{
+			name:   "exact subset",
+			coins:  effectiveCoins(1e5, 2e5, 3e5, 1e7),
+			target: 4e5,
+			num:    2,
+		},
+		{
+			name:   "lowest larger coin",
+			coins:  effectiveCoins(1e5, 2e5, 1e6),
+			target: 5e5,
+			change: 5e5 - testChangeFee,
+			waste:  testCostOfChange,
+			num:    1,
+		},
+		{
+			name:   "subset with change",
+			coins:  effectiveCoins(1e5, 2e5, 3e5, 4e5, 1e8),
+			target: 45e4,
+			change: 5e4 - testChangeFee,
+			waste:  testCostOfChange,
+			num:    2,
+		},
+		{
+			name:   "insufficient funds",
+			coins:  effectiveCoins(1e5, 2e5),
+			target: 3e5 + 1,
+			err:    coinset.ErrCoinsNoSelectionAvailable,
+		},
+	}
+
+	for _, test := range tests {
+		params := testFeeParams()
+		selector := &coinset.KnapsackCoinSelector{
+			FeeParams: params,
+			Rand:      rand.New(rand.NewSource(1)),
+		}
+		s, err := selector.Select(test.target, test.coins)
+		if err != test.err {
+			t.Errorf("%s: got error %v, want %v", test.name, err,
+				test.err)
+			continue
+		}
+		if err != nil {
+			continue
+		}
+
+		checkSelection(t, test.name, params, test.target, s)
+		if s.Change != test.change {
+			t.Errorf("%s: got change %v, want %v", test.name,
+				int64(s.Change), int64(test.change))
+		}
+		if s.Waste != test.waste {
+			t.Errorf("%s: got waste %v, want %v", test.name,
+				int64(s.Waste), int64(test.waste))
+		}
+		if len(s.Coins()) != test.num {
+			t.Errorf("%s: got %d coins, want %d", test.name,
+				len(s.Coins()), test.num)
+		}
+	}
+}
+
+func TestSingleRandomDrawSelector(t *testing.T) {
+	values := make([]monautil.Amount, 20)
+	for i := range values {
+		values[i] = 1e5
+	}
+	values = append(values, 1e6)
+	coins := effectiveCoins(values...)
+
+	for seed := int64(0); seed < 10; seed++ {
+		params := testFeeParams()
+		params.MinChange = 1000
+		selector := &coinset.SingleRandomDrawCoinSelector{
+			FeeParams: params,
+			Rand:      rand.New(rand.NewSource(seed)),
+		}
+		s, err := selector.Select(5e5, coins)
+		if err != nil {
+			t.Errorf("seed %d: unexpected error: %v", seed, err)
+			continue
+		}
+		checkSelection(t, "srd", params, 5e5, s)
+		if !s.NeedsChange() {
+			t.Errorf("seed %d: got no change", seed)
+		}
+
+		// With a single input, only the largest coin is enough.
+		params.MaxInputs = 1
+		selector.FeeParams = params
+		s, err = selector.Select(5e5, coins)
+		if err != nil {
+			t.Errorf("seed %d: max inputs: unexpected error: %v",
+				seed, err)
+			continue
+		}
+		if len(s.Coins()) != 1 || s.Coins()[0] != coins[20] {
+			t.Errorf("seed %d: max inputs: got %d coins, want the "+
+				"largest coin", seed, len(s.Coins()))
+		}
+	}
+
+	selector := &coinset.SingleRandomDrawCoinSelector{
+		FeeParams: testFeeParams(),
+	}
+	_, err := selector.Select(3e6, coins)
+	if err != coinset.ErrCoinsNoSelectionAvailable {
+		t.Errorf("insufficient funds: got error %v, want %v", err,
+			coinset.ErrCoinsNoSelectionAvailable)
+	}
+}
+
+func TestMinWasteSelector(t *testing.T) {
+	coins := effectiveCoins(1e5, 2e5, 3e5, 5e5)
+	selector := &coinset.MinWasteCoinSelector{
+		FeeParams: testFeeParams(),
+		Rand:      rand.New(rand.NewSource(1)),
+	}
+
+	// A changeless selection exists, so no change is created.
+	s, err := selector.Select(599500, coins)
+	if err != nil {
+		t.Fatalf("changeless: unexpected error: %v", err)
+	}
+	checkSelection(t, "changeless", selector.FeeParams, 599500, s)
+	if s.NeedsChange() || s.Waste != 500 {
+		t.Errorf("changeless: got change %v and waste %v, want none "+
+			"and 500", s.Change, int64(s.Waste))
+	}
+
+	// Without a changeless selection, change is created.
+	s, err = selector.Select(55e4, coins)
+	if err != nil {
+		t.Fatalf("change: unexpected error: %v", err)
+	}
+	checkSelection(t, "change", selector.FeeParams, 55e4, s)
+	if !s.NeedsChange() {
+		t.Errorf("change: got no change")
+	}
+
+	// The selection is usable through the CoinSelector interface.
+	var cs coinset.CoinSelector = selector
+	selected, err := cs.CoinSelect(5e5, coins)
+	if err != nil {
+		t.Fatalf("CoinSelect: unexpected error: %v", err)
+	}
+	if _, ok := selected.(*coinset.Selection); !ok {
+		t.Errorf("CoinSelect: got %T, want *coinset.Selection",
+			selected)
+	}
+
+	if _, err := selector.Select(2e6, coins); err != coinset.ErrCoinsNoSelectionAvailable {
+		t.Errorf("insufficient funds: got error %v, want %v", err,
+			coinset.ErrCoinsNoSelectionAvailable)
+	}
+}
+
+// scriptCoin is a test coin with a public key script.
+type scriptCoin struct {
+	coinset.Coin
+	script []byte
+}
+
+func (c *scriptCoin) PkScript() []byte { return c.script }
+
+func TestScriptInputWeight(t *testing.T) {
+	tests := []struct {
+		name   string
+		script []byte
+		input  txsizes.Input
+	}{
+		{
+			name:   "p2wpkh",
+			script: append([]byte{0x00, 0x14}, make([]byte, 20)...),
+			input:  txsizes.Input{Type: txsizes.P2WPKH},
+		},
+		{
+			name:   "p2sh",
+			script: append(append([]byte{0xa9, 0x14}, make([]byte, 20)...), 0x87),
+			input:  txsizes.Input{Type: txsizes.NestedP2WPKH},
+		},
+		{
+			name:   "p2pkh",
+			script: append(append([]byte{0x76, 0xa9, 0x14}, make([]byte, 20)...), 0x88, 0xac),
+			input:  txsizes.Input{Type: txsizes.P2PKH},
+		},
+		{
+			name:  "unknown",
+			input: txsizes.Input{Type: txsizes.P2PKH},
+		},
+	}
+
+	for _, test := range tests {
+		c := &scriptCoin{NewCoin(0, 1e8, 1), test.script}
+		if w := coinset.ScriptInputWeight(c); w != test.input.Weight() {
+			t.Errorf("%s: got weight %d, want %d", test.name, w,
+				test.input.Weight())
+		}
+	}
+}
@@ -0,0 +1,113 @@
+// Copyright (c) 2026 The monasuite developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package descriptor
+
+import (
+	"fmt"
+	"strings"
+)
+
+const (
+	// inputCharset is the set of characters a descriptor may consist of,
+	// ordered so that the characters that commonly appear in descriptors
+	// fall into the lower 32 positions of a group.
+	inputCharset = "0123456789()[],'/*abcdefgh@:$%{}" +
+		"IJKLMNOPQRSTUVWXYZ&+-.;<=>?!^_|~" +
+		"ijklmnopqrstuvwxyzABCDEFGH`#\"\\ "
+
+	// checksumCharset is the set of characters a checksum is written in.
+	checksumCharset = "qpzry9x8gf2tvdw0s3jn54khce6mua7l"
+
+	// checksumLength is the number of characters of a checksum.
+	checksumLength = 8
+)
+
+// polyMod feeds the 5-bit value val into the checksum state c.  The checksum
+// is a BCH code over GF(32) that detects up to 4 errors in descriptors of up
+// to 501 characters.
+func polyMod(c uint64, val int) uint64 {
+	c0 := c >> 35
+	c = ((c & 0x7ffffffff) << 5) ^ uint64(val)
+	if c0&1 != 0 {
+		c ^= 0xf5dee51989
+	}
+	if c0&2 != 0 {
+		c ^= 0xa9fdca3312
+	}
+	if c0&4 != 0 {
+		c ^= 0x1bab10e32d
+	}
+	if c0&8 != 0 {
+		c ^= 0x3706b1677a
+	}
+	if c0&16 != 0 {
+		c ^= 0x644d626ffd
+	}
+
+	return c
+}
+
+// Checksum returns the checksum of the passed descriptor, which must not
+// include a checksum itself.  An error is returned if the descriptor contains
+// characters that can't appear in a descriptor.
+func Checksum(desc string) (string, error) {
+	c := uint64(1)
+	cls, clsCount := 0, 0
+	for i := 0; i < len(desc); i++ {
+		pos := strings.IndexByte(inputCharset, desc[i])
+		if pos < 0 {
+			return "", fmt.Errorf("invalid character %q at position "+
+				"%d", desc[i], i)
+		}
+
+		// The lower 5 bits of the position are fed in directly, while
+		// the group numbers of every 3 characters are combined into a
+		// single extra value.
+		c = polyMod(c, pos&31)
+		cls = cls*3 + pos>>5
+		clsCount++
+		if clsCount == 3 {
+			c = polyMod(c, cls)
+			cls, clsCount = 0, 0
+		}
+	}
+	if clsCount > 0 {
+		c = polyMod(c, cls)
+	}
+	for i := 0; i < checksumLength; i++ {
+		c = polyMod(c, 0)
+	}
+	c ^= 1
+
+	var checksum [checksumLength]byte
+	for i := range checksum {
+		checksum[i] = checksumCharset[(c>>(5*(7-uint(i))))&31]
+	}
+
+	return string(checksum[:]), nil
+}
+
+// splitChecksum separates the checksum from the passed descriptor and
+// verifies it, if there is one.
+func splitChecksum(desc string) (string, error) {
+	pos := strings.IndexByte(desc, '#')
+	if pos < 0 {
+		return desc, nil
+	}
+
+	body, checksum := desc[:pos], desc[pos+1:]
+	if len(checksum) != checksumLength {
+		return "", ErrInvalidChecksum
+	}
+	expected, err := Checksum(body)
+	if err != nil {
+		return "", err
+	}
+	if checksum != expected {
+		return "", ErrInvalidChecksum
+	}
+
+	return body, nil
+}
@@ -0,0 +1,556 @@
+// Copyright (c) 2026 The monasuite developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package descriptor
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/monasuite/monad/chaincfg"
+	"github.com/monasuite/monad/txscript"
+	"github.com/monasuite/monautil"
+)
+
+var (
+	// ErrInvalidChecksum describes an error in which the checksum of a
+	// descriptor doesn't match the descriptor.
+	ErrInvalidChecksum = errors.New("invalid descriptor checksum")
+
+	// ErrNoAddress describes an error in which an address was requested
+	// for a descriptor whose output script has no address form, such as
+	// pk() and multi().
+	ErrNoAddress = errors.New("descriptor has no address")
+)
+
+// funcType identifies the script function of a descriptor expression.
+type funcType uint8
+
+const (
+	funcPk funcType = iota
+	funcPkh
+	funcWpkh
+	funcSh
+	funcWsh
+	funcMulti
+	funcSortedMulti
+	funcAddr
+	funcRaw
+)
+
+// funcNames maps the script functions to their names in descriptors.
+var funcNames = map[funcType]string{
+	funcPk:          "pk",
+	funcPkh:         "pkh",
+	funcWpkh:        "wpkh",
+	funcSh:          "sh",
+	funcWsh:         "wsh",
+	funcMulti:       "multi",
+	funcSortedMulti: "sortedmulti",
+	funcAddr:        "addr",
+	funcRaw:         "raw",
+}
+
+// scriptContext is the context an expression appears in, which restricts
+// the functions and keys it may use.
+type scriptContext uint8
+
+const (
+	contextTop scriptContext = iota
+	contextP2SH
+	contextP2WSH
+)
+
+// maxBareMultiSigKeys is the largest number of keys a multisig script may
+// have outside of P2SH and P2WSH to be standard.
+const maxBareMultiSigKeys = 3
+
+// expr is a script expression of a descriptor.
+type expr struct {
+	fn        funcType
+	keys      []*Key
+	threshold int
+	sub       *expr
+	addr      monautil.Address
+	script    []byte
+}
+
+// Descriptor is a parsed output script descriptor, which describes a single
+// output script or, if one of its keys ends with a wildcard, a range of
+// output scripts.
+type Descriptor struct {
+	top *expr
+	net *chaincfg.Params
+}
+
+// Expansion holds the output script a descriptor expands to at an index,
+// along with the scripts and keys needed to spend it.
+type Expansion struct {
+	// Script is the output script.
+	Script []byte
+
+	// RedeemScript is the redeem script of sh() descriptors.
+	RedeemScript []byte
+
+	// WitnessScript is the witness script of wsh() descriptors.
+	WitnessScript []byte
+
+	// PubKeys holds the public keys the scripts contain, in the order they
+	// appear in the descriptor.
+	PubKeys [][]byte
+
+	// Origins holds the origin of each of the public keys.
+	Origins []*KeyOrigin
+}
+
+// Parse parses the passed descriptor for the given network.  A checksum
+// following the descriptor is verified if present, but isn't required.
+func Parse(desc string, net *chaincfg.Params) (*Descriptor, error) {
+	body, err := splitChecksum(desc)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := Checksum(body); err != nil {
+		return nil, err
+	}
+
+	top, err := parseExpr(body, contextTop, net)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Descriptor{top: top, net: net}, nil
+}
+
+// String returns the descriptor, with private keys replaced by their public
+// counterparts, followed by its checksum.
+func (d *Descriptor) String() string {
+	return withChecksum(d.top.format(false))
+}
+
+// PrivateString returns the descriptor, including any private keys it was
+// parsed with, followed by its checksum.
+func (d *Descriptor) PrivateString() string {
+	return withChecksum(d.top.format(true))
+}
+
+// withChecksum appends the checksum to the passed descriptor.
+func withChecksum(desc string) string {
+	// A descriptor produced from a parsed one only consists of valid
+	// characters.
+	checksum, _ := Checksum(desc)
+	return desc + "#" + checksum
+}
+
+// IsRange returns true if the descriptor contains a key ending with a
+// wildcard, so that it expands to a different script per index.
+func (d *Descriptor) IsRange() bool {
+	return d.top.isRange()
+}
+
+// Keys returns the key expressions of the descriptor in the order they
+// appear in it.
+func (d *Descriptor) Keys() []*Key {
+	var keys []*Key
+	for e := d.top; e != nil; e = e.sub {
+		keys = append(keys, e.keys...)
+	}
+
+	return keys
+}
+
+// Expand returns the output script the descriptor expands to at the passed
+// index, along with its redeem or witness script and public keys.  The index
+// is ignored for descriptors that aren't ranged.
+func (d *Descriptor) Expand(index uint32) (*Expansion, error) {
+	exp := &Expansion{}
+	script, err := d.top.expand(index, exp)
+	if err != nil {
+		return nil, err
+	}
+	exp.Script = script
+
+	return exp, nil
+}
+
+// Script returns the output script the descriptor expands to at the passed
+// index.
+func (d *Descriptor) Script(index uint32) ([]byte, error) {
+	exp, err := d.Expand(index)
+	if err != nil {
+		return nil, err
+	}
+
+	return exp.Script, nil
+}
+
+// Address returns the address of the output script the descriptor expands to
+// at the passed index.  ErrNoAddress is returned for scripts that have no
+// address.
+func (d *Descriptor) Address(index uint32) (monautil.Address, error) {
+	exp, err := d.Expand(index)
+	if err != nil {
+		return nil, err
+	}
+
+	switch d.top.fn {
+	case funcPkh:
+		return monautil.NewAddressPubKeyHash(
+			monautil.Hash160(exp.PubKeys[0]), d.net,
+		)
+
+	case funcWpkh:
+		return monautil.NewAddressWitnessPubKeyHash(
+			monautil.Hash160(exp.PubKeys[0]), d.net,
+		)
+
+	case funcSh:
+		return monautil.NewAddressScriptHash(exp.RedeemScript, d.net)
+
+	case funcWsh:
+		scriptHash := sha256.Sum256(exp.WitnessScript)
+		return monautil.NewAddressWitnessScriptHash(
+			scriptHash[:], d.net,
+		)
+
+	case funcAddr:
+		return d.top.addr, nil
+
+	case funcRaw:
+		class, addrs, _, err := txscript.ExtractPkScriptAddrs(
+			exp.Script, d.net,
+		)
+		if err != nil {
+			return nil, err
+		}
+		switch class {
+		case txscript.PubKeyHashTy, txscript.ScriptHashTy,
+			txscript.WitnessV0PubKeyHashTy,
+			txscript.WitnessV0ScriptHashTy:
+
+			return addrs[0], nil
+		}
+	}
+
+	return nil, ErrNoAddress
+}
+
+// isRange returns whether any key of the expression ends with a wildcard.
+func (e *expr) isRange() bool {
+	for _, k := range e.keys {
+		if k.IsRange() {
+			return true
+		}
+	}
+
+	return e.sub != nil && e.sub.isRange()
+}
+
+// expand returns the script of the expression at the passed index, recording
+// the keys and inner scripts in exp.
+func (e *expr) expand(index uint32, exp *Expansion) ([]byte, error) {
+	pubKeys := make([][]byte, 0, len(e.keys))
+	for _, k := range e.keys {
+		pubKey, origin, err := k.Derive(index)
+		if err != nil {
+			return nil, err
+		}
+		pubKeys = append(pubKeys, pubKey)
+		exp.PubKeys = append(exp.PubKeys, pubKey)
+		exp.Origins = append(exp.Origins, origin)
+	}
+
+	builder := txscript.NewScriptBuilder()
+	switch e.fn {
+	case funcPk:
+		builder.AddData(pubKeys[0]).AddOp(txscript.OP_CHECKSIG)
+
+	case funcPkh:
+		builder.AddOp(txscript.OP_DUP).AddOp(txscript.OP_HASH160).
+			AddData(monautil.Hash160(pubKeys[0])).
+			AddOp(txscript.OP_EQUALVERIFY).AddOp(txscript.OP_CHECKSIG)
+
+	case funcWpkh:
+		builder.AddOp(txscript.OP_0).
+			AddData(monautil.Hash160(pubKeys[0]))
+
+	case funcMulti, funcSortedMulti:
+		if e.fn == funcSortedMulti {
+			sort.Slice(pubKeys, func(i, j int) bool {
+				return bytes.Compare(pubKeys[i], pubKeys[j]) < 0
+			})
+		}
+
+		builder.AddInt64(int64(e.threshold))
+		for _, pubKey := range pubKeys {
+			builder.AddData(pubKey)
+		}
+		builder.AddInt64(int64(len(pubKeys))).
+			AddOp(txscript.OP_CHECKMULTISIG)
+
+	case funcSh:
+		redeemScript, err := e.sub.expand(index, exp)
+		if err != nil {
+			return nil, err
+		}
+		exp.RedeemScript = redeemScript
+
+		builder.AddOp(txscript.OP_HASH160).
+			AddData(monautil.Hash160(redeemScript)).
+			AddOp(txscript.OP_EQUAL)
+
+	case funcWsh:
+		witnessScript, err := e.sub.expand(index, exp)
+		if err != nil {
+			return nil, err
+		}
+		exp.WitnessScript = witnessScript
+
+		scriptHash := sha256.Sum256(witnessScript)
+		builder.AddOp(txscript.OP_0).AddData(scriptHash[:])
+
+	case funcAddr:
+		return monautil.PayToAddrScript(e.addr)
+
+	case funcRaw:
+		return e.script, nil
+	}
+
+	return builder.Script()
+}
+
+// format returns the expression as it appears in a descriptor, keeping
+// private keys if private is set.
+func (e *expr) format(private bool) string {
+	var args []string
+	switch e.fn {
+	case funcSh, funcWsh:
+		args = append(args, e.sub.format(private))
+
+	case funcMulti, funcSortedMulti:
+		args = append(args, strconv.Itoa(e.threshold))
+		for _, k := range e.keys {
+			args = append(args, k.format(private))
+		}
+
+	case funcAddr:
+		args = append(args, e.addr.EncodeAddress())
+
+	case funcRaw:
+		args = append(args, hex.EncodeToString(e.script))
+
+	default:
+		args = append(args, e.keys[0].format(private))
+	}
+
+	return funcNames[e.fn] + "(" + strings.Join(args, ",") + ")"
+}
+
+// parseExpr parses a script expression appearing in the passed context.
+func parseExpr(s string, ctx scriptContext,
+	net *chaincfg.Params) (*expr, error) {
+
+	open := strings.IndexByte(s, '(')
+	if open < 0 || !strings.HasSuffix(s, ")") {
+		return nil, fmt.Errorf("invalid expression %q", s)
+	}
+	name := s[:open]
+	args, err := splitArgs(s[open+1 : len(s)-1])
+	if err != nil {
+		return nil, err
+	}
+
+	e := &expr{fn: funcPk}
+	found := false
+	for fn, fnName := range funcNames {
+		if fnName == name {
+			e.fn, found = fn, true
+			break
+		}
+	}
+	if !found {
+		return nil, fmt.Errorf("unknown function %q", name)
+	}
+
+	switch e.fn {
+	case funcPk, funcPkh, funcWpkh:
+		if len(args) != 1 {
+			return nil, fmt.Errorf("%s() takes a single key", name)
+		}
+		if e.fn == funcWpkh && ctx == contextP2WSH {
+			return nil, fmt.Errorf("wpkh() can't be used inside " +
+				"wsh()")
+		}
+
+		k, err := parseKey(args[0], net)
+		if err != nil {
+			return nil, err
+		}
+		if !k.isCompressed() &&
+			(e.fn == funcWpkh || ctx == contextP2WSH) {
+
+			return nil, fmt.Errorf("uncompressed key %s can't be "+
+				"used in segwit scripts", args[0])
+		}
+		e.keys = []*Key{k}
+
+	case funcSh, funcWsh:
+		if len(args) != 1 {
+			return nil, fmt.Errorf("%s() takes a single script",
+				name)
+		}
+
+		subCtx := contextP2WSH
+		switch {
+		case e.fn == funcSh && ctx == contextTop:
+			subCtx = contextP2SH
+		case e.fn == funcSh:
+			return nil, fmt.Errorf("sh() can only be used at the " +
+				"top level")
+		case ctx == contextP2WSH:
+			return nil, fmt.Errorf("wsh() can't be used inside " +
+				"wsh()")
+		}
+
+		e.sub, err = parseExpr(args[0], subCtx, net)
+		if err != nil {
+			return nil, err
+		}
+
+	case funcMulti, funcSortedMulti:
+		if err := e.parseMultiSig(args, ctx, net); err != nil {
+			return nil, err
+		}
+
+	case funcAddr, funcRaw:
+		if ctx != contextTop {
+			return nil, fmt.Errorf("%s() can only be used at the "+
+				"top level", name)
+		}
+		if len(args) != 1 {
+			return nil, fmt.Errorf("%s() takes a single argument",
+				name)
+		}
+
+		if e.fn == funcRaw {
+			e.script, err = hex.DecodeString(args[0])
+			if err != nil {
+				return nil, fmt.Errorf("invalid script %q",
+					args[0])
+			}
+			break
+		}
+
+		e.addr, err = monautil.DecodeAddress(args[0], net)
+		if err != nil {
+			return nil, err
+		}
+		if !e.addr.IsForNet(net) {
+			return nil, fmt.Errorf("address %s is for the wrong "+
+				"network", args[0])
+		}
+	}
+
+	return e, nil
+}
+
+// parseMultiSig parses the threshold and keys of a multi() or sortedmulti()
+// expression.
+func (e *expr) parseMultiSig(args []string, ctx scriptContext,
+	net *chaincfg.Params) error {
+
+	name := funcNames[e.fn]
+	if len(args) < 2 {
+		return fmt.Errorf("%s() takes a threshold and keys", name)
+	}
+
+	threshold, err := strconv.Atoi(args[0])
+	if err != nil || args[0][0] == '+' || args[0][0] == '-' {
+		return fmt.Errorf("invalid multisig threshold %q", args[0])
+	}
+
+	numKeys := len(args) - 1
+	switch {
+	case threshold < 1 || threshold > numKeys:
+		return fmt.Errorf("multisig threshold %d out of range for "+
+			"%d keys", threshold, numKeys)
+
+	case numKeys > txscript.MaxPubKeysPerMultiSig:
+		return fmt.Errorf("multisig with %d keys exceeds the maximum "+
+			"of %d", numKeys, txscript.MaxPubKeysPerMultiSig)
+
+	case ctx == contextTop && numKeys > maxBareMultiSigKeys:
+		return fmt.Errorf("bare multisig with %d keys exceeds the "+
+			"maximum of %d", numKeys, maxBareMultiSigKeys)
+	}
+
+	// The script consists of the threshold, the pushed keys, the number of
+	// keys and OP_CHECKMULTISIG.
+	scriptLen := 3
+	for _, arg := range args[1:] {
+		k, err := parseKey(arg, net)
+		if err != nil {
+			return err
+		}
+		if ctx == contextP2WSH && !k.isCompressed() {
+			return fmt.Errorf("uncompressed key %s can't be used "+
+				"in segwit scripts", arg)
+		}
+
+		e.keys = append(e.keys, k)
+		scriptLen += 1 + k.pubKeyLen()
+	}
+	if threshold > 16 {
+		scriptLen++
+	}
+	if numKeys > 16 {
+		scriptLen++
+	}
+
+	if ctx == contextP2SH && scriptLen > txscript.MaxScriptElementSize {
+		return fmt.Errorf("redeem script of %d bytes exceeds the "+
+			"maximum of %d", scriptLen, txscript.MaxScriptElementSize)
+	}
+	e.threshold = threshold
+
+	return nil
+}
+
+// splitArgs splits the arguments of an expression at the commas that aren't
+// nested in parentheses or brackets.
+func splitArgs(s string) ([]string, error) {
+	var (
+		args  []string
+		depth int
+		start int
+	)
+	for i := 0; i < len(s); i++ {
+		switch s[i] {
+		case '(', '[':
+			depth++
+		case ')', ']':
+			depth--
+			if depth < 0 {
+				return nil, fmt.Errorf("unbalanced %q in %q",
+					s[i], s)
+			}
+		case ',':
+			if depth == 0 {
+				args = append(args, s[start:i])
+				start = i + 1
+			}
+		}
+	}
+	if depth != 0 {
+		return nil, fmt.Errorf("unbalanced parentheses in %q", s)
+	}
+
+	return append(args, s[start:]), nil
+}
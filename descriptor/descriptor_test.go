@@ -0,0 +1,340 @@
+// Copyright (c) 2026 The monasuite developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package descriptor_test
+
+import (
+	"bytes"
+	"encoding/hex"
+	"strings"
+	"testing"
+
+	"github.com/monasuite/monad/chaincfg"
+	"github.com/monasuite/monautil/descriptor"
+	"github.com/monasuite/monautil/hdkeychain"
+)
+
+const (
+	// pubKey1 and pubKey2 are compressed public keys, pubKey2 sorting
+	// before pubKey1.
+	pubKey1 = "03a34b99f22c790c4e36b2b3c2c35a36db06226e41c692fc82b8b56ac1c540c5bd"
+	pubKey2 = "0279be667ef9dcbbac55a06295ce870b07029bfcdb2dce28d959f2815b16f81798"
+
+	// uncompressedKey is the uncompressed form of pubKey2.
+	uncompressedKey = "0479be667ef9dcbbac55a06295ce870b07029bfcdb2dce28d959f2815b16f81798483ada7726a3c4655da4fbfc0e1108a8fd17b448a68554199c47d08ffb10d4b8"
+
+	// masterPriv and masterPub are the master keys of BIP32 test vector
+	// 1, whose fingerprint is 3442193e.
+	masterPriv = "xprv9s21ZrQH143K3QTDL4LXw2F7HEK3wJUD2nW2nRk4stbPy6cq3jPPqjiChkVvvNKmPGJxWUtg6LnF5kejMRNNU3TGtRBeJgk33yuGBxrMPHi"
+	masterPub  = "xpub661MyMwAqRbcFtXgS5sYJABqqG9YLmC4Q1Rdap9gSE8NqtwybGhePY2gZ29ESFjqJoCu1Rupje8YtGqsefD265TMg7usUDFdp6W1EGMcet8"
+
+	// childPub is the public key of m/0' of BIP32 test vector 1.
+	childPub = "xpub68Gmy5EdvgibQVfPdqkBBCHxA5htiqg55crXYuXoQRKfDBFA1WEjWgP6LHhwBZeNK1VTsfTFUHCdrfp1bgwQ9xv5ski8PX9rL2dZXvgGDnw"
+)
+
+// TestChecksum ensures descriptor checksums are computed and verified.
+func TestChecksum(t *testing.T) {
+	checksum, err := descriptor.Checksum("raw(deadbeef)")
+	if err != nil {
+		t.Fatalf("Checksum: unexpected error: %v", err)
+	}
+	if checksum != "89f8spxm" {
+		t.Fatalf("Checksum: got %s, want 89f8spxm", checksum)
+	}
+
+	net := &chaincfg.MainNetParams
+	if _, err := descriptor.Parse("raw(deadbeef)#89f8spxm", net); err != nil {
+		t.Fatalf("Parse: unexpected error: %v", err)
+	}
+	for _, desc := range []string{
+		"raw(deadbeef)#89f8spxn",
+		"raw(deadbeef)#89f8spx",
+		"raw(deadbeef)#",
+	} {
+		_, err := descriptor.Parse(desc, net)
+		if err != descriptor.ErrInvalidChecksum {
+			t.Errorf("Parse(%s): got error %v, want %v", desc, err,
+				descriptor.ErrInvalidChecksum)
+		}
+	}
+}
+
+// TestScripts ensures descriptors expand to the expected output scripts and
+// addresses.
+func TestScripts(t *testing.T) {
+	tests := []struct {
+		desc   string
+		script string
+		addr   string
+	}{{
+		desc:   "pk(" + pubKey1 + ")",
+		script: "21" + pubKey1 + "ac",
+	}, {
+		desc:   "pkh(" + pubKey1 + ")",
+		script: "76a9149a1c78a507689f6f54b847ad1cef1e614ee23f1e88ac",
+		addr:   "M",
+	}, {
+		desc:   "wpkh(" + pubKey1 + ")",
+		script: "00149a1c78a507689f6f54b847ad1cef1e614ee23f1e",
+		addr:   "mona1",
+	}, {
+		desc:   "sh(wpkh(" + pubKey1 + "))",
+		script: "a91484ab21b1b2fd065d4504ff693d832434b6108d7b87",
+		addr:   "P",
+	}, {
+		desc:   "multi(1," + pubKey2 + "," + pubKey1 + ")",
+		script: "5121" + pubKey2 + "21" + pubKey1 + "52ae",
+	}, {
+		desc:   "sortedmulti(1," + pubKey1 + "," + pubKey2 + ")",
+		script: "5121" + pubKey2 + "21" + pubKey1 + "52ae",
+	}, {
+		desc:   "raw(76a9149a1c78a507689f6f54b847ad1cef1e614ee23f1e88ac)",
+		script: "76a9149a1c78a507689f6f54b847ad1cef1e614ee23f1e88ac",
+		addr:   "M",
+	}, {
+		desc:   "raw(deadbeef)",
+		script: "deadbeef",
+	}}
+
+	net := &chaincfg.MainNetParams
+	for _, test := range tests {
+		d, err := descriptor.Parse(test.desc, net)
+		if err != nil {
+			t.Errorf("Parse(%s): unexpected error: %v", test.desc, err)
+			continue
+		}
+		if d.IsRange() {
+			t.Errorf("%s: unexpectedly ranged", test.desc)
+		}
+
+		script, err := d.Script(0)
+		if err != nil {
+			t.Errorf("%s: Script: unexpected error: %v", test.desc,
+				err)
+			continue
+		}
+		if hex.EncodeToString(script) != test.script {
+			t.Errorf("%s: got script %x, want %s", test.desc,
+				script, test.script)
+		}
+
+		addr, err := d.Address(0)
+		if test.addr == "" {
+			if err != descriptor.ErrNoAddress {
+				t.Errorf("%s: got address error %v, want %v",
+					test.desc, err, descriptor.ErrNoAddress)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s: Address: unexpected error: %v",
+				test.desc, err)
+			continue
+		}
+		if !strings.HasPrefix(addr.EncodeAddress(), test.addr) {
+			t.Errorf("%s: got address %s, want prefix %s",
+				test.desc, addr, test.addr)
+		}
+
+		// The address and the descriptor must describe the same
+		// script.
+		addrDesc, err := descriptor.Parse(
+			"addr("+addr.EncodeAddress()+")", net,
+		)
+		if err != nil {
+			t.Errorf("%s: unable to parse address: %v", test.desc,
+				err)
+			continue
+		}
+		addrScript, err := addrDesc.Script(0)
+		if err != nil || !bytes.Equal(addrScript, script) {
+			t.Errorf("%s: address script %x differs (%v)",
+				test.desc, addrScript, err)
+		}
+	}
+}
+
+// TestRange ensures ranged descriptors derive the keys along the expected
+// paths and report their origins.
+func TestRange(t *testing.T) {
+	net := &chaincfg.MainNetParams
+	master, err := hdkeychain.NewKeyFromString(masterPriv)
+	if err != nil {
+		t.Fatalf("unable to parse master key: %v", err)
+	}
+
+	d, err := descriptor.Parse("wsh(multi(1,"+masterPriv+"/0h/1/*,"+
+		"[3442193e/0']"+childPub+"/1/*))", net)
+	if err != nil {
+		t.Fatalf("Parse: unexpected error: %v", err)
+	}
+	if !d.IsRange() {
+		t.Fatalf("descriptor not ranged")
+	}
+
+	for index := uint32(0); index < 3; index++ {
+		exp, err := d.Expand(index)
+		if err != nil {
+			t.Fatalf("Expand(%d): unexpected error: %v", index, err)
+		}
+
+		key := master
+		for _, i := range []uint32{hdkeychain.HardenedKeyStart, 1, index} {
+			key, err = key.Derive(i)
+			if err != nil {
+				t.Fatalf("unable to derive key: %v", err)
+			}
+		}
+		pubKey, err := key.ECPubKey()
+		if err != nil {
+			t.Fatalf("unable to get public key: %v", err)
+		}
+		want := pubKey.SerializeCompressed()
+
+		// Both keys derive to the same public key, with the same
+		// origin.
+		if len(exp.PubKeys) != 2 {
+			t.Fatalf("Expand(%d): got %d keys, want 2", index,
+				len(exp.PubKeys))
+		}
+		for i, got := range exp.PubKeys {
+			if !bytes.Equal(got, want) {
+				t.Errorf("Expand(%d): key %d is %x, want %x",
+					index, i, got, want)
+			}
+
+			origin := exp.Origins[i]
+			wantOrigin := "3442193e/0'/1/" + string('0'+rune(index))
+			if origin.String() != wantOrigin {
+				t.Errorf("Expand(%d): origin %d is %s, want %s",
+					index, i, origin, wantOrigin)
+			}
+			if origin.Fingerprint != master.Fingerprint() {
+				t.Errorf("Expand(%d): origin %d fingerprint is "+
+					"%08x, want %08x", index, i,
+					origin.Fingerprint, master.Fingerprint())
+			}
+		}
+		if !bytes.Contains(exp.WitnessScript, want) {
+			t.Errorf("Expand(%d): witness script lacks key", index)
+		}
+	}
+
+	// The public form of the descriptor can't derive the hardened step.
+	wantPub := "wsh(multi(1," + masterPub + "/0'/1/*,[3442193e/0']" +
+		childPub + "/1/*))"
+	if !strings.HasPrefix(d.String(), wantPub+"#") {
+		t.Fatalf("String: got %s, want %s", d, wantPub)
+	}
+	pub, err := descriptor.Parse(d.String(), net)
+	if err != nil {
+		t.Fatalf("Parse(%s): unexpected error: %v", d, err)
+	}
+	if _, err := pub.Expand(0); err != hdkeychain.ErrDeriveHardFromPublic {
+		t.Fatalf("Expand: got error %v, want %v", err,
+			hdkeychain.ErrDeriveHardFromPublic)
+	}
+}
+
+// TestRoundTrip ensures parsed descriptors serialize back to their original
+// form, with hardened markers normalized.
+func TestRoundTrip(t *testing.T) {
+	tests := []struct {
+		desc    string
+		private string
+		public  string
+	}{{
+		desc: "sh(wsh(sortedmulti(2,[d34db33f/48h/22h/0h/1h]" +
+			childPub + "/0/*," + pubKey1 + "," + pubKey2 + ")))",
+		private: "sh(wsh(sortedmulti(2,[d34db33f/48'/22'/0'/1']" +
+			childPub + "/0/*," + pubKey1 + "," + pubKey2 + ")))",
+	}, {
+		desc:    "pkh(" + masterPriv + "/1/*')",
+		private: "pkh(" + masterPriv + "/1/*')",
+		public:  "pkh(" + masterPub + "/1/*')",
+	}, {
+		desc: "pkh(" + uncompressedKey + ")",
+	}, {
+		desc: "sh(multi(1," + uncompressedKey + "," + pubKey1 + "))",
+	}}
+
+	net := &chaincfg.MainNetParams
+	for _, test := range tests {
+		if test.private == "" {
+			test.private = test.desc
+		}
+		if test.public == "" {
+			test.public = test.private
+		}
+
+		d, err := descriptor.Parse(test.desc, net)
+		if err != nil {
+			t.Errorf("Parse(%s): unexpected error: %v", test.desc, err)
+			continue
+		}
+
+		checksum, _ := descriptor.Checksum(test.private)
+		if d.PrivateString() != test.private+"#"+checksum {
+			t.Errorf("PrivateString: got %s, want %s",
+				d.PrivateString(), test.private)
+		}
+		checksum, _ = descriptor.Checksum(test.public)
+		if d.String() != test.public+"#"+checksum {
+			t.Errorf("String: got %s, want %s", d, test.public)
+		}
+
+		reparsed, err := descriptor.Parse(d.PrivateString(), net)
+		if err != nil {
+			t.Errorf("Parse(%s): unexpected error: %v",
+				d.PrivateString(), err)
+			continue
+		}
+		if reparsed.PrivateString() != d.PrivateString() {
+			t.Errorf("round trip of %s gave %s", d.PrivateString(),
+				reparsed.PrivateString())
+		}
+	}
+}
+
+// TestParseErrors ensures invalid descriptors are rejected.
+func TestParseErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		desc string
+	}{
+		{"unknown function", "combo(" + pubKey1 + ")"},
+		{"nested sh", "sh(sh(pkh(" + pubKey1 + ")))"},
+		{"sh in wsh", "wsh(sh(pkh(" + pubKey1 + ")))"},
+		{"wsh in wsh", "wsh(wsh(pkh(" + pubKey1 + ")))"},
+		{"wpkh in wsh", "wsh(wpkh(" + pubKey1 + "))"},
+		{"uncompressed wpkh", "wpkh(" + uncompressedKey + ")"},
+		{"uncompressed wsh", "wsh(pk(" + uncompressedKey + "))"},
+		{"addr in sh", "sh(addr(MLA8zTdhh5bbxLLPRfGKBdxH9vKdo5KXbb))"},
+		{"raw in wsh", "wsh(raw(deadbeef))"},
+		{"zero threshold", "multi(0," + pubKey1 + ")"},
+		{"threshold too high", "multi(3," + pubKey1 + "," + pubKey2 + ")"},
+		{"bad threshold", "multi(x," + pubKey1 + ")"},
+		{"bare multisig", "multi(1," + strings.Repeat(pubKey1+",", 3) +
+			pubKey1 + ")"},
+		{"too many keys", "wsh(multi(1," +
+			strings.Repeat(pubKey1+",", 20) + pubKey1 + "))"},
+		{"invalid key", "pk(deadbeef)"},
+		{"derived public key", "pk(" + pubKey1 + "/0)"},
+		{"wildcard not last", "pkh(" + childPub + "/*/0)"},
+		{"bad path", "pkh(" + childPub + "/x)"},
+		{"bad fingerprint", "pkh([d34db3/0]" + pubKey1 + ")"},
+		{"unclosed origin", "pkh([d34db33f/0" + pubKey1 + ")"},
+		{"extra args", "pkh(" + pubKey1 + "," + pubKey2 + ")"},
+		{"unbalanced", "sh(wpkh(" + pubKey1 + ")"},
+		{"invalid raw", "raw(xyz)"},
+		{"invalid character", "raw(deadbeef)\x01"},
+	}
+
+	net := &chaincfg.MainNetParams
+	for _, test := range tests {
+		if _, err := descriptor.Parse(test.desc, net); err == nil {
+			t.Errorf("%s: Parse(%s) succeeded", test.name, test.desc)
+		}
+	}
+}
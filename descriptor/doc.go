@@ -0,0 +1,28 @@
+// Copyright (c) 2026 The monasuite developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+/*
+Package descriptor implements output script descriptors as specified by
+BIP 380 and its companions.
+
+Overview
+
+An output script descriptor is a compact, human readable description of a
+set of output scripts, such as
+
+	wpkh([d34db33f/84'/22'/0']xpub.../0/*)
+	sh(wsh(multi(2,xpub.../0/*,xpub.../0/*)))
+
+Descriptors are built from the script functions pk, pkh, wpkh, sh, wsh,
+multi, sortedmulti, addr and raw.  Keys are given as hex encoded public keys,
+WIF encoded private keys or extended keys followed by a derivation path, and
+may be preceded by their origin: the fingerprint of the master key and the
+path leading to them.  A derivation path ending in a wildcard makes the
+descriptor ranged, describing one script per index.
+
+Descriptors may be followed by an 8 character checksum, separated by a '#',
+which is verified when present.  Descriptors are always serialized with
+their checksum and with hardened path elements marked by an apostrophe.
+*/
+package descriptor
@@ -0,0 +1,36 @@
+// Copyright (c) 2026 The monasuite developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package descriptor_test
+
+import (
+	"fmt"
+
+	"github.com/monasuite/monad/chaincfg"
+	"github.com/monasuite/monautil/descriptor"
+)
+
+// This example demonstrates how to derive the first addresses of a ranged
+// descriptor.
+func ExampleParse() {
+	desc := "wpkh([3442193e/0']xpub68Gmy5EdvgibQVfPdqkBBCHxA5htiqg55crXYuXoQRKfDBFA1WEjWgP6LHhwBZeNK1VTsfTFUHCdrfp1bgwQ9xv5ski8PX9rL2dZXvgGDnw/0/*)"
+	d, err := descriptor.Parse(desc, &chaincfg.MainNetParams)
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+
+	for index := uint32(0); index < 2; index++ {
+		addr, err := d.Address(index)
+		if err != nil {
+			fmt.Println(err)
+			return
+		}
+		fmt.Println(addr)
+	}
+
+	// Output:
+	// mona1qwlvfdv8ctae2ureaqjrugv4j8s5tw9yn65w299
+	// mona1qdhrn4uwfdlmga8daant52wadtxlseqayzqypvn
+}
@@ -0,0 +1,372 @@
+// Copyright (c) 2026 The monasuite developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package descriptor
+
+import (
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/monasuite/monad/btcec"
+	"github.com/monasuite/monad/chaincfg"
+	"github.com/monasuite/monautil"
+	"github.com/monasuite/monautil/hdkeychain"
+)
+
+// Wildcard describes whether, and how, the last step of the derivation path
+// of an extended key in a descriptor is left open.
+type Wildcard uint8
+
+const (
+	// NoWildcard indicates a key that always derives to the same public
+	// key.
+	NoWildcard Wildcard = iota
+
+	// UnhardenedWildcard indicates a key ending in /*, which is derived at
+	// the unhardened index a descriptor is expanded at.
+	UnhardenedWildcard
+
+	// HardenedWildcard indicates a key ending in /*', which is derived at
+	// the hardened index a descriptor is expanded at.
+	HardenedWildcard
+)
+
+// KeyOrigin describes where a key was derived from: the fingerprint of the
+// master key and the derivation path from it.
+type KeyOrigin struct {
+	// Fingerprint is the fingerprint of the master key, the first four
+	// bytes of the hash160 of its public key read as a little endian
+	// number, as returned by hdkeychain.ExtendedKey.Fingerprint and used by
+	// psbt.Bip32Derivation.
+	Fingerprint uint32
+
+	// Path holds the child indexes from the master key to the key, with
+	// hardened indexes offset by hdkeychain.HardenedKeyStart.
+	Path []uint32
+}
+
+// String returns the origin in the form used in descriptors, the fingerprint
+// as hex followed by the path, without the enclosing brackets.
+func (o *KeyOrigin) String() string {
+	var fp [4]byte
+	binary.LittleEndian.PutUint32(fp[:], o.Fingerprint)
+
+	return hex.EncodeToString(fp[:]) + formatPath(o.Path)
+}
+
+// Key is a key expression of a descriptor: a public key, a WIF encoded
+// private key or an extended key with a derivation path, optionally preceded
+// by the origin of the key.
+type Key struct {
+	// Origin is the origin of the key, or nil if the descriptor doesn't
+	// specify one.
+	Origin *KeyOrigin
+
+	// Path holds the derivation steps applied to an extended key, not
+	// including the wildcard.
+	Path []uint32
+
+	// Wildcard is the kind of wildcard an extended key ends with.
+	Wildcard Wildcard
+
+	pubKey []byte
+	wif    *monautil.WIF
+	extKey *hdkeychain.ExtendedKey
+}
+
+// IsRange returns true if the key ends with a wildcard, so that it derives to
+// a different public key per index.
+func (k *Key) IsRange() bool {
+	return k.Wildcard != NoWildcard
+}
+
+// IsPrivate returns true if the key was given as a private key.
+func (k *Key) IsPrivate() bool {
+	return k.wif != nil || (k.extKey != nil && k.extKey.IsPrivate())
+}
+
+// ExtendedKey returns the extended key of the key expression, or nil if it is
+// a plain key.
+func (k *Key) ExtendedKey() *hdkeychain.ExtendedKey {
+	return k.extKey
+}
+
+// isCompressed returns whether the key derives to compressed public keys.
+func (k *Key) isCompressed() bool {
+	switch {
+	case k.wif != nil:
+		return k.wif.CompressPubKey
+	case k.extKey != nil:
+		return true
+	default:
+		return len(k.pubKey) == btcec.PubKeyBytesLenCompressed
+	}
+}
+
+// pubKeyLen returns the length of the serialized public keys the key derives
+// to.
+func (k *Key) pubKeyLen() int {
+	if k.isCompressed() {
+		return btcec.PubKeyBytesLenCompressed
+	}
+	return btcec.PubKeyBytesLenUncompressed
+}
+
+// Derive returns the serialized public key the key expression derives to at
+// the passed index, together with its full origin.  The index is ignored if
+// the key doesn't end with a wildcard.  Keys without an origin are treated as
+// master keys, so their origin consists of their own fingerprint and the
+// derivation path following the key.
+func (k *Key) Derive(index uint32) ([]byte, *KeyOrigin, error) {
+	var (
+		pubKey []byte
+		path   []uint32
+	)
+	switch {
+	case k.wif != nil:
+		pubKey = k.wif.SerializePubKey()
+
+	case k.pubKey != nil:
+		pubKey = k.pubKey
+
+	default:
+		if k.IsRange() && index >= hdkeychain.HardenedKeyStart {
+			return nil, nil, fmt.Errorf("index %d out of range", index)
+		}
+
+		path = append(path, k.Path...)
+		switch k.Wildcard {
+		case UnhardenedWildcard:
+			path = append(path, index)
+		case HardenedWildcard:
+			path = append(path, index+hdkeychain.HardenedKeyStart)
+		}
+
+		key := k.extKey
+		for _, i := range path {
+			var err error
+			key, err = key.Derive(i)
+			if err != nil {
+				return nil, nil, err
+			}
+		}
+		ecPubKey, err := key.ECPubKey()
+		if err != nil {
+			return nil, nil, err
+		}
+		pubKey = ecPubKey.SerializeCompressed()
+	}
+
+	origin := &KeyOrigin{}
+	if k.Origin != nil {
+		origin.Fingerprint = k.Origin.Fingerprint
+		origin.Path = append(origin.Path, k.Origin.Path...)
+	} else {
+		origin.Fingerprint = k.fingerprint()
+	}
+	origin.Path = append(origin.Path, path...)
+
+	return pubKey, origin, nil
+}
+
+// fingerprint returns the fingerprint of the key expression itself.
+func (k *Key) fingerprint() uint32 {
+	var pubKey []byte
+	switch {
+	case k.wif != nil:
+		pubKey = k.wif.SerializePubKey()
+	case k.pubKey != nil:
+		pubKey = k.pubKey
+	default:
+		return k.extKey.Fingerprint()
+	}
+
+	return binary.LittleEndian.Uint32(monautil.Hash160(pubKey)[:4])
+}
+
+// String returns the key expression with private keys replaced by their
+// public counterparts.
+func (k *Key) String() string {
+	return k.format(false)
+}
+
+// format returns the key expression, keeping private keys if private is set.
+func (k *Key) format(private bool) string {
+	var b strings.Builder
+	if k.Origin != nil {
+		b.WriteString("[" + k.Origin.String() + "]")
+	}
+
+	switch {
+	case k.wif != nil:
+		if private {
+			b.WriteString(k.wif.String())
+		} else {
+			b.WriteString(hex.EncodeToString(k.wif.SerializePubKey()))
+		}
+
+	case k.pubKey != nil:
+		b.WriteString(hex.EncodeToString(k.pubKey))
+
+	default:
+		extKey := k.extKey
+		if !private && extKey.IsPrivate() {
+			// Neutering can't fail for a private key.
+			extKey, _ = extKey.Neuter()
+		}
+		b.WriteString(extKey.String())
+		b.WriteString(formatPath(k.Path))
+
+		switch k.Wildcard {
+		case UnhardenedWildcard:
+			b.WriteString("/*")
+		case HardenedWildcard:
+			b.WriteString("/*'")
+		}
+	}
+
+	return b.String()
+}
+
+// formatPath returns the derivation path with every index preceded by a
+// slash and hardened indexes marked by an apostrophe.
+func formatPath(path []uint32) string {
+	var b strings.Builder
+	for _, i := range path {
+		b.WriteByte('/')
+		if i >= hdkeychain.HardenedKeyStart {
+			b.WriteString(strconv.FormatUint(
+				uint64(i-hdkeychain.HardenedKeyStart), 10,
+			))
+			b.WriteByte('\'')
+		} else {
+			b.WriteString(strconv.FormatUint(uint64(i), 10))
+		}
+	}
+
+	return b.String()
+}
+
+// parsePathIndex parses a single element of a derivation path, which may be
+// marked as hardened by a trailing apostrophe or h.
+func parsePathIndex(s string) (uint32, error) {
+	var offset uint32
+	if strings.HasSuffix(s, "'") || strings.HasSuffix(s, "h") {
+		offset = hdkeychain.HardenedKeyStart
+		s = s[:len(s)-1]
+	}
+
+	i, err := strconv.ParseUint(s, 10, 32)
+	if err != nil || s == "" || s[0] == '+' ||
+		i >= hdkeychain.HardenedKeyStart {
+
+		return 0, fmt.Errorf("invalid path index %q", s)
+	}
+
+	return uint32(i) + offset, nil
+}
+
+// parseKey parses a key expression.
+func parseKey(s string, net *chaincfg.Params) (*Key, error) {
+	k := &Key{}
+
+	if strings.HasPrefix(s, "[") {
+		end := strings.IndexByte(s, ']')
+		if end < 0 {
+			return nil, fmt.Errorf("key origin of %q lacks ']'", s)
+		}
+
+		elems := strings.Split(s[1:end], "/")
+		fp, err := hex.DecodeString(elems[0])
+		if err != nil || len(fp) != 4 {
+			return nil, fmt.Errorf("invalid key origin fingerprint "+
+				"%q", elems[0])
+		}
+
+		k.Origin = &KeyOrigin{
+			Fingerprint: binary.LittleEndian.Uint32(fp),
+		}
+		for _, elem := range elems[1:] {
+			i, err := parsePathIndex(elem)
+			if err != nil {
+				return nil, err
+			}
+			k.Origin.Path = append(k.Origin.Path, i)
+		}
+
+		s = s[end+1:]
+	}
+
+	elems := strings.Split(s, "/")
+	keyStr, elems := elems[0], elems[1:]
+
+	// Plain public keys are given as hex.
+	if pubKey, err := hex.DecodeString(keyStr); err == nil {
+		if _, err := btcec.ParsePubKey(pubKey, btcec.S256()); err != nil {
+			return nil, fmt.Errorf("invalid public key %q: %v",
+				keyStr, err)
+		}
+		if pubKey[0] == 0x06 || pubKey[0] == 0x07 {
+			return nil, fmt.Errorf("hybrid public key %q is not "+
+				"allowed", keyStr)
+		}
+		if len(elems) > 0 {
+			return nil, fmt.Errorf("public key %q can't be "+
+				"derived", keyStr)
+		}
+
+		k.pubKey = pubKey
+		return k, nil
+	}
+
+	if wif, err := monautil.DecodeWIF(keyStr); err == nil {
+		if !wif.IsForNet(net) {
+			return nil, fmt.Errorf("private key %q is for the "+
+				"wrong network", keyStr)
+		}
+		if len(elems) > 0 {
+			return nil, fmt.Errorf("private key %q can't be "+
+				"derived", keyStr)
+		}
+
+		k.wif = wif
+		return k, nil
+	}
+
+	extKey, err := hdkeychain.NewKeyFromString(keyStr)
+	if err != nil {
+		return nil, fmt.Errorf("invalid key %q: %v", keyStr, err)
+	}
+	if !extKey.IsForNet(net) {
+		return nil, fmt.Errorf("extended key %q is for the wrong "+
+			"network", keyStr)
+	}
+	k.extKey = extKey
+
+	for i, elem := range elems {
+		if elem == "*" || elem == "*'" || elem == "*h" {
+			if i != len(elems)-1 {
+				return nil, fmt.Errorf("wildcard must be the " +
+					"last path element")
+			}
+
+			k.Wildcard = UnhardenedWildcard
+			if elem != "*" {
+				k.Wildcard = HardenedWildcard
+			}
+			break
+		}
+
+		index, err := parsePathIndex(elem)
+		if err != nil {
+			return nil, err
+		}
+		k.Path = append(k.Path, index)
+	}
+
+	return k, nil
+}
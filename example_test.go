@@ -4,6 +4,7 @@ import (
 	"fmt"
 	//"math"
 
+	"github.com/monasuite/monad/chaincfg"
 	"github.com/monasuite/monautil"
 	"github.com/shopspring/decimal"
 )
@@ -75,3 +76,65 @@ func ExampleAmount_unitConversions() {
 	// Satoshi to MicroBTC: 444333222111 μMONA
 	// Satoshi to Satoshi: 44433322211100 Watanabe
 }
+
+func ExampleParseAmount() {
+	for _, s := range []string{"1.23456789", "150 mMONA", "0.000000001"} {
+		amount, err := monautil.ParseAmount(s)
+		if err != nil {
+			fmt.Println(err)
+			continue
+		}
+		fmt.Println(amount)
+	}
+
+	// Output:
+	// 1.23456789 MONA
+	// 0.15 MONA
+	// monacoin amount is more precise than a watanabe
+}
+
+func ExampleAmountFormatter() {
+	f := monautil.AmountFormatter{
+		Unit:               monautil.AmountBTC,
+		Decimals:           2,
+		DecimalSeparator:   ",",
+		ThousandsSeparator: ".",
+	}
+	fmt.Println(f.Format(123456789012))
+
+	// Output:
+	// 1.234,57 MONA
+}
+
+// This example demonstrates building the output script paying to an address,
+// and extracting the address back from the script.
+func ExampleExtractPkScriptAddrs() {
+	net := &chaincfg.MainNetParams
+	addr, err := monautil.DecodeAddress(
+		"M9vQFWksNwMShpHKZJqDdMPFjkyGDRtxyn", net)
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	pkScript, err := monautil.PayToAddrScript(addr)
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	fmt.Printf("Script: %x\n", pkScript)
+
+	class, addrs, reqSigs, err := monautil.ExtractPkScriptAddrs(pkScript, net)
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	fmt.Println("Class:", class)
+	fmt.Println("Addresses:", addrs)
+	fmt.Println("Required signatures:", reqSigs)
+
+	// Output:
+	// Script: 76a914162c5ea71c0b23f5b9022ef047c4a86470a5b07088ac
+	// Class: pubkeyhash
+	// Addresses: [M9vQFWksNwMShpHKZJqDdMPFjkyGDRtxyn]
+	// Required signatures: 1
+}
@@ -0,0 +1,98 @@
+// Copyright (c) 2026 The monasuite developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package monautil
+
+// WitnessScaleFactor is the number of weight units per virtual byte, by which
+// the size of data outside of witnesses is scaled.  It mirrors
+// blockchain.WitnessScaleFactor, which can't be imported here as the
+// blockchain package imports this one.
+const WitnessScaleFactor = 4
+
+// WeightToVSize returns the virtual size of the passed weight, which is the
+// weight divided by the witness scale factor and rounded up.
+func WeightToVSize(weight int64) int64 {
+	return (weight + WitnessScaleFactor - 1) / WitnessScaleFactor
+}
+
+// FeeRatePerKVByte is a fee rate in watanabe per 1000 virtual bytes, the unit
+// used by the RPC server and fee estimators.
+type FeeRatePerKVByte Amount
+
+// FeeRatePerKWeight is a fee rate in watanabe per 1000 weight units.  One
+// virtual byte is four weight units.
+type FeeRatePerKWeight Amount
+
+// NewFeeRatePerKVByte returns the fee rate of a transaction which pays the
+// passed fee for the passed virtual size, truncated to a whole watanabe per
+// 1000 virtual bytes.  Zero is returned if the size isn't positive.
+func NewFeeRatePerKVByte(fee Amount, vsize int64) FeeRatePerKVByte {
+	if vsize <= 0 {
+		return 0
+	}
+	size := Amount(vsize)
+	return FeeRatePerKVByte(fee/size*1000 + fee%size*1000/size)
+}
+
+// FeePerKWeight converts the fee rate to a rate per 1000 weight units.  The
+// result is truncated, so it may be up to three watanabe per 1000 weight
+// units lower than the exact rate.
+func (r FeeRatePerKVByte) FeePerKWeight() FeeRatePerKWeight {
+	return FeeRatePerKWeight(r / WitnessScaleFactor)
+}
+
+// FeeForVSize returns the fee for a transaction of the passed virtual size,
+// rounded up to a whole watanabe so the fee rate is never undershot.
+func (r FeeRatePerKVByte) FeeForVSize(vsize int64) Amount {
+	return feeForSize(Amount(r), vsize)
+}
+
+// FeeForWeight returns the fee for a transaction of the passed weight at the
+// fee rate.  The weight is rounded up to whole virtual bytes, as done when
+// computing the virtual size of transactions.
+func (r FeeRatePerKVByte) FeeForWeight(weight int64) Amount {
+	return r.FeeForVSize(WeightToVSize(weight))
+}
+
+// String returns the fee rate as an amount of monacoin per 1000 virtual
+// bytes, such as "0.001 MONA/kvB".
+func (r FeeRatePerKVByte) String() string {
+	return Amount(r).String() + "/kvB"
+}
+
+// FeePerKVByte converts the fee rate to a rate per 1000 virtual bytes.
+func (r FeeRatePerKWeight) FeePerKVByte() FeeRatePerKVByte {
+	return FeeRatePerKVByte(r * WitnessScaleFactor)
+}
+
+// FeeForWeight returns the fee for a transaction of the passed weight, rounded
+// up to a whole watanabe so the fee rate is never undershot.
+func (r FeeRatePerKWeight) FeeForWeight(weight int64) Amount {
+	return feeForSize(Amount(r), weight)
+}
+
+// FeeForVSize returns the fee for a transaction of the passed virtual size at
+// the fee rate.
+func (r FeeRatePerKWeight) FeeForVSize(vsize int64) Amount {
+	return r.FeeForWeight(vsize * WitnessScaleFactor)
+}
+
+// String returns the fee rate as an amount of monacoin per 1000 weight units,
+// such as "0.00025 MONA/kw".
+func (r FeeRatePerKWeight) String() string {
+	return Amount(r).String() + "/kw"
+}
+
+// feeForSize returns the fee for size units at a rate per 1000 units, rounded
+// up to a whole watanabe.  The rate is split into whole and partial watanabe
+// per unit so the multiplication only overflows for rates and sizes far
+// beyond any transaction.
+func feeForSize(rate Amount, size int64) Amount {
+	if rate <= 0 || size <= 0 {
+		return 0
+	}
+	whole := rate / 1000 * Amount(size)
+	part := (rate%1000*Amount(size) + 999) / 1000
+	return whole + part
+}
@@ -0,0 +1,169 @@
+// Copyright (c) 2026 The monasuite developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package monautil_test
+
+import (
+	"testing"
+
+	. "github.com/monasuite/monautil"
+)
+
+func TestFeeRatePerKVByte(t *testing.T) {
+	tests := []struct {
+		name       string
+		rate       FeeRatePerKVByte
+		vsize      int64
+		weight     int64
+		fee        Amount
+		perKWeight FeeRatePerKWeight
+		s          string
+	}{
+		{
+			name:       "whole watanabe per byte",
+			rate:       100000,
+			vsize:      250,
+			weight:     1000,
+			fee:        25000,
+			perKWeight: 25000,
+			s:          "0.001 MONA/kvB",
+		},
+		{
+			name:       "rounded up",
+			rate:       1001,
+			vsize:      141,
+			weight:     561,
+			fee:        142,
+			perKWeight: 250,
+			s:          "0.00001001 MONA/kvB",
+		},
+		{
+			name:       "zero size",
+			rate:       1000,
+			vsize:      0,
+			weight:     0,
+			fee:        0,
+			perKWeight: 250,
+			s:          "0.00001 MONA/kvB",
+		},
+		{
+			name:       "large rate",
+			rate:       MaxSatoshi,
+			vsize:      100000,
+			weight:     400000,
+			fee:        MaxSatoshi * 100,
+			perKWeight: MaxSatoshi / 4,
+			s:          "105120000 MONA/kvB",
+		},
+	}
+
+	for _, test := range tests {
+		if fee := test.rate.FeeForVSize(test.vsize); fee != test.fee {
+			t.Errorf("%v: FeeForVSize: got %d, want %d", test.name,
+				int64(fee), int64(test.fee))
+		}
+		if fee := test.rate.FeeForWeight(test.weight); fee != test.fee {
+			t.Errorf("%v: FeeForWeight: got %d, want %d", test.name,
+				int64(fee), int64(test.fee))
+		}
+		if r := test.rate.FeePerKWeight(); r != test.perKWeight {
+			t.Errorf("%v: FeePerKWeight: got %d, want %d", test.name,
+				int64(r), int64(test.perKWeight))
+		}
+		if s := test.rate.String(); s != test.s {
+			t.Errorf("%v: String: got %q, want %q", test.name, s, test.s)
+		}
+	}
+}
+
+func TestFeeRatePerKWeight(t *testing.T) {
+	tests := []struct {
+		name      string
+		rate      FeeRatePerKWeight
+		weight    int64
+		vsize     int64
+		fee       Amount
+		perKVByte FeeRatePerKVByte
+		s         string
+	}{
+		{
+			name:      "whole watanabe per unit",
+			rate:      25000,
+			weight:    1000,
+			vsize:     250,
+			fee:       25000,
+			perKVByte: 100000,
+			s:         "0.00025 MONA/kw",
+		},
+		{
+			name:      "rounded up",
+			rate:      253,
+			weight:    561,
+			vsize:     141,
+			fee:       142,
+			perKVByte: 1012,
+			s:         "0.00000253 MONA/kw",
+		},
+	}
+
+	for _, test := range tests {
+		if fee := test.rate.FeeForWeight(test.weight); fee != test.fee {
+			t.Errorf("%v: FeeForWeight: got %d, want %d", test.name,
+				int64(fee), int64(test.fee))
+		}
+		if fee := test.rate.FeeForVSize(test.vsize); fee < test.fee {
+			t.Errorf("%v: FeeForVSize: got %d, want at least %d",
+				test.name, int64(fee), int64(test.fee))
+		}
+		if r := test.rate.FeePerKVByte(); r != test.perKVByte {
+			t.Errorf("%v: FeePerKVByte: got %d, want %d", test.name,
+				int64(r), int64(test.perKVByte))
+		}
+		if s := test.rate.String(); s != test.s {
+			t.Errorf("%v: String: got %q, want %q", test.name, s, test.s)
+		}
+	}
+}
+
+func TestNewFeeRatePerKVByte(t *testing.T) {
+	tests := []struct {
+		name  string
+		fee   Amount
+		vsize int64
+		rate  FeeRatePerKVByte
+	}{
+		{"exact", 25000, 250, 100000},
+		{"truncated", 1000, 141, 7092},
+		{"max fee", MaxSatoshi, 1000, MaxSatoshi},
+		{"zero size", 1000, 0, 0},
+	}
+
+	for _, test := range tests {
+		rate := NewFeeRatePerKVByte(test.fee, test.vsize)
+		if rate != test.rate {
+			t.Errorf("%v: got %d, want %d", test.name, int64(rate),
+				int64(test.rate))
+		}
+	}
+}
+
+func TestWeightToVSize(t *testing.T) {
+	tests := []struct {
+		weight int64
+		vsize  int64
+	}{
+		{weight: 0, vsize: 0},
+		{weight: 1, vsize: 1},
+		{weight: 4, vsize: 1},
+		{weight: 561, vsize: 141},
+		{weight: 564, vsize: 141},
+	}
+
+	for _, test := range tests {
+		if got := WeightToVSize(test.weight); got != test.vsize {
+			t.Errorf("WeightToVSize(%d): got %d, want %d",
+				test.weight, got, test.vsize)
+		}
+	}
+}
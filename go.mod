@@ -10,4 +10,5 @@ require (
 	github.com/monasuite/monad v0.22.1-beta
 	github.com/shopspring/decimal v1.2.0
 	golang.org/x/crypto v0.0.0-20200510223506-06a226fb4e37
+	golang.org/x/text v0.3.3
 )
@@ -442,10 +442,18 @@ func (k *ExtendedKey) Neuter() (*ExtendedKey, error) {
 		return k, nil
 	}
 
-	// Get the associated public extended key version bytes.
-	version, err := chaincfg.HDPrivateKeyToPublicKeyID(k.version)
-	if err != nil {
-		return nil, err
+	// Get the associated public extended key version bytes, which are
+	// either registered along with the private ones or, for standard
+	// BIP32 keys, known to chaincfg.
+	var version []byte
+	if v, ok := k.KeyVersion(); ok {
+		version = v.Public[:]
+	} else {
+		var err error
+		version, err = chaincfg.HDPrivateKeyToPublicKeyID(k.version)
+		if err != nil {
+			return nil, err
+		}
 	}
 
 	// Convert it to an extended public key.  The key for the new extended
@@ -504,10 +512,38 @@ func (k *ExtendedKey) ECPrivKey() (*btcec.PrivateKey, error) {
 	return privKey, nil
 }
 
-// Address converts the extended key to a standard monacoin pay-to-pubkey-hash
-// address for the passed network.
-func (k *ExtendedKey) Address(net *chaincfg.Params) (*monautil.AddressPubKeyHash, error) {
+// Address converts the extended key to a monacoin address for the passed
+// network, of the type designated by the version of the extended key: a
+// pay-to-pubkey-hash address for standard BIP32 keys, a P2SH nested
+// pay-to-witness-pubkey-hash address for BIP49 keys and a native one for
+// BIP84 keys.  ErrNoAddress is returned for the versions designating multisig
+// scripts.
+func (k *ExtendedKey) Address(net *chaincfg.Params) (monautil.Address, error) {
 	pkHash := monautil.Hash160(k.pubKeyBytes())
+
+	switch k.ScriptType() {
+	case ScriptTypeP2SHP2WPKH:
+		// The redeem script is the version 0 witness program of the
+		// public key hash.
+		witnessAddr, err := monautil.NewAddressWitnessPubKeyHash(
+			pkHash, net,
+		)
+		if err != nil {
+			return nil, err
+		}
+		redeemScript, err := monautil.PayToAddrScript(witnessAddr)
+		if err != nil {
+			return nil, err
+		}
+		return monautil.NewAddressScriptHash(redeemScript, net)
+
+	case ScriptTypeP2WPKH:
+		return monautil.NewAddressWitnessPubKeyHash(pkHash, net)
+
+	case ScriptTypeP2SHP2WSH, ScriptTypeP2WSH:
+		return nil, ErrNoAddress
+	}
+
 	return monautil.NewAddressPubKeyHash(pkHash, net)
 }
 
@@ -554,13 +590,34 @@ func (k *ExtendedKey) String() string {
 // IsForNet returns whether or not the extended key is associated with the
 // passed monacoin network.
 func (k *ExtendedKey) IsForNet(net *chaincfg.Params) bool {
-	return bytes.Equal(k.version, net.HDPrivateKeyID[:]) ||
-		bytes.Equal(k.version, net.HDPublicKeyID[:])
+	if bytes.Equal(k.version, net.HDPrivateKeyID[:]) ||
+		bytes.Equal(k.version, net.HDPublicKeyID[:]) {
+
+		return true
+	}
+
+	// Other registered versions, such as those of BIP49 and BIP84 keys,
+	// are for the network they were registered with.
+	v, ok := k.KeyVersion()
+	return ok && v.Net.Name == net.Name
 }
 
 // SetNet associates the extended key, and any child keys yet to be derived from
 // it, with the passed network.
 func (k *ExtendedKey) SetNet(net *chaincfg.Params) {
+	// Keep the script type of keys with registered versions if the
+	// network has versions for it.
+	if v, ok := k.KeyVersion(); ok {
+		if v, err := VersionFor(net, v.ScriptType); err == nil {
+			if k.isPrivate {
+				k.version = v.Private[:]
+			} else {
+				k.version = v.Public[:]
+			}
+			return
+		}
+	}
+
 	if k.isPrivate {
 		k.version = net.HDPrivateKeyID[:]
 	} else {
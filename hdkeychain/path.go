@@ -0,0 +1,138 @@
+// Copyright (c) 2026 The monasuite developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package hdkeychain
+
+import (
+	"encoding/binary"
+	"errors"
+	"strconv"
+	"strings"
+
+	"github.com/monasuite/monautil"
+)
+
+// ErrInvalidPath describes an error in which a derivation path string is not
+// of the form m/84'/22'/0'/1/5.
+var ErrInvalidPath = errors.New("invalid derivation path")
+
+// DerivationPath is a BIP32 derivation path: the child indexes leading from a
+// key to one of its descendants, with hardened indexes offset by
+// HardenedKeyStart.  It has the same representation as the paths of PSBT
+// BIP32 derivations, so it converts to and from []uint32 directly.
+type DerivationPath []uint32
+
+// ParsePath parses a derivation path such as m/84'/22'/0'/1/5.  Hardened
+// indexes are marked by a trailing apostrophe or h, and the leading m/ may be
+// omitted.  The path of the key itself is written as m.
+func ParsePath(path string) (DerivationPath, error) {
+	if path == "m" {
+		return DerivationPath{}, nil
+	}
+	path = strings.TrimPrefix(path, "m/")
+
+	elems := strings.Split(path, "/")
+	p := make(DerivationPath, 0, len(elems))
+	for _, elem := range elems {
+		var offset uint32
+		if strings.HasSuffix(elem, "'") || strings.HasSuffix(elem, "h") {
+			offset = HardenedKeyStart
+			elem = elem[:len(elem)-1]
+		}
+
+		// Only plain decimal numbers are accepted, so every path has a
+		// single string form.
+		if elem == "" || (elem[0] == '0' && len(elem) > 1) ||
+			elem[0] == '+' {
+
+			return nil, ErrInvalidPath
+		}
+		i, err := strconv.ParseUint(elem, 10, 32)
+		if err != nil || i >= HardenedKeyStart {
+			return nil, ErrInvalidPath
+		}
+
+		p = append(p, uint32(i)+offset)
+	}
+
+	return p, nil
+}
+
+// String returns the path in the form m/84'/22'/0'/1/5, with hardened
+// indexes marked by an apostrophe.
+func (p DerivationPath) String() string {
+	var b strings.Builder
+	b.WriteString("m")
+	for _, i := range p {
+		b.WriteByte('/')
+		if i >= HardenedKeyStart {
+			b.WriteString(strconv.FormatUint(
+				uint64(i-HardenedKeyStart), 10,
+			))
+			b.WriteByte('\'')
+		} else {
+			b.WriteString(strconv.FormatUint(uint64(i), 10))
+		}
+	}
+
+	return b.String()
+}
+
+// Child returns a copy of the path extended by the passed indexes.
+func (p DerivationPath) Child(indexes ...uint32) DerivationPath {
+	child := make(DerivationPath, 0, len(p)+len(indexes))
+	child = append(child, p...)
+	return append(child, indexes...)
+}
+
+// KeyOrigin describes where a derived key comes from: the fingerprint of the
+// key it was derived from and the path leading to it, as recorded in the
+// BIP32 derivations of a PSBT.
+type KeyOrigin struct {
+	// MasterKeyFingerprint is the fingerprint of the master key, the
+	// first four bytes of the hash160 of its public key.  It is read as a
+	// little endian number, which is the form psbt.Bip32Derivation uses.
+	MasterKeyFingerprint uint32
+
+	// Path is the derivation path from the master key.
+	Path DerivationPath
+}
+
+// Fingerprint returns the fingerprint of the extended key in the form used by
+// KeyOrigin: the first four bytes of the hash160 of its public key, read as a
+// little endian number.  Note that ParentFingerprint reads the same bytes as
+// a big endian number instead.
+func (k *ExtendedKey) Fingerprint() uint32 {
+	hash := monautil.Hash160(k.pubKeyBytes())
+	return binary.LittleEndian.Uint32(hash[:4])
+}
+
+// DerivePath derives the descendant of the extended key at the passed path,
+// treating the extended key as the master key.  Along with the derived key it
+// returns its origin, which holds the fingerprint of the extended key and a
+// copy of the path.
+//
+// The errors that Derive returns for any step of the path are returned as
+// is.  In particular, ErrInvalidChild indicates that one of the indexes of
+// the path doesn't derive to a usable key, in which case the caller is
+// expected to move on to the next index at that level.
+func (k *ExtendedKey) DerivePath(path DerivationPath) (*ExtendedKey,
+	*KeyOrigin, error) {
+
+	key := k
+	for _, i := range path {
+		var err error
+		key, err = key.Derive(i)
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+
+	origin := &KeyOrigin{
+		MasterKeyFingerprint: k.Fingerprint(),
+		Path:                 path.Child(),
+	}
+
+	return key, origin, nil
+}
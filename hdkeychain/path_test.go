@@ -0,0 +1,128 @@
+// Copyright (c) 2026 The monasuite developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package hdkeychain
+
+import (
+	"encoding/hex"
+	"reflect"
+	"testing"
+
+	"github.com/monasuite/monad/chaincfg"
+)
+
+// TestParsePath ensures derivation paths are parsed and formatted as
+// expected.
+func TestParsePath(t *testing.T) {
+	tests := []struct {
+		path      string
+		want      DerivationPath
+		formatted string
+	}{
+		{"m", DerivationPath{}, "m"},
+		{"m/0", DerivationPath{0}, "m/0"},
+		{
+			"m/84'/22'/0'/1/5",
+			DerivationPath{
+				HardenedKeyStart + 84, HardenedKeyStart + 22,
+				HardenedKeyStart, 1, 5,
+			},
+			"m/84'/22'/0'/1/5",
+		},
+		{
+			"m/44h/22h/0h",
+			DerivationPath{
+				HardenedKeyStart + 44, HardenedKeyStart + 22,
+				HardenedKeyStart,
+			},
+			"m/44'/22'/0'",
+		},
+		{"0/2147483647'", DerivationPath{0, 0xffffffff}, "m/0/2147483647'"},
+	}
+
+	for _, test := range tests {
+		path, err := ParsePath(test.path)
+		if err != nil {
+			t.Errorf("ParsePath(%s): unexpected error: %v", test.path,
+				err)
+			continue
+		}
+		if !reflect.DeepEqual(path, test.want) {
+			t.Errorf("ParsePath(%s): got %v, want %v", test.path,
+				[]uint32(path), []uint32(test.want))
+		}
+		if path.String() != test.formatted {
+			t.Errorf("ParsePath(%s): formatted as %s, want %s",
+				test.path, path, test.formatted)
+		}
+	}
+
+	invalid := []string{
+		"", "m/", "/0", "m/0/", "m//0", "m/x", "m/-1", "m/+1", "m/01",
+		"m/0''", "m/2147483648", "m/0/h", "M/0", "m/0 ",
+	}
+	for _, path := range invalid {
+		if _, err := ParsePath(path); err != ErrInvalidPath {
+			t.Errorf("ParsePath(%q): got error %v, want %v", path,
+				err, ErrInvalidPath)
+		}
+	}
+}
+
+// TestDerivePath ensures DerivePath derives the same key as the step by step
+// derivation of BIP32 test vector 1 and reports its origin.
+func TestDerivePath(t *testing.T) {
+	seed, _ := hex.DecodeString("000102030405060708090a0b0c0d0e0f")
+	master, err := NewMaster(seed, &chaincfg.MainNetParams)
+	if err != nil {
+		t.Fatalf("NewMaster: unexpected error: %v", err)
+	}
+
+	path, err := ParsePath("m/0'/1/2'/2/1000000000")
+	if err != nil {
+		t.Fatalf("ParsePath: unexpected error: %v", err)
+	}
+	key, origin, err := master.DerivePath(path)
+	if err != nil {
+		t.Fatalf("DerivePath: unexpected error: %v", err)
+	}
+
+	neutered, err := key.Neuter()
+	if err != nil {
+		t.Fatalf("Neuter: unexpected error: %v", err)
+	}
+	want := "xpub6H1LXWLaKsWFhvm6RVpEL9P4KfRZSW7abD2ttkWP3SSQvnyA8FSVqNT" +
+		"EcYFgJS2UaFcxupHiYkro49S8yGasTvXEYBVPamhGW6cFJodrTHy"
+	if neutered.String() != want {
+		t.Fatalf("DerivePath: got %s, want %s", neutered, want)
+	}
+
+	// The fingerprint of the master key of the vector is 3442193e, which
+	// reads as 0x3e194234 in little endian.
+	if origin.MasterKeyFingerprint != 0x3e194234 {
+		t.Fatalf("DerivePath: got fingerprint %08x, want 3e194234",
+			origin.MasterKeyFingerprint)
+	}
+	if !reflect.DeepEqual(origin.Path, path) {
+		t.Fatalf("DerivePath: got origin path %v, want %v",
+			origin.Path, path)
+	}
+
+	// The origin must not share the path passed in.
+	path[0] = 0
+	if origin.Path[0] != HardenedKeyStart {
+		t.Fatalf("DerivePath: origin path aliases the passed path")
+	}
+
+	// Hardened steps can't be derived from a public key.
+	pub, err := master.Neuter()
+	if err != nil {
+		t.Fatalf("Neuter: unexpected error: %v", err)
+	}
+	_, _, err = pub.DerivePath(DerivationPath{0, HardenedKeyStart})
+	if err != ErrDeriveHardFromPublic {
+		t.Fatalf("DerivePath: got error %v, want %v", err,
+			ErrDeriveHardFromPublic)
+	}
+}
@@ -0,0 +1,269 @@
+// Copyright (c) 2026 The monasuite developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package hdkeychain
+
+// References:
+//   [SLIP132]: SLIP-0132 - Registered HD version bytes for BIP-0032
+//   https://github.com/satoshilabs/slips/blob/master/slip-0132.md
+
+import (
+	"bytes"
+	"errors"
+	"sync"
+
+	"github.com/monasuite/monad/chaincfg"
+)
+
+// ScriptType identifies the kind of output script the keys of an extended key
+// are used in, as signalled by its version bytes.
+type ScriptType uint8
+
+const (
+	// ScriptTypeP2PKH is used by the standard BIP32 versions (xpub, tpub),
+	// for pay-to-pubkey-hash outputs as well as legacy P2SH multisig.
+	ScriptTypeP2PKH ScriptType = iota
+
+	// ScriptTypeP2SHP2WPKH is used by the BIP49 versions (ypub, upub), for
+	// pay-to-witness-pubkey-hash outputs nested in P2SH.
+	ScriptTypeP2SHP2WPKH
+
+	// ScriptTypeP2WPKH is used by the BIP84 versions (zpub, vpub), for
+	// native pay-to-witness-pubkey-hash outputs.
+	ScriptTypeP2WPKH
+
+	// ScriptTypeP2SHP2WSH is used by the multisig versions Ypub and Upub,
+	// for pay-to-witness-script-hash outputs nested in P2SH.
+	ScriptTypeP2SHP2WSH
+
+	// ScriptTypeP2WSH is used by the multisig versions Zpub and Vpub, for
+	// native pay-to-witness-script-hash outputs.
+	ScriptTypeP2WSH
+)
+
+// scriptTypeStrings is a map of script types back to their constant names for
+// pretty printing.
+var scriptTypeStrings = map[ScriptType]string{
+	ScriptTypeP2PKH:      "P2PKH",
+	ScriptTypeP2SHP2WPKH: "P2SH-P2WPKH",
+	ScriptTypeP2WPKH:     "P2WPKH",
+	ScriptTypeP2SHP2WSH:  "P2SH-P2WSH",
+	ScriptTypeP2WSH:      "P2WSH",
+}
+
+// String returns the ScriptType in human-readable form.
+func (t ScriptType) String() string {
+	if s, ok := scriptTypeStrings[t]; ok {
+		return s
+	}
+	return "Unknown ScriptType"
+}
+
+var (
+	// ErrDuplicateVersion describes an error in which the caller attempted
+	// to register version bytes that are already registered.
+	ErrDuplicateVersion = errors.New("duplicate extended key version")
+
+	// ErrUnknownVersion describes an error in which no version bytes are
+	// registered for the requested network and script type.
+	ErrUnknownVersion = errors.New("unknown extended key version")
+
+	// ErrNoAddress describes an error in which the caller requested the
+	// address of an extended key whose version designates multisig
+	// scripts, which have no single key address.
+	ErrNoAddress = errors.New("extended key version designates " +
+		"multisig scripts")
+)
+
+// KeyVersion describes a pair of registered extended key versions: the
+// network and script type they designate, and the version bytes of the
+// private and public extended keys.
+type KeyVersion struct {
+	Net        *chaincfg.Params
+	ScriptType ScriptType
+	Private    [4]byte
+	Public     [4]byte
+}
+
+var (
+	// registeredVersions holds all registered key versions, in the order
+	// they were registered.
+	registeredVersions []*KeyVersion
+
+	// versionsMtx protects registeredVersions.
+	versionsMtx sync.RWMutex
+)
+
+// RegisterVersion registers the passed private and public version bytes as
+// designating extended keys of the given network and script type.  The
+// versions of [SLIP132] are registered for the main and test networks by
+// default.  ErrDuplicateVersion is returned if either of the version bytes
+// is already registered.
+//
+// Note that the test network and regression test network share their
+// version bytes, so only the test network is registered by default.
+func RegisterVersion(net *chaincfg.Params, scriptType ScriptType,
+	private, public [4]byte) error {
+
+	versionsMtx.Lock()
+	defer versionsMtx.Unlock()
+
+	for _, v := range registeredVersions {
+		if v.Private == private || v.Public == public ||
+			v.Private == public || v.Public == private {
+
+			return ErrDuplicateVersion
+		}
+	}
+
+	registeredVersions = append(registeredVersions, &KeyVersion{
+		Net:        net,
+		ScriptType: scriptType,
+		Private:    private,
+		Public:     public,
+	})
+
+	return nil
+}
+
+// LookupVersion returns the registered key version the passed version bytes
+// belong to, which may be those of either a private or a public extended
+// key, and whether they were found.
+func LookupVersion(version []byte) (*KeyVersion, bool) {
+	versionsMtx.RLock()
+	defer versionsMtx.RUnlock()
+
+	for _, v := range registeredVersions {
+		if bytes.Equal(version, v.Private[:]) ||
+			bytes.Equal(version, v.Public[:]) {
+
+			return v, true
+		}
+	}
+
+	return nil, false
+}
+
+// VersionFor returns the registered key version for the passed network and
+// script type.  The standard versions of a network are always found for
+// ScriptTypeP2PKH, even if they aren't registered.  ErrUnknownVersion is
+// returned if no version is registered for the combination.
+func VersionFor(net *chaincfg.Params, scriptType ScriptType) (*KeyVersion,
+	error) {
+
+	versionsMtx.RLock()
+	defer versionsMtx.RUnlock()
+
+	for _, v := range registeredVersions {
+		if v.ScriptType == scriptType && v.Net.Name == net.Name {
+			return v, nil
+		}
+	}
+
+	if scriptType == ScriptTypeP2PKH {
+		return &KeyVersion{
+			Net:        net,
+			ScriptType: ScriptTypeP2PKH,
+			Private:    net.HDPrivateKeyID,
+			Public:     net.HDPublicKeyID,
+		}, nil
+	}
+
+	return nil, ErrUnknownVersion
+}
+
+// KeyVersion returns the registered key version of the extended key, and
+// whether its version bytes are registered.
+func (k *ExtendedKey) KeyVersion() (*KeyVersion, bool) {
+	return LookupVersion(k.version)
+}
+
+// ScriptType returns the script type designated by the version of the
+// extended key.  Keys with unregistered versions are treated as standard
+// BIP32 keys, so ScriptTypeP2PKH is returned for them.
+func (k *ExtendedKey) ScriptType() ScriptType {
+	if v, ok := k.KeyVersion(); ok {
+		return v.ScriptType
+	}
+	return ScriptTypeP2PKH
+}
+
+// ConvertVersion returns a copy of the extended key using the version bytes
+// registered for the passed network and script type, such as turning an xpub
+// into the equivalent zpub.  Private keys remain private and public keys
+// remain public.
+func (k *ExtendedKey) ConvertVersion(net *chaincfg.Params,
+	scriptType ScriptType) (*ExtendedKey, error) {
+
+	v, err := VersionFor(net, scriptType)
+	if err != nil {
+		return nil, err
+	}
+
+	version := v.Public
+	if k.isPrivate {
+		version = v.Private
+	}
+
+	return NewExtendedKey(version[:], k.key, k.chainCode, k.parentFP,
+		k.depth, k.childNum, k.isPrivate), nil
+}
+
+func init() {
+	versions := []struct {
+		net        *chaincfg.Params
+		scriptType ScriptType
+		private    [4]byte
+		public     [4]byte
+	}{
+		// xprv/xpub
+		{&chaincfg.MainNetParams, ScriptTypeP2PKH,
+			[4]byte{0x04, 0x88, 0xad, 0xe4},
+			[4]byte{0x04, 0x88, 0xb2, 0x1e}},
+		// yprv/ypub
+		{&chaincfg.MainNetParams, ScriptTypeP2SHP2WPKH,
+			[4]byte{0x04, 0x9d, 0x78, 0x78},
+			[4]byte{0x04, 0x9d, 0x7c, 0xb2}},
+		// zprv/zpub
+		{&chaincfg.MainNetParams, ScriptTypeP2WPKH,
+			[4]byte{0x04, 0xb2, 0x43, 0x0c},
+			[4]byte{0x04, 0xb2, 0x47, 0x46}},
+		// Yprv/Ypub
+		{&chaincfg.MainNetParams, ScriptTypeP2SHP2WSH,
+			[4]byte{0x02, 0x95, 0xb0, 0x05},
+			[4]byte{0x02, 0x95, 0xb4, 0x3f}},
+		// Zprv/Zpub
+		{&chaincfg.MainNetParams, ScriptTypeP2WSH,
+			[4]byte{0x02, 0xaa, 0x7a, 0x99},
+			[4]byte{0x02, 0xaa, 0x7e, 0xd3}},
+		// tprv/tpub
+		{&chaincfg.TestNet4Params, ScriptTypeP2PKH,
+			[4]byte{0x04, 0x35, 0x83, 0x94},
+			[4]byte{0x04, 0x35, 0x87, 0xcf}},
+		// uprv/upub
+		{&chaincfg.TestNet4Params, ScriptTypeP2SHP2WPKH,
+			[4]byte{0x04, 0x4a, 0x4e, 0x28},
+			[4]byte{0x04, 0x4a, 0x52, 0x62}},
+		// vprv/vpub
+		{&chaincfg.TestNet4Params, ScriptTypeP2WPKH,
+			[4]byte{0x04, 0x5f, 0x18, 0xbc},
+			[4]byte{0x04, 0x5f, 0x1c, 0xf6}},
+		// Uprv/Upub
+		{&chaincfg.TestNet4Params, ScriptTypeP2SHP2WSH,
+			[4]byte{0x02, 0x42, 0x85, 0xb5},
+			[4]byte{0x02, 0x42, 0x89, 0xef}},
+		// Vprv/Vpub
+		{&chaincfg.TestNet4Params, ScriptTypeP2WSH,
+			[4]byte{0x02, 0x57, 0x50, 0x48},
+			[4]byte{0x02, 0x57, 0x54, 0x83}},
+	}
+
+	for _, v := range versions {
+		if err := RegisterVersion(v.net, v.scriptType, v.private,
+			v.public); err != nil {
+
+			panic(err)
+		}
+	}
+}
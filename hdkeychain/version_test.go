@@ -0,0 +1,169 @@
+// Copyright (c) 2026 The monasuite developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package hdkeychain
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/monasuite/monad/chaincfg"
+	"github.com/monasuite/monautil"
+)
+
+// TestKeyVersions ensures the registered SLIP132 versions are recognized and
+// determine the network, script type and address of extended keys.
+func TestKeyVersions(t *testing.T) {
+	const (
+		xprv = "xprv9s21ZrQH143K3QTDL4LXw2F7HEK3wJUD2nW2nRk4stbPy6cq3jPPqjiChkVvvNKmPGJxWUtg6LnF5kejMRNNU3TGtRBeJgk33yuGBxrMPHi"
+		xpub = "xpub661MyMwAqRbcFtXgS5sYJABqqG9YLmC4Q1Rdap9gSE8NqtwybGhePY2gZ29ESFjqJoCu1Rupje8YtGqsefD265TMg7usUDFdp6W1EGMcet8"
+		zpub = "zpub6jftahH18ngZxUuv6oSniLNrBCSSE1B4EEU59bwTCEt8x6aS6b2mdfLxbS4QS53g85SWWP6wexqeer516433gYpZQoJie2tcMYdJ1SYYYAL"
+	)
+
+	master, err := NewKeyFromString(xprv)
+	if err != nil {
+		t.Fatalf("NewKeyFromString: unexpected error: %v", err)
+	}
+	pkHash := monautil.Hash160(master.pubKeyBytes())
+
+	p2pkh, _ := monautil.NewAddressPubKeyHash(
+		pkHash, &chaincfg.MainNetParams,
+	)
+	p2wpkh, _ := monautil.NewAddressWitnessPubKeyHash(
+		pkHash, &chaincfg.MainNetParams,
+	)
+	p2shP2wpkh, _ := monautil.NewAddressScriptHash(
+		append([]byte{0x00, 0x14}, pkHash...), &chaincfg.MainNetParams,
+	)
+
+	tests := []struct {
+		scriptType ScriptType
+		net        *chaincfg.Params
+		prefix     string
+		addr       monautil.Address
+	}{
+		{ScriptTypeP2PKH, &chaincfg.MainNetParams, "xpub", p2pkh},
+		{ScriptTypeP2SHP2WPKH, &chaincfg.MainNetParams, "ypub", p2shP2wpkh},
+		{ScriptTypeP2WPKH, &chaincfg.MainNetParams, "zpub", p2wpkh},
+		{ScriptTypeP2SHP2WSH, &chaincfg.MainNetParams, "Ypub", nil},
+		{ScriptTypeP2WSH, &chaincfg.MainNetParams, "Zpub", nil},
+		{ScriptTypeP2PKH, &chaincfg.TestNet4Params, "tpub", nil},
+		{ScriptTypeP2SHP2WPKH, &chaincfg.TestNet4Params, "upub", nil},
+		{ScriptTypeP2WPKH, &chaincfg.TestNet4Params, "vpub", nil},
+		{ScriptTypeP2SHP2WSH, &chaincfg.TestNet4Params, "Upub", nil},
+		{ScriptTypeP2WSH, &chaincfg.TestNet4Params, "Vpub", nil},
+	}
+
+	for _, test := range tests {
+		priv, err := master.ConvertVersion(test.net, test.scriptType)
+		if err != nil {
+			t.Errorf("%s: ConvertVersion: unexpected error: %v",
+				test.prefix, err)
+			continue
+		}
+		if !priv.IsPrivate() {
+			t.Errorf("%s: converted key is not private", test.prefix)
+		}
+
+		// The public key has the matching public version, and the
+		// version survives a round trip through its string form.
+		neutered, err := priv.Neuter()
+		if err != nil {
+			t.Errorf("%s: Neuter: unexpected error: %v", test.prefix,
+				err)
+			continue
+		}
+		pub, err := NewKeyFromString(neutered.String())
+		if err != nil {
+			t.Errorf("%s: NewKeyFromString: unexpected error: %v",
+				test.prefix, err)
+			continue
+		}
+		if !strings.HasPrefix(pub.String(), test.prefix) {
+			t.Errorf("%s: got key %s", test.prefix, pub)
+		}
+		if pub.ScriptType() != test.scriptType {
+			t.Errorf("%s: got script type %v, want %v", test.prefix,
+				pub.ScriptType(), test.scriptType)
+		}
+		if !pub.IsForNet(test.net) {
+			t.Errorf("%s: key is not for %s", test.prefix,
+				test.net.Name)
+		}
+
+		addr, err := pub.Address(test.net)
+		switch test.scriptType {
+		case ScriptTypeP2SHP2WSH, ScriptTypeP2WSH:
+			if err != ErrNoAddress {
+				t.Errorf("%s: Address: got error %v, want %v",
+					test.prefix, err, ErrNoAddress)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s: Address: unexpected error: %v",
+				test.prefix, err)
+			continue
+		}
+		if test.addr != nil &&
+			addr.EncodeAddress() != test.addr.EncodeAddress() {
+
+			t.Errorf("%s: got address %s, want %s", test.prefix,
+				addr, test.addr)
+		}
+	}
+
+	// Converting between versions matches the SLIP132 vectors.
+	pub, _ := NewKeyFromString(xpub)
+	converted, err := pub.ConvertVersion(
+		&chaincfg.MainNetParams, ScriptTypeP2WPKH,
+	)
+	if err != nil || converted.String() != zpub {
+		t.Fatalf("ConvertVersion: got %v (%v), want %s", converted,
+			err, zpub)
+	}
+
+	// Changing the network keeps the script type, and a zpub is not for
+	// the test network.
+	if converted.IsForNet(&chaincfg.TestNet4Params) {
+		t.Fatalf("zpub is for the test network")
+	}
+	converted.SetNet(&chaincfg.TestNet4Params)
+	if !strings.HasPrefix(converted.String(), "vpub") {
+		t.Fatalf("SetNet: got %s, want a vpub", converted)
+	}
+}
+
+// TestRegisterVersion ensures version bytes can only be registered once and
+// unregistered combinations are reported.
+func TestRegisterVersion(t *testing.T) {
+	err := RegisterVersion(
+		&chaincfg.SimNetParams, ScriptTypeP2WPKH,
+		[4]byte{0x04, 0xb2, 0x43, 0x0c}, [4]byte{0x01, 0x02, 0x03, 0x04},
+	)
+	if err != ErrDuplicateVersion {
+		t.Fatalf("RegisterVersion: got error %v, want %v", err,
+			ErrDuplicateVersion)
+	}
+
+	_, err = VersionFor(&chaincfg.SimNetParams, ScriptTypeP2WPKH)
+	if err != ErrUnknownVersion {
+		t.Fatalf("VersionFor: got error %v, want %v", err,
+			ErrUnknownVersion)
+	}
+
+	// The standard versions of a network are always known.
+	v, err := VersionFor(&chaincfg.SimNetParams, ScriptTypeP2PKH)
+	if err != nil {
+		t.Fatalf("VersionFor: unexpected error: %v", err)
+	}
+	if v.Public != chaincfg.SimNetParams.HDPublicKeyID {
+		t.Fatalf("VersionFor: got public version %x, want %x",
+			v.Public, chaincfg.SimNetParams.HDPublicKeyID)
+	}
+
+	if _, ok := LookupVersion([]byte{0x01, 0x02, 0x03, 0x04}); ok {
+		t.Fatalf("LookupVersion: found unregistered version")
+	}
+}
@@ -0,0 +1,28 @@
+// Copyright (c) 2026 The monasuite developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+/*
+Package hdscan discovers the used addresses of a BIP44 style HD wallet
+account.
+
+Overview
+
+An account of a BIP44, BIP49 or BIP84 wallet is an extended key with two
+branches: the external branch at index 0, which receiving addresses are
+derived from, and the internal branch at index 1, which change addresses are
+derived from.  As wallets hand out the addresses of each branch in order, the
+used addresses of an account can be discovered by checking the addresses of
+each branch in order until a number of consecutive addresses, the gap limit,
+turns out to be unused.
+
+A Scanner derives the addresses of an account and asks a UsedFunc whether
+each of their output scripts has been used.  UsedFuncs can be backed by a set
+of known scripts, by committed filters of blocks or by any other source such
+as an address index.  The type of the addresses follows the version of the
+account key, so a zpub yields native segwit addresses.
+
+The progress of a scan is kept in a State, which can be saved and passed to a
+new Scanner to resume the scan later, such as after new blocks arrive.
+*/
+package hdscan
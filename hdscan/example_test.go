@@ -0,0 +1,78 @@
+// Copyright (c) 2026 The monasuite developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package hdscan_test
+
+import (
+	"fmt"
+
+	"github.com/monasuite/monad/chaincfg"
+	"github.com/monasuite/monautil/hdkeychain"
+	"github.com/monasuite/monautil/hdscan"
+)
+
+// This example demonstrates how to discover the used addresses of an account
+// and find the next receiving address to hand out.
+func ExampleScanner_Scan() {
+	account, err := hdkeychain.NewKeyFromString("zpub6rFR7y4Q2AijBEqTUquhVz398htDFrtymD9xYYfG1m4wAcvPhXNfE3EfH1r1ADqtfSdVCToUG868RvUUkgDKf31mGDtKsAYz2oz2AGutZYs")
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	accountPath, err := hdkeychain.ParsePath("m/84'/22'/0'")
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+
+	// The used scripts would usually be looked up in committed filters or
+	// an address index.
+	used := make(map[string]bool)
+	s, err := hdscan.NewScanner(hdscan.Config{
+		AccountKey:  account,
+		AccountPath: accountPath,
+		Net:         &chaincfg.MainNetParams,
+		IsUsed: func(script []byte) (bool, error) {
+			return used[string(script)], nil
+		},
+	})
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+
+	// Pretend the first receiving address was paid to.
+	first, err := s.DeriveAddress(hdscan.ExternalBranch, 0)
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	used[string(first.Script)] = true
+
+	addrs, err := s.Scan()
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	for _, addr := range addrs {
+		if addr.Used {
+			fmt.Println("Used:", addr.Path, addr.Address)
+		}
+	}
+	fmt.Printf("Checked %d addresses\n", len(addrs))
+
+	next, err := s.DeriveAddress(
+		hdscan.ExternalBranch, s.State().External.NextUnused,
+	)
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	fmt.Println("Next:", next.Path, next.Address)
+
+	// Output:
+	// Used: m/84'/22'/0'/0/0 mona1qcr8te4kr609gcawutmrza0j4xv80jy8z4uvtfa
+	// Checked 41 addresses
+	// Next: m/84'/22'/0'/0/1 mona1qnjg0jd8228aq7egyzacy8cys3knf9xvrasqtgf
+}
@@ -0,0 +1,287 @@
+// Copyright (c) 2026 The monasuite developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package hdscan
+
+import (
+	"errors"
+
+	"github.com/monasuite/monad/chaincfg"
+	"github.com/monasuite/monautil"
+	"github.com/monasuite/monautil/hdkeychain"
+)
+
+const (
+	// ExternalBranch is the branch of an account that receiving addresses
+	// are derived from.
+	ExternalBranch uint32 = 0
+
+	// InternalBranch is the branch of an account that change addresses are
+	// derived from.
+	InternalBranch uint32 = 1
+
+	// DefaultGapLimit is the number of consecutive unused addresses after
+	// which a branch is considered exhausted, as recommended by BIP44.
+	DefaultGapLimit = 20
+)
+
+var (
+	// ErrMissingAccountKey describes an error in which a Config lacks the
+	// account key.
+	ErrMissingAccountKey = errors.New("account key is required")
+
+	// ErrMissingUsedFunc describes an error in which a Config lacks the
+	// function telling used scripts apart.
+	ErrMissingUsedFunc = errors.New("used function is required")
+
+	// ErrMissingNet describes an error in which a Config lacks the network
+	// the addresses are encoded for.
+	ErrMissingNet = errors.New("network is required")
+
+	// ErrInvalidBranch describes an error in which the caller requested an
+	// address of a branch other than the external and internal ones.
+	ErrInvalidBranch = errors.New("invalid branch")
+
+	// ErrInvalidIndex describes an error in which the caller requested an
+	// address at a hardened index, which isn't part of a BIP44 branch.
+	ErrInvalidIndex = errors.New("invalid address index")
+)
+
+// BranchState is the progress of a scan on a single branch of an account.
+type BranchState struct {
+	// Scanned is the number of addresses of the branch that have been
+	// checked at least once, starting at index 0.  A wallet should watch
+	// all of them for payments.
+	Scanned uint32
+
+	// NextUnused is the index following the last used address found on
+	// the branch, or 0 if none was found.  It is the index of the next
+	// address to hand out.
+	NextUnused uint32
+}
+
+// State is the progress of a scan on an account.  It can be saved and passed
+// to a new Scanner to resume scanning where a previous one left off.
+type State struct {
+	External BranchState
+	Internal BranchState
+}
+
+// branch returns the state of the passed branch.
+func (s *State) branch(branch uint32) *BranchState {
+	if branch == InternalBranch {
+		return &s.Internal
+	}
+	return &s.External
+}
+
+// Config holds the parameters of a Scanner.
+type Config struct {
+	// AccountKey is the extended key of the account, such as the key at
+	// m/84'/22'/0'.  The version of the key determines the type of the
+	// addresses, so a zpub yields P2WPKH addresses.  Use
+	// ExtendedKey.ConvertVersion to scan a key for another address type.
+	AccountKey *hdkeychain.ExtendedKey
+
+	// AccountPath is the derivation path of the account key, which is
+	// prepended to the paths of the addresses.  It may be nil.
+	AccountPath hdkeychain.DerivationPath
+
+	// Net is the network the addresses are encoded for.
+	Net *chaincfg.Params
+
+	// GapLimit is the number of consecutive unused addresses after which
+	// a branch is considered exhausted.  DefaultGapLimit is used if it is
+	// zero.
+	GapLimit uint32
+
+	// IsUsed reports whether an output script has been used.
+	IsUsed UsedFunc
+
+	// State is the progress of a previous scan to resume from.  The zero
+	// value starts a new scan.
+	State State
+}
+
+// Address is an address derived from an account during a scan.
+type Address struct {
+	// Branch is the branch the address was derived from.
+	Branch uint32
+
+	// Index is the child index of the address within its branch.
+	Index uint32
+
+	// Path is the derivation path of the address, the account path
+	// followed by the branch and index.
+	Path hdkeychain.DerivationPath
+
+	// Address is the address itself.
+	Address monautil.Address
+
+	// Script is the output script paying to the address.
+	Script []byte
+
+	// Used is true if the address has been used.
+	Used bool
+}
+
+// Scanner discovers the used addresses of the external and internal branches
+// of a BIP44 style account, deriving addresses on each branch until the gap
+// limit of consecutive unused addresses is reached.
+type Scanner struct {
+	cfg      Config
+	branches [2]*hdkeychain.ExtendedKey
+	state    State
+}
+
+// NewScanner returns a Scanner for the account described by the passed
+// config.  An error is returned if the config is incomplete or the version of
+// the account key designates multisig scripts.
+func NewScanner(cfg Config) (*Scanner, error) {
+	if cfg.AccountKey == nil {
+		return nil, ErrMissingAccountKey
+	}
+	if cfg.Net == nil {
+		return nil, ErrMissingNet
+	}
+	if cfg.IsUsed == nil {
+		return nil, ErrMissingUsedFunc
+	}
+	if _, err := cfg.AccountKey.Address(cfg.Net); err != nil {
+		return nil, err
+	}
+	if cfg.GapLimit == 0 {
+		cfg.GapLimit = DefaultGapLimit
+	}
+
+	s := &Scanner{cfg: cfg, state: cfg.State}
+	for i, branch := range []uint32{ExternalBranch, InternalBranch} {
+		key, err := cfg.AccountKey.Derive(branch)
+		if err != nil {
+			return nil, err
+		}
+		s.branches[i] = key
+	}
+
+	return s, nil
+}
+
+// State returns the progress of the scan, which may be saved and passed to a
+// new Scanner through Config.State to resume it.
+func (s *Scanner) State() State {
+	return s.state
+}
+
+// Scan checks the addresses of both branches of the account, starting at the
+// first unused address of each branch, until GapLimit consecutive addresses
+// of each branch are unused.  It returns the addresses that were checked, used
+// or not, in the order they were checked.
+//
+// Addresses of the gap that were checked by a previous scan are checked
+// again, since they may have been used since, such as when the scan is
+// resumed after new blocks arrived.
+//
+// If IsUsed fails, the error is returned along with the addresses checked so
+// far.  The state then reflects the progress made, so the scan can be
+// resumed by calling Scan again.
+func (s *Scanner) Scan() ([]Address, error) {
+	var addrs []Address
+	for _, branch := range []uint32{ExternalBranch, InternalBranch} {
+		branchAddrs, err := s.scanBranch(branch)
+		addrs = append(addrs, branchAddrs...)
+		if err != nil {
+			return addrs, err
+		}
+	}
+
+	return addrs, nil
+}
+
+// scanBranch checks the addresses of the passed branch until the gap limit is
+// reached.
+func (s *Scanner) scanBranch(branch uint32) ([]Address, error) {
+	state := s.state.branch(branch)
+
+	// The limit moves along as used addresses are found.  It never
+	// reaches into the hardened index range, which also keeps it from
+	// overflowing.
+	var addrs []Address
+	index := state.NextUnused
+	for ; index < gapEnd(state.NextUnused, s.cfg.GapLimit); index++ {
+		addr, err := s.deriveAddress(branch, index)
+		if err == hdkeychain.ErrInvalidChild {
+			// The index doesn't derive to a usable key, so it is
+			// skipped as required by BIP32.
+			continue
+		}
+		if err != nil {
+			return addrs, err
+		}
+
+		addr.Used, err = s.cfg.IsUsed(addr.Script)
+		if err != nil {
+			return addrs, err
+		}
+
+		if index >= state.Scanned {
+			state.Scanned = index + 1
+		}
+		if addr.Used {
+			state.NextUnused = index + 1
+		}
+		addrs = append(addrs, *addr)
+	}
+
+	return addrs, nil
+}
+
+// gapEnd returns the index following the gap of gapLimit addresses starting
+// at nextUnused, capped at the first hardened index.
+func gapEnd(nextUnused, gapLimit uint32) uint32 {
+	end := uint64(nextUnused) + uint64(gapLimit)
+	if end > hdkeychain.HardenedKeyStart {
+		return hdkeychain.HardenedKeyStart
+	}
+	return uint32(end)
+}
+
+// deriveAddress derives the address at the passed branch and index.
+func (s *Scanner) deriveAddress(branch, index uint32) (*Address, error) {
+	key, err := s.branches[branch].Derive(index)
+	if err != nil {
+		return nil, err
+	}
+
+	addr, err := key.Address(s.cfg.Net)
+	if err != nil {
+		return nil, err
+	}
+	script, err := monautil.PayToAddrScript(addr)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Address{
+		Branch:  branch,
+		Index:   index,
+		Path:    s.cfg.AccountPath.Child(branch, index),
+		Address: addr,
+		Script:  script,
+	}, nil
+}
+
+// DeriveAddress derives the address at the passed branch and index of the
+// account without checking whether it is used, such as to hand out the
+// address at the NextUnused index of the external branch.  ErrInvalidIndex
+// is returned for hardened indexes.
+func (s *Scanner) DeriveAddress(branch, index uint32) (*Address, error) {
+	if branch != ExternalBranch && branch != InternalBranch {
+		return nil, ErrInvalidBranch
+	}
+	if index >= hdkeychain.HardenedKeyStart {
+		return nil, ErrInvalidIndex
+	}
+
+	return s.deriveAddress(branch, index)
+}
@@ -0,0 +1,377 @@
+// Copyright (c) 2026 The monasuite developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package hdscan_test
+
+import (
+	"encoding/hex"
+	"errors"
+	"math"
+	"testing"
+
+	"github.com/monasuite/monad/chaincfg"
+	"github.com/monasuite/monautil/gcs"
+	"github.com/monasuite/monautil/hdkeychain"
+	"github.com/monasuite/monautil/hdscan"
+)
+
+// accountPath is the path of the account scanned by the tests.
+var accountPath = hdkeychain.DerivationPath{
+	hdkeychain.HardenedKeyStart + 84,
+	hdkeychain.HardenedKeyStart + 22,
+	hdkeychain.HardenedKeyStart,
+}
+
+// accountKey returns the neutered BIP84 account key of the master key of the
+// first BIP32 test vector.
+func accountKey(t *testing.T) *hdkeychain.ExtendedKey {
+	seed, _ := hex.DecodeString("000102030405060708090a0b0c0d0e0f")
+	master, err := hdkeychain.NewMaster(seed, &chaincfg.MainNetParams)
+	if err != nil {
+		t.Fatalf("NewMaster: unexpected error: %v", err)
+	}
+	account, _, err := master.DerivePath(accountPath)
+	if err != nil {
+		t.Fatalf("DerivePath: unexpected error: %v", err)
+	}
+	account, err = account.ConvertVersion(
+		&chaincfg.MainNetParams, hdkeychain.ScriptTypeP2WPKH,
+	)
+	if err != nil {
+		t.Fatalf("ConvertVersion: unexpected error: %v", err)
+	}
+	account, err = account.Neuter()
+	if err != nil {
+		t.Fatalf("Neuter: unexpected error: %v", err)
+	}
+
+	return account
+}
+
+// newScanner returns a scanner of the test account with the passed gap limit,
+// state and used function.
+func newScanner(t *testing.T, gapLimit uint32, state hdscan.State,
+	isUsed hdscan.UsedFunc) *hdscan.Scanner {
+
+	s, err := hdscan.NewScanner(hdscan.Config{
+		AccountKey:  accountKey(t),
+		AccountPath: accountPath,
+		Net:         &chaincfg.MainNetParams,
+		GapLimit:    gapLimit,
+		IsUsed:      isUsed,
+		State:       state,
+	})
+	if err != nil {
+		t.Fatalf("NewScanner: unexpected error: %v", err)
+	}
+
+	return s
+}
+
+// scripts returns the output scripts of the passed indexes of a branch of the
+// test account.
+func scripts(t *testing.T, branch uint32, indexes ...uint32) [][]byte {
+	s := newScanner(t, 0, hdscan.State{}, hdscan.ScriptSet(nil))
+
+	var scripts [][]byte
+	for _, index := range indexes {
+		addr, err := s.DeriveAddress(branch, index)
+		if err != nil {
+			t.Fatalf("DeriveAddress: unexpected error: %v", err)
+		}
+		scripts = append(scripts, addr.Script)
+	}
+
+	return scripts
+}
+
+// checkAddresses ensures the passed addresses are those of the given branch
+// starting at the given index, and that exactly the given indexes are used.
+func checkAddresses(t *testing.T, addrs []hdscan.Address, branch,
+	start uint32, used ...uint32) {
+
+	usedSet := make(map[uint32]bool)
+	for _, index := range used {
+		usedSet[index] = true
+	}
+
+	for i, addr := range addrs {
+		index := start + uint32(i)
+		if addr.Branch != branch || addr.Index != index {
+			t.Errorf("address #%d: got %d/%d, want %d/%d", i,
+				addr.Branch, addr.Index, branch, index)
+			continue
+		}
+		wantPath := accountPath.Child(branch, index).String()
+		if addr.Path.String() != wantPath {
+			t.Errorf("address #%d: got path %v, want %s", i,
+				addr.Path, wantPath)
+		}
+		if addr.Used != usedSet[index] {
+			t.Errorf("address #%d: got used %v, want %v", i,
+				addr.Used, usedSet[index])
+		}
+	}
+}
+
+// TestScanGapLimit ensures each branch is scanned until the gap limit of
+// consecutive unused addresses is reached.
+func TestScanGapLimit(t *testing.T) {
+	used := append(
+		scripts(t, hdscan.ExternalBranch, 0, 3, 7),
+		scripts(t, hdscan.InternalBranch, 1)...,
+	)
+	s := newScanner(t, 5, hdscan.State{}, hdscan.ScriptSet(used))
+
+	addrs, err := s.Scan()
+	if err != nil {
+		t.Fatalf("Scan: unexpected error: %v", err)
+	}
+	if len(addrs) != 13+7 {
+		t.Fatalf("Scan: got %d addresses, want %d", len(addrs), 13+7)
+	}
+	checkAddresses(t, addrs[:13], hdscan.ExternalBranch, 0, 0, 3, 7)
+	checkAddresses(t, addrs[13:], hdscan.InternalBranch, 0, 1)
+
+	want := hdscan.State{
+		External: hdscan.BranchState{Scanned: 13, NextUnused: 8},
+		Internal: hdscan.BranchState{Scanned: 7, NextUnused: 2},
+	}
+	if s.State() != want {
+		t.Errorf("State: got %+v, want %+v", s.State(), want)
+	}
+
+	// Scanning again checks the addresses of the gaps only.
+	addrs, err = s.Scan()
+	if err != nil {
+		t.Fatalf("Scan: unexpected error: %v", err)
+	}
+	if len(addrs) != 5+5 {
+		t.Fatalf("Scan: got %d addresses, want %d", len(addrs), 5+5)
+	}
+	checkAddresses(t, addrs[:5], hdscan.ExternalBranch, 8)
+	checkAddresses(t, addrs[5:], hdscan.InternalBranch, 2)
+	if s.State() != want {
+		t.Errorf("State: got %+v, want %+v", s.State(), want)
+	}
+}
+
+// TestScanDefaultGapLimit ensures the default gap limit is used when none is
+// configured.
+func TestScanDefaultGapLimit(t *testing.T) {
+	s := newScanner(t, 0, hdscan.State{}, hdscan.ScriptSet(nil))
+
+	addrs, err := s.Scan()
+	if err != nil {
+		t.Fatalf("Scan: unexpected error: %v", err)
+	}
+	if len(addrs) != 2*hdscan.DefaultGapLimit {
+		t.Errorf("Scan: got %d addresses, want %d", len(addrs),
+			2*hdscan.DefaultGapLimit)
+	}
+}
+
+// TestScanResume ensures a scan resumes from a saved state, checking the
+// addresses from the first unused one of each branch onwards.
+func TestScanResume(t *testing.T) {
+	used := scripts(t, hdscan.ExternalBranch, 0)
+	s := newScanner(t, 3, hdscan.State{}, hdscan.ScriptSet(used))
+	if _, err := s.Scan(); err != nil {
+		t.Fatalf("Scan: unexpected error: %v", err)
+	}
+	state := s.State()
+
+	// The last address of the gap was used since, which extends the scan
+	// of the external branch.
+	used = append(used, scripts(t, hdscan.ExternalBranch, 3)...)
+	s = newScanner(t, 3, state, hdscan.ScriptSet(used))
+	addrs, err := s.Scan()
+	if err != nil {
+		t.Fatalf("Scan: unexpected error: %v", err)
+	}
+	if len(addrs) != 6+3 {
+		t.Fatalf("Scan: got %d addresses, want %d", len(addrs), 6+3)
+	}
+	checkAddresses(t, addrs[:6], hdscan.ExternalBranch, 1, 3)
+	checkAddresses(t, addrs[6:], hdscan.InternalBranch, 0)
+
+	want := hdscan.State{
+		External: hdscan.BranchState{Scanned: 7, NextUnused: 4},
+		Internal: hdscan.BranchState{Scanned: 3},
+	}
+	if s.State() != want {
+		t.Errorf("State: got %+v, want %+v", s.State(), want)
+	}
+}
+
+// TestScanError ensures errors of the used function are returned along with
+// the addresses checked so far, and that the scan can be resumed afterwards.
+func TestScanError(t *testing.T) {
+	errFailed := errors.New("failed")
+	fail := scripts(t, hdscan.ExternalBranch, 2)[0]
+	used := hdscan.ScriptSet(scripts(t, hdscan.ExternalBranch, 1))
+	failing := true
+	isUsed := func(script []byte) (bool, error) {
+		if failing && string(script) == string(fail) {
+			return false, errFailed
+		}
+		return used(script)
+	}
+
+	s := newScanner(t, 2, hdscan.State{}, isUsed)
+	addrs, err := s.Scan()
+	if err != errFailed {
+		t.Fatalf("Scan: got error %v, want %v", err, errFailed)
+	}
+	checkAddresses(t, addrs, hdscan.ExternalBranch, 0, 1)
+	if len(addrs) != 2 {
+		t.Fatalf("Scan: got %d addresses, want 2", len(addrs))
+	}
+
+	failing = false
+	addrs, err = s.Scan()
+	if err != nil {
+		t.Fatalf("Scan: unexpected error: %v", err)
+	}
+	if len(addrs) != 2+2 {
+		t.Fatalf("Scan: got %d addresses, want %d", len(addrs), 2+2)
+	}
+	checkAddresses(t, addrs[:2], hdscan.ExternalBranch, 2)
+	checkAddresses(t, addrs[2:], hdscan.InternalBranch, 0)
+}
+
+// TestFilterMatcher ensures scripts matching any of a set of committed filters
+// are reported as used.
+func TestFilterMatcher(t *testing.T) {
+	used := scripts(t, hdscan.ExternalBranch, 0, 3)
+	internal := scripts(t, hdscan.InternalBranch, 0)
+
+	var keys [][gcs.KeySize]byte
+	var filters []*gcs.Filter
+	for i, data := range [][][]byte{used[:1], used[1:], internal} {
+		var key [gcs.KeySize]byte
+		key[0] = byte(i)
+		filter, err := gcs.BuildGCSFilter(19, 784931, key, data)
+		if err != nil {
+			t.Fatalf("BuildGCSFilter: unexpected error: %v", err)
+		}
+		keys = append(keys, key)
+		filters = append(filters, filter)
+	}
+
+	_, err := hdscan.FilterMatcher(filters, keys[:2])
+	if err != hdscan.ErrFilterKeyMismatch {
+		t.Fatalf("FilterMatcher: got error %v, want %v", err,
+			hdscan.ErrFilterKeyMismatch)
+	}
+	matcher, err := hdscan.FilterMatcher(filters, keys)
+	if err != nil {
+		t.Fatalf("FilterMatcher: unexpected error: %v", err)
+	}
+
+	isUsed := hdscan.AnyUsed(hdscan.ScriptSet(nil), matcher)
+	s := newScanner(t, 3, hdscan.State{}, isUsed)
+	addrs, err := s.Scan()
+	if err != nil {
+		t.Fatalf("Scan: unexpected error: %v", err)
+	}
+	if len(addrs) != 7+4 {
+		t.Fatalf("Scan: got %d addresses, want %d", len(addrs), 7+4)
+	}
+	checkAddresses(t, addrs[:7], hdscan.ExternalBranch, 0, 0, 3)
+	checkAddresses(t, addrs[7:], hdscan.InternalBranch, 0, 0)
+}
+
+// TestScanHardenedLimit ensures a scan stops at the first hardened index
+// instead of overflowing the index of its gap.
+func TestScanHardenedLimit(t *testing.T) {
+	state := hdscan.State{
+		External: hdscan.BranchState{
+			NextUnused: hdkeychain.HardenedKeyStart - 2,
+		},
+		Internal: hdscan.BranchState{
+			NextUnused: hdkeychain.HardenedKeyStart - 1,
+		},
+	}
+	s := newScanner(t, math.MaxUint32, state, hdscan.ScriptSet(nil))
+	addrs, err := s.Scan()
+	if err != nil {
+		t.Fatalf("Scan: unexpected error: %v", err)
+	}
+	if len(addrs) != 2+1 {
+		t.Fatalf("Scan: got %d addresses, want %d", len(addrs), 2+1)
+	}
+	for i, want := range []uint32{
+		hdkeychain.HardenedKeyStart - 2,
+		hdkeychain.HardenedKeyStart - 1,
+		hdkeychain.HardenedKeyStart - 1,
+	} {
+		if addrs[i].Index != want {
+			t.Errorf("Scan: address %d has index %d, want %d", i,
+				addrs[i].Index, want)
+		}
+	}
+	if got := s.State().External.Scanned; got != hdkeychain.HardenedKeyStart {
+		t.Errorf("Scan: scanned %d external addresses, want %d", got,
+			uint32(hdkeychain.HardenedKeyStart))
+	}
+
+	_, err = s.DeriveAddress(hdscan.ExternalBranch, hdkeychain.HardenedKeyStart)
+	if err != hdscan.ErrInvalidIndex {
+		t.Errorf("DeriveAddress: got error %v, want %v", err,
+			hdscan.ErrInvalidIndex)
+	}
+}
+
+// TestNewScannerErrors ensures incomplete configs and account keys without
+// single key addresses are rejected.
+func TestNewScannerErrors(t *testing.T) {
+	key := accountKey(t)
+	multisigKey, err := key.ConvertVersion(
+		&chaincfg.MainNetParams, hdkeychain.ScriptTypeP2WSH,
+	)
+	if err != nil {
+		t.Fatalf("ConvertVersion: unexpected error: %v", err)
+	}
+	isUsed := hdscan.ScriptSet(nil)
+	net := &chaincfg.MainNetParams
+
+	tests := []struct {
+		name string
+		cfg  hdscan.Config
+		err  error
+	}{{
+		name: "missing account key",
+		cfg:  hdscan.Config{Net: net, IsUsed: isUsed},
+		err:  hdscan.ErrMissingAccountKey,
+	}, {
+		name: "missing network",
+		cfg:  hdscan.Config{AccountKey: key, IsUsed: isUsed},
+		err:  hdscan.ErrMissingNet,
+	}, {
+		name: "missing used function",
+		cfg:  hdscan.Config{AccountKey: key, Net: net},
+		err:  hdscan.ErrMissingUsedFunc,
+	}, {
+		name: "multisig account key",
+		cfg: hdscan.Config{
+			AccountKey: multisigKey, Net: net, IsUsed: isUsed,
+		},
+		err: hdkeychain.ErrNoAddress,
+	}}
+
+	for _, test := range tests {
+		_, err := hdscan.NewScanner(test.cfg)
+		if err != test.err {
+			t.Errorf("%s: got error %v, want %v", test.name, err,
+				test.err)
+		}
+	}
+
+	s := newScanner(t, 0, hdscan.State{}, isUsed)
+	if _, err := s.DeriveAddress(2, 0); err != hdscan.ErrInvalidBranch {
+		t.Errorf("DeriveAddress: got error %v, want %v", err,
+			hdscan.ErrInvalidBranch)
+	}
+}
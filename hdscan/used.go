@@ -0,0 +1,79 @@
+// Copyright (c) 2026 The monasuite developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package hdscan
+
+import (
+	"errors"
+
+	"github.com/monasuite/monautil/gcs"
+)
+
+// ErrFilterKeyMismatch describes an error in which the number of committed
+// filters passed to FilterMatcher differs from the number of keys.
+var ErrFilterKeyMismatch = errors.New("number of filters and keys differ")
+
+// UsedFunc reports whether the passed output script has been used, that is,
+// whether it was paid to on chain.
+type UsedFunc func(script []byte) (bool, error)
+
+// ScriptSet returns a UsedFunc reporting the passed scripts as used.
+func ScriptSet(scripts [][]byte) UsedFunc {
+	set := make(map[string]struct{}, len(scripts))
+	for _, script := range scripts {
+		set[string(script)] = struct{}{}
+	}
+
+	return func(script []byte) (bool, error) {
+		_, ok := set[string(script)]
+		return ok, nil
+	}
+}
+
+// FilterMatcher returns a UsedFunc reporting a script as used if it matches
+// any of the passed committed filters, each queried with the key at the same
+// position in keys, which is usually derived from the hash of the block the
+// filter belongs to.  As filters have false positives, the scripts reported
+// as used should be confirmed by fetching the matching blocks.
+//
+// ErrFilterKeyMismatch is returned if there isn't exactly one key per filter.
+func FilterMatcher(filters []*gcs.Filter,
+	keys [][gcs.KeySize]byte) (UsedFunc, error) {
+
+	if len(filters) != len(keys) {
+		return nil, ErrFilterKeyMismatch
+	}
+
+	return func(script []byte) (bool, error) {
+		for i, filter := range filters {
+			match, err := filter.Match(keys[i], script)
+			if err != nil {
+				return false, err
+			}
+			if match {
+				return true, nil
+			}
+		}
+
+		return false, nil
+	}, nil
+}
+
+// AnyUsed returns a UsedFunc reporting a script as used if any of the passed
+// functions does, consulting them in order.
+func AnyUsed(funcs ...UsedFunc) UsedFunc {
+	return func(script []byte) (bool, error) {
+		for _, f := range funcs {
+			used, err := f(script)
+			if err != nil {
+				return false, err
+			}
+			if used {
+				return true, nil
+			}
+		}
+
+		return false, nil
+	}
+}
@@ -77,6 +77,29 @@ func TstAddressWitnessScriptHash(version byte, program [32]byte,
 	}
 }
 
+// TstAddressTaproot creates an AddressTaproot, initiating the fields as given.
+func TstAddressTaproot(version byte, program [32]byte,
+	hrp string) *AddressTaproot {
+
+	return &AddressTaproot{
+		hrp:            hrp,
+		witnessVersion: version,
+		witnessProgram: program,
+	}
+}
+
+// TstAddressWitnessProgram creates an AddressWitnessProgram, initiating the
+// fields as given.
+func TstAddressWitnessProgram(version byte, program []byte,
+	hrp string) *AddressWitnessProgram {
+
+	return &AddressWitnessProgram{
+		hrp:            hrp,
+		witnessVersion: version,
+		witnessProgram: program,
+	}
+}
+
 // TstAddressPubKey makes an AddressPubKey, setting the unexported fields with
 // the parameters.
 func TstAddressPubKey(serializedPubKey []byte, pubKeyFormat PubKeyFormat,
@@ -98,9 +121,10 @@ func TstAddressSAddr(addr string) []byte {
 }
 
 // TstAddressSegwitSAddr returns the expected witness program bytes for
-// bech32 encoded P2WPKH and P2WSH monacoin addresses.
+// bech32 encoded P2WPKH and P2WSH and bech32m encoded P2TR and other witness
+// program monacoin addresses.
 func TstAddressSegwitSAddr(addr string) []byte {
-	_, data, err := bech32.Decode(addr)
+	_, data, _, err := bech32.DecodeGeneric(addr)
 	if err != nil {
 		return []byte{}
 	}
@@ -0,0 +1,180 @@
+// Copyright (c) 2026 The monasuite developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package monautil
+
+import (
+	"bytes"
+	"encoding/base64"
+	"errors"
+
+	"github.com/monasuite/monad/btcec"
+	"github.com/monasuite/monad/chaincfg"
+	"github.com/monasuite/monad/chaincfg/chainhash"
+	"github.com/monasuite/monad/wire"
+)
+
+// References:
+//   [BIP137]: Signatures of Messages using Private Keys
+//   https://github.com/bitcoin/bips/blob/master/bip-0137.mediawiki
+
+// messageMagic is the prefix of signed messages, which keeps them from being
+// valid transaction signature hashes.
+const messageMagic = "Monacoin Signed Message:\n"
+
+// The header byte of a message signature is the sum of the recovery id of the
+// public key, 0 to 3, and one of these values, which designate the type of
+// the address the message was signed for [BIP137].
+const (
+	// headerP2PKHUncompressed is used for P2PKH addresses of uncompressed
+	// public keys.
+	headerP2PKHUncompressed = 27
+
+	// headerP2PKHCompressed is used for P2PKH addresses of compressed
+	// public keys.  Some wallets use it for segwit addresses as well.
+	headerP2PKHCompressed = 31
+
+	// headerP2SHP2WPKH is used for P2WPKH addresses nested in P2SH.
+	headerP2SHP2WPKH = 35
+
+	// headerP2WPKH is used for native P2WPKH addresses.
+	headerP2WPKH = 39
+)
+
+var (
+	// ErrMalformedSignature describes an error in which a message
+	// signature is not the base64 encoding of a 65 byte compact signature
+	// with a header byte valid for the address it is verified against.
+	ErrMalformedSignature = errors.New("malformed message signature")
+
+	// ErrUnsupportedAddress describes an error in which a message
+	// signature is verified against an address that isn't controlled by a
+	// single public key hash.
+	ErrUnsupportedAddress = errors.New("address type does not support " +
+		"message signatures")
+
+	// ErrWrongNet describes an error in which a message signature is
+	// verified against an address of another network.
+	ErrWrongNet = errors.New("address is not for the network")
+)
+
+// messageHash returns the hash a signature of the passed message commits to:
+// the double SHA256 of the message magic followed by the message, each
+// prefixed by their length.
+func messageHash(msg string) []byte {
+	var buf bytes.Buffer
+	wire.WriteVarString(&buf, 0, messageMagic)
+	wire.WriteVarString(&buf, 0, msg)
+	return chainhash.DoubleHashB(buf.Bytes())
+}
+
+// SignMessage signs the passed message with the private key of the WIF and
+// returns the base64 encoded compact signature, as done by the signmessage
+// RPC of Monacoin Core.  The signature is made for the P2PKH address of the
+// key, in the compressed or uncompressed form the WIF specifies.  Wallets
+// such as Electrum-Mona accept it for the segwit addresses of compressed keys
+// as well.
+func SignMessage(wif *WIF, msg string) (string, error) {
+	sig, err := btcec.SignCompact(
+		btcec.S256(), wif.PrivKey, messageHash(msg), wif.CompressPubKey,
+	)
+	if err != nil {
+		return "", err
+	}
+
+	return base64.StdEncoding.EncodeToString(sig), nil
+}
+
+// VerifyMessage returns whether the passed base64 encoded signature is a valid
+// signature of the message by the key controlling the passed address.  P2PKH,
+// P2WPKH and P2SH-P2WPKH addresses are supported.  The header byte of the
+// signature must designate the type of the address following [BIP137], except
+// that segwit addresses also accept the compressed P2PKH header bytes used by
+// wallets which predate it.  Segwit addresses are only controlled by
+// compressed keys.
+//
+// An error is returned if the address is of another type or isn't for the
+// passed network, or if the signature is malformed or its header byte
+// designates another type of address.
+func VerifyMessage(addr Address, sig string, msg string,
+	net *chaincfg.Params) (bool, error) {
+
+	if !addr.IsForNet(net) {
+		return false, ErrWrongNet
+	}
+
+	// The header byte is the recovery id added to the base designating
+	// the address type.  The compressed P2PKH base is accepted for every
+	// address type.
+	var addrHeader byte
+	switch addr.(type) {
+	case *AddressPubKeyHash:
+		addrHeader = headerP2PKHUncompressed
+
+	case *AddressScriptHash:
+		addrHeader = headerP2SHP2WPKH
+
+	case *AddressWitnessPubKeyHash:
+		addrHeader = headerP2WPKH
+
+	default:
+		return false, ErrUnsupportedAddress
+	}
+
+	sigBytes, err := base64.StdEncoding.DecodeString(sig)
+	if err != nil || len(sigBytes) != 65 {
+		return false, ErrMalformedSignature
+	}
+	header := sigBytes[0]
+	if header < headerP2PKHUncompressed || header > headerP2WPKH+3 {
+		return false, ErrMalformedSignature
+	}
+	recoveryID := (header - headerP2PKHUncompressed) % 4
+	base := header - recoveryID
+	if base != addrHeader && base != headerP2PKHCompressed {
+		return false, ErrMalformedSignature
+	}
+
+	// The key can be recovered from a P2PKH header byte only, so segwit
+	// header bytes are translated to the compressed P2PKH one with the same
+	// recovery id.
+	compressed := base != headerP2PKHUncompressed
+	sigBytes[0] = headerP2PKHUncompressed + recoveryID
+	if compressed {
+		sigBytes[0] = headerP2PKHCompressed + recoveryID
+	}
+
+	pubKey, _, err := btcec.RecoverCompact(
+		btcec.S256(), sigBytes, messageHash(msg),
+	)
+	if err != nil {
+		return false, nil
+	}
+
+	var serialized []byte
+	if compressed {
+		serialized = pubKey.SerializeCompressed()
+	} else {
+		serialized = pubKey.SerializeUncompressed()
+	}
+	pkHash := Hash160(serialized)
+
+	if a, ok := addr.(*AddressScriptHash); ok {
+		// The redeem script is the version 0 witness program of the
+		// public key hash.
+		var witnessAddr Address
+		witnessAddr, err = NewAddressWitnessPubKeyHash(pkHash, net)
+		if err != nil {
+			return false, err
+		}
+		var redeemScript []byte
+		redeemScript, err = PayToAddrScript(witnessAddr)
+		if err != nil {
+			return false, err
+		}
+		return bytes.Equal(a.ScriptAddress(), Hash160(redeemScript)), nil
+	}
+
+	return bytes.Equal(addr.ScriptAddress(), pkHash), nil
+}
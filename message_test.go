@@ -0,0 +1,208 @@
+// Copyright (c) 2026 The monasuite developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package monautil_test
+
+import (
+	"encoding/base64"
+	"testing"
+
+	"github.com/monasuite/monad/chaincfg"
+	. "github.com/monasuite/monautil"
+)
+
+// messageAddresses returns the P2PKH, P2WPKH and P2SH-P2WPKH addresses of the
+// key of the passed WIF on the main network.
+func messageAddresses(t *testing.T, wif *WIF) (p2pkh, p2wpkh,
+	p2shP2wpkh Address) {
+
+	net := &chaincfg.MainNetParams
+	pkHash := Hash160(wif.SerializePubKey())
+
+	var err error
+	p2pkh, err = NewAddressPubKeyHash(pkHash, net)
+	if err != nil {
+		t.Fatalf("NewAddressPubKeyHash: unexpected error: %v", err)
+	}
+	p2wpkh, err = NewAddressWitnessPubKeyHash(pkHash, net)
+	if err != nil {
+		t.Fatalf("NewAddressWitnessPubKeyHash: unexpected error: %v", err)
+	}
+	redeemScript := append([]byte{0x00, 0x14}, pkHash...)
+	p2shP2wpkh, err = NewAddressScriptHash(redeemScript, net)
+	if err != nil {
+		t.Fatalf("NewAddressScriptHash: unexpected error: %v", err)
+	}
+
+	return p2pkh, p2wpkh, p2shP2wpkh
+}
+
+// withHeader returns the passed base64 encoded signature with the header byte
+// raised by the given offset.
+func withHeader(sig string, offset byte) string {
+	b, _ := base64.StdEncoding.DecodeString(sig)
+	b[0] += offset
+	return base64.StdEncoding.EncodeToString(b)
+}
+
+// TestSignMessage ensures messages are signed deterministically, with the
+// header byte reflecting the form of the public key.
+func TestSignMessage(t *testing.T) {
+	tests := []struct {
+		wif string
+		msg string
+		sig string
+	}{{
+		wif: "T4ff2X9NeYy292nC39BjDDGaMBoXg3JzPXXcuYce8KgVmZ9RD9U1",
+		msg: "Hello, Monacoin!",
+		sig: "IDV9itC5QvmgKkWdqwWXXq3vYMol/yJj/undAzNh2/TmImiOu1B7kYNJLCm4T+kcx1otcnmgHLHu/T2KnGcE2T4=",
+	}, {
+		wif: "6uVF44f9XiE9R2JwcNnvyEey3JrWc8KaPmynHtZzoX4WdQbujwQ",
+		msg: "Hello, Monacoin!",
+		sig: "HDV9itC5QvmgKkWdqwWXXq3vYMol/yJj/undAzNh2/TmImiOu1B7kYNJLCm4T+kcx1otcnmgHLHu/T2KnGcE2T4=",
+	}}
+
+	for _, test := range tests {
+		wif, err := DecodeWIF(test.wif)
+		if err != nil {
+			t.Fatalf("DecodeWIF: unexpected error: %v", err)
+		}
+		sig, err := SignMessage(wif, test.msg)
+		if err != nil {
+			t.Errorf("SignMessage: unexpected error: %v", err)
+			continue
+		}
+		if sig != test.sig {
+			t.Errorf("SignMessage: got %s, want %s", sig, test.sig)
+		}
+	}
+}
+
+// TestVerifyMessage ensures signatures are verified against P2PKH and segwit
+// addresses with the header byte conventions of the various wallets.
+func TestVerifyMessage(t *testing.T) {
+	const msg = "Hello, Monacoin!"
+
+	compressed, err := DecodeWIF(
+		"T4ff2X9NeYy292nC39BjDDGaMBoXg3JzPXXcuYce8KgVmZ9RD9U1",
+	)
+	if err != nil {
+		t.Fatalf("DecodeWIF: unexpected error: %v", err)
+	}
+	uncompressed, err := DecodeWIF(
+		"6uVF44f9XiE9R2JwcNnvyEey3JrWc8KaPmynHtZzoX4WdQbujwQ",
+	)
+	if err != nil {
+		t.Fatalf("DecodeWIF: unexpected error: %v", err)
+	}
+
+	compressedSig, err := SignMessage(compressed, msg)
+	if err != nil {
+		t.Fatalf("SignMessage: unexpected error: %v", err)
+	}
+	uncompressedSig, err := SignMessage(uncompressed, msg)
+	if err != nil {
+		t.Fatalf("SignMessage: unexpected error: %v", err)
+	}
+
+	p2pkh, p2wpkh, p2shP2wpkh := messageAddresses(t, compressed)
+	uncompressedP2PKH, _, _ := messageAddresses(t, uncompressed)
+
+	tests := []struct {
+		name  string
+		addr  Address
+		sig   string
+		msg   string
+		valid bool
+	}{
+		{"compressed p2pkh", p2pkh, compressedSig, msg, true},
+		{"uncompressed p2pkh", uncompressedP2PKH, uncompressedSig, msg, true},
+		{"p2wpkh with p2pkh header", p2wpkh, compressedSig, msg, true},
+		{"p2wpkh with p2wpkh header", p2wpkh, withHeader(compressedSig, 8), msg, true},
+		{"p2sh-p2wpkh with p2pkh header", p2shP2wpkh, compressedSig, msg, true},
+		{"p2sh-p2wpkh with p2sh-p2wpkh header", p2shP2wpkh, withHeader(compressedSig, 4), msg, true},
+		{"wrong message", p2pkh, compressedSig, msg + "!", false},
+		{"wrong key form", p2pkh, uncompressedSig, msg, false},
+		{"wrong recovery id", p2pkh, withHeader(compressedSig, 1), msg, false},
+		{"other key", uncompressedP2PKH, compressedSig, msg, false},
+	}
+
+	for _, test := range tests {
+		valid, err := VerifyMessage(
+			test.addr, test.sig, test.msg, &chaincfg.MainNetParams,
+		)
+		if err != nil {
+			t.Errorf("%s: unexpected error: %v", test.name, err)
+			continue
+		}
+		if valid != test.valid {
+			t.Errorf("%s: got valid %v, want %v", test.name, valid,
+				test.valid)
+		}
+	}
+}
+
+// TestVerifyMessageErrors ensures malformed signatures, header bytes of other
+// address types, unsupported addresses and addresses of other networks are rejected with the expected errors.
+func TestVerifyMessageErrors(t *testing.T) {
+	const msg = "Hello, Monacoin!"
+
+	wif, err := DecodeWIF(
+		"T4ff2X9NeYy292nC39BjDDGaMBoXg3JzPXXcuYce8KgVmZ9RD9U1",
+	)
+	if err != nil {
+		t.Fatalf("DecodeWIF: unexpected error: %v", err)
+	}
+	sig, err := SignMessage(wif, msg)
+	if err != nil {
+		t.Fatalf("SignMessage: unexpected error: %v", err)
+	}
+	p2pkh, p2wpkh, p2shP2wpkh := messageAddresses(t, wif)
+
+	p2wsh, err := NewAddressWitnessScriptHash(
+		make([]byte, 32), &chaincfg.MainNetParams,
+	)
+	if err != nil {
+		t.Fatalf("NewAddressWitnessScriptHash: unexpected error: %v", err)
+	}
+	testnetP2PKH, err := NewAddressPubKeyHash(
+		p2pkh.ScriptAddress(), &chaincfg.TestNet4Params,
+	)
+	if err != nil {
+		t.Fatalf("NewAddressPubKeyHash: unexpected error: %v", err)
+	}
+
+	sigBytes, _ := base64.StdEncoding.DecodeString(sig)
+
+	tests := []struct {
+		name string
+		addr Address
+		sig  string
+		err  error
+	}{
+		{"invalid base64", p2pkh, "!" + sig[1:], ErrMalformedSignature},
+		{"short signature", p2pkh, base64.StdEncoding.EncodeToString(sigBytes[:64]), ErrMalformedSignature},
+		{"header too low", p2pkh, withHeader(sig, 256-6), ErrMalformedSignature},
+		{"header too high", p2pkh, withHeader(sig, 12), ErrMalformedSignature},
+		{"p2pkh with p2sh-p2wpkh header", p2pkh, withHeader(sig, 4), ErrMalformedSignature},
+		{"p2pkh with p2wpkh header", p2pkh, withHeader(sig, 8), ErrMalformedSignature},
+		{"p2wpkh with uncompressed header", p2wpkh, withHeader(sig, 256-4), ErrMalformedSignature},
+		{"p2wpkh with p2sh-p2wpkh header", p2wpkh, withHeader(sig, 4), ErrMalformedSignature},
+		{"p2sh-p2wpkh with uncompressed header", p2shP2wpkh, withHeader(sig, 256-4), ErrMalformedSignature},
+		{"p2sh-p2wpkh with p2wpkh header", p2shP2wpkh, withHeader(sig, 8), ErrMalformedSignature},
+		{"p2wsh address", p2wsh, sig, ErrUnsupportedAddress},
+		{"p2wsh address with malformed signature", p2wsh, "!" + sig[1:], ErrUnsupportedAddress},
+		{"wrong network", testnetP2PKH, sig, ErrWrongNet},
+	}
+
+	for _, test := range tests {
+		_, err := VerifyMessage(
+			test.addr, test.sig, msg, &chaincfg.MainNetParams,
+		)
+		if err != test.err {
+			t.Errorf("%s: got error %v, want %v", test.name, err,
+				test.err)
+		}
+	}
+}
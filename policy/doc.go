@@ -0,0 +1,32 @@
+// Copyright (c) 2026 The monasuite developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+/*
+Package policy checks transactions against the relay policy of Monacoin Core.
+
+Overview
+
+Nodes only relay and mine transactions which are standard, a set of rules
+stricter than the consensus rules.  Checking a transaction locally before
+broadcasting it tells why it would be rejected, rather than only that it was.
+
+A Policy holds the configurable parameters of the rules, such as the minimum
+relay fee which decides the outputs that are dust, and the size and number of
+OP_RETURN outputs carrying data.  Its Check method returns every Violation of
+the rules by a transaction, each identifying the Rule violated and the input or
+output violating it:
+
+	violations := policy.Check(tx, prevOuts)
+	for _, v := range violations {
+		if v.Rule == policy.RuleDust {
+			// Raise the value of output v.Index.
+		}
+	}
+
+The outputs spent by the transaction are optional.  Without them, the scripts
+they pay to and the signature operations of P2SH and witness inputs are not
+checked.  When they are passed, the inputs whose spent output is missing
+violate RuleMissingPrevOut.
+*/
+package policy
@@ -0,0 +1,36 @@
+// Copyright (c) 2026 The monasuite developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package policy_test
+
+import (
+	"fmt"
+
+	"github.com/monasuite/monad/chaincfg/chainhash"
+	"github.com/monasuite/monad/wire"
+	"github.com/monasuite/monautil"
+	"github.com/monasuite/monautil/policy"
+)
+
+// This example demonstrates finding the dust outputs of a transaction.
+func ExampleCheck() {
+	// Pay 100000 watanabe and 500 watanabe to two P2PKH outputs.
+	pkScript := []byte{
+		0x76, 0xa9, 0x14, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00,
+		0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00,
+		0x00, 0x00, 0x00, 0x88, 0xac,
+	}
+	msgTx := wire.NewMsgTx(2)
+	msgTx.AddTxIn(wire.NewTxIn(wire.NewOutPoint(&chainhash.Hash{}, 0),
+		nil, nil))
+	msgTx.AddTxOut(wire.NewTxOut(100000, pkScript))
+	msgTx.AddTxOut(wire.NewTxOut(500, pkScript))
+
+	for _, v := range policy.Check(monautil.NewTx(msgTx), nil) {
+		fmt.Printf("%v: %v\n", v.Rule, v)
+	}
+
+	// Output:
+	// dust output: output 1: payment of 0.000005 MONA is dust
+}
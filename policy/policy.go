@@ -0,0 +1,490 @@
+// Copyright (c) 2026 The monasuite developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package policy
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/monasuite/monad/txscript"
+	"github.com/monasuite/monad/wire"
+	"github.com/monasuite/monautil"
+	"github.com/monasuite/monautil/txsizes"
+)
+
+const (
+	// DefaultMinRelayFee is the minimum relay fee rate outputs are
+	// checked against for dust when Policy.MinRelayFee is zero.
+	DefaultMinRelayFee = monautil.FeeRatePerKVByte(1000)
+
+	// DefaultMaxDataCarrierSize is the largest size of the public key
+	// script of an output carrying data when Policy.MaxDataCarrierSize is
+	// zero: OP_RETURN followed by a push of 80 bytes.
+	DefaultMaxDataCarrierSize = 83
+
+	// DefaultMaxDataCarrierOutputs is the largest number of outputs
+	// carrying data when Policy.MaxDataCarrierOutputs is zero.
+	DefaultMaxDataCarrierOutputs = 1
+
+	// MaxStandardTxVersion is the highest standard transaction version.
+	MaxStandardTxVersion = 2
+
+	// MaxStandardTxWeight is the largest weight of a standard
+	// transaction.
+	MaxStandardTxWeight = 400000
+
+	// MaxStandardSigScriptSize is the largest size of a standard
+	// signature script, which allows spending a 15-of-15 multisig P2SH
+	// output with compressed keys.
+	MaxStandardSigScriptSize = 1650
+
+	// MaxStandardMultiSigKeys is the largest number of public keys of a
+	// standard bare multisig output script.
+	MaxStandardMultiSigKeys = 3
+
+	// MaxStandardP2SHSigOps is the largest number of signature operations
+	// of the redeem script of a standard P2SH input.
+	MaxStandardP2SHSigOps = 15
+
+	// MaxStandardTxSigOpsCost is the largest signature operation cost of
+	// a standard transaction, a fifth of the limit of a block.
+	MaxStandardTxSigOpsCost = 80000 / 5
+)
+
+var (
+	// ErrPrevOutsMismatch describes an error in which the number of
+	// previous outputs doesn't match the number of inputs of a
+	// transaction.
+	ErrPrevOutsMismatch = errors.New("number of previous outputs doesn't " +
+		"match the inputs")
+
+	// ErrMissingPrevOut describes an error in which the previous output
+	// of an input is nil.
+	ErrMissingPrevOut = errors.New("missing previous output")
+)
+
+// Rule identifies a relay policy rule a transaction violates.
+type Rule uint8
+
+// These constants define the relay policy rules.
+const (
+	// RuleVersion is violated by transaction versions below one or above
+	// MaxStandardTxVersion.
+	RuleVersion Rule = iota
+
+	// RuleWeight is violated by transactions heavier than
+	// MaxStandardTxWeight.
+	RuleWeight
+
+	// RuleSigOpsCost is violated by transactions whose signature
+	// operation cost exceeds MaxStandardTxSigOpsCost.
+	RuleSigOpsCost
+
+	// RuleSigScriptSize is violated by inputs whose signature script is
+	// larger than MaxStandardSigScriptSize.
+	RuleSigScriptSize
+
+	// RuleSigScriptPushOnly is violated by inputs whose signature script
+	// contains opcodes other than data pushes.
+	RuleSigScriptPushOnly
+
+	// RuleNonStandardInput is violated by inputs spending outputs with a
+	// public key script of no standard form.
+	RuleNonStandardInput
+
+	// RuleP2SHSigOps is violated by P2SH inputs whose redeem script has
+	// more than MaxStandardP2SHSigOps signature operations.
+	RuleP2SHSigOps
+
+	// RuleNonStandardOutput is violated by outputs with a public key
+	// script of no standard form, including bare multisig scripts with
+	// more than MaxStandardMultiSigKeys keys.
+	RuleNonStandardOutput
+
+	// RuleDust is violated by outputs whose value is below the dust
+	// threshold of their script.
+	RuleDust
+
+	// RuleDataCarrierSize is violated by outputs carrying data whose
+	// public key script is larger than the data carrier size limit.
+	RuleDataCarrierSize
+
+	// RuleDataCarrierCount is violated by transactions with more outputs
+	// carrying data than allowed.
+	RuleDataCarrierCount
+
+	// RuleMissingPrevOut is violated when the previous outputs passed
+	// don't match the inputs: by the whole transaction when their number
+	// differs from the number of inputs, and by every input whose
+	// previous output is nil otherwise.  The scripts of the previous
+	// outputs missing are not checked.
+	RuleMissingPrevOut
+)
+
+// ruleStrings houses the human-readable strings which describe each rule.
+var ruleStrings = map[Rule]string{
+	RuleVersion:           "nonstandard version",
+	RuleWeight:            "transaction too heavy",
+	RuleSigOpsCost:        "too many signature operations",
+	RuleSigScriptSize:     "signature script too large",
+	RuleSigScriptPushOnly: "signature script not push only",
+	RuleNonStandardInput:  "nonstandard input",
+	RuleP2SHSigOps:        "too many p2sh signature operations",
+	RuleNonStandardOutput: "nonstandard output script",
+	RuleDust:              "dust output",
+	RuleDataCarrierSize:   "data carrier too large",
+	RuleDataCarrierCount:  "too many data carriers",
+	RuleMissingPrevOut:    "missing previous output",
+}
+
+// String returns the Rule as a human-readable name.
+func (r Rule) String() string {
+	if s := ruleStrings[r]; s != "" {
+		return s
+	}
+	return fmt.Sprintf("Unknown Rule (%d)", uint8(r))
+}
+
+// Violation is a violation of a relay policy rule by a transaction.  It
+// implements the error interface.
+type Violation struct {
+	// Rule is the rule violated.
+	Rule Rule
+
+	// Index is the index of the input or output violating the rule, or -1
+	// for rules about the whole transaction.
+	Index int
+
+	// Description describes the violation.
+	Description string
+}
+
+// Error satisfies the error interface and prints human-readable errors.
+func (v *Violation) Error() string {
+	return v.Description
+}
+
+// newViolation returns a violation of the rule by the input or output at the
+// passed index, which is -1 for the whole transaction.
+func newViolation(rule Rule, index int, format string,
+	args ...interface{}) *Violation {
+
+	return &Violation{
+		Rule:        rule,
+		Index:       index,
+		Description: fmt.Sprintf(format, args...),
+	}
+}
+
+// Policy holds the configurable parameters of the relay policy.  The zero
+// value is the default policy of the reference implementation.
+type Policy struct {
+	// MinRelayFee is the minimum relay fee rate, which determines the
+	// outputs that are dust.  DefaultMinRelayFee is used when it is
+	// zero.
+	MinRelayFee monautil.FeeRatePerKVByte
+
+	// MaxDataCarrierSize is the largest size of the public key script of
+	// an output carrying data.  DefaultMaxDataCarrierSize is used when it
+	// is zero.
+	MaxDataCarrierSize int
+
+	// MaxDataCarrierOutputs is the largest number of outputs carrying
+	// data.  DefaultMaxDataCarrierOutputs is used when it is zero.
+	MaxDataCarrierOutputs int
+}
+
+// minRelayFee returns the minimum relay fee rate.
+func (p *Policy) minRelayFee() monautil.FeeRatePerKVByte {
+	if p.MinRelayFee == 0 {
+		return DefaultMinRelayFee
+	}
+	return p.MinRelayFee
+}
+
+// Check returns the violations of the default relay policy by the
+// transaction.  See Policy.Check.
+func Check(tx *monautil.Tx, prevOuts []*wire.TxOut) []*Violation {
+	var p Policy
+	return p.Check(tx, prevOuts)
+}
+
+// Check returns the violations of the relay policy by the transaction, or nil
+// if it is standard.  The prevOuts are the outputs spent by the inputs of the
+// transaction, in the same order.  They may be nil, in which case the scripts
+// they pay to are not checked, and the signature operations of P2SH and
+// witness inputs are not counted.  Otherwise a previous output missing is
+// reported as a violation of RuleMissingPrevOut.
+//
+// Checks depending on the state of the chain, such as whether the transaction
+// is final or pays enough fees, are left to the caller.
+func (p *Policy) Check(tx *monautil.Tx, prevOuts []*wire.TxOut) []*Violation {
+	var violations []*Violation
+	msgTx := tx.MsgTx()
+	if prevOuts != nil && len(prevOuts) != len(msgTx.TxIn) {
+		violations = append(violations, newViolation(
+			RuleMissingPrevOut, -1, "%d previous outputs for %d "+
+				"inputs", len(prevOuts), len(msgTx.TxIn)))
+		prevOuts = nil
+	}
+
+	if msgTx.Version < 1 || msgTx.Version > MaxStandardTxVersion {
+		violations = append(violations, newViolation(RuleVersion, -1,
+			"transaction version %d is not in the valid range of "+
+				"1-%d", msgTx.Version, MaxStandardTxVersion))
+	}
+	if weight := tx.Weight(); weight > MaxStandardTxWeight {
+		violations = append(violations, newViolation(RuleWeight, -1,
+			"weight of transaction %d is larger than max allowed "+
+				"weight of %d", weight, MaxStandardTxWeight))
+	}
+	if cost := sigOpsCost(msgTx, prevOuts); cost > MaxStandardTxSigOpsCost {
+		violations = append(violations, newViolation(RuleSigOpsCost, -1,
+			"signature operation cost of %d is larger than max "+
+				"allowed cost of %d", cost,
+			MaxStandardTxSigOpsCost))
+	}
+
+	for i, txIn := range msgTx.TxIn {
+		violations = append(violations, p.checkInput(i, txIn,
+			prevOuts)...)
+	}
+
+	maxDataCarrierOutputs := p.MaxDataCarrierOutputs
+	if maxDataCarrierOutputs == 0 {
+		maxDataCarrierOutputs = DefaultMaxDataCarrierOutputs
+	}
+	numDataCarriers := 0
+	for i, txOut := range msgTx.TxOut {
+		if IsDataCarrier(txOut.PkScript) {
+			numDataCarriers++
+		}
+		if v := p.checkOutput(i, txOut); v != nil {
+			violations = append(violations, v)
+		}
+	}
+	if numDataCarriers > maxDataCarrierOutputs {
+		violations = append(violations, newViolation(
+			RuleDataCarrierCount, -1, "%d outputs carry data, "+
+				"more than the allowed %d", numDataCarriers,
+			maxDataCarrierOutputs))
+	}
+
+	return violations
+}
+
+// checkInput returns the violations of the relay policy by the input at the
+// passed index.
+func (p *Policy) checkInput(i int, txIn *wire.TxIn,
+	prevOuts []*wire.TxOut) []*Violation {
+
+	var violations []*Violation
+	sigScript := txIn.SignatureScript
+	if len(sigScript) > MaxStandardSigScriptSize {
+		violations = append(violations, newViolation(RuleSigScriptSize,
+			i, "input %d: signature script size of %d bytes is "+
+				"larger than max allowed size of %d bytes", i,
+			len(sigScript), MaxStandardSigScriptSize))
+	}
+	if !txscript.IsPushOnlyScript(sigScript) {
+		violations = append(violations, newViolation(
+			RuleSigScriptPushOnly, i, "input %d: signature script "+
+				"is not push only", i))
+	}
+	if prevOuts == nil {
+		return violations
+	}
+	if prevOuts[i] == nil {
+		return append(violations, newViolation(RuleMissingPrevOut, i,
+			"input %d: previous output is missing", i))
+	}
+
+	pkScript := prevOuts[i].PkScript
+	switch txscript.GetScriptClass(pkScript) {
+	case txscript.ScriptHashTy:
+		numSigOps := txscript.GetPreciseSigOpCount(sigScript, pkScript,
+			true)
+		if numSigOps > MaxStandardP2SHSigOps {
+			violations = append(violations, newViolation(
+				RuleP2SHSigOps, i, "input %d: %d signature "+
+					"operations are more than the allowed "+
+					"max of %d", i, numSigOps,
+				MaxStandardP2SHSigOps))
+		}
+
+	case txscript.NonStandardTy:
+		if !isWitnessV1Plus(pkScript) {
+			violations = append(violations, newViolation(
+				RuleNonStandardInput, i, "input %d: spends a "+
+					"non-standard script form", i))
+		}
+	}
+
+	return violations
+}
+
+// checkOutput returns the violation of the relay policy by the output at the
+// passed index, if any.
+func (p *Policy) checkOutput(i int, txOut *wire.TxOut) *Violation {
+	pkScript := txOut.PkScript
+	if IsDataCarrier(pkScript) {
+		maxSize := p.MaxDataCarrierSize
+		if maxSize == 0 {
+			maxSize = DefaultMaxDataCarrierSize
+		}
+		if len(pkScript) > maxSize {
+			return newViolation(RuleDataCarrierSize, i, "output "+
+				"%d: data carrier script of %d bytes is larger "+
+				"than max allowed size of %d bytes", i,
+				len(pkScript), maxSize)
+		}
+		return nil
+	}
+
+	switch txscript.GetScriptClass(pkScript) {
+	case txscript.MultiSigTy:
+		numPubKeys, numSigs, err := txscript.CalcMultiSigStats(pkScript)
+		switch {
+		case err != nil:
+			return newViolation(RuleNonStandardOutput, i, "output %d: "+
+				"multi-signature script parse failure: %v", i, err)
+
+		case numPubKeys < 1 || numPubKeys > MaxStandardMultiSigKeys:
+			return newViolation(RuleNonStandardOutput, i, "output %d: "+
+				"multi-signature script with %d public keys, "+
+				"not in the allowed range of 1-%d", i, numPubKeys,
+				MaxStandardMultiSigKeys)
+
+		case numSigs < 1 || numSigs > numPubKeys:
+			return newViolation(RuleNonStandardOutput, i, "output %d: "+
+				"multi-signature script requiring %d of %d "+
+				"signatures", i, numSigs, numPubKeys)
+		}
+
+	case txscript.NonStandardTy:
+		if !isWitnessV1Plus(pkScript) {
+			return newViolation(RuleNonStandardOutput, i, "output %d: "+
+				"non-standard script form", i)
+		}
+	}
+
+	if IsDust(txOut, p.minRelayFee()) {
+		return newViolation(RuleDust, i, "output %d: payment of %v is "+
+			"dust", i, monautil.Amount(txOut.Value))
+	}
+
+	return nil
+}
+
+// IsDataCarrier returns whether the public key script is an output carrying
+// data: OP_RETURN followed by data pushes only.  Such outputs are provably
+// unspendable, so they are never dust.
+func IsDataCarrier(pkScript []byte) bool {
+	return len(pkScript) > 0 && pkScript[0] == txscript.OP_RETURN &&
+		txscript.IsPushOnlyScript(pkScript[1:])
+}
+
+// isWitnessV1Plus returns whether the script is a witness program of a version
+// above zero, which are standard to pay to and spend from although their
+// rules aren't known yet.  Version zero programs of other sizes than P2WPKH
+// and P2WSH programs are invalid.
+func isWitnessV1Plus(pkScript []byte) bool {
+	if !txscript.IsWitnessProgram(pkScript) {
+		return false
+	}
+	version, _, err := txscript.ExtractWitnessProgramInfo(pkScript)
+	return err == nil && version != 0
+}
+
+// DustThreshold returns the smallest value of an output paying to the passed
+// public key script which isn't dust at the minimum relay fee rate.  An output
+// is dust when spending it costs more than a third of its value at the minimum
+// relay fee, using the size of a typical input.  The threshold of outputs
+// carrying data is zero.
+func DustThreshold(pkScript []byte,
+	minRelayFee monautil.FeeRatePerKVByte) monautil.Amount {
+
+	if IsDataCarrier(pkScript) {
+		return 0
+	}
+
+	// The outpoint, script length and sequence number of the input take
+	// 41 bytes, and a typical signature script 107 bytes, which are
+	// discounted when spent as a witness.
+	size := int64(txsizes.OutputSize(len(pkScript))) + 41
+	if txscript.IsWitnessProgram(pkScript) {
+		size += 107 / monautil.WitnessScaleFactor
+	} else {
+		size += 107
+	}
+
+	// The fee of spending the output is truncated to a whole watanabe
+	// before being tripled, as the reference implementation does.
+	return monautil.Amount(3 * (int64(minRelayFee) * size / 1000))
+}
+
+// IsDust returns whether the output is dust at the minimum relay fee rate.
+// See DustThreshold.
+func IsDust(txOut *wire.TxOut, minRelayFee monautil.FeeRatePerKVByte) bool {
+	return monautil.Amount(txOut.Value) < DustThreshold(txOut.PkScript,
+		minRelayFee)
+}
+
+// SigOpsCost returns the signature operation cost of the transaction.
+// Signature operations outside of witnesses cost four times as much as those
+// in witnesses.  The prevOuts are the outputs spent by the inputs of the
+// transaction, in the same order.  When they are nil, the signature
+// operations of P2SH redeem scripts and witnesses are not counted.
+// ErrPrevOutsMismatch is returned if there are previous outputs but not one
+// for every input, and ErrMissingPrevOut if one of them is nil.
+func SigOpsCost(tx *monautil.Tx, prevOuts []*wire.TxOut) (int, error) {
+	msgTx := tx.MsgTx()
+	if prevOuts != nil {
+		if len(prevOuts) != len(msgTx.TxIn) {
+			return 0, ErrPrevOutsMismatch
+		}
+		for _, prevOut := range prevOuts {
+			if prevOut == nil {
+				return 0, ErrMissingPrevOut
+			}
+		}
+	}
+
+	return sigOpsCost(msgTx, prevOuts), nil
+}
+
+// sigOpsCost returns the signature operation cost of the transaction.  The
+// signature operations of the P2SH redeem scripts and witnesses of the inputs
+// whose previous output is nil are not counted.
+func sigOpsCost(msgTx *wire.MsgTx, prevOuts []*wire.TxOut) int {
+	numSigOps := 0
+	for _, txIn := range msgTx.TxIn {
+		numSigOps += txscript.GetSigOpCount(txIn.SignatureScript)
+	}
+	for _, txOut := range msgTx.TxOut {
+		numSigOps += txscript.GetSigOpCount(txOut.PkScript)
+	}
+	cost := numSigOps * monautil.WitnessScaleFactor
+	if prevOuts == nil {
+		return cost
+	}
+
+	for i, txIn := range msgTx.TxIn {
+		if prevOuts[i] == nil {
+			continue
+		}
+		pkScript := prevOuts[i].PkScript
+		if txscript.IsPayToScriptHash(pkScript) {
+			cost += txscript.GetPreciseSigOpCount(
+				txIn.SignatureScript, pkScript, true,
+			) * monautil.WitnessScaleFactor
+		}
+		cost += txscript.GetWitnessSigOpCount(
+			txIn.SignatureScript, pkScript, txIn.Witness,
+		)
+	}
+
+	return cost
+}
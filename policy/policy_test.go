@@ -0,0 +1,396 @@
+// Copyright (c) 2026 The monasuite developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package policy_test
+
+import (
+	"bytes"
+	"encoding/hex"
+	"testing"
+
+	"github.com/monasuite/monad/chaincfg/chainhash"
+	"github.com/monasuite/monad/txscript"
+	"github.com/monasuite/monad/wire"
+	"github.com/monasuite/monautil"
+	"github.com/monasuite/monautil/policy"
+)
+
+// hexToBytes converts the passed hex string into bytes and will panic if
+// there is an error.  This is only provided for the hard-coded constants so
+// errors in the source code can be detected.  It will only (and must only) be
+// called with hard-coded values.
+func hexToBytes(s string) []byte {
+	b, err := hex.DecodeString(s)
+	if err != nil {
+		panic("invalid hex in source file: " + s)
+	}
+	return b
+}
+
+var (
+	p2pkhScript = hexToBytes("76a914" +
+		"0000000000000000000000000000000000000000" + "88ac")
+	p2shScript = hexToBytes("a914" +
+		"0000000000000000000000000000000000000000" + "87")
+	p2wpkhScript = hexToBytes("0014" +
+		"0000000000000000000000000000000000000000")
+	p2wshScript = hexToBytes("0020" +
+		"0000000000000000000000000000000000000000000000000000000000000000")
+	p2trScript = hexToBytes("5120" +
+		"0000000000000000000000000000000000000000000000000000000000000000")
+
+	// badV0Script is a version zero witness program of an invalid size.
+	badV0Script = hexToBytes("0018" +
+		"000000000000000000000000000000000000000000000000")
+
+	pubKey = "21" +
+		"0279be667ef9dcbbac55a06295ce870b07029bfcdb2dce28d959f2815b16f81798"
+
+	// multiSig1of1Script and multiSig1of4Script are bare multisig scripts
+	// with one and four public keys.
+	multiSig1of1Script = hexToBytes("51" + pubKey + "51ae")
+	multiSig1of4Script = hexToBytes("51" + pubKey + pubKey + pubKey +
+		pubKey + "54ae")
+
+	// dataScript carries 80 bytes of data, the most allowed by default.
+	dataScript = append(hexToBytes("6a4c50"), make([]byte, 80)...)
+
+	// pushScript is a signature script pushing a signature and a public
+	// key.
+	pushScript = append(append([]byte{0x47}, make([]byte, 71)...),
+		append([]byte{0x21}, make([]byte, 33)...)...)
+)
+
+// newTx returns a transaction spending one input for every signature script,
+// paying 10000 watanabe to every output script.
+func newTx(sigScripts [][]byte, pkScripts ...[]byte) *monautil.Tx {
+	msgTx := wire.NewMsgTx(2)
+	for i, sigScript := range sigScripts {
+		prevOut := wire.NewOutPoint(&chainhash.Hash{1}, uint32(i))
+		msgTx.AddTxIn(wire.NewTxIn(prevOut, sigScript, nil))
+	}
+	for _, pkScript := range pkScripts {
+		msgTx.AddTxOut(wire.NewTxOut(10000, pkScript))
+	}
+	return monautil.NewTx(msgTx)
+}
+
+// withVersion sets the version of the transaction and returns it.
+func withVersion(tx *monautil.Tx, version int32) *monautil.Tx {
+	tx.MsgTx().Version = version
+	return tx
+}
+
+// p2shRedeemScript returns a signature script spending a P2SH output with a
+// redeem script of numSigOps OP_CHECKSIG opcodes, along with the P2SH output.
+func p2shRedeemScript(numSigOps int) ([]byte, *wire.TxOut) {
+	redeemScript := bytes.Repeat([]byte{txscript.OP_CHECKSIG}, numSigOps)
+	sigScript, err := txscript.NewScriptBuilder().
+		AddData(redeemScript).Script()
+	if err != nil {
+		panic(err)
+	}
+	pkScript, err := txscript.NewScriptBuilder().
+		AddOp(txscript.OP_HASH160).
+		AddData(monautil.Hash160(redeemScript)).
+		AddOp(txscript.OP_EQUAL).Script()
+	if err != nil {
+		panic(err)
+	}
+	return sigScript, wire.NewTxOut(100000, pkScript)
+}
+
+type violation struct {
+	rule  policy.Rule
+	index int
+}
+
+// TestCheck ensures the violations of the relay policy by transactions are
+// reported.
+func TestCheck(t *testing.T) {
+	p2shSigScript, p2shPrevOut := p2shRedeemScript(15)
+	p2shSigScript16, p2shPrevOut16 := p2shRedeemScript(16)
+
+	manyMultiSigs := make([][]byte, 201)
+	for i := range manyMultiSigs {
+		manyMultiSigs[i] = multiSig1of1Script
+	}
+
+	tests := []struct {
+		name     string
+		tx       *monautil.Tx
+		prevOuts []*wire.TxOut
+		policy   policy.Policy
+		want     []violation
+	}{
+		{
+			name: "standard outputs",
+			tx: newTx([][]byte{pushScript}, p2pkhScript, p2shScript,
+				p2wpkhScript, p2wshScript, p2trScript,
+				multiSig1of1Script, dataScript),
+		},
+		{
+			name: "version zero",
+			tx: withVersion(newTx([][]byte{pushScript},
+				p2pkhScript), 0),
+			want: []violation{{policy.RuleVersion, -1}},
+		},
+		{
+			name: "version three",
+			tx: withVersion(newTx([][]byte{pushScript},
+				p2pkhScript), 3),
+			want: []violation{{policy.RuleVersion, -1}},
+		},
+		{
+			name: "too heavy",
+			tx: newTx([][]byte{pushScript},
+				make([]byte, policy.MaxStandardTxWeight/4)),
+			want: []violation{
+				{policy.RuleWeight, -1},
+				{policy.RuleNonStandardOutput, 0},
+			},
+		},
+		{
+			name: "sigscript too large",
+			tx: newTx([][]byte{append(hexToBytes("4d7006"),
+				make([]byte, 1648)...)}, p2pkhScript),
+			want: []violation{{policy.RuleSigScriptSize, 0}},
+		},
+		{
+			name: "sigscript not push only",
+			tx: newTx([][]byte{pushScript, {txscript.OP_CHECKSIG}},
+				p2pkhScript),
+			want: []violation{{policy.RuleSigScriptPushOnly, 1}},
+		},
+		{
+			name: "nonstandard outputs",
+			tx: newTx([][]byte{pushScript}, p2pkhScript,
+				[]byte{txscript.OP_TRUE}, badV0Script,
+				multiSig1of4Script),
+			want: []violation{
+				{policy.RuleNonStandardOutput, 1},
+				{policy.RuleNonStandardOutput, 2},
+				{policy.RuleNonStandardOutput, 3},
+			},
+		},
+		{
+			name: "dust",
+			tx: newTx([][]byte{pushScript}, p2pkhScript,
+				p2wpkhScript),
+			policy: policy.Policy{MinRelayFee: 40000},
+			want: []violation{
+				{policy.RuleDust, 0},
+				{policy.RuleDust, 1},
+			},
+		},
+		{
+			name: "data carrier too large",
+			tx: newTx([][]byte{pushScript}, p2pkhScript,
+				append(hexToBytes("6a4c51"), make([]byte, 81)...)),
+			want: []violation{{policy.RuleDataCarrierSize, 1}},
+		},
+		{
+			name: "data carrier size allowed",
+			tx: newTx([][]byte{pushScript}, p2pkhScript,
+				append(hexToBytes("6a4c51"), make([]byte, 81)...)),
+			policy: policy.Policy{MaxDataCarrierSize: 84},
+		},
+		{
+			name: "too many data carriers",
+			tx: newTx([][]byte{pushScript}, dataScript,
+				dataScript),
+			want: []violation{{policy.RuleDataCarrierCount, -1}},
+		},
+		{
+			name: "data carrier count allowed",
+			tx: newTx([][]byte{pushScript}, dataScript,
+				dataScript),
+			policy: policy.Policy{MaxDataCarrierOutputs: 2},
+		},
+		{
+			name: "too many sigops",
+			tx:   newTx([][]byte{pushScript}, manyMultiSigs...),
+			want: []violation{{policy.RuleSigOpsCost, -1}},
+		},
+		{
+			name: "standard inputs",
+			tx: newTx([][]byte{pushScript, p2shSigScript, nil},
+				p2pkhScript),
+			prevOuts: []*wire.TxOut{
+				wire.NewTxOut(100000, p2pkhScript),
+				p2shPrevOut,
+				wire.NewTxOut(100000, p2trScript),
+			},
+		},
+		{
+			name: "too many p2sh sigops",
+			tx: newTx([][]byte{pushScript, p2shSigScript16},
+				p2pkhScript),
+			prevOuts: []*wire.TxOut{
+				wire.NewTxOut(100000, p2pkhScript),
+				p2shPrevOut16,
+			},
+			want: []violation{{policy.RuleP2SHSigOps, 1}},
+		},
+		{
+			name: "p2sh sigops unknown without prevouts",
+			tx: newTx([][]byte{pushScript, p2shSigScript16},
+				p2pkhScript),
+		},
+		{
+			name: "nonstandard input",
+			tx:   newTx([][]byte{nil, nil}, p2pkhScript),
+			prevOuts: []*wire.TxOut{
+				wire.NewTxOut(100000, p2wpkhScript),
+				wire.NewTxOut(100000, []byte{txscript.OP_TRUE}),
+			},
+			want: []violation{{policy.RuleNonStandardInput, 1}},
+		},
+		{
+			name: "prevouts mismatch",
+			tx: newTx([][]byte{pushScript, p2shSigScript16},
+				p2pkhScript),
+			prevOuts: []*wire.TxOut{p2shPrevOut16},
+			want:     []violation{{policy.RuleMissingPrevOut, -1}},
+		},
+		{
+			name: "missing prevout",
+			tx: newTx([][]byte{p2shSigScript16, pushScript},
+				p2pkhScript),
+			prevOuts: []*wire.TxOut{p2shPrevOut16, nil},
+			want: []violation{
+				{policy.RuleP2SHSigOps, 0},
+				{policy.RuleMissingPrevOut, 1},
+			},
+		},
+	}
+
+	for _, test := range tests {
+		violations := test.policy.Check(test.tx, test.prevOuts)
+		if len(violations) != len(test.want) {
+			t.Errorf("%s: got %d violations %v, want %d", test.name,
+				len(violations), violations, len(test.want))
+			continue
+		}
+		for i, v := range violations {
+			want := test.want[i]
+			if v.Rule != want.rule || v.Index != want.index {
+				t.Errorf("%s: violation %d is %v at %d, want %v "+
+					"at %d", test.name, i, v.Rule, v.Index,
+					want.rule, want.index)
+			}
+			if v.Error() == "" {
+				t.Errorf("%s: violation %d has no description",
+					test.name, i)
+			}
+		}
+	}
+}
+
+// TestDustThreshold ensures the dust thresholds of the standard scripts match
+// the reference implementation.
+func TestDustThreshold(t *testing.T) {
+	tests := []struct {
+		name        string
+		pkScript    []byte
+		minRelayFee monautil.FeeRatePerKVByte
+		want        monautil.Amount
+	}{
+		{"p2pkh", p2pkhScript, 1000, 546},
+		{"p2sh", p2shScript, 1000, 540},
+		{"p2wpkh", p2wpkhScript, 1000, 294},
+		{"p2wsh", p2wshScript, 1000, 330},
+		{"p2tr", p2trScript, 1000, 330},
+		{"data carrier", dataScript, 1000, 0},
+		{"p2pkh higher fee", p2pkhScript, 3000, 1638},
+		{"p2wpkh truncated", p2wpkhScript, 1001, 294},
+		{"p2pkh truncated", p2pkhScript, 1999, 1089},
+	}
+
+	for _, test := range tests {
+		got := policy.DustThreshold(test.pkScript, test.minRelayFee)
+		if got != test.want {
+			t.Errorf("%s: got threshold %d, want %d", test.name,
+				got, test.want)
+		}
+
+		txOut := wire.NewTxOut(int64(test.want), test.pkScript)
+		if policy.IsDust(txOut, test.minRelayFee) {
+			t.Errorf("%s: output of %d is dust", test.name,
+				test.want)
+		}
+		if test.want == 0 {
+			continue
+		}
+		txOut.Value--
+		if !policy.IsDust(txOut, test.minRelayFee) {
+			t.Errorf("%s: output of %d isn't dust", test.name,
+				txOut.Value)
+		}
+	}
+}
+
+// TestSigOpsCost ensures the signature operation cost of transactions is
+// counted, including P2SH and witness inputs when the spent outputs are
+// known.
+func TestSigOpsCost(t *testing.T) {
+	p2shSigScript, p2shPrevOut := p2shRedeemScript(3)
+	tx := newTx([][]byte{p2shSigScript, nil}, multiSig1of1Script)
+	tx.MsgTx().TxIn[1].Witness = wire.TxWitness{make([]byte, 71),
+		make([]byte, 33)}
+	prevOuts := []*wire.TxOut{
+		p2shPrevOut,
+		wire.NewTxOut(100000, p2wpkhScript),
+	}
+
+	tests := []struct {
+		name     string
+		prevOuts []*wire.TxOut
+		want     int
+		wantErr  error
+	}{
+		// The bare multisig output counts 20 signature operations.
+		{"without prevouts", nil, 80, nil},
+		// The redeem script adds 3 and the P2WPKH input 1 unscaled.
+		{"with prevouts", prevOuts, 80 + 12 + 1, nil},
+		{"prevouts mismatch", prevOuts[:1], 0,
+			policy.ErrPrevOutsMismatch},
+		{"missing prevout", []*wire.TxOut{p2shPrevOut, nil}, 0,
+			policy.ErrMissingPrevOut},
+	}
+
+	for _, test := range tests {
+		got, err := policy.SigOpsCost(tx, test.prevOuts)
+		if err != test.wantErr {
+			t.Errorf("%s: got error %v, want %v", test.name, err,
+				test.wantErr)
+			continue
+		}
+		if got != test.want {
+			t.Errorf("%s: got cost %d, want %d", test.name, got,
+				test.want)
+		}
+	}
+}
+
+// TestRuleStringer tests the stringized output for the Rule type.
+func TestRuleStringer(t *testing.T) {
+	tests := []struct {
+		in   policy.Rule
+		want string
+	}{
+		{policy.RuleVersion, "nonstandard version"},
+		{policy.RuleDust, "dust output"},
+		{policy.RuleDataCarrierCount, "too many data carriers"},
+		{policy.RuleMissingPrevOut, "missing previous output"},
+		{0xff, "Unknown Rule (255)"},
+	}
+
+	for i, test := range tests {
+		if got := test.in.String(); got != test.want {
+			t.Errorf("String #%d: got %q, want %q", i, got,
+				test.want)
+		}
+	}
+}
@@ -0,0 +1,537 @@
+// Copyright (c) 2026 The monasuite developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package psbt
+
+// The analysis API summarizes a PSBT for a user about to approve it: the fee
+// it pays, the virtual size the transaction is expected to have once all
+// inputs are finalized, which outputs return funds to the user's own wallet,
+// and which inputs still lack UTXO information or signatures.
+
+import (
+	"bytes"
+	"crypto/sha256"
+
+	"github.com/monasuite/monad/txscript"
+	"github.com/monasuite/monad/wire"
+	"github.com/monasuite/monautil"
+)
+
+const (
+	// maxSigSize is the largest size of a DER encoded signature along with
+	// its sighash type byte.
+	maxSigSize = 73
+
+	// compressedPubKeySize is the size of a compressed public key.
+	compressedPubKeySize = 33
+
+	// inputBaseSize is the size of a transaction input without its
+	// signature script: the outpoint, followed by the sequence number.
+	inputBaseSize = 32 + 4 + 4
+
+	// p2shP2wpkhSigScriptSize is the size of the signature script of a
+	// P2SH-P2WPKH input, which pushes the 22 byte witness program.
+	p2shP2wpkhSigScriptSize = 1 + 22
+
+	// p2shP2wshSigScriptSize is the size of the signature script of a
+	// P2SH-P2WSH input, which pushes the 34 byte witness program.
+	p2shP2wshSigScriptSize = 1 + 34
+)
+
+// InputScriptType identifies the kind of script an input spends, as far as it
+// is needed to estimate the size of the finalized input.
+type InputScriptType uint8
+
+const (
+	// UnknownInput is an input whose script isn't supported by the size
+	// estimation, or whose UTXO is missing.
+	UnknownInput InputScriptType = iota
+
+	// P2PKHInput spends a pay-to-pubkey-hash output.
+	P2PKHInput
+
+	// P2SHP2WPKHInput spends a pay-to-witness-pubkey-hash output nested
+	// in pay-to-script-hash.
+	P2SHP2WPKHInput
+
+	// P2WPKHInput spends a native pay-to-witness-pubkey-hash output.
+	P2WPKHInput
+
+	// P2SHMultiSigInput spends a multisig script through
+	// pay-to-script-hash.
+	P2SHMultiSigInput
+
+	// P2SHP2WSHMultiSigInput spends a multisig witness script through
+	// pay-to-witness-script-hash nested in pay-to-script-hash.
+	P2SHP2WSHMultiSigInput
+
+	// P2WSHMultiSigInput spends a multisig witness script through native
+	// pay-to-witness-script-hash.
+	P2WSHMultiSigInput
+)
+
+// inputScriptTypeToName houses the human-readable strings which describe
+// each input script type.
+var inputScriptTypeToName = []string{
+	UnknownInput:           "unknown",
+	P2PKHInput:             "p2pkh",
+	P2SHP2WPKHInput:        "p2sh-p2wpkh",
+	P2WPKHInput:            "p2wpkh",
+	P2SHMultiSigInput:      "p2sh-multisig",
+	P2SHP2WSHMultiSigInput: "p2sh-p2wsh-multisig",
+	P2WSHMultiSigInput:     "p2wsh-multisig",
+}
+
+// String implements the Stringer interface by returning the name of the input
+// script type.
+func (t InputScriptType) String() string {
+	if int(t) >= len(inputScriptTypeToName) {
+		return "Invalid"
+	}
+	return inputScriptTypeToName[t]
+}
+
+// InputAnalysis describes a single input of an analyzed packet.
+type InputAnalysis struct {
+	// Value is the value of the output spent by the input.  It is zero if
+	// the UTXO is missing.
+	Value monautil.Amount
+
+	// ScriptType is the kind of script the input spends.
+	ScriptType InputScriptType
+
+	// MissingUtxo is true if neither the witness nor the non-witness UTXO
+	// of the input is known.
+	MissingUtxo bool
+
+	// Finalized is true if the input has been finalized.
+	Finalized bool
+
+	// SignaturesRequired is the number of signatures the input needs to
+	// be finalized.  It is zero for finalized and unknown inputs.
+	SignaturesRequired int
+
+	// Signatures is the number of partial signatures the input carries.
+	Signatures int
+}
+
+// OutputAnalysis describes a single output of an analyzed packet.
+type OutputAnalysis struct {
+	// Value is the value of the output.
+	Value monautil.Amount
+
+	// IsChange is true if every key that can spend the output is derived
+	// from one of the extended public keys passed to Analyze.
+	IsChange bool
+}
+
+// Analysis summarizes the fee, size and change of a packet, along with the
+// inputs that keep it from being finalized.
+type Analysis struct {
+	// Fee is the difference between the values of the inputs and outputs.
+	// It is only known if MissingUtxos is empty, and zero otherwise.
+	Fee monautil.Amount
+
+	// FeeRate is the fee rate paid for EstimatedVSize.  It is zero unless
+	// both the fee and the size are known.
+	FeeRate monautil.FeeRatePerKVByte
+
+	// EstimatedWeight and EstimatedVSize are the weight and virtual size
+	// of the transaction once every input is finalized, assuming
+	// signatures of the largest possible size.  They are only known if
+	// UnknownInputs is empty, and zero otherwise.
+	EstimatedWeight int64
+	EstimatedVSize  int64
+
+	// Inputs and Outputs describe the individual inputs and outputs.
+	Inputs  []InputAnalysis
+	Outputs []OutputAnalysis
+
+	// MissingUtxos holds the indexes of the inputs without UTXO data.
+	MissingUtxos []int
+
+	// MissingSignatures holds the indexes of the inputs that are not
+	// finalized and lack some of the signatures they require, including
+	// the ones whose requirements can't be determined.
+	MissingSignatures []int
+
+	// UnknownInputs holds the indexes of the inputs whose size can't be
+	// estimated, because the script they spend isn't supported or their
+	// UTXO is missing.
+	UnknownInputs []int
+}
+
+// Analyze computes the fee and estimated size of the passed packet, and marks
+// the outputs that pay to keys derived from any of the passed extended public
+// keys as change.  An output is only considered change if its script pays to
+// a single key or a multisig script, and every key it pays to has a BIP32
+// derivation descending from one of the passed keys.  Derivations attached to
+// a foreign output, or to a multisig script another party can spend alone,
+// are therefore not trusted.  The XPubs of the packet itself can be passed to
+// detect change of the wallets that created it.
+func Analyze(p *Packet, changeKeys []*XPub) (*Analysis, error) {
+	if err := VerifyInputOutputLen(p, false, false); err != nil {
+		return nil, err
+	}
+
+	a := &Analysis{
+		Inputs:  make([]InputAnalysis, len(p.Inputs)),
+		Outputs: make([]OutputAnalysis, len(p.Outputs)),
+	}
+
+	// The size of the transaction without witness data starts out with
+	// the version, the locktime and the input and output counts.
+	baseSize := 4 + 4 + wire.VarIntSerializeSize(uint64(len(p.Inputs))) +
+		wire.VarIntSerializeSize(uint64(len(p.Outputs)))
+
+	var (
+		inputSum, outputSum int64
+		witnessSizes        = make([]int, len(p.Inputs))
+		hasWitness          bool
+	)
+
+	for i := range p.Inputs {
+		pInput := &p.Inputs[i]
+		input := &a.Inputs[i]
+		input.Signatures = len(pInput.PartialSigs)
+		input.Finalized = isFinalized(p, i)
+
+		utxo := inputUtxo(p, i)
+		if utxo == nil {
+			input.MissingUtxo = true
+			a.MissingUtxos = append(a.MissingUtxos, i)
+		} else {
+			input.Value = monautil.Amount(utxo.Value)
+			inputSum += utxo.Value
+
+			input.ScriptType, input.SignaturesRequired = classifyInput(
+				pInput, utxo.PkScript,
+			)
+		}
+		if input.Finalized {
+			input.SignaturesRequired = 0
+		}
+
+		if !input.Finalized && (input.ScriptType == UnknownInput ||
+			input.Signatures < input.SignaturesRequired) {
+
+			a.MissingSignatures = append(a.MissingSignatures, i)
+		}
+
+		sigScriptSize, witnessSize, ok := estimateInputSize(
+			pInput, input.ScriptType, input.Finalized,
+		)
+		if !ok {
+			a.UnknownInputs = append(a.UnknownInputs, i)
+			continue
+		}
+
+		baseSize += inputBaseSize + sigScriptSize +
+			wire.VarIntSerializeSize(uint64(sigScriptSize))
+		witnessSizes[i] = witnessSize
+		if witnessSize > 0 {
+			hasWitness = true
+		}
+	}
+
+	for i, txOut := range p.UnsignedTx.TxOut {
+		a.Outputs[i].Value = monautil.Amount(txOut.Value)
+		a.Outputs[i].IsChange = isChange(
+			txOut.PkScript, &p.Outputs[i], changeKeys,
+		)
+
+		outputSum += txOut.Value
+		baseSize += txOut.SerializeSize()
+	}
+
+	if len(a.UnknownInputs) == 0 {
+		a.EstimatedWeight = int64(baseSize) * monautil.WitnessScaleFactor
+		if hasWitness {
+			// The marker and flag bytes, followed by the witness of
+			// every input, which is a single zero byte for inputs
+			// without one.
+			a.EstimatedWeight += 2
+			for _, witnessSize := range witnessSizes {
+				if witnessSize == 0 {
+					witnessSize = 1
+				}
+				a.EstimatedWeight += int64(witnessSize)
+			}
+		}
+		a.EstimatedVSize = monautil.WeightToVSize(a.EstimatedWeight)
+	}
+
+	if len(a.MissingUtxos) == 0 {
+		a.Fee = monautil.Amount(inputSum - outputSum)
+		a.FeeRate = monautil.NewFeeRatePerKVByte(
+			a.Fee, a.EstimatedVSize,
+		)
+	}
+
+	return a, nil
+}
+
+// inputUtxo returns the output spent by the input at the given index, or nil
+// if it isn't known.
+func inputUtxo(p *Packet, inIndex int) *wire.TxOut {
+	pInput := &p.Inputs[inIndex]
+	if pInput.WitnessUtxo != nil {
+		return pInput.WitnessUtxo
+	}
+	if pInput.NonWitnessUtxo != nil {
+		outIndex := p.UnsignedTx.TxIn[inIndex].PreviousOutPoint.Index
+		if int(outIndex) < len(pInput.NonWitnessUtxo.TxOut) {
+			return pInput.NonWitnessUtxo.TxOut[outIndex]
+		}
+	}
+
+	return nil
+}
+
+// classifyInput determines the kind of script spent by an input paying to
+// pkScript, along with the number of signatures it requires.
+func classifyInput(pInput *PInput, pkScript []byte) (InputScriptType, int) {
+	multiSigs := func(script []byte) int {
+		if txscript.GetScriptClass(script) != txscript.MultiSigTy {
+			return 0
+		}
+		_, numSigs, err := txscript.CalcMultiSigStats(script)
+		if err != nil {
+			return 0
+		}
+		return numSigs
+	}
+
+	switch txscript.GetScriptClass(pkScript) {
+	case txscript.PubKeyHashTy:
+		return P2PKHInput, 1
+
+	case txscript.WitnessV0PubKeyHashTy:
+		return P2WPKHInput, 1
+
+	case txscript.WitnessV0ScriptHashTy:
+		if numSigs := multiSigs(pInput.WitnessScript); numSigs > 0 {
+			return P2WSHMultiSigInput, numSigs
+		}
+
+	case txscript.ScriptHashTy:
+		switch txscript.GetScriptClass(pInput.RedeemScript) {
+		case txscript.WitnessV0PubKeyHashTy:
+			return P2SHP2WPKHInput, 1
+
+		case txscript.WitnessV0ScriptHashTy:
+			numSigs := multiSigs(pInput.WitnessScript)
+			if numSigs > 0 {
+				return P2SHP2WSHMultiSigInput, numSigs
+			}
+
+		case txscript.MultiSigTy:
+			return P2SHMultiSigInput, multiSigs(pInput.RedeemScript)
+		}
+	}
+
+	return UnknownInput, 0
+}
+
+// estimateInputSize returns the size of the signature script and of the
+// serialized witness of the input once it is finalized.  Finalized inputs are
+// measured, while the size of other inputs is estimated from their script
+// type.  False is returned if the size can't be determined.
+func estimateInputSize(pInput *PInput, scriptType InputScriptType,
+	finalized bool) (int, int, bool) {
+
+	if finalized {
+		return len(pInput.FinalScriptSig), len(pInput.FinalScriptWitness),
+			true
+	}
+
+	pubKeySize := compressedPubKeySize
+	if len(pInput.Bip32Derivation) > 0 {
+		pubKeySize = len(pInput.Bip32Derivation[0].PubKey)
+	}
+
+	// A P2PKH style witness holds the signature and the public key.
+	pkhWitnessSize := 1 + 1 + maxSigSize + 1 + pubKeySize
+
+	// A multisig witness holds the empty dummy element, the signatures
+	// and the witness script.
+	multiSigWitnessSize := func() int {
+		_, numSigs, _ := txscript.CalcMultiSigStats(pInput.WitnessScript)
+		scriptLen := len(pInput.WitnessScript)
+		return wire.VarIntSerializeSize(uint64(numSigs+2)) + 1 +
+			numSigs*(1+maxSigSize) +
+			wire.VarIntSerializeSize(uint64(scriptLen)) + scriptLen
+	}
+
+	switch scriptType {
+	case P2PKHInput:
+		return 1 + maxSigSize + 1 + pubKeySize, 0, true
+
+	case P2SHP2WPKHInput:
+		return p2shP2wpkhSigScriptSize, pkhWitnessSize, true
+
+	case P2WPKHInput:
+		return 0, pkhWitnessSize, true
+
+	case P2SHMultiSigInput:
+		_, numSigs, _ := txscript.CalcMultiSigStats(pInput.RedeemScript)
+		scriptLen := len(pInput.RedeemScript)
+		return 1 + numSigs*(1+maxSigSize) + pushSize(scriptLen) +
+			scriptLen, 0, true
+
+	case P2SHP2WSHMultiSigInput:
+		return p2shP2wshSigScriptSize, multiSigWitnessSize(), true
+
+	case P2WSHMultiSigInput:
+		return 0, multiSigWitnessSize(), true
+	}
+
+	return 0, 0, false
+}
+
+// pushSize returns the size of the opcode, including any length bytes, that
+// pushes data of the given length onto the stack.
+func pushSize(dataLen int) int {
+	switch {
+	case dataLen < txscript.OP_PUSHDATA1:
+		return 1
+	case dataLen <= 0xff:
+		return 2
+	case dataLen <= 0xffff:
+		return 3
+	default:
+		return 5
+	}
+}
+
+// isChange returns true if every key that can spend the output is derived
+// from one of the passed extended keys, as recorded in the BIP32 derivations
+// of the output.  Outputs paying to a script must pay to a multisig or
+// pay-to-pubkey script, so that no foreign key can spend it.
+func isChange(pkScript []byte, pOutput *POutput, changeKeys []*XPub) bool {
+	pubKeys := outputKeys(pkScript, pOutput)
+	if len(pubKeys) == 0 {
+		return false
+	}
+
+	for _, pubKey := range pubKeys {
+		if !isChangeKey(pubKey, pOutput, changeKeys) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// isChangeKey returns true if the output carries a BIP32 derivation of the
+// public key from one of the passed extended keys.
+func isChangeKey(pubKey []byte, pOutput *POutput, changeKeys []*XPub) bool {
+	for _, derivation := range pOutput.Bip32Derivation {
+		if !bytes.Equal(derivation.PubKey, pubKey) {
+			continue
+		}
+		for _, xPub := range changeKeys {
+			if derivesFrom(derivation, xPub) {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// derivesFrom returns true if the public key of the derivation is derived from
+// the passed extended key along the recorded path.
+func derivesFrom(derivation *Bip32Derivation, xPub *XPub) bool {
+	if derivation.MasterKeyFingerprint != xPub.MasterKeyFingerprint ||
+		len(derivation.Bip32Path) < len(xPub.Bip32Path) {
+
+		return false
+	}
+	if !pathsEqual(derivation.Bip32Path[:len(xPub.Bip32Path)],
+		xPub.Bip32Path) {
+
+		return false
+	}
+
+	key := xPub.ExtendedKey
+	for _, index := range derivation.Bip32Path[len(xPub.Bip32Path):] {
+		var err error
+		key, err = key.Derive(index)
+		if err != nil {
+			return false
+		}
+	}
+
+	pubKey, err := key.ECPubKey()
+	if err != nil {
+		return false
+	}
+
+	return bytes.Equal(pubKey.SerializeCompressed(), derivation.PubKey) ||
+		bytes.Equal(pubKey.SerializeUncompressed(), derivation.PubKey)
+}
+
+// outputKeys returns the public keys that can spend the output paying to
+// pkScript.  The witness or redeem script of the output is used if it matches
+// the output script.  Outputs paying to a key hash, directly or through
+// P2SH-P2WPKH, return the key of the BIP32 derivation that hashes to it.
+// Multisig and pay-to-pubkey scripts return all of their keys.  Nil is
+// returned for any other output, which can't be attributed to keys.
+func outputKeys(pkScript []byte, pOutput *POutput) [][]byte {
+	// scriptHashOf returns the P2SH output script paying to the script.
+	scriptHashOf := func(script []byte) []byte {
+		s := []byte{txscript.OP_HASH160, txscript.OP_DATA_20}
+		s = append(s, monautil.Hash160(script)...)
+		return append(s, txscript.OP_EQUAL)
+	}
+
+	script := pkScript
+	switch {
+	case pOutput.WitnessScript != nil:
+		witnessScriptHash := sha256.Sum256(pOutput.WitnessScript)
+		p2wsh := append([]byte{txscript.OP_0, txscript.OP_DATA_32},
+			witnessScriptHash[:]...)
+		if !bytes.Equal(pkScript, p2wsh) &&
+			!(bytes.Equal(pOutput.RedeemScript, p2wsh) &&
+				bytes.Equal(pkScript, scriptHashOf(p2wsh))) {
+
+			return nil
+		}
+		script = pOutput.WitnessScript
+
+	case pOutput.RedeemScript != nil:
+		if !bytes.Equal(pkScript, scriptHashOf(pOutput.RedeemScript)) {
+			return nil
+		}
+		script = pOutput.RedeemScript
+	}
+
+	var pubKeyHash []byte
+	switch txscript.GetScriptClass(script) {
+	case txscript.PubKeyHashTy:
+		pubKeyHash = script[3:23]
+
+	case txscript.WitnessV0PubKeyHashTy:
+		pubKeyHash = script[2:22]
+
+	case txscript.PubKeyTy, txscript.MultiSigTy:
+		pubKeys, err := txscript.PushedData(script)
+		if err != nil {
+			return nil
+		}
+		return pubKeys
+
+	default:
+		return nil
+	}
+
+	for _, derivation := range pOutput.Bip32Derivation {
+		if bytes.Equal(monautil.Hash160(derivation.PubKey), pubKeyHash) {
+			return [][]byte{derivation.PubKey}
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,462 @@
+// Copyright (c) 2026 The monasuite developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package psbt
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"reflect"
+	"testing"
+
+	"github.com/monasuite/monad/btcec"
+	"github.com/monasuite/monad/chaincfg"
+	"github.com/monasuite/monad/txscript"
+	"github.com/monasuite/monad/wire"
+	"github.com/monasuite/monautil"
+	"github.com/monasuite/monautil/hdkeychain"
+)
+
+// analysisTestWallet is a master key along with helpers to derive keys and
+// scripts from it for the analysis tests.
+type analysisTestWallet struct {
+	t      *testing.T
+	master *hdkeychain.ExtendedKey
+}
+
+func (w *analysisTestWallet) derive(path ...uint32) *hdkeychain.ExtendedKey {
+	key := w.master
+	for _, index := range path {
+		var err error
+		key, err = key.Derive(index)
+		if err != nil {
+			w.t.Fatalf("unable to derive key: %v", err)
+		}
+	}
+	return key
+}
+
+func (w *analysisTestWallet) pubKey(path ...uint32) []byte {
+	pub, err := w.derive(path...).ECPubKey()
+	if err != nil {
+		w.t.Fatalf("unable to get public key: %v", err)
+	}
+	return pub.SerializeCompressed()
+}
+
+func (w *analysisTestWallet) derivation(path ...uint32) *Bip32Derivation {
+	return &Bip32Derivation{
+		PubKey:               w.pubKey(path...),
+		MasterKeyFingerprint: w.master.Fingerprint(),
+		Bip32Path:            path,
+	}
+}
+
+func p2shScript(script []byte) []byte {
+	s := []byte{txscript.OP_HASH160, txscript.OP_DATA_20}
+	s = append(s, monautil.Hash160(script)...)
+	return append(s, txscript.OP_EQUAL)
+}
+
+func p2wshScript(script []byte) []byte {
+	hash := sha256.Sum256(script)
+	return append([]byte{txscript.OP_0, txscript.OP_DATA_32}, hash[:]...)
+}
+
+func p2wpkhScript(pubKey []byte) []byte {
+	return append([]byte{txscript.OP_0, txscript.OP_DATA_20},
+		monautil.Hash160(pubKey)...)
+}
+
+func p2pkhScript(pubKey []byte) []byte {
+	s := []byte{txscript.OP_DUP, txscript.OP_HASH160, txscript.OP_DATA_20}
+	s = append(s, monautil.Hash160(pubKey)...)
+	return append(s, txscript.OP_EQUALVERIFY, txscript.OP_CHECKSIG)
+}
+
+func multiSigScript(t *testing.T, required int, pubKeys ...[]byte) []byte {
+	builder := txscript.NewScriptBuilder().AddInt64(int64(required))
+	for _, pubKey := range pubKeys {
+		builder.AddData(pubKey)
+	}
+	script, err := builder.AddInt64(int64(len(pubKeys))).
+		AddOp(txscript.OP_CHECKMULTISIG).Script()
+	if err != nil {
+		t.Fatalf("unable to build script: %v", err)
+	}
+	return script
+}
+
+// TestAnalyze signs and finalizes a packet spending every supported input
+// type and checks that the estimated size is an upper bound that is close to
+// TestAnalyzeMultiSigChange checks that multisig outputs are only reported as
+// change if every one of their keys derives from the change keys.
+func TestAnalyzeMultiSigChange(t *testing.T) {
+	seed := bytes.Repeat([]byte{0x56}, hdkeychain.RecommendedSeedLen)
+	master, err := hdkeychain.NewMaster(seed, &chaincfg.MainNetParams)
+	if err != nil {
+		t.Fatalf("unable to create master key: %v", err)
+	}
+	w := &analysisTestWallet{t: t, master: master}
+
+	account, err := w.derive(1).Neuter()
+	if err != nil {
+		t.Fatalf("unable to neuter key: %v", err)
+	}
+	changeKeys := []*XPub{{
+		ExtendedKey:          account,
+		MasterKeyFingerprint: master.Fingerprint(),
+		Bip32Path:            []uint32{1},
+	}}
+
+	foreignKey, err := btcec.NewPrivateKey(btcec.S256())
+	if err != nil {
+		t.Fatalf("unable to create key: %v", err)
+	}
+	foreignPubKey := foreignKey.PubKey().SerializeCompressed()
+
+	ours := multiSigScript(t, 2, w.pubKey(1, 1, 0), w.pubKey(1, 1, 1))
+	shared := multiSigScript(t, 1, foreignPubKey, w.pubKey(1, 1, 2))
+
+	tests := []struct {
+		name    string
+		txOut   *wire.TxOut
+		pOutput POutput
+		change  bool
+	}{
+		{
+			name:  "p2wsh with all keys ours",
+			txOut: wire.NewTxOut(1000, p2wshScript(ours)),
+			pOutput: POutput{
+				WitnessScript: ours,
+				Bip32Derivation: []*Bip32Derivation{
+					w.derivation(1, 1, 0),
+					w.derivation(1, 1, 1),
+				},
+			},
+			change: true,
+		},
+		{
+			name:  "p2sh with all keys ours",
+			txOut: wire.NewTxOut(1000, p2shScript(ours)),
+			pOutput: POutput{
+				RedeemScript: ours,
+				Bip32Derivation: []*Bip32Derivation{
+					w.derivation(1, 1, 0),
+					w.derivation(1, 1, 1),
+				},
+			},
+			change: true,
+		},
+		{
+			name:  "p2wsh with a key derivation missing",
+			txOut: wire.NewTxOut(1000, p2wshScript(ours)),
+			pOutput: POutput{
+				WitnessScript: ours,
+				Bip32Derivation: []*Bip32Derivation{
+					w.derivation(1, 1, 0),
+				},
+			},
+		},
+		{
+			name:  "1-of-2 p2wsh with a foreign key",
+			txOut: wire.NewTxOut(1000, p2wshScript(shared)),
+			pOutput: POutput{
+				WitnessScript: shared,
+				Bip32Derivation: []*Bip32Derivation{
+					w.derivation(1, 1, 2),
+				},
+			},
+		},
+		{
+			name:  "1-of-2 p2sh with a foreign key",
+			txOut: wire.NewTxOut(1000, p2shScript(shared)),
+			pOutput: POutput{
+				RedeemScript: shared,
+				Bip32Derivation: []*Bip32Derivation{
+					w.derivation(1, 1, 2),
+					{
+						PubKey:               foreignPubKey,
+						MasterKeyFingerprint: master.Fingerprint(),
+						Bip32Path:            []uint32{1, 1, 3},
+					},
+				},
+			},
+		},
+	}
+
+	for _, test := range tests {
+		packet, err := New(
+			[]*wire.OutPoint{{Index: 0}}, []*wire.TxOut{test.txOut},
+			2, 0, []uint32{wire.MaxTxInSequenceNum},
+		)
+		if err != nil {
+			t.Fatalf("%s: unable to create packet: %v", test.name, err)
+		}
+		packet.Outputs[0] = test.pOutput
+
+		analysis, err := Analyze(packet, changeKeys)
+		if err != nil {
+			t.Fatalf("%s: unable to analyze packet: %v", test.name, err)
+		}
+		if analysis.Outputs[0].IsChange != test.change {
+			t.Fatalf("%s: expected change %v", test.name, test.change)
+		}
+	}
+}
+
+// the actual size, along with the fee and change detection.
+func TestAnalyze(t *testing.T) {
+	seed := bytes.Repeat([]byte{0x55}, hdkeychain.RecommendedSeedLen)
+	master, err := hdkeychain.NewMaster(seed, &chaincfg.MainNetParams)
+	if err != nil {
+		t.Fatalf("unable to create master key: %v", err)
+	}
+	w := &analysisTestWallet{t: t, master: master}
+
+	multiSig := multiSigScript(
+		t, 2, w.pubKey(3, 0), w.pubKey(3, 1), w.pubKey(3, 2),
+	)
+
+	// Each funding output is paired with the input data needed to spend
+	// it.
+	type spend struct {
+		txOut      *wire.TxOut
+		pInput     PInput
+		scriptType InputScriptType
+	}
+	spends := []spend{{
+		txOut: wire.NewTxOut(100000, p2pkhScript(w.pubKey(0, 0))),
+		pInput: PInput{Bip32Derivation: []*Bip32Derivation{
+			w.derivation(0, 0),
+		}},
+		scriptType: P2PKHInput,
+	}, {
+		txOut: wire.NewTxOut(
+			200000, p2shScript(p2wpkhScript(w.pubKey(0, 1))),
+		),
+		pInput: PInput{
+			RedeemScript: p2wpkhScript(w.pubKey(0, 1)),
+			Bip32Derivation: []*Bip32Derivation{
+				w.derivation(0, 1),
+			},
+		},
+		scriptType: P2SHP2WPKHInput,
+	}, {
+		txOut: wire.NewTxOut(300000, p2wpkhScript(w.pubKey(0, 2))),
+		pInput: PInput{Bip32Derivation: []*Bip32Derivation{
+			w.derivation(0, 2),
+		}},
+		scriptType: P2WPKHInput,
+	}, {
+		txOut: wire.NewTxOut(400000, p2wshScript(multiSig)),
+		pInput: PInput{
+			WitnessScript: multiSig,
+			Bip32Derivation: []*Bip32Derivation{
+				w.derivation(3, 0), w.derivation(3, 1),
+			},
+		},
+		scriptType: P2WSHMultiSigInput,
+	}, {
+		txOut: wire.NewTxOut(500000, p2shScript(multiSig)),
+		pInput: PInput{
+			RedeemScript: multiSig,
+			Bip32Derivation: []*Bip32Derivation{
+				w.derivation(3, 1), w.derivation(3, 2),
+			},
+		},
+		scriptType: P2SHMultiSigInput,
+	}}
+
+	prevTx := wire.NewMsgTx(2)
+	prevTx.AddTxIn(&wire.TxIn{})
+	for _, s := range spends {
+		prevTx.AddTxOut(s.txOut)
+	}
+	prevHash := prevTx.TxHash()
+
+	// The change output pays to the internal chain of the account that is
+	// passed to Analyze as an xpub.
+	account, err := w.derive(1).Neuter()
+	if err != nil {
+		t.Fatalf("unable to neuter key: %v", err)
+	}
+	changeXPub := &XPub{
+		ExtendedKey:          account,
+		MasterKeyFingerprint: master.Fingerprint(),
+		Bip32Path:            []uint32{1},
+	}
+	changePubKey := w.pubKey(1, 1, 7)
+
+	var (
+		inputs    []*wire.OutPoint
+		sequences []uint32
+	)
+	for i := range spends {
+		inputs = append(inputs, wire.NewOutPoint(&prevHash, uint32(i)))
+		sequences = append(sequences, wire.MaxTxInSequenceNum)
+	}
+	outputs := []*wire.TxOut{
+		wire.NewTxOut(1000000, p2pkhScript(w.pubKey(9))),
+		wire.NewTxOut(490000, p2wpkhScript(changePubKey)),
+		// A foreign output falsely claiming to be change.
+		wire.NewTxOut(5000, p2wpkhScript(w.pubKey(9))),
+	}
+	packet, err := New(inputs, outputs, 2, 0, sequences)
+	if err != nil {
+		t.Fatalf("unable to create packet: %v", err)
+	}
+	for i, s := range spends {
+		packet.Inputs[i] = s.pInput
+		if s.scriptType == P2PKHInput || s.scriptType == P2SHMultiSigInput {
+			packet.Inputs[i].NonWitnessUtxo = prevTx
+		} else {
+			packet.Inputs[i].WitnessUtxo = s.txOut
+		}
+	}
+	packet.Outputs[1].Bip32Derivation = []*Bip32Derivation{
+		w.derivation(1, 1, 7),
+	}
+	packet.Outputs[2].Bip32Derivation = []*Bip32Derivation{
+		w.derivation(1, 1, 8),
+	}
+
+	changeKeys := []*XPub{changeXPub}
+	analysis, err := Analyze(packet, changeKeys)
+	if err != nil {
+		t.Fatalf("unable to analyze packet: %v", err)
+	}
+
+	if analysis.Fee != 5000 {
+		t.Fatalf("expected fee 5000, got %v", int64(analysis.Fee))
+	}
+	for i, s := range spends {
+		input := analysis.Inputs[i]
+		if input.ScriptType != s.scriptType {
+			t.Fatalf("input %d: expected %v, got %v", i,
+				s.scriptType, input.ScriptType)
+		}
+		if input.Value != monautil.Amount(s.txOut.Value) {
+			t.Fatalf("input %d: unexpected value %v", i,
+				input.Value)
+		}
+	}
+	if !reflect.DeepEqual(analysis.MissingSignatures,
+		[]int{0, 1, 2, 3, 4}) {
+
+		t.Fatalf("unexpected inputs missing signatures: %v",
+			analysis.MissingSignatures)
+	}
+	if len(analysis.MissingUtxos) != 0 || len(analysis.UnknownInputs) != 0 {
+		t.Fatalf("unexpected incomplete inputs: %v, %v",
+			analysis.MissingUtxos, analysis.UnknownInputs)
+	}
+	changes := []bool{false, true, false}
+	for i, change := range changes {
+		if analysis.Outputs[i].IsChange != change {
+			t.Fatalf("output %d: expected change %v", i, change)
+		}
+	}
+
+	// Sign and finalize every input to compare the estimate with the
+	// real transaction.
+	signer, err := NewKeySigner([]*hdkeychain.ExtendedKey{master}, nil)
+	if err != nil {
+		t.Fatalf("unable to create signer: %v", err)
+	}
+	if _, err := signer.Sign(packet); err != nil {
+		t.Fatalf("unable to sign packet: %v", err)
+	}
+	signed, err := Analyze(packet, changeKeys)
+	if err != nil {
+		t.Fatalf("unable to analyze packet: %v", err)
+	}
+	if len(signed.MissingSignatures) != 0 {
+		t.Fatalf("unexpected inputs missing signatures: %v",
+			signed.MissingSignatures)
+	}
+	if signed.EstimatedVSize != analysis.EstimatedVSize {
+		t.Fatalf("estimate changed after signing")
+	}
+
+	if err := MaybeFinalizeAll(packet); err != nil {
+		t.Fatalf("unable to finalize packet: %v", err)
+	}
+	tx, err := Extract(packet)
+	if err != nil {
+		t.Fatalf("unable to extract tx: %v", err)
+	}
+	weight := int64(tx.SerializeSizeStripped()*3 + tx.SerializeSize())
+
+	// Every signature may be up to two bytes shorter than estimated.
+	const numSigs = 7
+	if analysis.EstimatedWeight < weight ||
+		analysis.EstimatedWeight > weight+numSigs*2*4 {
+
+		t.Fatalf("estimated weight %d too far from actual weight %d",
+			analysis.EstimatedWeight, weight)
+	}
+
+	// Finalized inputs are measured exactly.
+	finalized, err := Analyze(packet, changeKeys)
+	if err != nil {
+		t.Fatalf("unable to analyze packet: %v", err)
+	}
+	if finalized.EstimatedWeight != weight {
+		t.Fatalf("expected weight %d, got %d", weight,
+			finalized.EstimatedWeight)
+	}
+	wantRate := monautil.NewFeeRatePerKVByte(
+		finalized.Fee, finalized.EstimatedVSize,
+	)
+	if finalized.FeeRate != wantRate {
+		t.Fatalf("unexpected fee rate %v", finalized.FeeRate)
+	}
+}
+
+func TestAnalyzeIncomplete(t *testing.T) {
+	key, err := btcec.NewPrivateKey(btcec.S256())
+	if err != nil {
+		t.Fatalf("unable to create key: %v", err)
+	}
+	pubKey := key.PubKey().SerializeCompressed()
+
+	packet, err := New(
+		[]*wire.OutPoint{{Index: 0}, {Index: 1}, {Index: 2}},
+		[]*wire.TxOut{wire.NewTxOut(1000, p2wpkhScript(pubKey))},
+		2, 0, []uint32{0, 0, 0},
+	)
+	if err != nil {
+		t.Fatalf("unable to create packet: %v", err)
+	}
+	packet.Inputs[0].WitnessUtxo = wire.NewTxOut(
+		2000, p2wpkhScript(pubKey),
+	)
+	packet.Inputs[2].WitnessUtxo = wire.NewTxOut(
+		2000, []byte{txscript.OP_TRUE},
+	)
+
+	analysis, err := Analyze(packet, nil)
+	if err != nil {
+		t.Fatalf("unable to analyze packet: %v", err)
+	}
+
+	if !reflect.DeepEqual(analysis.MissingUtxos, []int{1}) {
+		t.Fatalf("unexpected missing utxos: %v", analysis.MissingUtxos)
+	}
+	if !reflect.DeepEqual(analysis.UnknownInputs, []int{1, 2}) {
+		t.Fatalf("unexpected unknown inputs: %v",
+			analysis.UnknownInputs)
+	}
+	if !reflect.DeepEqual(analysis.MissingSignatures, []int{0, 1, 2}) {
+		t.Fatalf("unexpected inputs missing signatures: %v",
+			analysis.MissingSignatures)
+	}
+	if analysis.Fee != 0 || analysis.FeeRate != 0 ||
+		analysis.EstimatedVSize != 0 {
+
+		t.Fatalf("unexpected fee or size for incomplete packet: %+v",
+			analysis)
+	}
+}
@@ -3,8 +3,18 @@ package psbt
 import (
 	"bytes"
 	"encoding/binary"
+
+	"github.com/monasuite/monad/chaincfg/chainhash"
+	"github.com/monasuite/monautil/base58"
+	"github.com/monasuite/monautil/hdkeychain"
 )
 
+// serializedExtendedKeyLen is the length of a BIP 32 extended key in its raw
+// serialized form, which is how it is used as the key data of a global xpub
+// entry.  It consists of the version (4), depth (1), parent fingerprint (4),
+// child number (4), chain code (32) and key data (33).
+const serializedExtendedKeyLen = 78
+
 // Bip32Derivation encapsulates the data for the input and output
 // Bip32Derivation key-value fields.
 //
@@ -75,3 +85,79 @@ func SerializeBIP32Derivation(masterKeyFingerprint uint32,
 
 	return derivationPath
 }
+
+// XPub encapsulates the data of a global xpub key-value field, which informs
+// signers about the extended public keys of the wallet that created the
+// PSBT.
+type XPub struct {
+	// ExtendedKey is the extended public key as defined by BIP 32.
+	ExtendedKey *hdkeychain.ExtendedKey
+
+	// MasterKeyFingerprint is the finger print of the master pubkey.
+	MasterKeyFingerprint uint32
+
+	// Bip32Path is the BIP 32 path of the extended key with each child
+	// index as a distinct integer.  The number of elements must match the
+	// depth of the extended key.
+	Bip32Path []uint32
+}
+
+// checkValid ensures that the extended key of the XPub is a public key and
+// that its depth matches the length of the derivation path.
+func (x *XPub) checkValid() bool {
+	if x.ExtendedKey == nil || x.ExtendedKey.IsPrivate() {
+		return false
+	}
+
+	return int(x.ExtendedKey.Depth()) == len(x.Bip32Path)
+}
+
+// XPubSorter implements sort.Interface for the XPub struct, ordering the
+// entries by their serialized extended key.
+type XPubSorter []*XPub
+
+func (s XPubSorter) Len() int { return len(s) }
+
+func (s XPubSorter) Swap(i, j int) { s[i], s[j] = s[j], s[i] }
+
+func (s XPubSorter) Less(i, j int) bool {
+	return bytes.Compare(
+		EncodeExtendedKey(s[i].ExtendedKey),
+		EncodeExtendedKey(s[j].ExtendedKey),
+	) < 0
+}
+
+// DecodeExtendedKey decodes the 78 byte raw serialization of an extended key,
+// as used in the key data of a global xpub field, into an extended key.
+func DecodeExtendedKey(encoded []byte) (*hdkeychain.ExtendedKey, error) {
+	if len(encoded) != serializedExtendedKeyLen {
+		return nil, ErrInvalidKeydata
+	}
+
+	// The hdkeychain package only parses the base58 encoding, so we need
+	// to append the checksum ourselves before handing it over.
+	checkSum := chainhash.DoubleHashB(encoded)[:4]
+	serialized := make([]byte, 0, len(encoded)+len(checkSum))
+	serialized = append(serialized, encoded...)
+	serialized = append(serialized, checkSum...)
+
+	return hdkeychain.NewKeyFromString(base58.Encode(serialized))
+}
+
+// EncodeExtendedKey returns the 78 byte raw serialization of an extended key,
+// which is its base58 encoding without the trailing checksum.
+func EncodeExtendedKey(key *hdkeychain.ExtendedKey) []byte {
+	decoded := base58.Decode(key.String())
+	return decoded[:len(decoded)-4]
+}
+
+// readXPubDerivation deserializes the value of a global xpub field.  Unlike
+// readBip32Derivation, an empty derivation path is allowed, since the xpub
+// may be the master public key itself.
+func readXPubDerivation(path []byte) (uint32, []uint32, error) {
+	if len(path) == 4 {
+		return binary.LittleEndian.Uint32(path), nil, nil
+	}
+
+	return readBip32Derivation(path)
+}
@@ -0,0 +1,259 @@
+// Copyright (c) 2026 The monasuite developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package bip322
+
+// References:
+//   [BIP322]: Generic Signed Message Format
+//   https://github.com/bitcoin/bips/blob/master/bip-0322.mediawiki
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+
+	"github.com/monasuite/monad/chaincfg/chainhash"
+	"github.com/monasuite/monad/txscript"
+	"github.com/monasuite/monad/wire"
+	"github.com/monasuite/monautil/psbt"
+)
+
+// messageTag is the tag of the tagged hash messages are committed to with.
+const messageTag = "BIP0322-signed-message"
+
+// Format is the encoding of a BIP322 signature.
+type Format uint8
+
+const (
+	// FormatSimple encodes the witness of the to_sign transaction only.
+	// It is only available for proofs without additional inputs whose
+	// challenge is spent by a witness alone.
+	FormatSimple Format = iota
+
+	// FormatFull encodes the complete to_sign transaction, which is
+	// required for non-witness challenges and proofs of funds.
+	FormatFull
+)
+
+var (
+	// ErrNotSimple describes an error in which a proof can't be encoded
+	// in the simple format, because it has additional inputs or spends
+	// the challenge with a signature script.
+	ErrNotSimple = errors.New("proof can't be encoded in the simple " +
+		"format")
+
+	// ErrUnknownFormat describes an error in which a proof is to be
+	// encoded in a format other than FormatSimple and FormatFull.
+	ErrUnknownFormat = errors.New("unknown signature format")
+)
+
+// MessageHash returns the tagged hash of the passed message that the to_spend
+// transaction commits to.
+func MessageHash(msg string) chainhash.Hash {
+	tag := sha256.Sum256([]byte(messageTag))
+
+	h := sha256.New()
+	h.Write(tag[:])
+	h.Write(tag[:])
+	h.Write([]byte(msg))
+
+	var hash chainhash.Hash
+	copy(hash[:], h.Sum(nil))
+	return hash
+}
+
+// BuildToSpend returns the virtual to_spend transaction of [BIP322], which
+// commits to the passed message and pays nothing to the challenge, the output
+// script the message is signed for.
+func BuildToSpend(challenge []byte, msg string) *wire.MsgTx {
+	hash := MessageHash(msg)
+	sigScript := make([]byte, 0, 2+chainhash.HashSize)
+	sigScript = append(sigScript, txscript.OP_0, txscript.OP_DATA_32)
+	sigScript = append(sigScript, hash[:]...)
+
+	prevOut := wire.NewOutPoint(&chainhash.Hash{}, wire.MaxPrevOutIndex)
+	txIn := wire.NewTxIn(prevOut, sigScript, nil)
+	txIn.Sequence = 0
+
+	tx := wire.NewMsgTx(0)
+	tx.AddTxIn(txIn)
+	tx.AddTxOut(wire.NewTxOut(0, challenge))
+	return tx
+}
+
+// BuildToSign returns the unsigned virtual to_sign transaction of [BIP322],
+// which spends the output of the passed to_spend transaction and, for proofs
+// of funds, the passed outpoints, and has a single OP_RETURN output.
+func BuildToSign(toSpend *wire.MsgTx, funds ...wire.OutPoint) *wire.MsgTx {
+	toSpendHash := toSpend.TxHash()
+
+	tx := wire.NewMsgTx(0)
+	txIn := wire.NewTxIn(wire.NewOutPoint(&toSpendHash, 0), nil, nil)
+	txIn.Sequence = 0
+	tx.AddTxIn(txIn)
+	for i := range funds {
+		txIn := wire.NewTxIn(&funds[i], nil, nil)
+		txIn.Sequence = 0
+		tx.AddTxIn(txIn)
+	}
+	tx.AddTxOut(wire.NewTxOut(0, []byte{txscript.OP_RETURN}))
+
+	return tx
+}
+
+// Challenge describes the output script a message is signed for, along with
+// the scripts needed to sign for it.
+type Challenge struct {
+	// Script is the output script, such as the one paying to the address
+	// whose ownership is proven.
+	Script []byte
+
+	// RedeemScript is the redeem script of P2SH output scripts, including
+	// P2SH-P2WPKH and P2SH-P2WSH.
+	RedeemScript []byte
+
+	// WitnessScript is the witness script of P2WSH and P2SH-P2WSH output
+	// scripts.
+	WitnessScript []byte
+}
+
+// Utxo is an unspent output spent by a proof of funds in addition to the
+// challenge, along with the scripts needed to sign for it.
+type Utxo struct {
+	// OutPoint is the outpoint of the output.
+	OutPoint wire.OutPoint
+
+	// TxOut is the output itself.
+	TxOut *wire.TxOut
+
+	// PrevTx is the transaction holding the output.  It is required for
+	// non-witness outputs, which are signed with the whole previous
+	// transaction at hand.
+	PrevTx *wire.MsgTx
+
+	// RedeemScript is the redeem script of P2SH outputs.
+	RedeemScript []byte
+
+	// WitnessScript is the witness script of P2WSH and P2SH-P2WSH
+	// outputs.
+	WitnessScript []byte
+}
+
+// isWitness returns whether an output with the passed script and redeem
+// script is spent by a witness.
+func isWitness(script, redeemScript []byte) bool {
+	return txscript.IsWitnessProgram(script) ||
+		(redeemScript != nil && txscript.IsWitnessProgram(redeemScript))
+}
+
+// addInput adds the output spent by the input at the passed index and its
+// scripts to the packet being updated.
+func addInput(u *psbt.Updater, inIndex int, txOut *wire.TxOut,
+	prevTx *wire.MsgTx, redeemScript, witnessScript []byte) error {
+
+	var err error
+	if isWitness(txOut.PkScript, redeemScript) {
+		err = u.AddInWitnessUtxo(txOut, inIndex)
+	} else {
+		err = u.AddInNonWitnessUtxo(prevTx, inIndex)
+	}
+	if err != nil {
+		return err
+	}
+
+	if redeemScript != nil {
+		if err := u.AddInRedeemScript(redeemScript, inIndex); err != nil {
+			return err
+		}
+	}
+	if witnessScript != nil {
+		err := u.AddInWitnessScript(witnessScript, inIndex)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// NewPacket returns a PSBT of the to_sign transaction proving that the signer
+// can spend the challenge, and for proofs of funds the passed UTXOs as well,
+// committing to the passed message.  The packet holds the outputs spent by
+// its inputs and their scripts, so it can be signed by the usual PSBT signers
+// such as psbt.KeySigner and then encoded with Encode.
+func NewPacket(msg string, challenge *Challenge,
+	funds ...*Utxo) (*psbt.Packet, error) {
+
+	toSpend := BuildToSpend(challenge.Script, msg)
+
+	outPoints := make([]wire.OutPoint, len(funds))
+	for i, utxo := range funds {
+		outPoints[i] = utxo.OutPoint
+	}
+	toSign := BuildToSign(toSpend, outPoints...)
+
+	p, err := psbt.NewFromUnsignedTx(toSign)
+	if err != nil {
+		return nil, err
+	}
+	u, err := psbt.NewUpdater(p)
+	if err != nil {
+		return nil, err
+	}
+
+	err = addInput(
+		u, 0, toSpend.TxOut[0], toSpend, challenge.RedeemScript,
+		challenge.WitnessScript,
+	)
+	if err != nil {
+		return nil, err
+	}
+	for i, utxo := range funds {
+		err := addInput(
+			u, i+1, utxo.TxOut, utxo.PrevTx, utxo.RedeemScript,
+			utxo.WitnessScript,
+		)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return p, nil
+}
+
+// Encode finalizes the inputs of the signed packet that aren't finalized yet
+// and returns the base64 encoded signature in the passed format.
+func Encode(p *psbt.Packet, format Format) (string, error) {
+	if err := psbt.MaybeFinalizeAll(p); err != nil {
+		return "", err
+	}
+	tx, err := psbt.Extract(p)
+	if err != nil {
+		return "", err
+	}
+
+	var buf bytes.Buffer
+	switch format {
+	case FormatSimple:
+		txIn := tx.TxIn[0]
+		if len(tx.TxIn) != 1 || len(txIn.SignatureScript) != 0 ||
+			len(txIn.Witness) == 0 {
+
+			return "", ErrNotSimple
+		}
+		err = psbt.WriteTxWitness(&buf, txIn.Witness)
+
+	case FormatFull:
+		err = tx.Serialize(&buf)
+
+	default:
+		return "", ErrUnknownFormat
+	}
+	if err != nil {
+		return "", err
+	}
+
+	return base64.StdEncoding.EncodeToString(buf.Bytes()), nil
+}
@@ -0,0 +1,398 @@
+// Copyright (c) 2026 The monasuite developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package bip322_test
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"testing"
+
+	"github.com/monasuite/monad/btcec"
+	"github.com/monasuite/monad/chaincfg"
+	"github.com/monasuite/monad/txscript"
+	"github.com/monasuite/monad/wire"
+	"github.com/monasuite/monautil"
+	"github.com/monasuite/monautil/psbt"
+	"github.com/monasuite/monautil/psbt/bip322"
+)
+
+// vectorWIF is the private key of the BIP 322 test vectors.
+const vectorWIF = "L3VFeEujGtevx9w18HD1fhRbCH67Az2dpCymeRE1SoPK6XQtaN2k"
+
+// net is the network of the addresses used by the tests.
+var net = &chaincfg.MainNetParams
+
+// newKey returns a private key derived deterministically from the passed
+// seed byte.
+func newKey(seed byte) *btcec.PrivateKey {
+	b := make([]byte, 32)
+	b[31] = seed
+	key, _ := btcec.PrivKeyFromBytes(btcec.S256(), b)
+	return key
+}
+
+// pubKey returns the compressed serialization of the public key of the passed
+// private key.
+func pubKey(key *btcec.PrivateKey) []byte {
+	return key.PubKey().SerializeCompressed()
+}
+
+// sign signs the passed packet with the given keys and encodes the resulting
+// signature in the given format.
+func sign(p *psbt.Packet, format bip322.Format,
+	keys ...*btcec.PrivateKey) (string, error) {
+
+	signer, err := psbt.NewKeySigner(nil, keys)
+	if err != nil {
+		return "", err
+	}
+	if _, err := signer.Sign(p); err != nil {
+		return "", err
+	}
+
+	return bip322.Encode(p, format)
+}
+
+// TestMessageHash ensures messages are hashed as in the BIP 322 test vectors.
+func TestMessageHash(t *testing.T) {
+	tests := []struct {
+		msg  string
+		hash string
+	}{
+		{"", "c90c269c4f8fcbe6880f72a721ddfbf1914268a794cbb21cfafee13770ae19f1"},
+		{"Hello World", "f0eb03b1a75ac6d9847f55c624a99169b5dccba2a31f5b23bea77ba270de0a7a"},
+	}
+
+	for _, test := range tests {
+		hash := bip322.MessageHash(test.msg)
+		if got := hex.EncodeToString(hash[:]); got != test.hash {
+			t.Errorf("MessageHash(%q): got %s, want %s", test.msg, got,
+				test.hash)
+		}
+	}
+}
+
+// TestVectors ensures the virtual transactions for a P2WPKH address match the
+// BIP 322 test vectors, and that both the signatures of the test vectors and
+// those made by the package verify.
+func TestVectors(t *testing.T) {
+	tests := []struct {
+		msg     string
+		toSpend string
+		toSign  string
+		sig     string
+	}{{
+		msg:     "",
+		toSpend: "c5680aa69bb8d860bf82d4e9cd3504b55dde018de765a91bb566283c545a99a7",
+		toSign:  "1e9654e951a5ba44c8604c4de6c67fd78a27e81dcadcfe1edf638ba3aaebaed6",
+		sig:     "AkcwRAIgM2gBAQqvZX15ZiysmKmQpDrG83avLIT492QBzLnQIxYCIBaTpOaD20qRlEylyxFSeEA2ba9YOixpX8z46TSDtS40ASECx/EgAxlkQpQ9hYjgGu6EBCPMVPwVIVJqO4XCsMvViHI=",
+	}, {
+		msg:     "Hello World",
+		toSpend: "b79d196740ad5217771c1098fc4a4b51e0535c32236c71f1ea4d61a2d603352b",
+		toSign:  "88737ae86f2077145f93cc4b153ae9a1cb8d56afa511988c149c5c8c9d93bddf",
+		sig:     "AkcwRAIgZRfIY3p7/DoVTty6YZbWS71bc5Vct9p9Fia83eRmw2QCICK/ENGfwLtptFluMGs2KsqoNSk89pO7F29zJLUx9a/sASECx/EgAxlkQpQ9hYjgGu6EBCPMVPwVIVJqO4XCsMvViHI=",
+	}}
+
+	wif, err := monautil.DecodeWIF(vectorWIF)
+	if err != nil {
+		t.Fatalf("DecodeWIF: unexpected error: %v", err)
+	}
+	addr, err := monautil.NewAddressWitnessPubKeyHash(
+		monautil.Hash160(wif.SerializePubKey()), net,
+	)
+	if err != nil {
+		t.Fatalf("NewAddressWitnessPubKeyHash: unexpected error: %v", err)
+	}
+	challenge, _ := monautil.PayToAddrScript(addr)
+
+	for _, test := range tests {
+		toSpend := bip322.BuildToSpend(challenge, test.msg)
+		if got := toSpend.TxHash().String(); got != test.toSpend {
+			t.Errorf("%q: got to_spend %s, want %s", test.msg, got,
+				test.toSpend)
+		}
+		toSign := bip322.BuildToSign(toSpend)
+		if got := toSign.TxHash().String(); got != test.toSign {
+			t.Errorf("%q: got to_sign %s, want %s", test.msg, got,
+				test.toSign)
+		}
+
+		p, err := bip322.NewPacket(
+			test.msg, &bip322.Challenge{Script: challenge},
+		)
+		if err != nil {
+			t.Fatalf("NewPacket: unexpected error: %v", err)
+		}
+		sig, err := sign(p, bip322.FormatSimple, wif.PrivKey)
+		if err != nil {
+			t.Errorf("%q: unexpected error signing: %v", test.msg, err)
+			continue
+		}
+
+		// The test vectors were made by a signer grinding for low R
+		// values, which produces different signatures.
+		for _, sig := range []string{sig, test.sig} {
+			valid, err := bip322.Verify(addr, test.msg, sig, net, nil)
+			if err != nil || !valid {
+				t.Errorf("%q: Verify(%s): got %v, %v, want true",
+					test.msg, sig, valid, err)
+			}
+		}
+		valid, err := bip322.Verify(addr, test.msg+"!", test.sig, net, nil)
+		if err != nil || valid {
+			t.Errorf("%q: Verify with wrong message: got %v, %v, "+
+				"want false", test.msg, valid, err)
+		}
+	}
+}
+
+// TestSignVerify ensures messages signed for the various kinds of addresses
+// verify in the formats available to them.
+func TestSignVerify(t *testing.T) {
+	const msg = "Monacoin custody proof"
+
+	key1, key2, key3 := newKey(1), newKey(2), newKey(3)
+	pkHash := monautil.Hash160(pubKey(key1))
+
+	p2pkh, _ := monautil.NewAddressPubKeyHash(pkHash, net)
+	p2wpkh, _ := monautil.NewAddressWitnessPubKeyHash(pkHash, net)
+	p2wpkhScript, _ := monautil.PayToAddrScript(p2wpkh)
+	p2shP2wpkh, _ := monautil.NewAddressScriptHash(p2wpkhScript, net)
+
+	pubKeys := make([]*monautil.AddressPubKey, 3)
+	for i, key := range []*btcec.PrivateKey{key1, key2, key3} {
+		pubKeys[i], _ = monautil.NewAddressPubKey(pubKey(key), net)
+	}
+	multisig, err := txscript.MultiSigScript(pubKeys, 2)
+	if err != nil {
+		t.Fatalf("MultiSigScript: unexpected error: %v", err)
+	}
+	scriptHash := sha256.Sum256(multisig)
+	p2wsh, _ := monautil.NewAddressWitnessScriptHash(scriptHash[:], net)
+	p2wshScript, _ := monautil.PayToAddrScript(p2wsh)
+	p2shP2wsh, _ := monautil.NewAddressScriptHash(p2wshScript, net)
+	p2sh, _ := monautil.NewAddressScriptHash(multisig, net)
+
+	tests := []struct {
+		name          string
+		addr          monautil.Address
+		redeemScript  []byte
+		witnessScript []byte
+		keys          []*btcec.PrivateKey
+		simple        bool
+	}{
+		{"p2pkh", p2pkh, nil, nil, []*btcec.PrivateKey{key1}, false},
+		{"p2wpkh", p2wpkh, nil, nil, []*btcec.PrivateKey{key1}, true},
+		{"p2sh-p2wpkh", p2shP2wpkh, p2wpkhScript, nil, []*btcec.PrivateKey{key1}, false},
+		{"p2wsh multisig", p2wsh, nil, multisig, []*btcec.PrivateKey{key1, key3}, true},
+		{"p2sh-p2wsh multisig", p2shP2wsh, p2wshScript, multisig, []*btcec.PrivateKey{key2, key3}, false},
+		{"p2sh multisig", p2sh, multisig, nil, []*btcec.PrivateKey{key1, key2}, false},
+	}
+
+	for _, test := range tests {
+		script, _ := monautil.PayToAddrScript(test.addr)
+		challenge := &bip322.Challenge{
+			Script:        script,
+			RedeemScript:  test.redeemScript,
+			WitnessScript: test.witnessScript,
+		}
+
+		for _, format := range []bip322.Format{bip322.FormatSimple, bip322.FormatFull} {
+			p, err := bip322.NewPacket(msg, challenge)
+			if err != nil {
+				t.Fatalf("%s: NewPacket: unexpected error: %v",
+					test.name, err)
+			}
+			sig, err := sign(p, format, test.keys...)
+			if format == bip322.FormatSimple && !test.simple {
+				if err != bip322.ErrNotSimple {
+					t.Errorf("%s: got error %v, want %v",
+						test.name, err, bip322.ErrNotSimple)
+				}
+				continue
+			}
+			if err != nil {
+				t.Errorf("%s: unexpected error signing: %v",
+					test.name, err)
+				continue
+			}
+
+			valid, err := bip322.Verify(test.addr, msg, sig, net, nil)
+			if err != nil || !valid {
+				t.Errorf("%s: format %d: Verify: got %v, %v, "+
+					"want true", test.name, format, valid, err)
+			}
+
+			// The signature doesn't prove control of another
+			// address.
+			valid, err = bip322.Verify(p2wpkh, msg, sig, net, nil)
+			if test.addr != p2wpkh && (err != nil || valid) {
+				t.Errorf("%s: format %d: Verify for other "+
+					"address: got %v, %v, want false",
+					test.name, format, valid, err)
+			}
+		}
+	}
+
+	// A multisig challenge with too few signatures can't be encoded.
+	p, err := bip322.NewPacket(msg, &bip322.Challenge{
+		Script: p2wshScript, WitnessScript: multisig,
+	})
+	if err != nil {
+		t.Fatalf("NewPacket: unexpected error: %v", err)
+	}
+	if _, err := sign(p, bip322.FormatFull, key2); err == nil {
+		t.Errorf("encoded proof signed by a single key of a 2-of-3 " +
+			"multisig")
+	}
+
+	// Legacy signatures are accepted for P2PKH addresses.
+	wif, _ := monautil.NewWIF(key1, net, true)
+	legacySig, err := monautil.SignMessage(wif, msg)
+	if err != nil {
+		t.Fatalf("SignMessage: unexpected error: %v", err)
+	}
+	valid, err := bip322.Verify(p2pkh, msg, legacySig, net, nil)
+	if err != nil || !valid {
+		t.Errorf("legacy signature: Verify: got %v, %v, want true",
+			valid, err)
+	}
+}
+
+// TestProofOfFunds ensures proofs spending additional outputs are verified
+// against the outputs returned by the fetcher.
+func TestProofOfFunds(t *testing.T) {
+	const msg = "Proof of reserves"
+
+	key1, key2 := newKey(1), newKey(2)
+	addr1, _ := monautil.NewAddressWitnessPubKeyHash(
+		monautil.Hash160(pubKey(key1)), net,
+	)
+	addr2, _ := monautil.NewAddressPubKeyHash(
+		monautil.Hash160(pubKey(key2)), net,
+	)
+	script1, _ := monautil.PayToAddrScript(addr1)
+	script2, _ := monautil.PayToAddrScript(addr2)
+
+	// The funds are a P2WPKH output of the challenge key and a P2PKH
+	// output of another key.
+	prevTx := wire.NewMsgTx(2)
+	prevTx.AddTxIn(&wire.TxIn{})
+	prevTx.AddTxOut(wire.NewTxOut(100000000, script1))
+	prevTx.AddTxOut(wire.NewTxOut(50000000, script2))
+	prevHash := prevTx.TxHash()
+
+	funds := []*bip322.Utxo{{
+		OutPoint: *wire.NewOutPoint(&prevHash, 0),
+		TxOut:    prevTx.TxOut[0],
+	}, {
+		OutPoint: *wire.NewOutPoint(&prevHash, 1),
+		TxOut:    prevTx.TxOut[1],
+		PrevTx:   prevTx,
+	}}
+
+	p, err := bip322.NewPacket(
+		msg, &bip322.Challenge{Script: script1}, funds...,
+	)
+	if err != nil {
+		t.Fatalf("NewPacket: unexpected error: %v", err)
+	}
+	if _, err := sign(p, bip322.FormatSimple, key1, key2); err != bip322.ErrNotSimple {
+		t.Fatalf("got error %v, want %v", err, bip322.ErrNotSimple)
+	}
+	sig, err := bip322.Encode(p, bip322.FormatFull)
+	if err != nil {
+		t.Fatalf("Encode: unexpected error: %v", err)
+	}
+
+	fetch := func(op wire.OutPoint) (*wire.TxOut, error) {
+		if op.Hash != prevHash || int(op.Index) >= len(prevTx.TxOut) {
+			return nil, errors.New("unknown output")
+		}
+		return prevTx.TxOut[op.Index], nil
+	}
+	valid, err := bip322.Verify(addr1, msg, sig, net, fetch)
+	if err != nil || !valid {
+		t.Errorf("Verify: got %v, %v, want true", valid, err)
+	}
+
+	_, err = bip322.Verify(addr1, msg, sig, net, nil)
+	if err != bip322.ErrMissingPrevOut {
+		t.Errorf("Verify without fetcher: got error %v, want %v", err,
+			bip322.ErrMissingPrevOut)
+	}
+
+	missing := func(op wire.OutPoint) (*wire.TxOut, error) {
+		return nil, nil
+	}
+	_, err = bip322.Verify(addr1, msg, sig, net, missing)
+	if err != bip322.ErrPrevOutNotFound {
+		t.Errorf("Verify with missing outputs: got error %v, want %v",
+			err, bip322.ErrPrevOutNotFound)
+	}
+
+	// The proof fails if the outputs turn out to be different.
+	other := func(op wire.OutPoint) (*wire.TxOut, error) {
+		return wire.NewTxOut(50000000, script1), nil
+	}
+	valid, err = bip322.Verify(addr1, msg, sig, net, other)
+	if err != nil || valid {
+		t.Errorf("Verify with other outputs: got %v, %v, want false",
+			valid, err)
+	}
+}
+
+// TestVerifyErrors ensures malformed signatures, unsupported addresses and
+// time locked proofs are rejected with the expected errors.
+func TestVerifyErrors(t *testing.T) {
+	const msg = "Hello World"
+
+	key := newKey(1)
+	addr, _ := monautil.NewAddressWitnessPubKeyHash(
+		monautil.Hash160(pubKey(key)), net,
+	)
+	script, _ := monautil.PayToAddrScript(addr)
+
+	// Build a time locked proof.
+	p, err := bip322.NewPacket(msg, &bip322.Challenge{Script: script})
+	if err != nil {
+		t.Fatalf("NewPacket: unexpected error: %v", err)
+	}
+	p.UnsignedTx.LockTime = 500000
+	timeLocked, err := sign(p, bip322.FormatFull, key)
+	if err != nil {
+		t.Fatalf("unexpected error signing: %v", err)
+	}
+
+	taproot, err := monautil.NewAddressTaproot(make([]byte, 32), net)
+	if err != nil {
+		t.Fatalf("NewAddressTaproot: unexpected error: %v", err)
+	}
+	testnet, _ := monautil.NewAddressWitnessPubKeyHash(
+		addr.ScriptAddress(), &chaincfg.TestNet4Params,
+	)
+
+	tests := []struct {
+		name string
+		addr monautil.Address
+		sig  string
+		err  error
+	}{
+		{"invalid base64", addr, "!!!!", monautil.ErrMalformedSignature},
+		{"trailing bytes", addr, "AQEAAA==", monautil.ErrMalformedSignature},
+		{"time locked", addr, timeLocked, bip322.ErrTimeLocked},
+		{"taproot address", taproot, timeLocked, monautil.ErrUnsupportedAddress},
+		{"wrong network", testnet, timeLocked, monautil.ErrWrongNet},
+	}
+
+	for _, test := range tests {
+		_, err := bip322.Verify(test.addr, msg, test.sig, net, nil)
+		if err != test.err {
+			t.Errorf("%s: got error %v, want %v", test.name, err,
+				test.err)
+		}
+	}
+}
@@ -0,0 +1,38 @@
+// Copyright (c) 2026 The monasuite developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+/*
+Package bip322 implements the generic signed message format of BIP 322.
+
+Overview
+
+Messages signed with the signmessage RPC can only be verified against P2PKH
+addresses.  BIP 322 instead proves that the signer can spend an output paying
+to the address, so messages can be signed for any address the script engine
+can spend, including segwit and multisig addresses.
+
+The proof is a virtual transaction, to_sign, spending the output of another
+virtual transaction, to_spend, which pays to the address and commits to the
+message.  Neither transaction is valid on chain.  A proof of funds spends
+additional unspent outputs of the signer as well.
+
+Signatures come in two formats.  The simple format holds the witness of
+to_sign only, and is available for addresses spent by a witness alone.  The
+full format holds the whole to_sign transaction, and is required for legacy
+addresses and proofs of funds.
+
+Signing
+
+NewPacket returns to_sign as a PSBT, which is signed by the usual PSBT signers
+such as psbt.KeySigner, possibly by several parties in turn for multisig
+addresses.  Encode then finalizes the packet and encodes the signature.
+
+Verification
+
+Verify runs the scripts of a proof with the script engine.  Legacy signatures
+are accepted for P2PKH addresses as well.  Proofs depending on time locks are
+reported as inconclusive, since verifying them requires the state of the
+chain.
+*/
+package bip322
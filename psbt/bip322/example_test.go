@@ -0,0 +1,77 @@
+// Copyright (c) 2026 The monasuite developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package bip322_test
+
+import (
+	"fmt"
+
+	"github.com/monasuite/monad/btcec"
+	"github.com/monasuite/monad/chaincfg"
+	"github.com/monasuite/monautil"
+	"github.com/monasuite/monautil/psbt"
+	"github.com/monasuite/monautil/psbt/bip322"
+)
+
+// This example demonstrates how to sign a message for a P2WPKH address and
+// verify the signature.
+func ExampleVerify() {
+	const msg = "Hello World"
+
+	wif, err := monautil.DecodeWIF(vectorWIF)
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	addr, err := monautil.NewAddressWitnessPubKeyHash(
+		monautil.Hash160(wif.SerializePubKey()),
+		&chaincfg.MainNetParams,
+	)
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	script, err := monautil.PayToAddrScript(addr)
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+
+	// Sign the to_sign transaction as any other PSBT.
+	p, err := bip322.NewPacket(msg, &bip322.Challenge{Script: script})
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	signer, err := psbt.NewKeySigner(
+		nil, []*btcec.PrivateKey{wif.PrivKey},
+	)
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	if _, err := signer.Sign(p); err != nil {
+		fmt.Println(err)
+		return
+	}
+	sig, err := bip322.Encode(p, bip322.FormatSimple)
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+
+	valid, err := bip322.Verify(
+		addr, msg, sig, &chaincfg.MainNetParams, nil,
+	)
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	fmt.Println("Address:", addr)
+	fmt.Println("Valid:", valid)
+
+	// Output:
+	// Address: mona1q9vza2e8x573nczrlzms0wvx3gsqjx7vagmqyz7
+	// Valid: true
+}
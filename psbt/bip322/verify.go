@@ -0,0 +1,214 @@
+// Copyright (c) 2026 The monasuite developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package bip322
+
+import (
+	"bytes"
+	"encoding/base64"
+	"errors"
+
+	"github.com/monasuite/monad/chaincfg"
+	"github.com/monasuite/monad/txscript"
+	"github.com/monasuite/monad/wire"
+	"github.com/monasuite/monautil"
+)
+
+var (
+	// ErrMissingPrevOut describes an error in which a proof of funds is
+	// verified without a way to look up the outputs it spends.
+	ErrMissingPrevOut = errors.New("proof spends outputs that can't be " +
+		"looked up")
+
+	// ErrPrevOutNotFound describes an error in which the output spent by
+	// an input of a proof of funds couldn't be found by the fetcher.
+	ErrPrevOutNotFound = errors.New("missing previous output")
+
+	// ErrTimeLocked describes an error in which a proof is only valid once
+	// its lock time or the relative lock time of one of its inputs has
+	// passed, which is inconclusive without knowing the state of the
+	// chain.
+	ErrTimeLocked = errors.New("proof is time locked")
+)
+
+// PrevOutFetcher returns the unspent output at the passed outpoint, which a
+// proof of funds spends, or nil if there is none.
+type PrevOutFetcher func(op wire.OutPoint) (*wire.TxOut, error)
+
+// Verify returns whether the passed base64 encoded signature, in either the
+// simple or the full format, proves that its signer controls the address and
+// commits to the message.  Legacy signatures made with the signmessage RPC
+// are accepted for P2PKH addresses, as [BIP322] allows.
+//
+// The scripts of the proof are run by the script engine with the standard
+// verification flags.  Proofs of funds are only accepted if fetch is given,
+// which is used to look up the outputs spent by their additional inputs.
+// Checking that those outputs are still unspent is up to fetch.
+//
+// An error is returned if the signature is malformed, if the address isn't
+// for the passed network or can't be spent by the script engine, if the proof
+// is time locked, or if fetch fails or doesn't find a spent output.
+func Verify(addr monautil.Address, msg, sig string, net *chaincfg.Params,
+	fetch PrevOutFetcher) (bool, error) {
+
+	if !addr.IsForNet(net) {
+		return false, monautil.ErrWrongNet
+	}
+
+	// Witness programs of version 1 and above can't be verified by the
+	// script engine, which would reject every proof for them.
+	challenge, err := monautil.PayToAddrScript(addr)
+	if err != nil {
+		return false, err
+	}
+	if monautil.GetScriptClass(challenge) == monautil.WitnessV1PlusTy {
+		return false, monautil.ErrUnsupportedAddress
+	}
+
+	raw, err := base64.StdEncoding.DecodeString(sig)
+	if err != nil {
+		return false, monautil.ErrMalformedSignature
+	}
+
+	// A compact signature is 65 bytes long, which is too short for the
+	// other formats.
+	if _, ok := addr.(*monautil.AddressPubKeyHash); ok && len(raw) == 65 {
+		return monautil.VerifyMessage(addr, sig, msg, net)
+	}
+
+	toSpend := BuildToSpend(challenge, msg)
+
+	toSign, err := decodeToSign(raw, toSpend)
+	if err != nil {
+		return false, err
+	}
+	if !isToSign(toSign, toSpend) {
+		return false, nil
+	}
+	if isTimeLocked(toSign) {
+		return false, ErrTimeLocked
+	}
+
+	prevOuts := make([]*wire.TxOut, len(toSign.TxIn))
+	prevOuts[0] = toSpend.TxOut[0]
+	for i, txIn := range toSign.TxIn[1:] {
+		if fetch == nil {
+			return false, ErrMissingPrevOut
+		}
+		prevOuts[i+1], err = fetch(txIn.PreviousOutPoint)
+		if err != nil {
+			return false, err
+		}
+		if prevOuts[i+1] == nil {
+			return false, ErrPrevOutNotFound
+		}
+	}
+
+	sigHashes := txscript.NewTxSigHashes(toSign)
+	for i, prevOut := range prevOuts {
+		vm, err := txscript.NewEngine(
+			prevOut.PkScript, toSign, i,
+			txscript.StandardVerifyFlags, nil, sigHashes,
+			prevOut.Value,
+		)
+		if err != nil {
+			return false, nil
+		}
+		if err := vm.Execute(); err != nil {
+			return false, nil
+		}
+	}
+
+	return true, nil
+}
+
+// decodeToSign decodes the to_sign transaction from a signature in the full
+// format, or builds it around the witness of a signature in the simple
+// format.
+func decodeToSign(raw []byte, toSpend *wire.MsgTx) (*wire.MsgTx, error) {
+	// Signatures that decode as a transaction are in the full format.
+	var tx wire.MsgTx
+	r := bytes.NewReader(raw)
+	if err := tx.Deserialize(r); err == nil && r.Len() == 0 &&
+		len(tx.TxIn) > 0 {
+
+		return &tx, nil
+	}
+
+	witness, err := readTxWitness(raw)
+	if err != nil {
+		return nil, monautil.ErrMalformedSignature
+	}
+	toSign := BuildToSign(toSpend)
+	toSign.TxIn[0].Witness = witness
+
+	return toSign, nil
+}
+
+// readTxWitness decodes a witness stack that is encoded as in transactions
+// and makes up the whole of the passed bytes.
+func readTxWitness(raw []byte) (wire.TxWitness, error) {
+	r := bytes.NewReader(raw)
+	count, err := wire.ReadVarInt(r, 0)
+	if err != nil {
+		return nil, err
+	}
+	if count > uint64(len(raw)) {
+		return nil, monautil.ErrMalformedSignature
+	}
+
+	witness := make(wire.TxWitness, count)
+	for i := range witness {
+		witness[i], err = wire.ReadVarBytes(
+			r, 0, uint32(len(raw)), "witness item",
+		)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if r.Len() != 0 {
+		return nil, monautil.ErrMalformedSignature
+	}
+
+	return witness, nil
+}
+
+// isToSign returns whether the passed transaction has the form [BIP322]
+// requires of to_sign transactions spending the passed to_spend transaction.
+func isToSign(tx, toSpend *wire.MsgTx) bool {
+	if tx.Version != 0 && tx.Version != 2 {
+		return false
+	}
+
+	toSpendHash := toSpend.TxHash()
+	prevOut := tx.TxIn[0].PreviousOutPoint
+	if prevOut.Hash != toSpendHash || prevOut.Index != 0 {
+		return false
+	}
+
+	return len(tx.TxOut) == 1 && tx.TxOut[0].Value == 0 &&
+		bytes.Equal(tx.TxOut[0].PkScript, []byte{txscript.OP_RETURN})
+}
+
+// isTimeLocked returns whether the passed transaction only becomes valid once
+// its lock time or the relative lock time of one of its inputs has passed.
+func isTimeLocked(tx *wire.MsgTx) bool {
+	if tx.LockTime != 0 {
+		return true
+	}
+	if tx.Version < 2 {
+		return false
+	}
+
+	for _, txIn := range tx.TxIn {
+		seq := txIn.Sequence
+		if seq&wire.SequenceLockTimeDisabled == 0 &&
+			seq&wire.SequenceLockTimeMask != 0 {
+
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,436 @@
+// Copyright (c) 2026 The monasuite developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package psbt
+
+// The Combiner merges several PSBTs describing the same unsigned transaction
+// into one, for example the packets returned by the cosigners of a multisig
+// input.  Key-value pairs present in several packets are deduplicated, while
+// a key carrying different values in different packets is reported as a
+// conflict instead of picking one of them silently.
+
+import (
+	"bytes"
+	"fmt"
+)
+
+// CombineConflictError is returned by Combine when the same key carries
+// different values in two of the packets being combined.
+type CombineConflictError struct {
+	// Packet is the position, among the arguments of Combine, of the
+	// packet whose value conflicts with one of the packets before it.
+	Packet int
+
+	// Section is the map of the packet the key belongs to; one of
+	// "global", "input" or "output".
+	Section string
+
+	// Index is the index of the input or output the key belongs to.  It
+	// is zero for global keys.
+	Index int
+
+	// Key is the conflicting key, consisting of the key type followed by
+	// the key data.
+	Key []byte
+}
+
+// Error returns a human-readable description of the conflict.
+func (e *CombineConflictError) Error() string {
+	if e.Section == "global" {
+		return fmt.Sprintf("packet %d has a conflicting value for "+
+			"global key %x", e.Packet, e.Key)
+	}
+
+	return fmt.Sprintf("packet %d has a conflicting value for key %x "+
+		"of %s %d", e.Packet, e.Key, e.Section, e.Index)
+}
+
+// Combine merges the passed packets, which must all describe the same unsigned
+// transaction, into a new packet containing the union of their key-value
+// pairs.  Pairs present in several packets are only included once.  If the
+// same key has different values in two packets, a *CombineConflictError is
+// returned.  None of the passed packets are modified.
+//
+// For version 2 packets the modifiable flags are combined so that inputs or
+// outputs are only modifiable if they are in all packets.
+func Combine(packets ...*Packet) (*Packet, error) {
+	if len(packets) == 0 {
+		return nil, fmt.Errorf("no packets to combine")
+	}
+
+	for i, packet := range packets {
+		if err := VerifyInputOutputLen(packet, false, false); err != nil {
+			return nil, fmt.Errorf("packet %d: %v", i, err)
+		}
+	}
+
+	combined := copyPacket(packets[0])
+	for i := range combined.Inputs {
+		combined.Inputs[i] = copyInput(&combined.Inputs[i])
+	}
+	for i := range combined.Outputs {
+		combined.Outputs[i] = copyOutput(&combined.Outputs[i])
+	}
+
+	for i, packet := range packets[1:] {
+		packetIndex := i + 1
+		if err := verifySameTx(combined, packet); err != nil {
+			return nil, fmt.Errorf("packet %d: %v", packetIndex, err)
+		}
+
+		conflict := func(section string, index int,
+			key []byte) *CombineConflictError {
+
+			return &CombineConflictError{
+				Packet:  packetIndex,
+				Section: section,
+				Index:   index,
+				Key:     key,
+			}
+		}
+
+		if key := combined.mergeGlobals(packet); key != nil {
+			return nil, conflict("global", 0, key)
+		}
+		for idx := range packet.Inputs {
+			key := combined.Inputs[idx].merge(&packet.Inputs[idx])
+			if key != nil {
+				return nil, conflict("input", idx, key)
+			}
+		}
+		for idx := range packet.Outputs {
+			key := combined.Outputs[idx].merge(&packet.Outputs[idx])
+			if key != nil {
+				return nil, conflict("output", idx, key)
+			}
+		}
+	}
+
+	// The locktime of a version 2 packet may change once the locktime
+	// requirements of all packets are merged.
+	if combined.Version == PsbtVersion2 {
+		lockTime, err := combined.DetermineLockTime()
+		if err != nil {
+			return nil, err
+		}
+		combined.UnsignedTx.LockTime = lockTime
+	}
+
+	if err := combined.SanityCheck(); err != nil {
+		return nil, err
+	}
+
+	return combined, nil
+}
+
+// verifySameTx makes sure the two packets are of the same version and
+// describe the same unsigned transaction.
+func verifySameTx(p1, p2 *Packet) error {
+	if p1.Version != p2.Version {
+		return fmt.Errorf("PSBT versions are different")
+	}
+
+	tx1, tx2 := p1.UnsignedTx, p2.UnsignedTx
+	if tx1.Version != tx2.Version {
+		return fmt.Errorf("transaction versions are different")
+	}
+	if err := VerifyInputPrevOutpointsEqual(tx1.TxIn, tx2.TxIn); err != nil {
+		return err
+	}
+	for idx, in := range tx1.TxIn {
+		if in.Sequence != tx2.TxIn[idx].Sequence {
+			return fmt.Errorf("sequence of input %d is different",
+				idx)
+		}
+	}
+	if err := VerifyOutputsEqual(tx1.TxOut, tx2.TxOut); err != nil {
+		return err
+	}
+
+	// The locktime of a version 2 packet follows from the combined input
+	// requirements, so it's only checked for version 0.
+	if p1.Version == PsbtVersion0 && tx1.LockTime != tx2.LockTime {
+		return fmt.Errorf("locktimes are different")
+	}
+
+	return nil
+}
+
+// mergeGlobals adds the global fields of the passed packet to p.  If a field
+// conflicts with the one already present, its key is returned.
+func (p *Packet) mergeGlobals(other *Packet) []byte {
+	for _, xPub := range other.XPubs {
+		keydata := EncodeExtendedKey(xPub.ExtendedKey)
+
+		var found bool
+		for _, x := range p.XPubs {
+			if !bytes.Equal(EncodeExtendedKey(x.ExtendedKey), keydata) {
+				continue
+			}
+			if x.MasterKeyFingerprint != xPub.MasterKeyFingerprint ||
+				!pathsEqual(x.Bip32Path, xPub.Bip32Path) {
+
+				return append([]byte{byte(XpubType)}, keydata...)
+			}
+			found = true
+		}
+		if !found {
+			p.XPubs = append(p.XPubs, xPub)
+		}
+	}
+
+	if p.Version == PsbtVersion2 {
+		if p.FallbackLockTime != other.FallbackLockTime {
+			return []byte{byte(FallbackLocktimeType)}
+		}
+
+		// Inputs and outputs remain modifiable only if no signer of
+		// any packet committed to them, while the SIGHASH_SINGLE flag
+		// is set as soon as any signer used it.
+		single := (p.TxModifiable | other.TxModifiable) &
+			TxModifiableSigHashSingle
+		p.TxModifiable = p.TxModifiable&other.TxModifiable | single
+	}
+
+	for _, kv := range other.Unknowns {
+		var found bool
+		for _, x := range p.Unknowns {
+			if !bytes.Equal(x.Key, kv.Key) {
+				continue
+			}
+			if !bytes.Equal(x.Value, kv.Value) {
+				return kv.Key
+			}
+			found = true
+		}
+		if !found {
+			p.Unknowns = append(p.Unknowns, kv)
+		}
+	}
+
+	return nil
+}
+
+// merge adds the fields of the passed input to pi.  If a field conflicts with
+// the one already present, its key is returned.
+func (pi *PInput) merge(other *PInput) []byte {
+	switch {
+	case pi.NonWitnessUtxo == nil:
+		pi.NonWitnessUtxo = other.NonWitnessUtxo
+
+	case other.NonWitnessUtxo != nil &&
+		pi.NonWitnessUtxo.TxHash() != other.NonWitnessUtxo.TxHash():
+
+		return []byte{byte(NonWitnessUtxoType)}
+	}
+
+	switch {
+	case pi.WitnessUtxo == nil:
+		pi.WitnessUtxo = other.WitnessUtxo
+
+	case other.WitnessUtxo != nil &&
+		!TxOutsEqual(pi.WitnessUtxo, other.WitnessUtxo):
+
+		return []byte{byte(WitnessUtxoType)}
+	}
+
+	for _, sig := range other.PartialSigs {
+		var found bool
+		for _, x := range pi.PartialSigs {
+			if !bytes.Equal(x.PubKey, sig.PubKey) {
+				continue
+			}
+			if !bytes.Equal(x.Signature, sig.Signature) {
+				return append(
+					[]byte{byte(PartialSigType)}, sig.PubKey...,
+				)
+			}
+			found = true
+		}
+		if !found {
+			pi.PartialSigs = append(pi.PartialSigs, sig)
+		}
+	}
+
+	switch {
+	case pi.SighashType == 0:
+		pi.SighashType = other.SighashType
+
+	case other.SighashType != 0 && pi.SighashType != other.SighashType:
+		return []byte{byte(SighashType)}
+	}
+
+	var ok bool
+	if pi.RedeemScript, ok = mergeBytes(
+		pi.RedeemScript, other.RedeemScript,
+	); !ok {
+		return []byte{byte(RedeemScriptInputType)}
+	}
+	if pi.WitnessScript, ok = mergeBytes(
+		pi.WitnessScript, other.WitnessScript,
+	); !ok {
+		return []byte{byte(WitnessScriptInputType)}
+	}
+
+	pi.Bip32Derivation, ok = mergeBip32Derivations(
+		pi.Bip32Derivation, other.Bip32Derivation,
+	)
+	if !ok {
+		return []byte{byte(Bip32DerivationInputType)}
+	}
+
+	if pi.FinalScriptSig, ok = mergeBytes(
+		pi.FinalScriptSig, other.FinalScriptSig,
+	); !ok {
+		return []byte{byte(FinalScriptSigType)}
+	}
+	if pi.FinalScriptWitness, ok = mergeBytes(
+		pi.FinalScriptWitness, other.FinalScriptWitness,
+	); !ok {
+		return []byte{byte(FinalScriptWitnessType)}
+	}
+
+	switch {
+	case pi.RequiredTimeLocktime == 0:
+		pi.RequiredTimeLocktime = other.RequiredTimeLocktime
+
+	case other.RequiredTimeLocktime != 0 &&
+		pi.RequiredTimeLocktime != other.RequiredTimeLocktime:
+
+		return []byte{byte(RequiredTimeLocktimeType)}
+	}
+
+	switch {
+	case pi.RequiredHeightLocktime == 0:
+		pi.RequiredHeightLocktime = other.RequiredHeightLocktime
+
+	case other.RequiredHeightLocktime != 0 &&
+		pi.RequiredHeightLocktime != other.RequiredHeightLocktime:
+
+		return []byte{byte(RequiredHeightLocktimeType)}
+	}
+
+	for _, kv := range other.Unknowns {
+		var found bool
+		for _, x := range pi.Unknowns {
+			if !bytes.Equal(x.Key, kv.Key) {
+				continue
+			}
+			if !bytes.Equal(x.Value, kv.Value) {
+				return kv.Key
+			}
+			found = true
+		}
+		if !found {
+			pi.Unknowns = append(pi.Unknowns, kv)
+		}
+	}
+
+	return nil
+}
+
+// merge adds the fields of the passed output to po.  If a field conflicts
+// with the one already present, its key is returned.
+func (po *POutput) merge(other *POutput) []byte {
+	var ok bool
+	if po.RedeemScript, ok = mergeBytes(
+		po.RedeemScript, other.RedeemScript,
+	); !ok {
+		return []byte{byte(RedeemScriptOutputType)}
+	}
+	if po.WitnessScript, ok = mergeBytes(
+		po.WitnessScript, other.WitnessScript,
+	); !ok {
+		return []byte{byte(WitnessScriptOutputType)}
+	}
+
+	po.Bip32Derivation, ok = mergeBip32Derivations(
+		po.Bip32Derivation, other.Bip32Derivation,
+	)
+	if !ok {
+		return []byte{byte(Bip32DerivationOutputType)}
+	}
+
+	return nil
+}
+
+// mergeBytes returns the value of a field present in either a or b, where a
+// nil slice means the field is absent.  If both are present but differ, false
+// is returned.
+func mergeBytes(a, b []byte) ([]byte, bool) {
+	switch {
+	case a == nil:
+		return b, true
+	case b == nil || bytes.Equal(a, b):
+		return a, true
+	default:
+		return nil, false
+	}
+}
+
+// mergeBip32Derivations returns the union of the two lists of derivations.
+// If the same public key has different origins in the two lists, false is
+// returned.
+func mergeBip32Derivations(a, b []*Bip32Derivation) ([]*Bip32Derivation,
+	bool) {
+
+	for _, derivation := range b {
+		var found bool
+		for _, x := range a {
+			if !bytes.Equal(x.PubKey, derivation.PubKey) {
+				continue
+			}
+			if x.MasterKeyFingerprint !=
+				derivation.MasterKeyFingerprint ||
+				!pathsEqual(x.Bip32Path, derivation.Bip32Path) {
+
+				return nil, false
+			}
+			found = true
+		}
+		if !found {
+			a = append(a, derivation)
+		}
+	}
+
+	return a, true
+}
+
+// pathsEqual returns true if both derivation paths are the same.
+func pathsEqual(a, b []uint32) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+
+	return true
+}
+
+// copyInput returns a copy of the passed input whose lists can be appended to
+// without affecting the original.
+func copyInput(pi *PInput) PInput {
+	c := *pi
+	c.PartialSigs = append([]*PartialSig(nil), pi.PartialSigs...)
+	c.Bip32Derivation = append(
+		[]*Bip32Derivation(nil), pi.Bip32Derivation...,
+	)
+	c.Unknowns = append([]*Unknown(nil), pi.Unknowns...)
+
+	return c
+}
+
+// copyOutput returns a copy of the passed output whose lists can be appended
+// to without affecting the original.
+func copyOutput(po *POutput) POutput {
+	c := *po
+	c.Bip32Derivation = append(
+		[]*Bip32Derivation(nil), po.Bip32Derivation...,
+	)
+
+	return c
+}
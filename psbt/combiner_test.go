@@ -0,0 +1,226 @@
+// Copyright (c) 2026 The monasuite developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package psbt
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+
+	"github.com/monasuite/monad/chaincfg/chainhash"
+	"github.com/monasuite/monad/txscript"
+	"github.com/monasuite/monad/wire"
+)
+
+// newCombinerTestPacket returns a packet spending a single witness input to a
+// single output, as it would be sent to each of the signers.
+func newCombinerTestPacket(t *testing.T) *Packet {
+	t.Helper()
+
+	packet, err := New(
+		[]*wire.OutPoint{{Hash: chainhash.Hash{0x01}, Index: 1}},
+		[]*wire.TxOut{{Value: 1000, PkScript: []byte{txscript.OP_TRUE}}},
+		2, 0, []uint32{wire.MaxTxInSequenceNum},
+	)
+	if err != nil {
+		t.Fatalf("unable to create packet: %v", err)
+	}
+	packet.Inputs[0].WitnessUtxo = &wire.TxOut{
+		Value: 2000, PkScript: []byte{txscript.OP_TRUE},
+	}
+	packet.Inputs[0].Bip32Derivation = []*Bip32Derivation{{
+		PubKey:               []byte{0x02, 0x01},
+		MasterKeyFingerprint: 1,
+		Bip32Path:            []uint32{1, 2},
+	}}
+
+	return packet
+}
+
+func TestCombine(t *testing.T) {
+	packet1 := newCombinerTestPacket(t)
+	packet1.Inputs[0].PartialSigs = []*PartialSig{{
+		PubKey: []byte{0x02, 0x01}, Signature: []byte{0x30, 0x01},
+	}}
+	packet2 := newCombinerTestPacket(t)
+	packet2.Inputs[0].PartialSigs = []*PartialSig{{
+		PubKey: []byte{0x02, 0x02}, Signature: []byte{0x30, 0x02},
+	}}
+	packet2.Inputs[0].WitnessScript = []byte{txscript.OP_TRUE}
+	packet2.Unknowns = []Unknown{{Key: []byte{0xfc}, Value: []byte{1}}}
+
+	combined, err := Combine(packet1, packet2, packet1)
+	if err != nil {
+		t.Fatalf("unable to combine packets: %v", err)
+	}
+
+	input := combined.Inputs[0]
+	if len(input.PartialSigs) != 2 {
+		t.Fatalf("expected 2 partial sigs, got %d",
+			len(input.PartialSigs))
+	}
+	if len(input.Bip32Derivation) != 1 {
+		t.Fatalf("expected 1 derivation, got %d",
+			len(input.Bip32Derivation))
+	}
+	if !bytes.Equal(input.WitnessScript, []byte{txscript.OP_TRUE}) {
+		t.Fatalf("witness script not merged")
+	}
+	if !reflect.DeepEqual(combined.Unknowns, packet2.Unknowns) {
+		t.Fatalf("global unknowns not merged: %v", combined.Unknowns)
+	}
+
+	// The packets passed in must not be modified.
+	if len(packet1.Inputs[0].PartialSigs) != 1 ||
+		packet1.Inputs[0].WitnessScript != nil {
+
+		t.Fatalf("combining modified the first packet")
+	}
+}
+
+func TestCombineConflicts(t *testing.T) {
+	tests := []struct {
+		name    string
+		modify  func(p *Packet)
+		section string
+		key     []byte
+	}{{
+		name: "sighash type",
+		modify: func(p *Packet) {
+			p.Inputs[0].SighashType = txscript.SigHashNone
+		},
+		section: "input",
+		key:     []byte{byte(SighashType)},
+	}, {
+		name: "partial sig",
+		modify: func(p *Packet) {
+			p.Inputs[0].PartialSigs[0].Signature = []byte{0x30, 0x02}
+		},
+		section: "input",
+		key:     []byte{byte(PartialSigType), 0x02, 0x01},
+	}, {
+		name: "input derivation",
+		modify: func(p *Packet) {
+			p.Inputs[0].Bip32Derivation[0].Bip32Path = []uint32{1, 3}
+		},
+		section: "input",
+		key:     []byte{byte(Bip32DerivationInputType)},
+	}, {
+		name: "witness utxo",
+		modify: func(p *Packet) {
+			p.Inputs[0].WitnessUtxo.Value++
+		},
+		section: "input",
+		key:     []byte{byte(WitnessUtxoType)},
+	}, {
+		name: "output redeem script",
+		modify: func(p *Packet) {
+			p.Outputs[0].RedeemScript = []byte{txscript.OP_FALSE}
+		},
+		section: "output",
+		key:     []byte{byte(RedeemScriptOutputType)},
+	}, {
+		name: "global unknown",
+		modify: func(p *Packet) {
+			p.Unknowns[0].Value = []byte{2}
+		},
+		section: "global",
+		key:     []byte{0xfc},
+	}}
+
+	for _, test := range tests {
+		packet1 := newCombinerTestPacket(t)
+		packet1.Inputs[0].SighashType = txscript.SigHashAll
+		packet1.Inputs[0].PartialSigs = []*PartialSig{{
+			PubKey: []byte{0x02, 0x01}, Signature: []byte{0x30, 0x01},
+		}}
+		packet1.Outputs[0].RedeemScript = []byte{txscript.OP_TRUE}
+		packet1.Unknowns = []Unknown{{Key: []byte{0xfc}, Value: []byte{1}}}
+
+		packet2 := newCombinerTestPacket(t)
+		packet2.Inputs[0].SighashType = txscript.SigHashAll
+		packet2.Inputs[0].PartialSigs = []*PartialSig{{
+			PubKey: []byte{0x02, 0x01}, Signature: []byte{0x30, 0x01},
+		}}
+		packet2.Outputs[0].RedeemScript = []byte{txscript.OP_TRUE}
+		packet2.Unknowns = []Unknown{{Key: []byte{0xfc}, Value: []byte{1}}}
+		test.modify(packet2)
+
+		_, err := Combine(packet1, packet2)
+		conflict, ok := err.(*CombineConflictError)
+		if !ok {
+			t.Errorf("%s: expected conflict, got %v", test.name, err)
+			continue
+		}
+		if conflict.Packet != 1 || conflict.Section != test.section ||
+			conflict.Index != 0 || !bytes.Equal(conflict.Key, test.key) {
+
+			t.Errorf("%s: unexpected conflict %v", test.name, conflict)
+		}
+	}
+}
+
+func TestCombineDifferentTx(t *testing.T) {
+	if _, err := Combine(); err == nil {
+		t.Fatalf("expected error combining no packets")
+	}
+
+	packet1 := newCombinerTestPacket(t)
+
+	packet2 := newCombinerTestPacket(t)
+	packet2.UnsignedTx.TxOut[0].Value++
+	if _, err := Combine(packet1, packet2); err == nil {
+		t.Fatalf("expected error combining different outputs")
+	}
+
+	packet3 := newCombinerTestPacket(t)
+	packet3.UnsignedTx.TxIn[0].PreviousOutPoint.Index++
+	if _, err := Combine(packet1, packet3); err == nil {
+		t.Fatalf("expected error combining different inputs")
+	}
+
+	packet4 := newCombinerTestPacket(t)
+	packet4.UnsignedTx.LockTime = 1
+	if _, err := Combine(packet1, packet4); err == nil {
+		t.Fatalf("expected error combining different locktimes")
+	}
+}
+
+func TestCombineV2(t *testing.T) {
+	newPacket := func(flags TxModifiableFlags) *Packet {
+		packet, err := ConvertToV2(newCombinerTestPacket(t))
+		if err != nil {
+			t.Fatalf("unable to convert packet: %v", err)
+		}
+		packet.TxModifiable = flags
+		return packet
+	}
+
+	packet1 := newPacket(TxModifiableInputs | TxModifiableOutputs)
+	packet2 := newPacket(TxModifiableInputs | TxModifiableSigHashSingle)
+	packet2.Inputs[0].RequiredHeightLocktime = 100
+
+	combined, err := Combine(packet1, packet2)
+	if err != nil {
+		t.Fatalf("unable to combine packets: %v", err)
+	}
+
+	expected := TxModifiableInputs | TxModifiableSigHashSingle
+	if combined.TxModifiable != expected {
+		t.Fatalf("expected flags %03b, got %03b", expected,
+			combined.TxModifiable)
+	}
+
+	// The merged locktime requirement determines the locktime.
+	if combined.UnsignedTx.LockTime != 100 {
+		t.Fatalf("expected locktime 100, got %d",
+			combined.UnsignedTx.LockTime)
+	}
+
+	v0 := newCombinerTestPacket(t)
+	if _, err := Combine(packet1, v0); err == nil {
+		t.Fatalf("expected error combining different versions")
+	}
+}
@@ -0,0 +1,122 @@
+// Copyright (c) 2026 The monasuite developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package psbt
+
+// The Constructor role was introduced with version 2 PSBTs in BIP 370.  It
+// adds inputs and outputs to a packet created by NewV2, one at a time, for as
+// long as the modifiable flags of the packet allow it.  Signers clear those
+// flags as their signatures commit to the inputs or outputs.
+
+import (
+	"github.com/monasuite/monad/wire"
+)
+
+// Constructor encapsulates the role 'Constructor' as specified in BIP370; it
+// accepts a version 2 Packet and has methods to add inputs and outputs to it.
+type Constructor struct {
+	Cpsbt *Packet
+}
+
+// NewConstructor returns a new instance of Constructor, if the passed Packet
+// is a valid version 2 PSBT, else an error.
+func NewConstructor(p *Packet) (*Constructor, error) {
+	if p.Version != PsbtVersion2 {
+		return nil, ErrUnsupportedPsbtVersion
+	}
+	if err := p.SanityCheck(); err != nil {
+		return nil, err
+	}
+
+	return &Constructor{Cpsbt: p}, nil
+}
+
+// AddInput appends an input spending prevOut with the given sequence number
+// to the packet.  The passed input may carry any additional input data, such
+// as the UTXO being spent or locktime requirements, and may be nil.  An error
+// is returned if the packet's inputs aren't modifiable, if the outpoint is
+// already spent by the packet, or if the input's locktime requirements can't
+// be met.  Once any input has been signed, the locktime of the transaction
+// may no longer change, so inputs that would change it are rejected too.
+//
+// NOTE: New inputs are always appended, so the pairing of inputs and outputs
+// that SIGHASH_SINGLE signatures commit to is never disturbed.
+func (c *Constructor) AddInput(prevOut wire.OutPoint, sequence uint32,
+	input *PInput) error {
+
+	p := c.Cpsbt
+	if p.TxModifiable&TxModifiableInputs == 0 {
+		return ErrInputsNotModifiable
+	}
+
+	for _, txIn := range p.UnsignedTx.TxIn {
+		if txIn.PreviousOutPoint == prevOut {
+			return ErrDuplicateInput
+		}
+	}
+
+	var pInput PInput
+	if input != nil {
+		pInput = *input
+	}
+
+	// Work out the locktime of the transaction with the new input in place
+	// before modifying the packet, so a failure leaves it untouched.
+	inputs := make([]PInput, 0, len(p.Inputs)+1)
+	inputs = append(inputs, p.Inputs...)
+	candidate := Packet{
+		FallbackLockTime: p.FallbackLockTime,
+		Inputs:           append(inputs, pInput),
+	}
+	lockTime, err := candidate.DetermineLockTime()
+	if err != nil {
+		return err
+	}
+	if lockTime != p.UnsignedTx.LockTime && hasSignatures(p) {
+		return ErrInvalidLockTime
+	}
+
+	p.UnsignedTx.AddTxIn(&wire.TxIn{
+		PreviousOutPoint: prevOut,
+		Sequence:         sequence,
+	})
+	p.UnsignedTx.LockTime = lockTime
+	p.Inputs = candidate.Inputs
+
+	return p.SanityCheck()
+}
+
+// AddOutput appends the passed transaction output to the packet, along with
+// any additional output data in output, which may be nil.  An error is
+// returned if the packet's outputs aren't modifiable.
+func (c *Constructor) AddOutput(txOut *wire.TxOut, output *POutput) error {
+	p := c.Cpsbt
+	if p.TxModifiable&TxModifiableOutputs == 0 {
+		return ErrOutputsNotModifiable
+	}
+
+	var pOutput POutput
+	if output != nil {
+		pOutput = *output
+	}
+
+	p.UnsignedTx.AddTxOut(txOut)
+	p.Outputs = append(p.Outputs, pOutput)
+
+	return p.SanityCheck()
+}
+
+// hasSignatures returns true if any input of the packet carries a partial
+// signature or has been finalized.
+func hasSignatures(p *Packet) bool {
+	for _, pInput := range p.Inputs {
+		if len(pInput.PartialSigs) > 0 || pInput.FinalScriptSig != nil ||
+			pInput.FinalScriptWitness != nil {
+
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,92 @@
+// Copyright (c) 2026 The monasuite developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package psbt
+
+// ConvertToV2 returns a version 2 copy of the passed packet.  The transaction,
+// all input and output data and the global fields carry over unchanged, and
+// the locktime of the transaction becomes the fallback locktime.  Converting
+// the result back with ConvertToV0 yields a packet that serializes to the
+// same bytes as the original.  Packets with a transaction version below 2,
+// or with unknown input fields that clash with the version 2 input fields,
+// can't be converted.  Outputs keep no unknown fields, as a version 0 packet
+// with an unknown output field or a version 2 output field fails to parse,
+// so no output field can clash.
+func ConvertToV2(p *Packet) (*Packet, error) {
+	if err := p.SanityCheck(); err != nil {
+		return nil, err
+	}
+	if p.Version == PsbtVersion2 {
+		return copyPacket(p), nil
+	}
+
+	// BIP 370 requires a transaction version of at least 2.
+	if p.UnsignedTx.Version < 2 {
+		return nil, ErrInvalidPsbtFormat
+	}
+
+	// Unknown input fields using the key types that version 2 assigns to
+	// the transaction input can't be carried over without changing their
+	// meaning.
+	for _, pInput := range p.Inputs {
+		for _, kv := range pInput.Unknowns {
+			if isV2InputType(InputType(kv.Key[0])) {
+				return nil, ErrInvalidPsbtFormat
+			}
+		}
+	}
+
+	converted := copyPacket(p)
+	converted.Version = PsbtVersion2
+	converted.FallbackLockTime = p.UnsignedTx.LockTime
+
+	return converted, nil
+}
+
+// ConvertToV0 returns a version 0 copy of the passed packet.  The transaction,
+// including the locktime determined for it, and all input and output data
+// carry over unchanged, so converting the result back with ConvertToV2 yields
+// the original packet.  The modifiable flags and the locktime requirements of
+// the inputs have no version 0 representation, so ErrNotConvertible is
+// returned for packets setting them rather than dropping them.  The fallback
+// locktime is the locktime of the transaction when no input requires one.
+func ConvertToV0(p *Packet) (*Packet, error) {
+	if err := p.SanityCheck(); err != nil {
+		return nil, err
+	}
+	if p.Version == PsbtVersion0 {
+		return copyPacket(p), nil
+	}
+
+	if p.TxModifiable != 0 {
+		return nil, ErrNotConvertible
+	}
+	for _, pInput := range p.Inputs {
+		if pInput.RequiredTimeLocktime != 0 ||
+			pInput.RequiredHeightLocktime != 0 {
+
+			return nil, ErrNotConvertible
+		}
+	}
+
+	converted := copyPacket(p)
+	converted.Version = PsbtVersion0
+	converted.FallbackLockTime = 0
+
+	return converted, nil
+}
+
+// copyPacket returns a copy of the passed packet with its own transaction and
+// input and output lists, so that either can be modified without affecting
+// the other.  The contents of the individual fields are shared.
+func copyPacket(p *Packet) *Packet {
+	c := *p
+	c.UnsignedTx = p.UnsignedTx.Copy()
+	c.XPubs = append([]*XPub(nil), p.XPubs...)
+	c.Inputs = append([]PInput(nil), p.Inputs...)
+	c.Outputs = append([]POutput(nil), p.Outputs...)
+	c.Unknowns = append([]Unknown(nil), p.Unknowns...)
+
+	return &c
+}
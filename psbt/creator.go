@@ -61,3 +61,30 @@ func New(inputs []*wire.OutPoint,
 		Unknowns:   nil,
 	}, nil
 }
+
+// NewV2 creates a new, empty version 2 PSBT packet as described in BIP 370.
+// Inputs and outputs are added afterwards through a Constructor, for as long
+// as the passed modifiable flags allow it.  The transaction version must be
+// at least 2, and the fallback locktime is used for the transaction unless
+// one of the inputs requires a locktime.  Referencing the PSBT BIP, this
+// function serves the role of the Creator.
+func NewV2(txVersion int32, fallbackLockTime uint32,
+	modifiable TxModifiableFlags) (*Packet, error) {
+
+	if txVersion < 2 {
+		return nil, ErrInvalidPsbtFormat
+	}
+
+	unsignedTx := wire.NewMsgTx(txVersion)
+	unsignedTx.LockTime = fallbackLockTime
+
+	return &Packet{
+		UnsignedTx:       unsignedTx,
+		Version:          PsbtVersion2,
+		FallbackLockTime: fallbackLockTime,
+		TxModifiable:     modifiable,
+		Inputs:           []PInput{},
+		Outputs:          []POutput{},
+		Unknowns:         nil,
+	}, nil
+}
@@ -0,0 +1,32 @@
+// Copyright (c) 2026 The monasuite developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+/*
+Package feebump builds transactions raising the fee of a transaction stuck
+below the fee rate miners accept, as PSBTs ready to be signed.
+
+Overview
+
+A transaction to bump is described by an Original: the transaction, signed or
+not, along with the outputs spent by its inputs.  NewOriginalFromPacket takes
+both from a PSBT.
+
+The fee can be raised in two ways.  A Replacer builds a replacement of the
+transaction as described in BIP 125, which spends the same inputs, makes the
+same payments and pays the higher fee from the change, adding coins as inputs
+when the change doesn't suffice.  The replacement pays at least the fees of the
+transactions it replaces plus the incremental relay fee for its own size, at a
+higher fee rate than the original.  Only transactions signaling
+replaceability can be replaced.
+
+A CPFP builds a child spending an output of the transaction that pays to the
+wallet, with a fee high enough for the parent and child together to reach the
+target fee rate.  This works for any transaction, including those received
+from others.
+
+Both are funded through the Funding fields, which hold the coins that may be
+added as inputs, the coin selector choosing them, and the source of new change
+addresses.
+*/
+package feebump
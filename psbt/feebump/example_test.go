@@ -0,0 +1,66 @@
+// Copyright (c) 2026 The monasuite developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package feebump_test
+
+import (
+	"fmt"
+
+	"github.com/monasuite/monad/txscript"
+	"github.com/monasuite/monad/wire"
+	"github.com/monasuite/monautil"
+	"github.com/monasuite/monautil/psbt/feebump"
+)
+
+// This example demonstrates how to replace a transaction by one paying a
+// higher fee rate, taking the fee from its change output.
+func ExampleReplacer_Replace() {
+	// The original transaction spends a P2WPKH output of 1 MONA, paying
+	// 0.5 MONA and returning the rest but a fee of 0.0000141 MONA as
+	// change at index 1.
+	walletScript, err := txscript.PayToAddrScript(p2wpkhAddress(newKey(1)))
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	paymentScript, err := txscript.PayToAddrScript(p2wpkhAddress(newKey(2)))
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	spent := wire.NewTxOut(1e8, walletScript)
+	msgTx := wire.NewMsgTx(2)
+	msgTx.AddTxIn(&wire.TxIn{
+		PreviousOutPoint: wire.OutPoint{Index: 1},
+		Sequence:         wire.MaxTxInSequenceNum - 2,
+	})
+	msgTx.AddTxOut(wire.NewTxOut(5e7, paymentScript))
+	msgTx.AddTxOut(wire.NewTxOut(5e7-1410, walletScript))
+
+	orig, err := feebump.NewOriginal(
+		monautil.NewTx(msgTx), []*wire.TxOut{spent},
+	)
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	replacer := &feebump.Replacer{
+		FeeRate: monautil.FeeRatePerKVByte(100000),
+	}
+	replacement, err := replacer.Replace(orig, 1)
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+
+	tx := replacement.Packet.UnsignedTx
+	fmt.Println("Original fee:", orig.Fee())
+	fmt.Println("Replacement fee:", replacement.Fee)
+	fmt.Println("Change:", monautil.Amount(tx.TxOut[1].Value))
+
+	// Output:
+	// Original fee: 0.0000141 MONA
+	// Replacement fee: 0.000141 MONA
+	// Change: 0.499859 MONA
+}
@@ -0,0 +1,552 @@
+// Copyright (c) 2026 The monasuite developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package feebump
+
+import (
+	"errors"
+
+	"github.com/monasuite/monad/wire"
+	"github.com/monasuite/monautil"
+	"github.com/monasuite/monautil/coinset"
+	"github.com/monasuite/monautil/policy"
+	"github.com/monasuite/monautil/psbt"
+	"github.com/monasuite/monautil/psbt/txbuilder"
+	"github.com/monasuite/monautil/txsizes"
+)
+
+const (
+	// DefaultIncrementalRelayFee is the fee rate a replacement must pay
+	// for its own size on top of the fees of the transactions it replaces,
+	// when Replacer.IncrementalRelayFee is zero.  It matches the default
+	// of the reference implementation.
+	DefaultIncrementalRelayFee = monautil.FeeRatePerKVByte(1000)
+
+	// replaceableSequence is the sequence number the inputs of
+	// replacements and children are given to signal replaceability.
+	replaceableSequence = wire.MaxTxInSequenceNum - 2
+)
+
+var (
+	// ErrNotReplaceable describes an error in which a transaction to be
+	// replaced doesn't signal replaceability as described in BIP 125.
+	ErrNotReplaceable = errors.New("transaction doesn't signal " +
+		"replaceability")
+
+	// ErrInvalidOutputIndex describes an error in which an output index
+	// doesn't refer to an output of the original transaction.
+	ErrInvalidOutputIndex = errors.New("invalid output index")
+)
+
+// Funding describes how bumped transactions are funded with additional coins
+// when the outputs they already spend can't pay the higher fee, and how their
+// change is paid.
+type Funding struct {
+	// Coins are the coins that may be added as inputs.  Coins without
+	// confirmations are never added, as BIP 125 forbids replacements
+	// from spending new unconfirmed outputs, and the fees of their
+	// ancestors would count toward the fee rate of a child.
+	Coins []coinset.Coin
+
+	// Selector chooses the coins added as inputs.  No coins are added if
+	// it is nil.
+	Selector coinset.CoinSelector
+
+	// InputWeight returns the weight of the input spending a coin.
+	// coinset.ScriptInputWeight is used when it is nil.
+	InputWeight coinset.InputWeightFunc
+
+	// PrevTxs looks up the previous transactions of the added coins to
+	// fill in the NonWitnessUtxo of the inputs.  It is required for coins
+	// which aren't spent with witness data, and optional otherwise.
+	PrevTxs txbuilder.PrevTxSource
+
+	// IsNestedWitness returns whether a P2SH coin nests a witness
+	// program, as described for txbuilder.Builder.  The inputs of the
+	// original transaction that have a witness, or only a WitnessUtxo in
+	// the packet they were taken from, are known to be spent with
+	// witness data without it.
+	IsNestedWitness txbuilder.NestedWitnessFunc
+
+	// ChangeSource returns the address change is paid to when the
+	// original transaction has no change output.  Children always pay to
+	// an address of the change source.
+	ChangeSource txbuilder.ChangeSource
+
+	// ChangeScriptSize is the size of the public key script of new change
+	// outputs, used to estimate the fee before the change address is
+	// known.  The size of a P2WPKH script is used when it is zero.
+	ChangeScriptSize int
+
+	// MinRelayFee is the minimum relay fee rate, which determines the
+	// change that is dust.  policy.DefaultMinRelayFee is used when it is
+	// zero.
+	MinRelayFee monautil.FeeRatePerKVByte
+}
+
+// minRelayFee returns the minimum relay fee rate.
+func (f *Funding) minRelayFee() monautil.FeeRatePerKVByte {
+	if f.MinRelayFee == 0 {
+		return policy.DefaultMinRelayFee
+	}
+	return f.MinRelayFee
+}
+
+// fund returns the inputs of a transaction spending the fixed coins, along
+// with coins added until the inputs pay the payments and the fee returned by
+// minFee for the weight of the transaction, and its change output, if any.
+// The change is paid to changeScript, or to an address of the change source
+// if it is nil.  When changeRequired is set, coins are added until the change
+// isn't dust.
+func (f *Funding) fund(fixed []coinset.Coin, payments []*wire.TxOut,
+	changeScript []byte, changeRequired bool,
+	minFee func(weight int64) monautil.Amount) ([]coinset.Coin,
+	*wire.TxOut, error) {
+
+	changeScriptSize := len(changeScript)
+	if changeScript == nil {
+		changeScriptSize = f.ChangeScriptSize
+		if changeScriptSize == 0 {
+			changeScriptSize = txsizes.P2WPKHPkScriptSize
+		}
+	}
+	var changeDust monautil.Amount
+	if changeRequired {
+		changeDust = policy.DustThreshold(changeScript, f.minRelayFee())
+	}
+
+	var paymentValue, fixedValue monautil.Amount
+	for _, txOut := range payments {
+		paymentValue += monautil.Amount(txOut.Value)
+	}
+	spent := make(map[wire.OutPoint]bool, len(fixed))
+	for _, c := range fixed {
+		fixedValue += c.Value()
+		spent[wire.OutPoint{Hash: *c.Hash(), Index: c.Index()}] = true
+	}
+	var candidates []coinset.Coin
+	for _, c := range f.Coins {
+		op := wire.OutPoint{Hash: *c.Hash(), Index: c.Index()}
+		if c.NumConfs() > 0 && !spent[op] {
+			candidates = append(candidates, c)
+		}
+	}
+
+	// needed returns the value the inputs must have to pay the payments
+	// and the fee, with a change output if withChange is set.
+	needed := func(inputs []coinset.Coin, withChange bool) monautil.Amount {
+		size := -1
+		if withChange {
+			size = changeScriptSize
+		}
+		weight := txbuilder.EstimateWeight(inputs, payments, size,
+			f.InputWeight)
+		return paymentValue + minFee(weight) + changeDust
+	}
+
+	// Ask the selector for the value missing with a change output, until
+	// the selected coins pay the fee for spending them as well, as done by
+	// txbuilder.Builder.
+	inputs := fixed
+	inputValue := fixedValue
+	var target monautil.Amount
+	for inputValue < needed(inputs, changeRequired) {
+		next := needed(inputs, true) - fixedValue
+		if f.Selector == nil || next <= target {
+			return nil, nil, coinset.ErrCoinsNoSelectionAvailable
+		}
+		target = next
+
+		selection, err := f.Selector.CoinSelect(target, candidates)
+		if err != nil {
+			return nil, nil, err
+		}
+		inputs = append(fixed[:len(fixed):len(fixed)],
+			selection.Coins()...)
+		inputValue = fixedValue
+		for _, c := range selection.Coins() {
+			inputValue += c.Value()
+		}
+	}
+
+	// Add a change output if the value left after the fee with change
+	// isn't dust.  A new change address is only requested now, and the
+	// fee is computed with the size of its actual script.
+	if !changeRequired && inputValue <= needed(inputs, true) {
+		return inputs, nil, nil
+	}
+	if changeScript == nil {
+		if f.ChangeSource == nil {
+			return nil, nil, txbuilder.ErrNoChangeSource
+		}
+		addr, err := f.ChangeSource()
+		if err != nil {
+			return nil, nil, err
+		}
+		changeScript, err = monautil.PayToAddrScript(addr)
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+	weight := txbuilder.EstimateWeight(inputs, payments, len(changeScript),
+		f.InputWeight)
+	change := inputValue - paymentValue - minFee(weight)
+	if change < policy.DustThreshold(changeScript, f.minRelayFee()) {
+		if changeRequired {
+			return nil, nil, coinset.ErrCoinsNoSelectionAvailable
+		}
+		return inputs, nil, nil
+	}
+
+	return inputs, wire.NewTxOut(int64(change), changeScript), nil
+}
+
+// isWitnessCoin returns whether the coin is spent with witness data, so the
+// WitnessUtxo of its input is enough to sign for it.
+func (f *Funding) isWitnessCoin(c coinset.Coin) bool {
+	if c, ok := c.(*prevOutCoin); ok && c.witness {
+		return true
+	}
+	return txbuilder.IsWitnessCoin(c, f.IsNestedWitness)
+}
+
+// prevTx returns the previous transaction of a coin, or nil if it isn't
+// needed and can't be looked up.  The transactions known for the outputs spent
+// by the original transaction are used without looking them up.
+func (f *Funding) prevTx(c coinset.Coin) (*wire.MsgTx, error) {
+	if c, ok := c.(*prevOutCoin); ok && c.prevTx != nil {
+		return c.prevTx, nil
+	}
+
+	op := wire.OutPoint{Hash: *c.Hash(), Index: c.Index()}
+	var (
+		prevTx *wire.MsgTx
+		err    error
+	)
+	if f.PrevTxs != nil {
+		prevTx, err = f.PrevTxs(c.Hash())
+	}
+	if err != nil || prevTx == nil {
+		if f.isWitnessCoin(c) {
+			return nil, nil
+		}
+		return nil, &txbuilder.MissingPrevTxError{OutPoint: op, Err: err}
+	}
+
+	// The transaction must actually contain the coin, as signers rely on
+	// it for the value being spent.
+	if prevTx.TxHash() != op.Hash || int(op.Index) >= len(prevTx.TxOut) {
+		return nil, txbuilder.ErrPrevTxMismatch
+	}
+	coinOut := wire.NewTxOut(int64(c.Value()), c.PkScript())
+	if !psbt.TxOutsEqual(prevTx.TxOut[op.Index], coinOut) {
+		return nil, txbuilder.ErrPrevTxMismatch
+	}
+
+	return prevTx, nil
+}
+
+// newPacket returns the PSBT of the transaction spending the inputs with the
+// passed sequence numbers to the outputs, with the UTXO information of the
+// inputs.
+func (f *Funding) newPacket(version int32, lockTime uint32,
+	inputs []coinset.Coin, sequences []uint32,
+	txOuts []*wire.TxOut) (*psbt.Packet, error) {
+
+	outPoints := make([]*wire.OutPoint, len(inputs))
+	for i, c := range inputs {
+		outPoints[i] = wire.NewOutPoint(c.Hash(), c.Index())
+	}
+	packet, err := psbt.New(outPoints, txOuts, version, lockTime, sequences)
+	if err != nil {
+		return nil, err
+	}
+
+	for i, c := range inputs {
+		prevTx, err := f.prevTx(c)
+		if err != nil {
+			return nil, err
+		}
+
+		pInput := &packet.Inputs[i]
+		pInput.NonWitnessUtxo = prevTx
+		if f.isWitnessCoin(c) {
+			pInput.WitnessUtxo = wire.NewTxOut(
+				int64(c.Value()), c.PkScript(),
+			)
+		}
+	}
+
+	return packet, nil
+}
+
+// Replacer builds replacements of transactions paying a higher fee, as
+// described in BIP 125.  A replacement spends all the inputs of the original
+// transaction and makes the same payments.  The higher fee is taken from the
+// change output of the original transaction, which is dropped if it would be
+// dust, and coins are added as inputs when the change doesn't suffice.
+//
+// The fee of a replacement is the largest of the fee at FeeRate, the fees of
+// the original transaction and its descendants plus the incremental relay fee
+// for the size of the replacement, and the smallest fee whose fee rate is
+// higher than the fee rate of the original transaction.
+type Replacer struct {
+	Funding
+
+	// FeeRate is the fee rate the replacement should pay.  If it is zero,
+	// the replacement pays the smallest fee that is accepted.
+	FeeRate monautil.FeeRatePerKVByte
+
+	// IncrementalRelayFee is the fee rate the replacement pays for its own
+	// size on top of the replaced fees.  DefaultIncrementalRelayFee is
+	// used when it is zero.
+	IncrementalRelayFee monautil.FeeRatePerKVByte
+
+	// DescendantFees are the fees of the unconfirmed transactions
+	// spending outputs of the original transaction, which are evicted
+	// along with it, so the replacement pays for them too.
+	DescendantFees monautil.Amount
+}
+
+// Replace returns a replacement of the original transaction as a PSBT ready
+// to be signed.  The change output of the original transaction is at the
+// passed index, or it has none if the index is -1.  The order of the outputs
+// is kept, with a new change output appended, and added inputs follow the
+// inputs of the original transaction.  The inputs signal replaceability, so
+// the replacement may be replaced in turn.
+func (r *Replacer) Replace(orig *Original, changeIndex int) (*txbuilder.BuiltTx,
+	error) {
+
+	msgTx := orig.Tx.MsgTx()
+	if len(orig.PrevOuts) != len(msgTx.TxIn) {
+		return nil, ErrPrevOutsMismatch
+	}
+	if changeIndex < -1 || changeIndex >= len(msgTx.TxOut) {
+		return nil, ErrInvalidOutputIndex
+	}
+	signals := false
+	for _, txIn := range msgTx.TxIn {
+		if txIn.Sequence <= replaceableSequence {
+			signals = true
+			break
+		}
+	}
+	if !signals {
+		return nil, ErrNotReplaceable
+	}
+
+	// The payments are the outputs other than change.
+	var (
+		payments     []*wire.TxOut
+		changeScript []byte
+	)
+	for i, txOut := range msgTx.TxOut {
+		if i == changeIndex {
+			changeScript = txOut.PkScript
+			continue
+		}
+		payments = append(payments, wire.NewTxOut(
+			txOut.Value, txOut.PkScript,
+		))
+	}
+
+	incrementalRelayFee := r.IncrementalRelayFee
+	if incrementalRelayFee == 0 {
+		incrementalRelayFee = DefaultIncrementalRelayFee
+	}
+	origFee := orig.Fee()
+	origVSize := orig.virtualSize(r.InputWeight)
+	replacedFee := origFee + r.DescendantFees
+	minFee := func(weight int64) monautil.Amount {
+		size := monautil.WeightToVSize(weight)
+		fee := r.FeeRate.FeeForVSize(size)
+		if incremental := replacedFee +
+			incrementalRelayFee.FeeForVSize(size); incremental > fee {
+
+			fee = incremental
+		}
+
+		// The fee rate must be higher than the fee rate of the
+		// original transaction, computed without overflowing.
+		if origFee > 0 && origVSize > 0 {
+			s, v := monautil.Amount(size), monautil.Amount(origVSize)
+			higher := origFee/v*s + origFee%v*s/v + 1
+			if higher > fee {
+				fee = higher
+			}
+		}
+		return fee
+	}
+
+	inputs, changeOut, err := r.fund(orig.coins(), payments, changeScript,
+		false, minFee)
+	if err != nil {
+		return nil, err
+	}
+
+	// Put the outputs back in their original order, with the change in
+	// its original place, or appended if it is new.
+	txOuts := make([]*wire.TxOut, 0, len(msgTx.TxOut)+1)
+	newChangeIndex := -1
+	nextPayment := 0
+	for i := range msgTx.TxOut {
+		switch {
+		case i != changeIndex:
+			txOuts = append(txOuts, payments[nextPayment])
+			nextPayment++
+
+		case changeOut != nil:
+			newChangeIndex = len(txOuts)
+			txOuts = append(txOuts, changeOut)
+		}
+	}
+	if changeOut != nil && changeIndex < 0 {
+		newChangeIndex = len(txOuts)
+		txOuts = append(txOuts, changeOut)
+	}
+
+	sequences := make([]uint32, len(inputs))
+	for i := range inputs {
+		sequences[i] = replaceableSequence
+		if i < len(msgTx.TxIn) && msgTx.TxIn[i].Sequence < sequences[i] {
+			sequences[i] = msgTx.TxIn[i].Sequence
+		}
+	}
+	packet, err := r.newPacket(msgTx.Version, msgTx.LockTime, inputs,
+		sequences, txOuts)
+	if err != nil {
+		return nil, err
+	}
+
+	// Carry over the derivations and scripts of the packet the original
+	// transaction was taken from, leaving out signatures.
+	for i := range msgTx.TxIn {
+		if i >= len(orig.pInputs) {
+			break
+		}
+		from, to := &orig.pInputs[i], &packet.Inputs[i]
+		to.SighashType = from.SighashType
+		to.RedeemScript = from.RedeemScript
+		to.WitnessScript = from.WitnessScript
+		to.Bip32Derivation = from.Bip32Derivation
+	}
+	if len(orig.pOutputs) == len(msgTx.TxOut) {
+		j := 0
+		for i := range msgTx.TxOut {
+			if i == changeIndex && changeOut == nil {
+				continue
+			}
+			packet.Outputs[j] = orig.pOutputs[i]
+			j++
+		}
+	}
+
+	return newBuiltTx(packet, inputs, newChangeIndex), nil
+}
+
+// CPFP builds children paying for their parents, so that the fee rate of the
+// parent and child together reaches a target fee rate and miners mine both
+// for the fees of the child.  The child spends an output of the parent paying
+// to the wallet, and pays all of its value but the fee to an address of the
+// change source.  Coins are added as inputs when the output can't pay the fee.
+type CPFP struct {
+	Funding
+
+	// FeeRate is the fee rate the parent and child together should pay.
+	// The child pays at least the minimum relay fee for its own size
+	// even if the parent already pays it.
+	FeeRate monautil.FeeRatePerKVByte
+
+	// SignalRBF sets the sequence numbers of the inputs of the child to
+	// signal that it may be replaced, as described in BIP 125.
+	SignalRBF bool
+}
+
+// Child returns a child spending the output at the passed index of the parent
+// as a PSBT ready to be signed.  The parent should be signed, as the child
+// refers to it by its hash, which changes when signature scripts are added.
+func (c *CPFP) Child(parent *Original, outputIndex int) (*txbuilder.BuiltTx,
+	error) {
+
+	parentTx := parent.Tx.MsgTx()
+	if len(parent.PrevOuts) != len(parentTx.TxIn) {
+		return nil, ErrPrevOutsMismatch
+	}
+	if outputIndex < 0 || outputIndex >= len(parentTx.TxOut) {
+		return nil, ErrInvalidOutputIndex
+	}
+	if c.ChangeSource == nil {
+		return nil, txbuilder.ErrNoChangeSource
+	}
+	addr, err := c.ChangeSource()
+	if err != nil {
+		return nil, err
+	}
+	changeScript, err := monautil.PayToAddrScript(addr)
+	if err != nil {
+		return nil, err
+	}
+
+	parentFee := parent.Fee()
+	parentVSize := parent.virtualSize(c.InputWeight)
+	minRelayFee := c.minRelayFee()
+	minFee := func(weight int64) monautil.Amount {
+		size := monautil.WeightToVSize(weight)
+		fee := c.FeeRate.FeeForVSize(parentVSize+size) - parentFee
+		if own := minRelayFee.FeeForVSize(size); own > fee {
+			fee = own
+		}
+		return fee
+	}
+
+	spent := &prevOutCoin{
+		outPoint: wire.OutPoint{
+			Hash:  *parent.Tx.Hash(),
+			Index: uint32(outputIndex),
+		},
+		txOut:  parentTx.TxOut[outputIndex],
+		prevTx: parentTx,
+	}
+	inputs, changeOut, err := c.fund([]coinset.Coin{spent}, nil,
+		changeScript, true, minFee)
+	if err != nil {
+		return nil, err
+	}
+
+	sequence := uint32(wire.MaxTxInSequenceNum)
+	if c.SignalRBF {
+		sequence = replaceableSequence
+	}
+	sequences := make([]uint32, len(inputs))
+	for i := range sequences {
+		sequences[i] = sequence
+	}
+	packet, err := c.newPacket(txbuilder.DefaultTxVersion, 0, inputs,
+		sequences, []*wire.TxOut{changeOut})
+	if err != nil {
+		return nil, err
+	}
+
+	return newBuiltTx(packet, inputs, 0), nil
+}
+
+// newBuiltTx returns the built transaction of the packet spending the inputs.
+func newBuiltTx(packet *psbt.Packet, inputs []coinset.Coin,
+	changeIndex int) *txbuilder.BuiltTx {
+
+	var inputValue, outputValue monautil.Amount
+	for _, c := range inputs {
+		inputValue += c.Value()
+	}
+	for _, txOut := range packet.UnsignedTx.TxOut {
+		outputValue += monautil.Amount(txOut.Value)
+	}
+
+	return &txbuilder.BuiltTx{
+		Packet:      packet,
+		InputValue:  inputValue,
+		Fee:         inputValue - outputValue,
+		ChangeIndex: changeIndex,
+	}
+}
@@ -0,0 +1,573 @@
+// Copyright (c) 2026 The monasuite developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package feebump_test
+
+import (
+	"testing"
+
+	"github.com/monasuite/monad/wire"
+	"github.com/monasuite/monautil"
+	"github.com/monasuite/monautil/coinset"
+	"github.com/monasuite/monautil/policy"
+	"github.com/monasuite/monautil/psbt"
+	"github.com/monasuite/monautil/psbt/feebump"
+	"github.com/monasuite/monautil/psbt/internal/testwallet"
+	"github.com/monasuite/monautil/psbt/txbuilder"
+)
+
+const (
+	// lowFeeRate is the fee rate of the original transactions of the
+	// tests, 10 watanabe per virtual byte.
+	lowFeeRate = monautil.FeeRatePerKVByte(10000)
+
+	// highFeeRate is the fee rate transactions are bumped to, 100
+	// watanabe per virtual byte.
+	highFeeRate = monautil.FeeRatePerKVByte(100000)
+)
+
+// testSelector is the coin selector of the tests, which selects coins in
+// order until their value is enough.
+var testSelector = &coinset.MinIndexCoinSelector{MaxInputs: 10}
+
+// The fixtures of the tests are shared with the tests of the other packages
+// building transactions.
+var (
+	newKey        = testwallet.NewKey
+	p2wpkhAddress = testwallet.P2WPKHAddress
+	p2pkhAddress  = testwallet.P2PKHAddress
+	payment       = testwallet.Payment
+	net           = testwallet.Net
+)
+
+// wallet is a test wallet building transactions with txbuilder.
+type wallet struct {
+	*testwallet.Wallet
+}
+
+// newWallet returns a wallet with one confirmed coin of each passed value.
+// See testwallet.New.
+func newWallet(seed byte, legacy bool, values ...monautil.Amount) *wallet {
+	return &wallet{testwallet.New(seed, legacy, values...)}
+}
+
+// build builds and signs a transaction paying the outputs from the coins of
+// the wallet at the passed fee rate, returning the built transaction and the
+// signed one.
+func (w *wallet) build(t *testing.T, feeRate monautil.FeeRatePerKVByte,
+	rbf bool, outputs ...*txbuilder.Output) (*txbuilder.BuiltTx,
+	*wire.MsgTx) {
+
+	t.Helper()
+
+	b := &txbuilder.Builder{
+		Selector:     testSelector,
+		FeeRate:      feeRate,
+		ChangeSource: w.ChangeSource,
+		PrevTxs:      w.PrevTx,
+		SignalRBF:    rbf,
+	}
+	built, err := b.Build(outputs, w.Coins)
+	if err != nil {
+		t.Fatalf("unable to build transaction: %v", err)
+	}
+	return built, w.sign(t, built.Packet)
+}
+
+// sign signs a copy of the packet with the keys of the wallet and returns the
+// final transaction.
+func (w *wallet) sign(t *testing.T, p *psbt.Packet) *wire.MsgTx {
+	t.Helper()
+
+	msgTx, err := w.Sign(p)
+	if err != nil {
+		t.Fatalf("unable to sign transaction: %v", err)
+	}
+	return msgTx
+}
+
+// fee returns the fee paid by the signed transaction of the wallet.
+func (w *wallet) fee(msgTx *wire.MsgTx) monautil.Amount {
+	var fee monautil.Amount
+	for _, prevOut := range w.PrevOuts(msgTx) {
+		fee += monautil.Amount(prevOut.Value)
+	}
+	for _, txOut := range msgTx.TxOut {
+		fee -= monautil.Amount(txOut.Value)
+	}
+	return fee
+}
+
+// checkReplacement checks that the replacement of the original transaction
+// spends its inputs, makes its payments and pays enough fees to replace it.
+func checkReplacement(t *testing.T, name string, w *wallet,
+	orig *wire.MsgTx, origChange int, feeRate monautil.FeeRatePerKVByte,
+	built *txbuilder.BuiltTx) {
+
+	t.Helper()
+
+	tx := built.Packet.UnsignedTx
+	for i, txIn := range orig.TxIn {
+		if i >= len(tx.TxIn) ||
+			tx.TxIn[i].PreviousOutPoint != txIn.PreviousOutPoint {
+
+			t.Errorf("%s: input %d of the original isn't spent",
+				name, i)
+		}
+	}
+	for i, txIn := range tx.TxIn {
+		if txIn.Sequence > wire.MaxTxInSequenceNum-2 {
+			t.Errorf("%s: input %d doesn't signal replaceability",
+				name, i)
+		}
+	}
+
+	// Every payment must be kept.
+	j := 0
+	for i, txOut := range orig.TxOut {
+		if i == origChange {
+			if built.ChangeIndex == j {
+				j++
+			}
+			continue
+		}
+		if j == built.ChangeIndex {
+			j++
+		}
+		if j >= len(tx.TxOut) || !psbt.TxOutsEqual(tx.TxOut[j], txOut) {
+			t.Errorf("%s: payment %d isn't kept", name, i)
+		}
+		j++
+	}
+
+	signed := w.sign(t, built.Packet)
+	fee, origFee := w.fee(signed), w.fee(orig)
+	if fee != built.Fee {
+		t.Errorf("%s: fee is %v, want %v", name, built.Fee, fee)
+	}
+
+	// The replacement must pay the fee rate, the original fee plus the
+	// incremental relay fee, and a higher fee rate than the original.
+	vsize := monautil.NewTx(signed).VirtualSize()
+	origVSize := monautil.NewTx(orig).VirtualSize()
+	if minFee := feeRate.FeeForVSize(vsize); fee < minFee {
+		t.Errorf("%s: fee %v for %d vbytes is below %v", name, fee,
+			vsize, minFee)
+	}
+	incremental := feebump.DefaultIncrementalRelayFee.FeeForVSize(vsize)
+	if fee < origFee+incremental {
+		t.Errorf("%s: fee %v doesn't pay %v on top of %v", name, fee,
+			incremental, origFee)
+	}
+	if int64(fee)*origVSize <= int64(origFee)*vsize {
+		t.Errorf("%s: fee rate of %v for %d vbytes isn't above %v "+
+			"for %d vbytes", name, fee, vsize, origFee, origVSize)
+	}
+}
+
+// TestReplace tests replacing transactions by transactions paying higher fees.
+func TestReplace(t *testing.T) {
+	w := newWallet(1, false, 1e8, 5e7)
+	more := newWallet(100, false, 3e7)
+	legacy := newWallet(150, true, 1e8)
+
+	// signalingTx returns a signed transaction of the wallet paying the
+	// outputs at a low fee rate, and its change index.
+	signalingTx := func(w *wallet, outputs ...*txbuilder.Output) (
+		*wire.MsgTx, int) {
+
+		built, signed := w.build(t, lowFeeRate, true, outputs...)
+		return signed, built.ChangeIndex
+	}
+
+	withChange, withChangeIndex := signalingTx(w, payment(200, 5e7))
+	twoPayments, twoPaymentsIndex := signalingTx(
+		w, payment(200, 4e7), payment(201, 1e7),
+	)
+	legacyTx, legacyIndex := signalingTx(legacy, payment(200, 5e7))
+
+	// The original without change spends a coin entirely, leaving just
+	// the fee at the low fee rate.
+	exact := newWallet(50, false, 5e7+1100)
+	exactTx, exactIndex := signalingTx(exact, payment(200, 5e7))
+	exact.Coins = append(exact.Coins, more.Coins...)
+	exact.Keys = append(exact.Keys, more.Keys...)
+	for hash, msgTx := range more.Txs {
+		exact.Txs[hash] = msgTx
+	}
+
+	// The original with small change can't pay the higher fee from it
+	// alone, so the change is dropped.
+	small := newWallet(60, false, 5e7+1804)
+	smallTx, smallIndex := signalingTx(small, payment(200, 5e7))
+
+	tests := []struct {
+		name        string
+		w           *wallet
+		orig        *wire.MsgTx
+		changeIndex int
+		feeRate     monautil.FeeRatePerKVByte
+		addCoins    bool
+		wantInputs  int
+		wantChange  bool
+	}{
+		{
+			name:        "shrink change",
+			w:           w,
+			orig:        withChange,
+			changeIndex: withChangeIndex,
+			feeRate:     highFeeRate,
+			wantInputs:  1,
+			wantChange:  true,
+		},
+		{
+			name:        "minimum replacement",
+			w:           w,
+			orig:        withChange,
+			changeIndex: withChangeIndex,
+			wantInputs:  1,
+			wantChange:  true,
+		},
+		{
+			name:        "two payments",
+			w:           w,
+			orig:        twoPayments,
+			changeIndex: twoPaymentsIndex,
+			feeRate:     highFeeRate,
+			wantInputs:  1,
+			wantChange:  true,
+		},
+		{
+			name:        "legacy inputs",
+			w:           legacy,
+			orig:        legacyTx,
+			changeIndex: legacyIndex,
+			feeRate:     highFeeRate,
+			wantInputs:  1,
+			wantChange:  true,
+		},
+		{
+			name:        "drop change",
+			w:           small,
+			orig:        smallTx,
+			changeIndex: smallIndex,
+			wantInputs:  1,
+			wantChange:  false,
+		},
+		{
+			name:        "add inputs",
+			w:           exact,
+			orig:        exactTx,
+			changeIndex: exactIndex,
+			feeRate:     highFeeRate,
+			addCoins:    true,
+			wantInputs:  2,
+			wantChange:  true,
+		},
+	}
+
+	for _, test := range tests {
+		orig, err := feebump.NewOriginal(
+			monautil.NewTx(test.orig), test.w.PrevOuts(test.orig),
+		)
+		if err != nil {
+			t.Fatalf("%s: unable to create original: %v", test.name,
+				err)
+		}
+		r := &feebump.Replacer{
+			Funding: feebump.Funding{
+				PrevTxs:      test.w.PrevTx,
+				ChangeSource: test.w.ChangeSource,
+			},
+			FeeRate: test.feeRate,
+		}
+		if test.addCoins {
+			r.Coins = test.w.Coins
+			r.Selector = testSelector
+		}
+
+		built, err := r.Replace(orig, test.changeIndex)
+		if err != nil {
+			t.Errorf("%s: unexpected error: %v", test.name, err)
+			continue
+		}
+		if n := len(built.Packet.UnsignedTx.TxIn); n != test.wantInputs {
+			t.Errorf("%s: spends %d inputs, want %d", test.name, n,
+				test.wantInputs)
+		}
+		if (built.ChangeIndex >= 0) != test.wantChange {
+			t.Errorf("%s: change index %d, want change %v",
+				test.name, built.ChangeIndex, test.wantChange)
+		}
+		checkReplacement(t, test.name, test.w, test.orig,
+			test.changeIndex, test.feeRate, built)
+	}
+}
+
+// TestReplaceFromPacket tests replacing transactions given as PSBTs.
+func TestReplaceFromPacket(t *testing.T) {
+	w := newWallet(1, false, 1e8)
+	built, signed := w.build(t, lowFeeRate, true, payment(200, 5e7))
+
+	// Record a derivation for the change output, which must be carried
+	// over to the replacement.
+	changePubKey := w.ChangeKey.PubKey().SerializeCompressed()
+	derivation := &psbt.Bip32Derivation{
+		PubKey:               changePubKey,
+		MasterKeyFingerprint: 0x01020304,
+		Bip32Path:            []uint32{84 | 0x80000000, 1, 0},
+	}
+	built.Packet.Outputs[built.ChangeIndex].Bip32Derivation =
+		[]*psbt.Bip32Derivation{derivation}
+
+	orig, err := feebump.NewOriginalFromPacket(built.Packet)
+	if err != nil {
+		t.Fatalf("unable to create original: %v", err)
+	}
+	if orig.Fee() != w.fee(signed) {
+		t.Errorf("original fee is %v, want %v", orig.Fee(),
+			w.fee(signed))
+	}
+
+	r := &feebump.Replacer{FeeRate: highFeeRate}
+	replacement, err := r.Replace(orig, built.ChangeIndex)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	checkReplacement(t, "packet", w, signed, built.ChangeIndex,
+		highFeeRate, replacement)
+
+	pOutput := replacement.Packet.Outputs[replacement.ChangeIndex]
+	if len(pOutput.Bip32Derivation) != 1 ||
+		pOutput.Bip32Derivation[0] != derivation {
+
+		t.Errorf("change derivation isn't carried over")
+	}
+}
+
+// TestReplaceErrors tests the errors returned when a transaction can't be
+// replaced.
+func TestReplaceErrors(t *testing.T) {
+	w := newWallet(1, false, 1e8)
+	final, _ := w.build(t, lowFeeRate, false, payment(200, 5e7))
+	_, signaling := w.build(t, lowFeeRate, true, payment(200, 5e7))
+	unconfirmed := newWallet(100, false, 1e8)
+	unconfirmed.Coins[0].(*coinset.SimpleCoin).TxNumConfs = 0
+
+	newOriginal := func(msgTx *wire.MsgTx) *feebump.Original {
+		orig, err := feebump.NewOriginal(
+			monautil.NewTx(msgTx), w.PrevOuts(msgTx),
+		)
+		if err != nil {
+			t.Fatalf("unable to create original: %v", err)
+		}
+		return orig
+	}
+
+	tests := []struct {
+		name        string
+		replacer    *feebump.Replacer
+		orig        *feebump.Original
+		changeIndex int
+		wantErr     error
+	}{
+		{
+			name:        "not signaling",
+			replacer:    &feebump.Replacer{FeeRate: highFeeRate},
+			orig:        newOriginal(final.Packet.UnsignedTx),
+			changeIndex: -1,
+			wantErr:     feebump.ErrNotReplaceable,
+		},
+		{
+			name:        "invalid change index",
+			replacer:    &feebump.Replacer{FeeRate: highFeeRate},
+			orig:        newOriginal(signaling),
+			changeIndex: 2,
+			wantErr:     feebump.ErrInvalidOutputIndex,
+		},
+		{
+			name:        "no coins",
+			replacer:    &feebump.Replacer{FeeRate: highFeeRate},
+			orig:        newOriginal(signaling),
+			changeIndex: -1,
+			wantErr:     coinset.ErrCoinsNoSelectionAvailable,
+		},
+		{
+			name: "unconfirmed coins",
+			replacer: &feebump.Replacer{
+				Funding: feebump.Funding{
+					Coins:    unconfirmed.Coins,
+					Selector: testSelector,
+				},
+				FeeRate: highFeeRate,
+			},
+			orig:        newOriginal(signaling),
+			changeIndex: -1,
+			wantErr:     coinset.ErrCoinsNoSelectionAvailable,
+		},
+	}
+
+	for _, test := range tests {
+		_, err := test.replacer.Replace(test.orig, test.changeIndex)
+		if err != test.wantErr {
+			t.Errorf("%s: got error %v, want %v", test.name, err,
+				test.wantErr)
+		}
+	}
+
+	_, err := feebump.NewOriginal(monautil.NewTx(signaling), nil)
+	if err != feebump.ErrPrevOutsMismatch {
+		t.Errorf("got error %v, want %v", err,
+			feebump.ErrPrevOutsMismatch)
+	}
+}
+
+// TestChild tests building children paying for their parents.
+func TestChild(t *testing.T) {
+	w := newWallet(1, false, 1e8)
+	more := newWallet(100, false, 3e7)
+
+	// The parent pays 5 MONA to a payment and the rest back as change.
+	parentBuilt, parent := w.build(t, lowFeeRate, false, payment(200, 5e7))
+	w.Txs[parent.TxHash()] = parent
+
+	// The payment of 1000 watanabe can't pay the fee of a child alone.
+	tiny, tinyParent := w.build(t, lowFeeRate, false, payment(1, 1000))
+	w.Txs[tinyParent.TxHash()] = tinyParent
+	tinyIndex := 1 - tiny.ChangeIndex
+	w.Keys = append(w.Keys, more.Keys...)
+	for hash, msgTx := range more.Txs {
+		w.Txs[hash] = msgTx
+	}
+
+	tests := []struct {
+		name        string
+		parent      *wire.MsgTx
+		outputIndex int
+		feeRate     monautil.FeeRatePerKVByte
+		addCoins    bool
+		wantInputs  int
+	}{
+		{
+			name:        "spend change",
+			parent:      parent,
+			outputIndex: parentBuilt.ChangeIndex,
+			feeRate:     highFeeRate,
+			wantInputs:  1,
+		},
+		{
+			name:        "parent pays enough",
+			parent:      parent,
+			outputIndex: parentBuilt.ChangeIndex,
+			feeRate:     lowFeeRate / 2,
+			wantInputs:  1,
+		},
+		{
+			name:        "add inputs",
+			parent:      tinyParent,
+			outputIndex: tinyIndex,
+			feeRate:     highFeeRate,
+			addCoins:    true,
+			wantInputs:  2,
+		},
+	}
+
+	for _, test := range tests {
+		orig, err := feebump.NewOriginal(
+			monautil.NewTx(test.parent), w.PrevOuts(test.parent),
+		)
+		if err != nil {
+			t.Fatalf("%s: unable to create original: %v", test.name,
+				err)
+		}
+		c := &feebump.CPFP{
+			Funding: feebump.Funding{
+				PrevTxs:      w.PrevTx,
+				ChangeSource: w.ChangeSource,
+			},
+			FeeRate: test.feeRate,
+		}
+		if test.addCoins {
+			c.Coins = more.Coins
+			c.Selector = testSelector
+		}
+
+		built, err := c.Child(orig, test.outputIndex)
+		if err != nil {
+			t.Errorf("%s: unexpected error: %v", test.name, err)
+			continue
+		}
+		tx := built.Packet.UnsignedTx
+		if len(tx.TxIn) != test.wantInputs {
+			t.Errorf("%s: spends %d inputs, want %d", test.name,
+				len(tx.TxIn), test.wantInputs)
+		}
+		wantOutPoint := wire.OutPoint{
+			Hash:  test.parent.TxHash(),
+			Index: uint32(test.outputIndex),
+		}
+		if tx.TxIn[0].PreviousOutPoint != wantOutPoint {
+			t.Errorf("%s: child spends %v, want %v", test.name,
+				tx.TxIn[0].PreviousOutPoint, wantOutPoint)
+		}
+		if len(tx.TxOut) != 1 || built.ChangeIndex != 0 {
+			t.Errorf("%s: child has %d outputs", test.name,
+				len(tx.TxOut))
+			continue
+		}
+
+		// The parent and child together must pay the fee rate, and
+		// the child at least the minimum relay fee.
+		child := w.sign(t, built.Packet)
+		fee := w.fee(child)
+		if fee != built.Fee {
+			t.Errorf("%s: fee is %v, want %v", test.name,
+				built.Fee, fee)
+		}
+		vsize := monautil.NewTx(child).VirtualSize()
+		parentVSize := monautil.NewTx(test.parent).VirtualSize()
+		packageFee := fee + w.fee(test.parent)
+		minFee := test.feeRate.FeeForVSize(vsize + parentVSize)
+		if packageFee < minFee {
+			t.Errorf("%s: package fee %v is below %v", test.name,
+				packageFee, minFee)
+		}
+		minRelay := policy.DefaultMinRelayFee
+		if fee < minRelay.FeeForVSize(vsize) {
+			t.Errorf("%s: child fee %v is below the minimum relay "+
+				"fee", test.name, fee)
+		}
+
+		// The fee shouldn't exceed what is needed by more than a few
+		// virtual bytes of estimation slack.
+		maxFee := test.feeRate.FeeForVSize(vsize + parentVSize + 4)
+		if fee > minRelay.FeeForVSize(vsize+4) && packageFee > maxFee {
+			t.Errorf("%s: package fee %v is above %v", test.name,
+				packageFee, maxFee)
+		}
+	}
+}
+
+// TestChildErrors tests the errors returned when a child can't be built.
+func TestChildErrors(t *testing.T) {
+	w := newWallet(1, false, 1e8)
+	_, parent := w.build(t, lowFeeRate, false, payment(200, 5e7))
+	orig, err := feebump.NewOriginal(monautil.NewTx(parent),
+		w.PrevOuts(parent))
+	if err != nil {
+		t.Fatalf("unable to create original: %v", err)
+	}
+
+	c := &feebump.CPFP{FeeRate: highFeeRate}
+	if _, err := c.Child(orig, 0); err != txbuilder.ErrNoChangeSource {
+		t.Errorf("got error %v, want %v", err,
+			txbuilder.ErrNoChangeSource)
+	}
+
+	c.ChangeSource = w.ChangeSource
+	if _, err := c.Child(orig, 2); err != feebump.ErrInvalidOutputIndex {
+		t.Errorf("got error %v, want %v", err,
+			feebump.ErrInvalidOutputIndex)
+	}
+}
@@ -0,0 +1,217 @@
+// Copyright (c) 2026 The monasuite developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package feebump
+
+import (
+	"errors"
+
+	"github.com/monasuite/monad/chaincfg/chainhash"
+	"github.com/monasuite/monad/wire"
+	"github.com/monasuite/monautil"
+	"github.com/monasuite/monautil/coinset"
+	"github.com/monasuite/monautil/psbt"
+	"github.com/monasuite/monautil/psbt/txbuilder"
+)
+
+var (
+	// ErrPrevOutsMismatch describes an error in which the number of
+	// previous outputs doesn't match the number of inputs of a
+	// transaction.
+	ErrPrevOutsMismatch = errors.New("number of previous outputs doesn't " +
+		"match the inputs")
+
+	// ErrMissingUtxo describes an error in which an input of a packet
+	// carries neither a WitnessUtxo nor a NonWitnessUtxo.
+	ErrMissingUtxo = errors.New("input has no utxo information")
+)
+
+// Original is a transaction whose fee is to be bumped, along with the outputs
+// spent by its inputs.
+type Original struct {
+	// Tx is the transaction.  It may be signed or not.
+	Tx *monautil.Tx
+
+	// PrevOuts are the outputs spent by the inputs of the transaction, in
+	// the same order as the inputs.
+	PrevOuts []*wire.TxOut
+
+	// PrevTxs are the transactions of the outputs spent by the inputs, in
+	// the same order as the inputs.  They are used as the NonWitnessUtxo
+	// of the inputs of the bumped transactions, and are required for the
+	// inputs which aren't spent with witness data.  Entries may be nil for
+	// other inputs, and the slice may be nil if there are none.
+	PrevTxs []*wire.MsgTx
+
+	// pInputs and pOutputs are the input and output data of the packet
+	// the transaction was taken from, if any.
+	pInputs  []psbt.PInput
+	pOutputs []psbt.POutput
+}
+
+// NewOriginal returns the original transaction spending the passed outputs,
+// in the order of its inputs.
+func NewOriginal(tx *monautil.Tx, prevOuts []*wire.TxOut) (*Original, error) {
+	if len(prevOuts) != len(tx.MsgTx().TxIn) {
+		return nil, ErrPrevOutsMismatch
+	}
+
+	return &Original{Tx: tx, PrevOuts: prevOuts}, nil
+}
+
+// NewOriginalFromPacket returns the original transaction of a PSBT, taking the
+// spent outputs from the UTXO information of its inputs.  The signed
+// transaction is used when every input is finalized, and the unsigned one
+// otherwise.  The BIP 32 derivations and scripts of the inputs and outputs of
+// the packet are carried over to the bumped transactions.
+func NewOriginalFromPacket(p *psbt.Packet) (*Original, error) {
+	if err := psbt.VerifyInputOutputLen(p, true, true); err != nil {
+		return nil, err
+	}
+
+	o := &Original{
+		PrevOuts: make([]*wire.TxOut, len(p.Inputs)),
+		PrevTxs:  make([]*wire.MsgTx, len(p.Inputs)),
+		pInputs:  p.Inputs,
+		pOutputs: p.Outputs,
+	}
+	finalized := true
+	for i, pInput := range p.Inputs {
+		op := p.UnsignedTx.TxIn[i].PreviousOutPoint
+		switch {
+		case pInput.WitnessUtxo != nil:
+			o.PrevOuts[i] = pInput.WitnessUtxo
+
+		case pInput.NonWitnessUtxo != nil:
+			prevTx := pInput.NonWitnessUtxo
+			if prevTx.TxHash() != op.Hash ||
+				int(op.Index) >= len(prevTx.TxOut) {
+
+				return nil, psbt.ErrInvalidPrevOutNonWitnessTransaction
+			}
+			o.PrevOuts[i] = prevTx.TxOut[op.Index]
+
+		default:
+			return nil, ErrMissingUtxo
+		}
+		o.PrevTxs[i] = pInput.NonWitnessUtxo
+
+		if pInput.FinalScriptSig == nil &&
+			pInput.FinalScriptWitness == nil {
+
+			finalized = false
+		}
+	}
+
+	msgTx := p.UnsignedTx
+	if finalized {
+		signed, err := psbt.Extract(p)
+		if err != nil {
+			return nil, err
+		}
+		msgTx = signed
+	}
+	o.Tx = monautil.NewTx(msgTx)
+
+	return o, nil
+}
+
+// Fee returns the fee paid by the transaction.
+func (o *Original) Fee() monautil.Amount {
+	var fee monautil.Amount
+	for _, prevOut := range o.PrevOuts {
+		fee += monautil.Amount(prevOut.Value)
+	}
+	for _, txOut := range o.Tx.MsgTx().TxOut {
+		fee -= monautil.Amount(txOut.Value)
+	}
+	return fee
+}
+
+// virtualSize returns the virtual size of the transaction.  The size of
+// transactions with unsigned inputs is estimated from the weights of their
+// inputs.
+func (o *Original) virtualSize(inputWeight coinset.InputWeightFunc) int64 {
+	msgTx := o.Tx.MsgTx()
+	for _, txIn := range msgTx.TxIn {
+		if len(txIn.SignatureScript) == 0 && len(txIn.Witness) == 0 {
+			weight := txbuilder.EstimateWeight(o.coins(),
+				msgTx.TxOut, -1, inputWeight)
+			return monautil.WeightToVSize(weight)
+		}
+	}
+	return o.Tx.VirtualSize()
+}
+
+// coins returns the outputs spent by the transaction as coins.
+func (o *Original) coins() []coinset.Coin {
+	msgTx := o.Tx.MsgTx()
+	coins := make([]coinset.Coin, len(msgTx.TxIn))
+	for i, txIn := range msgTx.TxIn {
+		// Inputs are known to be spent with witness data if they have
+		// a witness, or if the packet they were taken from only has
+		// their WitnessUtxo.
+		witness := len(txIn.Witness) > 0 ||
+			i < len(o.pInputs) && o.pInputs[i].WitnessUtxo != nil &&
+				o.pInputs[i].NonWitnessUtxo == nil
+		coins[i] = &prevOutCoin{
+			outPoint: txIn.PreviousOutPoint,
+			txOut:    o.PrevOuts[i],
+			prevTx:   o.prevTx(i),
+			witness:  witness,
+		}
+	}
+	return coins
+}
+
+// prevTx returns the transaction of the output spent by the input at the
+// passed index, or nil if it is unknown.
+func (o *Original) prevTx(i int) *wire.MsgTx {
+	if i < len(o.PrevTxs) {
+		return o.PrevTxs[i]
+	}
+	return nil
+}
+
+// prevOutCoin is an output spent by an original transaction, as a coin.  The
+// witness flag is set if the output is known to be spent with witness data.
+type prevOutCoin struct {
+	outPoint wire.OutPoint
+	txOut    *wire.TxOut
+	prevTx   *wire.MsgTx
+	witness  bool
+}
+
+// Ensure that prevOutCoin is a coinset.Coin.
+var _ coinset.Coin = (*prevOutCoin)(nil)
+
+// Hash returns the hash of the transaction of the output.
+func (c *prevOutCoin) Hash() *chainhash.Hash {
+	return &c.outPoint.Hash
+}
+
+// Index returns the index of the output in its transaction.
+func (c *prevOutCoin) Index() uint32 {
+	return c.outPoint.Index
+}
+
+// Value returns the value of the output.
+func (c *prevOutCoin) Value() monautil.Amount {
+	return monautil.Amount(c.txOut.Value)
+}
+
+// PkScript returns the public key script of the output.
+func (c *prevOutCoin) PkScript() []byte {
+	return c.txOut.PkScript
+}
+
+// NumConfs returns zero, as the confirmations of the output are unknown.
+func (c *prevOutCoin) NumConfs() int64 {
+	return 0
+}
+
+// ValueAge returns zero, as the confirmations of the output are unknown.
+func (c *prevOutCoin) ValueAge() int64 {
+	return 0
+}
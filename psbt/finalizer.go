@@ -8,10 +8,13 @@ package psbt
 // in which all necessary signatures are encoded, and
 // uses it to construct valid final sigScript and scriptWitness
 // fields.
-// NOTE that p2sh (legacy) and p2wsh currently support only
-// multisig and no other custom script.
+// NOTE that out of the box p2sh (legacy) and p2wsh support only
+// multisig; other scripts require a ScriptFinalizer to be registered
+// with RegisterFinalizer.
 
 import (
+	"bytes"
+
 	"github.com/monasuite/monad/txscript"
 )
 
@@ -102,15 +105,12 @@ func isFinalizableLegacyInput(p *Packet, pInput *PInput, inIndex int) bool {
 
 // isFinalizable checks whether the structure of the entry for the input of the
 // psbt.Packet at index inIndex contains sufficient information to finalize
-// this input.
+// this input.  Whether the signatures and other data satisfy the script is up
+// to the ScriptFinalizer matching it, as some scripts can be spent without any
+// signature.
 func isFinalizable(p *Packet, inIndex int) bool {
 	pInput := p.Inputs[inIndex]
 
-	// The input cannot be finalized without any signatures
-	if pInput.PartialSigs == nil {
-		return false
-	}
-
 	// For an input to be finalized, we'll one of two possible top-level
 	// UTXOs present. Each UTXO type has a distinct set of requirements to
 	// be considered finalized.
@@ -233,81 +233,34 @@ func finalizeNonWitnessInput(p *Packet, inIndex int) error {
 		return ErrInputAlreadyFinalized
 	}
 
-	// Our goal here is to construct a sigScript given the pubkey,
-	// signature (keytype 02), of which there might be multiple, and the
-	// redeem script field (keytype 04) if present (note, it is not present
-	// for p2pkh type inputs).
-	var sigScript []byte
-
+	// Our goal here is to construct a sigScript that satisfies either the
+	// redeem script field (keytype 04) if present, or the output script
+	// being spent otherwise (e.g. for p2pkh type inputs).
 	pInput := p.Inputs[inIndex]
-	containsRedeemScript := pInput.RedeemScript != nil
-
-	var (
-		pubKeys [][]byte
-		sigs    [][]byte
-	)
-	for _, ps := range pInput.PartialSigs {
-		pubKeys = append(pubKeys, ps.PubKey)
-
-		sigOK := checkSigHashFlags(ps.Signature, &pInput)
-		if !sigOK {
-			return ErrInvalidSigHashFlags
-		}
-
-		sigs = append(sigs, ps.Signature)
+	script := pInput.RedeemScript
+	if script == nil {
+		outIndex := p.UnsignedTx.TxIn[inIndex].PreviousOutPoint.Index
+		script = pInput.NonWitnessUtxo.TxOut[outIndex].PkScript
 	}
 
-	// We have failed to identify at least 1 (sig, pub) pair in the PSBT,
-	// which indicates it was not ready to be finalized. As a result, we
-	// can't proceed.
-	if len(sigs) < 1 || len(pubKeys) < 1 {
-		return ErrNotFinalizable
+	stack, err := buildFinalStack(&pInput, script)
+	if err != nil {
+		return err
 	}
 
-	// If this input doesn't need a redeem script (P2PKH), then we'll
-	// construct a simple sigScript that's just the signature then the
-	// pubkey (OP_CHECKSIG).
-	var err error
-	if !containsRedeemScript {
-		// At this point, we should only have a single signature and
-		// pubkey.
-		if len(sigs) != 1 || len(pubKeys) != 1 {
-			return ErrNotFinalizable
-		}
-
-		// In this case, our sigScript is just: <sig> <pubkey>.
-		builder := txscript.NewScriptBuilder()
-		builder.AddData(sigs[0]).AddData(pubKeys[0])
-		sigScript, err = builder.Script()
-		if err != nil {
-			return err
-		}
-	} else {
-		// This is assumed p2sh multisig Given redeemScript and pubKeys
-		// we can decide in what order signatures must be appended.
-		orderedSigs, err := extractKeyOrderFromScript(
-			pInput.RedeemScript, pubKeys, sigs,
-		)
-		if err != nil {
-			return err
-		}
-
-		// At this point, we assume that this is a mult-sig input, so
-		// we construct our sigScript which looks something like this
-		// (mind the extra element for the extra multi-sig pop):
-		//  * <nil> <sigs...> <redeemScript>
-		//
-		// TODO(waxwing): the below is specific to the multisig case.
-		builder := txscript.NewScriptBuilder()
-		builder.AddOp(txscript.OP_FALSE)
-		for _, os := range orderedSigs {
-			builder.AddData(os)
-		}
+	// The sigScript pushes the stack items in order, followed by the
+	// redeem script for p2sh inputs:
+	//  * <items...> [redeemScript]
+	builder := txscript.NewScriptBuilder()
+	for _, item := range stack {
+		builder.AddData(item)
+	}
+	if pInput.RedeemScript != nil {
 		builder.AddData(pInput.RedeemScript)
-		sigScript, err = builder.Script()
-		if err != nil {
-			return err
-		}
+	}
+	sigScript, err := builder.Script()
+	if err != nil {
+		return err
 	}
 
 	// At this point, a sigScript has been constructed.  Remove all fields
@@ -336,113 +289,49 @@ func finalizeWitnessInput(p *Packet, inIndex int) error {
 
 	// Depending on the actual output type, we'll either populate a
 	// serializedWitness or a witness as well asa sigScript.
-	var (
-		sigScript         []byte
-		serializedWitness []byte
-	)
+	var sigScript []byte
 
 	pInput := p.Inputs[inIndex]
+	script := pInput.WitnessUtxo.PkScript
 
-	// First we'll validate and collect the pubkey+sig pairs from the set
-	// of partial signatures.
-	var (
-		pubKeys [][]byte
-		sigs    [][]byte
-	)
-	for _, ps := range pInput.PartialSigs {
-		pubKeys = append(pubKeys, ps.PubKey)
-
-		sigOK := checkSigHashFlags(ps.Signature, &pInput)
-		if !sigOK {
-			return ErrInvalidSigHashFlags
-
-		}
-
-		sigs = append(sigs, ps.Signature)
-	}
+	// If there's a redeem script, then this is either a p2wsh or a p2wkh
+	// output nested in a p2sh.  In this case, we'll take the redeem script
+	// (the witness program in this case), and push it on the stack within
+	// the sigScript.
+	if pInput.RedeemScript != nil {
+		script = pInput.RedeemScript
 
-	// If at this point, we don't have any pubkey+sig pairs, then we bail
-	// as we can't proceed.
-	if len(sigs) == 0 || len(pubKeys) == 0 {
-		return ErrNotFinalizable
-	}
-
-	containsRedeemScript := pInput.RedeemScript != nil
-	cointainsWitnessScript := pInput.WitnessScript != nil
-
-	// If there's no redeem script, then we assume that this is native
-	// segwit input.
-	var err error
-	if !containsRedeemScript {
-		// If we have only a sigley pubkey+sig pair, and no witness
-		// script, then we assume this is a P2WKH input.
-		if len(pubKeys) == 1 && len(sigs) == 1 &&
-			!cointainsWitnessScript {
-
-			serializedWitness, err = writePKHWitness(
-				sigs[0], pubKeys[0],
-			)
-			if err != nil {
-				return err
-			}
-		} else {
-			// Otherwise, we must have a witnessScript field, so
-			// we'll generate a valid multi-sig witness.
-			//
-			// NOTE: We tacitly assume multisig.
-			//
-			// TODO(roasbeef): need to add custom finalize for
-			// non-multisig P2WSH outputs (HTLCs, delay outputs,
-			// etc).
-			if !cointainsWitnessScript {
-				return ErrNotFinalizable
-			}
-
-			serializedWitness, err = getMultisigScriptWitness(
-				pInput.WitnessScript, pubKeys, sigs,
-			)
-			if err != nil {
-				return err
-			}
-		}
-	} else {
-		// Otherwise, we assume that this is a p2wsh multi-sig output,
-		// which is nested in a p2sh, or a p2wkh nested in a p2sh.
-		//
-		// In this case, we'll take the redeem script (the witness
-		// program in this case), and push it on the stack within the
-		// sigScript.
 		builder := txscript.NewScriptBuilder()
 		builder.AddData(pInput.RedeemScript)
+
+		var err error
 		sigScript, err = builder.Script()
 		if err != nil {
 			return err
 		}
+	}
 
-		// If don't have a witness script, then we assume this is a
-		// nested p2wkh output.
-		if !cointainsWitnessScript {
-			// Assumed p2sh-p2wkh Here the witness is just (sig,
-			// pub) as for p2pkh case
-			if len(sigs) != 1 || len(pubKeys) != 1 {
-				return ErrNotFinalizable
-			}
+	// For p2wsh outputs, nested or not, the witness satisfies the witness
+	// script, which is then appended to the witness stack.  Otherwise we
+	// satisfy the witness program itself, which is only possible for
+	// p2wkh.
+	if pInput.WitnessScript != nil {
+		script = pInput.WitnessScript
+	}
 
-			serializedWitness, err = writePKHWitness(sigs[0], pubKeys[0])
-			if err != nil {
-				return err
-			}
+	stack, err := buildFinalStack(&pInput, script)
+	if err != nil {
+		return err
+	}
+	if pInput.WitnessScript != nil {
+		stack = append(stack, pInput.WitnessScript)
+	}
 
-		} else {
-			// Otherwise, we assume that this is a p2wsh multi-sig,
-			// so we generate the proper witness.
-			serializedWitness, err = getMultisigScriptWitness(
-				pInput.WitnessScript, pubKeys, sigs,
-			)
-			if err != nil {
-				return err
-			}
-		}
+	// Now that we have the full witness stack, we'll serialize it in the
+	// expected format.
+	var serializedWitness bytes.Buffer
+	if err := WriteTxWitness(&serializedWitness, stack); err != nil {
+		return err
 	}
 
 	// At this point, a witness has been constructed, and a sigScript (if
@@ -453,7 +342,7 @@ func finalizeWitnessInput(p *Packet, inIndex int) error {
 		newInput.FinalScriptSig = sigScript
 	}
 
-	newInput.FinalScriptWitness = serializedWitness
+	newInput.FinalScriptWitness = serializedWitness.Bytes()
 
 	// Finally, we overwrite the entry in the input list at the correct
 	// index.
@@ -5,5 +5,7 @@ go 1.13
 require (
 	github.com/davecgh/go-spew v1.1.1
 	github.com/monasuite/monad v0.22.1-beta
-	github.com/monasuite/monautil v1.1.1
+	github.com/monasuite/monautil v1.2.0
 )
+
+replace github.com/monasuite/monautil => ../
@@ -0,0 +1,154 @@
+// Copyright (c) 2026 The monasuite developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+// Package testwallet provides the wallet fixtures shared by the tests of the
+// packages building transactions.
+package testwallet
+
+import (
+	"bytes"
+	"errors"
+
+	"github.com/monasuite/monad/btcec"
+	"github.com/monasuite/monad/chaincfg"
+	"github.com/monasuite/monad/chaincfg/chainhash"
+	"github.com/monasuite/monad/wire"
+	"github.com/monasuite/monautil"
+	"github.com/monasuite/monautil/coinset"
+	"github.com/monasuite/monautil/psbt"
+	"github.com/monasuite/monautil/psbt/txbuilder"
+)
+
+// Net is the network of the addresses of the wallets.
+var Net = &chaincfg.MainNetParams
+
+// NewKey returns a private key derived deterministically from the passed
+// non-zero seed byte.
+func NewKey(seed byte) *btcec.PrivateKey {
+	b := make([]byte, 32)
+	b[31] = seed
+	key, _ := btcec.PrivKeyFromBytes(btcec.S256(), b)
+	return key
+}
+
+// P2WPKHAddress returns the P2WPKH address of the passed key.
+func P2WPKHAddress(key *btcec.PrivateKey) monautil.Address {
+	addr, _ := monautil.NewAddressWitnessPubKeyHash(
+		monautil.Hash160(key.PubKey().SerializeCompressed()), Net,
+	)
+	return addr
+}
+
+// P2PKHAddress returns the P2PKH address of the passed key.
+func P2PKHAddress(key *btcec.PrivateKey) monautil.Address {
+	addr, _ := monautil.NewAddressPubKeyHash(
+		monautil.Hash160(key.PubKey().SerializeCompressed()), Net,
+	)
+	return addr
+}
+
+// Payment returns a payment of the passed amount to the P2WPKH address of a
+// key derived from the passed seed.
+func Payment(seed byte, amount monautil.Amount) *txbuilder.Output {
+	return &txbuilder.Output{
+		Address: P2WPKHAddress(NewKey(seed)),
+		Amount:  amount,
+	}
+}
+
+// Wallet holds coins paying to the addresses of its keys, along with the
+// transactions creating them.  The change key receives the change of the
+// transactions built by the tests.
+type Wallet struct {
+	Keys      []*btcec.PrivateKey
+	ChangeKey *btcec.PrivateKey
+	Coins     []coinset.Coin
+	Txs       map[chainhash.Hash]*wire.MsgTx
+}
+
+// New returns a wallet with one confirmed coin of each passed value, paying
+// to the P2WPKH address of a key, or to its P2PKH address if legacy is set.
+// The non-zero seed derives the change key, and distinguishes the keys of
+// different wallets.
+func New(seed byte, legacy bool, values ...monautil.Amount) *Wallet {
+	w := &Wallet{
+		ChangeKey: NewKey(seed),
+		Txs:       make(map[chainhash.Hash]*wire.MsgTx),
+	}
+	w.Keys = append(w.Keys, w.ChangeKey)
+	for i, value := range values {
+		key := NewKey(seed + byte(i) + 1)
+		addr := P2WPKHAddress(key)
+		if legacy {
+			addr = P2PKHAddress(key)
+		}
+		pkScript, _ := monautil.PayToAddrScript(addr)
+
+		// Every coin is the second output of its own transaction.
+		msgTx := wire.NewMsgTx(wire.TxVersion)
+		prevOut := wire.OutPoint{Index: uint32(seed) + uint32(i)}
+		msgTx.AddTxIn(&wire.TxIn{PreviousOutPoint: prevOut})
+		msgTx.AddTxOut(wire.NewTxOut(1000, pkScript))
+		msgTx.AddTxOut(wire.NewTxOut(int64(value), pkScript))
+
+		w.Keys = append(w.Keys, key)
+		w.Txs[msgTx.TxHash()] = msgTx
+		w.Coins = append(w.Coins, &coinset.SimpleCoin{
+			Tx:         monautil.NewTx(msgTx),
+			TxIndex:    1,
+			TxNumConfs: 6,
+		})
+	}
+	return w
+}
+
+// PrevTx looks up the transactions of the wallet.
+func (w *Wallet) PrevTx(hash *chainhash.Hash) (*wire.MsgTx, error) {
+	msgTx, ok := w.Txs[*hash]
+	if !ok {
+		return nil, errors.New("unknown transaction")
+	}
+	return msgTx, nil
+}
+
+// ChangeSource returns the address of the change key of the wallet.
+func (w *Wallet) ChangeSource() (monautil.Address, error) {
+	return P2WPKHAddress(w.ChangeKey), nil
+}
+
+// PrevOuts returns the outputs spent by the inputs of the transaction, which
+// must all be transactions of the wallet.
+func (w *Wallet) PrevOuts(msgTx *wire.MsgTx) []*wire.TxOut {
+	prevOuts := make([]*wire.TxOut, len(msgTx.TxIn))
+	for i, txIn := range msgTx.TxIn {
+		op := txIn.PreviousOutPoint
+		prevOuts[i] = w.Txs[op.Hash].TxOut[op.Index]
+	}
+	return prevOuts
+}
+
+// Sign signs a copy of the packet with the keys of the wallet and returns the
+// final transaction, leaving the packet untouched.
+func (w *Wallet) Sign(p *psbt.Packet) (*wire.MsgTx, error) {
+	var buf bytes.Buffer
+	if err := p.Serialize(&buf); err != nil {
+		return nil, err
+	}
+	p, err := psbt.NewFromRawBytes(&buf, false)
+	if err != nil {
+		return nil, err
+	}
+
+	signer, err := psbt.NewKeySigner(nil, w.Keys)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := signer.Sign(p); err != nil {
+		return nil, err
+	}
+	if err := psbt.MaybeFinalizeAll(p); err != nil {
+		return nil, err
+	}
+	return psbt.Extract(p)
+}
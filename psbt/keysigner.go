@@ -0,0 +1,403 @@
+// Copyright (c) 2026 The monasuite developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package psbt
+
+// The KeySigner builds on the Signer role: rather than being handed finished
+// signatures, it holds private keys, works out which inputs they can sign by
+// looking at the BIP32 derivations and scripts of each input, computes the
+// legacy or BIP143 signature hash and adds the resulting partial signatures
+// through Updater.Sign.
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"errors"
+
+	"github.com/monasuite/monad/btcec"
+	"github.com/monasuite/monad/txscript"
+	"github.com/monasuite/monad/wire"
+	"github.com/monasuite/monautil"
+	"github.com/monasuite/monautil/hdkeychain"
+)
+
+// SignNoMatchingKey indicates that none of the keys of a KeySigner could sign
+// the input, so no signature was attached.
+const SignNoMatchingKey = 2
+
+var (
+	// ErrMissingUtxo indicates that an input can't be signed or analyzed
+	// because neither its NonWitnessUtxo nor its WitnessUtxo is known.
+	ErrMissingUtxo = errors.New("PSBT input is missing its UTXO")
+
+	// ErrNotPrivateKey indicates that an extended key passed to a
+	// KeySigner is a public key.
+	ErrNotPrivateKey = errors.New("extended key is not a private key")
+
+	// ErrRedeemScriptMismatch indicates that an input spending a P2SH
+	// output has no redeem script or one that doesn't hash to the output,
+	// or that an input carries a redeem script but doesn't spend a P2SH
+	// output.
+	ErrRedeemScriptMismatch = errors.New("redeem script does not match " +
+		"the output being spent")
+
+	// ErrWitnessScriptMismatch indicates that an input spending a P2WSH
+	// program has no witness script or one that doesn't hash to the
+	// program, or that an input carries a witness script but doesn't
+	// spend a P2WSH program.
+	ErrWitnessScriptMismatch = errors.New("witness script does not match " +
+		"the output being spent")
+)
+
+// InputSignReport describes what KeySigner.Sign did with a single input.
+type InputSignReport struct {
+	// Index is the index of the input within the packet.
+	Index int
+
+	// Outcome is SignSuccesful if at least one signature was added,
+	// SignFinalized if the input was already finalized, SignNoMatchingKey
+	// if none of the keys belong to the input or all of them have signed
+	// it already, and SignInvalid if signing failed, in which case Err is
+	// set.
+	Outcome SignOutcome
+
+	// Signed holds the public keys a partial signature was added for.
+	Signed [][]byte
+
+	// AlreadySigned holds the public keys the signer holds but skipped,
+	// since the input already carries a partial signature for them.
+	AlreadySigned [][]byte
+
+	// Err is the reason signing the input failed.
+	Err error
+}
+
+// signingKey is a private key a KeySigner found for an input, together with
+// the serialization of the public key it is known by in the packet.
+type signingKey struct {
+	privKey *btcec.PrivateKey
+	pubKey  []byte
+}
+
+// KeySigner signs PSBT inputs with the private keys it holds.  Keys are
+// either extended master keys, which are matched against the BIP32
+// derivations of an input by their fingerprint and derived along the
+// recorded path, or plain private keys, which are matched against both the
+// derivations and the public keys and public key hashes in the scripts of an
+// input.
+type KeySigner struct {
+	roots []*hdkeychain.ExtendedKey
+	keys  []*btcec.PrivateKey
+}
+
+// NewKeySigner returns a KeySigner holding the passed extended master keys and
+// plain private keys.  Either list may be empty.  An error is returned if any
+// of the extended keys is public.
+func NewKeySigner(roots []*hdkeychain.ExtendedKey,
+	keys []*btcec.PrivateKey) (*KeySigner, error) {
+
+	for _, root := range roots {
+		if !root.IsPrivate() {
+			return nil, ErrNotPrivateKey
+		}
+	}
+
+	return &KeySigner{roots: roots, keys: keys}, nil
+}
+
+// Sign adds a partial signature to every input of the packet that one of the
+// signer's keys can sign, and returns a report per input.  The signature
+// hash type of an input is taken from its SighashType field, defaulting to
+// SIGHASH_ALL.  An error is only returned if the packet itself is malformed;
+// failures to sign single inputs are recorded in their reports instead.
+func (s *KeySigner) Sign(p *Packet) ([]InputSignReport, error) {
+	if err := VerifyInputOutputLen(p, true, false); err != nil {
+		return nil, err
+	}
+	u, err := NewUpdater(p)
+	if err != nil {
+		return nil, err
+	}
+
+	sigHashes := txscript.NewTxSigHashes(p.UnsignedTx)
+	reports := make([]InputSignReport, len(p.Inputs))
+	for i := range p.Inputs {
+		reports[i] = s.signInput(u, i, sigHashes)
+	}
+
+	return reports, nil
+}
+
+// signInput signs the input at the given index with every matching key that
+// hasn't signed it yet.
+func (s *KeySigner) signInput(u *Updater, inIndex int,
+	sigHashes *txscript.TxSigHashes) InputSignReport {
+
+	report := InputSignReport{Index: inIndex}
+	if isFinalized(u.Upsbt, inIndex) {
+		report.Outcome = SignFinalized
+		return report
+	}
+
+	pInput := &u.Upsbt.Inputs[inIndex]
+	txIn := u.Upsbt.UnsignedTx.TxIn[inIndex]
+
+	// Find the output being spent, which determines how the input is
+	// signed.
+	utxo := pInput.WitnessUtxo
+	if utxo == nil && pInput.NonWitnessUtxo != nil {
+		outIndex := txIn.PreviousOutPoint.Index
+		if int(outIndex) >= len(pInput.NonWitnessUtxo.TxOut) {
+			report.Outcome = SignInvalid
+			report.Err = ErrInvalidPrevOutNonWitnessTransaction
+			return report
+		}
+		utxo = pInput.NonWitnessUtxo.TxOut[outIndex]
+	}
+	if utxo == nil {
+		report.Outcome = SignInvalid
+		report.Err = ErrMissingUtxo
+		return report
+	}
+	if err := checkUtxo(pInput, txIn, utxo); err != nil {
+		report.Outcome = SignInvalid
+		report.Err = err
+		return report
+	}
+
+	// The script that is committed to by the signature is the witness
+	// script for P2WSH, the redeem script for P2SH and the output script
+	// otherwise.  A P2WPKH program is turned into the matching P2PKH
+	// script by the BIP143 signature hash.
+	script := utxo.PkScript
+	if pInput.RedeemScript != nil {
+		script = pInput.RedeemScript
+	}
+	witness := pInput.WitnessScript != nil ||
+		txscript.IsWitnessProgram(script)
+	if pInput.WitnessScript != nil {
+		script = pInput.WitnessScript
+	}
+
+	sigHashType := pInput.SighashType
+	if sigHashType == 0 {
+		sigHashType = txscript.SigHashAll
+	}
+
+	keys := s.matchKeys(pInput, script)
+	if len(keys) == 0 {
+		report.Outcome = SignNoMatchingKey
+		return report
+	}
+
+	for _, key := range keys {
+		if hasPartialSig(pInput, key.pubKey) {
+			report.AlreadySigned = append(
+				report.AlreadySigned, key.pubKey,
+			)
+			continue
+		}
+
+		var (
+			sig []byte
+			err error
+		)
+		if witness {
+			sig, err = txscript.RawTxInWitnessSignature(
+				u.Upsbt.UnsignedTx, sigHashes, inIndex,
+				utxo.Value, script, sigHashType, key.privKey,
+			)
+		} else {
+			sig, err = txscript.RawTxInSignature(
+				u.Upsbt.UnsignedTx, inIndex, script,
+				sigHashType, key.privKey,
+			)
+		}
+		if err == nil {
+			_, err = u.Sign(inIndex, sig, key.pubKey, nil, nil)
+		}
+		if err != nil {
+			report.Outcome = SignInvalid
+			report.Err = err
+			return report
+		}
+
+		report.Signed = append(report.Signed, key.pubKey)
+	}
+
+	if len(report.Signed) > 0 {
+		report.Outcome = SignSuccesful
+	} else {
+		report.Outcome = SignNoMatchingKey
+	}
+
+	return report
+}
+
+// checkUtxo verifies that the UTXO information and scripts of the input
+// describe the output it spends, before anything is signed.  The
+// NonWitnessUtxo must be the transaction of the outpoint, and the redeem and
+// witness scripts must hash to the P2SH and P2WSH outputs spent.
+func checkUtxo(pInput *PInput, txIn *wire.TxIn, utxo *wire.TxOut) error {
+	if pInput.NonWitnessUtxo != nil &&
+		pInput.NonWitnessUtxo.TxHash() != txIn.PreviousOutPoint.Hash {
+
+		return ErrInvalidPrevOutNonWitnessTransaction
+	}
+
+	script := utxo.PkScript
+	switch {
+	case txscript.IsPayToScriptHash(script):
+		if pInput.RedeemScript == nil || !bytes.Equal(
+			script[2:22], monautil.Hash160(pInput.RedeemScript),
+		) {
+
+			return ErrRedeemScriptMismatch
+		}
+		script = pInput.RedeemScript
+
+	case pInput.RedeemScript != nil:
+		return ErrRedeemScriptMismatch
+	}
+
+	switch {
+	case txscript.IsPayToWitnessScriptHash(script):
+		scriptHash := sha256.Sum256(pInput.WitnessScript)
+		if pInput.WitnessScript == nil ||
+			!bytes.Equal(script[2:], scriptHash[:]) {
+
+			return ErrWitnessScriptMismatch
+		}
+
+	case pInput.WitnessScript != nil:
+		return ErrWitnessScriptMismatch
+	}
+
+	return nil
+}
+
+// scriptKeys returns the public keys and public key hashes the script pays
+// to if it is a P2PK, P2PKH, P2WPKH or bare multisig script.
+func scriptKeys(script []byte) ([][]byte, [][]byte) {
+	switch txscript.GetScriptClass(script) {
+	case txscript.PubKeyTy, txscript.MultiSigTy:
+		pubKeys, err := txscript.PushedData(script)
+		if err != nil {
+			return nil, nil
+		}
+		return pubKeys, nil
+
+	case txscript.PubKeyHashTy:
+		return nil, [][]byte{script[3:23]}
+
+	case txscript.WitnessV0PubKeyHashTy:
+		return nil, [][]byte{script[2:]}
+	}
+
+	return nil, nil
+}
+
+// containsBytes returns true if the list holds the passed bytes.
+func containsBytes(list [][]byte, b []byte) bool {
+	for _, item := range list {
+		if bytes.Equal(item, b) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// matchKeys returns the keys of the signer that belong to the input, each
+// only once.  Extended keys are derived along the BIP32 derivations whose
+// master fingerprint they match, while plain keys must appear in the
+// derivations or be paid to by the script being signed, either directly or
+// hashed, which must be a P2PK, P2PKH, P2WPKH or bare multisig script.
+func (s *KeySigner) matchKeys(pInput *PInput, script []byte) []signingKey {
+	var keys []signingKey
+	addKey := func(privKey *btcec.PrivateKey, pubKey []byte) {
+		for _, k := range keys {
+			if bytes.Equal(k.pubKey, pubKey) {
+				return
+			}
+		}
+		keys = append(keys, signingKey{privKey: privKey, pubKey: pubKey})
+	}
+
+	for _, derivation := range pInput.Bip32Derivation {
+		for _, root := range s.roots {
+			if root.Fingerprint() != derivation.MasterKeyFingerprint {
+				continue
+			}
+
+			privKey, ok := deriveMatching(root, derivation)
+			if ok {
+				addKey(privKey, derivation.PubKey)
+			}
+		}
+	}
+
+	scriptPubKeys, scriptHashes := scriptKeys(script)
+	for _, privKey := range s.keys {
+		pub := privKey.PubKey()
+		for _, pubKey := range [][]byte{
+			pub.SerializeCompressed(), pub.SerializeUncompressed(),
+		} {
+			for _, derivation := range pInput.Bip32Derivation {
+				if bytes.Equal(derivation.PubKey, pubKey) {
+					addKey(privKey, pubKey)
+				}
+			}
+			if containsBytes(scriptPubKeys, pubKey) || containsBytes(
+				scriptHashes, monautil.Hash160(pubKey),
+			) {
+
+				addKey(privKey, pubKey)
+			}
+		}
+	}
+
+	return keys
+}
+
+// deriveMatching derives the private key of the passed derivation from root
+// and returns it if its public key matches the one of the derivation.
+func deriveMatching(root *hdkeychain.ExtendedKey,
+	derivation *Bip32Derivation) (*btcec.PrivateKey, bool) {
+
+	key := root
+	for _, index := range derivation.Bip32Path {
+		var err error
+		key, err = key.Derive(index)
+		if err != nil {
+			return nil, false
+		}
+	}
+
+	privKey, err := key.ECPrivKey()
+	if err != nil {
+		return nil, false
+	}
+
+	pub := privKey.PubKey()
+	if !bytes.Equal(pub.SerializeCompressed(), derivation.PubKey) &&
+		!bytes.Equal(pub.SerializeUncompressed(), derivation.PubKey) {
+
+		return nil, false
+	}
+
+	return privKey, true
+}
+
+// hasPartialSig returns true if the input already carries a partial signature
+// for the passed public key.
+func hasPartialSig(pInput *PInput, pubKey []byte) bool {
+	for _, sig := range pInput.PartialSigs {
+		if bytes.Equal(sig.PubKey, pubKey) {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,349 @@
+// Copyright (c) 2026 The monasuite developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package psbt
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/monasuite/monad/btcec"
+	"github.com/monasuite/monad/chaincfg"
+	"github.com/monasuite/monad/txscript"
+	"github.com/monasuite/monad/wire"
+	"github.com/monasuite/monautil"
+	"github.com/monasuite/monautil/hdkeychain"
+)
+
+func TestKeySigner(t *testing.T) {
+	seed := bytes.Repeat([]byte{0x24}, hdkeychain.RecommendedSeedLen)
+	master, err := hdkeychain.NewMaster(seed, &chaincfg.MainNetParams)
+	if err != nil {
+		t.Fatalf("unable to create master key: %v", err)
+	}
+	fingerprint := master.Fingerprint()
+
+	derive := func(path ...uint32) *btcec.PrivateKey {
+		key := master
+		for _, index := range path {
+			key, err = key.Derive(index)
+			if err != nil {
+				t.Fatalf("unable to derive key: %v", err)
+			}
+		}
+		privKey, err := key.ECPrivKey()
+		if err != nil {
+			t.Fatalf("unable to get private key: %v", err)
+		}
+		return privKey
+	}
+	p2wpkhKey := derive(0, 1)
+	p2pkhKey := derive(0, 2)
+	plainKey, err := btcec.NewPrivateKey(btcec.S256())
+	if err != nil {
+		t.Fatalf("unable to create key: %v", err)
+	}
+	otherKey, err := btcec.NewPrivateKey(btcec.S256())
+	if err != nil {
+		t.Fatalf("unable to create key: %v", err)
+	}
+
+	pubKeyOf := func(k *btcec.PrivateKey) []byte {
+		return k.PubKey().SerializeCompressed()
+	}
+	p2wpkhScript := func(k *btcec.PrivateKey) []byte {
+		script, err := txscript.NewScriptBuilder().
+			AddOp(txscript.OP_0).
+			AddData(monautil.Hash160(pubKeyOf(k))).
+			Script()
+		if err != nil {
+			t.Fatalf("unable to build script: %v", err)
+		}
+		return script
+	}
+	p2pkhScript, err := txscript.NewScriptBuilder().
+		AddOp(txscript.OP_DUP).
+		AddOp(txscript.OP_HASH160).
+		AddData(monautil.Hash160(pubKeyOf(p2pkhKey))).
+		AddOp(txscript.OP_EQUALVERIFY).
+		AddOp(txscript.OP_CHECKSIG).
+		Script()
+	if err != nil {
+		t.Fatalf("unable to build script: %v", err)
+	}
+
+	// The transaction funding the inputs of the packet: one output per
+	// input, the last of which belongs to a key the signer doesn't have.
+	prevTx := wire.NewMsgTx(2)
+	prevTx.AddTxIn(&wire.TxIn{})
+	prevTx.AddTxOut(wire.NewTxOut(10000, p2wpkhScript(p2wpkhKey)))
+	prevTx.AddTxOut(wire.NewTxOut(20000, p2pkhScript))
+	prevTx.AddTxOut(wire.NewTxOut(30000, p2wpkhScript(plainKey)))
+	prevTx.AddTxOut(wire.NewTxOut(40000, p2wpkhScript(otherKey)))
+	prevHash := prevTx.TxHash()
+
+	var inputs []*wire.OutPoint
+	var sequences []uint32
+	for i := range prevTx.TxOut {
+		inputs = append(inputs, wire.NewOutPoint(&prevHash, uint32(i)))
+		sequences = append(sequences, wire.MaxTxInSequenceNum)
+	}
+	packet, err := New(
+		inputs, []*wire.TxOut{wire.NewTxOut(90000, p2pkhScript)}, 2,
+		0, sequences,
+	)
+	if err != nil {
+		t.Fatalf("unable to create packet: %v", err)
+	}
+
+	packet.Inputs[0].WitnessUtxo = prevTx.TxOut[0]
+	packet.Inputs[0].Bip32Derivation = []*Bip32Derivation{{
+		PubKey:               pubKeyOf(p2wpkhKey),
+		MasterKeyFingerprint: fingerprint,
+		Bip32Path:            []uint32{0, 1},
+	}}
+	packet.Inputs[1].NonWitnessUtxo = prevTx
+	packet.Inputs[1].Bip32Derivation = []*Bip32Derivation{{
+		PubKey:               pubKeyOf(p2pkhKey),
+		MasterKeyFingerprint: fingerprint,
+		Bip32Path:            []uint32{0, 2},
+	}}
+	packet.Inputs[2].WitnessUtxo = prevTx.TxOut[2]
+	packet.Inputs[2].SighashType = txscript.SigHashSingle
+	packet.Inputs[3].WitnessUtxo = prevTx.TxOut[3]
+
+	signer, err := NewKeySigner(
+		[]*hdkeychain.ExtendedKey{master},
+		[]*btcec.PrivateKey{plainKey},
+	)
+	if err != nil {
+		t.Fatalf("unable to create signer: %v", err)
+	}
+	reports, err := signer.Sign(packet)
+	if err != nil {
+		t.Fatalf("unable to sign packet: %v", err)
+	}
+
+	expected := []struct {
+		outcome SignOutcome
+		signed  []byte
+	}{
+		{SignSuccesful, pubKeyOf(p2wpkhKey)},
+		{SignSuccesful, pubKeyOf(p2pkhKey)},
+		{SignSuccesful, pubKeyOf(plainKey)},
+		{SignNoMatchingKey, nil},
+	}
+	for i, exp := range expected {
+		report := reports[i]
+		if report.Index != i || report.Outcome != exp.outcome ||
+			report.Err != nil {
+
+			t.Fatalf("input %d: unexpected report %+v", i, report)
+		}
+		if exp.signed == nil {
+			continue
+		}
+		if len(report.Signed) != 1 ||
+			!bytes.Equal(report.Signed[0], exp.signed) {
+
+			t.Fatalf("input %d: unexpected signed keys %x", i,
+				report.Signed)
+		}
+	}
+
+	sig := packet.Inputs[2].PartialSigs[0].Signature
+	if txscript.SigHashType(sig[len(sig)-1]) != txscript.SigHashSingle {
+		t.Fatalf("input 2 was not signed with its sighash type")
+	}
+
+	// Signing a second time skips the keys that already signed.
+	reports, err = signer.Sign(packet)
+	if err != nil {
+		t.Fatalf("unable to sign packet: %v", err)
+	}
+	if reports[0].Outcome != SignNoMatchingKey ||
+		len(reports[0].AlreadySigned) != 1 {
+
+		t.Fatalf("unexpected report on second signing: %+v",
+			reports[0])
+	}
+
+	// Once the last input is signed as well, the transaction must pass
+	// script validation.
+	otherSigner, err := NewKeySigner(nil, []*btcec.PrivateKey{otherKey})
+	if err != nil {
+		t.Fatalf("unable to create signer: %v", err)
+	}
+	if _, err := otherSigner.Sign(packet); err != nil {
+		t.Fatalf("unable to sign packet: %v", err)
+	}
+	if err := MaybeFinalizeAll(packet); err != nil {
+		t.Fatalf("unable to finalize packet: %v", err)
+	}
+	tx, err := Extract(packet)
+	if err != nil {
+		t.Fatalf("unable to extract tx: %v", err)
+	}
+
+	sigHashes := txscript.NewTxSigHashes(tx)
+	for i, prevOut := range prevTx.TxOut {
+		vm, err := txscript.NewEngine(
+			prevOut.PkScript, tx, i, txscript.StandardVerifyFlags,
+			nil, sigHashes, prevOut.Value,
+		)
+		if err != nil {
+			t.Fatalf("input %d: unable to create engine: %v", i, err)
+		}
+		if err := vm.Execute(); err != nil {
+			t.Fatalf("input %d: invalid signature: %v", i, err)
+		}
+	}
+}
+
+func TestKeySignerErrors(t *testing.T) {
+	seed := bytes.Repeat([]byte{0x24}, hdkeychain.RecommendedSeedLen)
+	master, err := hdkeychain.NewMaster(seed, &chaincfg.MainNetParams)
+	if err != nil {
+		t.Fatalf("unable to create master key: %v", err)
+	}
+	masterPub, err := master.Neuter()
+	if err != nil {
+		t.Fatalf("unable to neuter key: %v", err)
+	}
+
+	_, err = NewKeySigner([]*hdkeychain.ExtendedKey{masterPub}, nil)
+	if err != ErrNotPrivateKey {
+		t.Fatalf("expected ErrNotPrivateKey, got %v", err)
+	}
+
+	signer, err := NewKeySigner(
+		[]*hdkeychain.ExtendedKey{master}, nil,
+	)
+	if err != nil {
+		t.Fatalf("unable to create signer: %v", err)
+	}
+
+	// An input without UTXO information can't be signed.
+	packet := newTestXPubPacket(t)
+	reports, err := signer.Sign(packet)
+	if err != nil {
+		t.Fatalf("unable to sign packet: %v", err)
+	}
+	if reports[0].Outcome != SignInvalid ||
+		reports[0].Err != ErrMissingUtxo {
+
+		t.Fatalf("unexpected report %+v", reports[0])
+	}
+
+	// The UTXO information must describe the output being spent before
+	// anything is signed.
+	plainKey, err := btcec.NewPrivateKey(btcec.S256())
+	if err != nil {
+		t.Fatalf("unable to create key: %v", err)
+	}
+	plainSigner, err := NewKeySigner(nil, []*btcec.PrivateKey{plainKey})
+	if err != nil {
+		t.Fatalf("unable to create signer: %v", err)
+	}
+	pubKey := plainKey.PubKey().SerializeCompressed()
+	p2wpkhScript, err := txscript.NewScriptBuilder().
+		AddOp(txscript.OP_0).AddData(monautil.Hash160(pubKey)).Script()
+	if err != nil {
+		t.Fatalf("unable to build script: %v", err)
+	}
+	p2shScript := func(redeemScript []byte) []byte {
+		script, err := txscript.NewScriptBuilder().
+			AddOp(txscript.OP_HASH160).
+			AddData(monautil.Hash160(redeemScript)).
+			AddOp(txscript.OP_EQUAL).Script()
+		if err != nil {
+			t.Fatalf("unable to build script: %v", err)
+		}
+		return script
+	}
+
+	// The hash of the key is pushed by a script of no known template.
+	nonStandardScript, err := txscript.NewScriptBuilder().
+		AddData(monautil.Hash160(pubKey)).AddOp(txscript.OP_DROP).
+		AddOp(txscript.OP_TRUE).Script()
+	if err != nil {
+		t.Fatalf("unable to build script: %v", err)
+	}
+
+	otherTx := wire.NewMsgTx(2)
+	otherTx.AddTxOut(wire.NewTxOut(10000, p2wpkhScript))
+
+	tests := []struct {
+		name    string
+		pInput  PInput
+		outcome SignOutcome
+		err     error
+	}{
+		{
+			name:    "non-witness utxo of another transaction",
+			pInput:  PInput{NonWitnessUtxo: otherTx},
+			outcome: SignInvalid,
+			err:     ErrInvalidPrevOutNonWitnessTransaction,
+		},
+		{
+			name: "p2sh without redeem script",
+			pInput: PInput{WitnessUtxo: wire.NewTxOut(
+				10000, p2shScript(p2wpkhScript),
+			)},
+			outcome: SignInvalid,
+			err:     ErrRedeemScriptMismatch,
+		},
+		{
+			name: "p2sh with wrong redeem script",
+			pInput: PInput{
+				WitnessUtxo: wire.NewTxOut(
+					10000, p2shScript(nonStandardScript),
+				),
+				RedeemScript: p2wpkhScript,
+			},
+			outcome: SignInvalid,
+			err:     ErrRedeemScriptMismatch,
+		},
+		{
+			name: "redeem script without p2sh",
+			pInput: PInput{
+				WitnessUtxo:  wire.NewTxOut(10000, p2wpkhScript),
+				RedeemScript: p2wpkhScript,
+			},
+			outcome: SignInvalid,
+			err:     ErrRedeemScriptMismatch,
+		},
+		{
+			name: "witness script without p2wsh",
+			pInput: PInput{
+				WitnessUtxo:   wire.NewTxOut(10000, p2wpkhScript),
+				WitnessScript: p2wpkhScript,
+			},
+			outcome: SignInvalid,
+			err:     ErrWitnessScriptMismatch,
+		},
+		{
+			name: "key hash in nonstandard script",
+			pInput: PInput{WitnessUtxo: wire.NewTxOut(
+				10000, nonStandardScript,
+			)},
+			outcome: SignNoMatchingKey,
+		},
+	}
+
+	for _, test := range tests {
+		packet := newTestXPubPacket(t)
+		packet.Inputs[0] = test.pInput
+		reports, err := plainSigner.Sign(packet)
+		if err != nil {
+			t.Fatalf("%s: unable to sign packet: %v", test.name, err)
+		}
+		if reports[0].Outcome != test.outcome ||
+			reports[0].Err != test.err {
+
+			t.Fatalf("%s: unexpected report %+v", test.name,
+				reports[0])
+		}
+	}
+}
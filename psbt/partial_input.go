@@ -6,6 +6,7 @@ import (
 	"io"
 	"sort"
 
+	"github.com/monasuite/monad/chaincfg/chainhash"
 	"github.com/monasuite/monad/txscript"
 	"github.com/monasuite/monad/wire"
 )
@@ -22,7 +23,14 @@ type PInput struct {
 	Bip32Derivation    []*Bip32Derivation
 	FinalScriptSig     []byte
 	FinalScriptWitness []byte
-	Unknowns           []*Unknown
+
+	// RequiredTimeLocktime and RequiredHeightLocktime are the minimum
+	// time and height based locktimes this input requires in a version 2
+	// packet.  A value of zero means there is no such requirement.
+	RequiredTimeLocktime   uint32
+	RequiredHeightLocktime uint32
+
+	Unknowns []*Unknown
 }
 
 // NewPsbtInput creates an instance of PsbtInput given either a nonWitnessUtxo
@@ -61,7 +69,10 @@ func (pi *PInput) IsSane() bool {
 }
 
 // deserialize attempts to deserialize a new PInput from the passed io.Reader.
-func (pi *PInput) deserialize(r io.Reader) error {
+// For a version 2 packet txIn must be non-nil and receives the outpoint and
+// sequence described by the input; for a version 0 packet it must be nil.
+func (pi *PInput) deserialize(r io.Reader, txIn *wire.TxIn) error {
+	v2Fields := make(map[InputType]bool)
 	for {
 		keyint, keydata, err := getKey(r)
 		if err != nil {
@@ -78,7 +89,27 @@ func (pi *PInput) deserialize(r io.Reader) error {
 			return err
 		}
 
-		switch InputType(keyint) {
+		// Version 0 packets predate the version 2 fields, so their key
+		// types are kept as unknowns there like any other.
+		inputType := InputType(keyint)
+		if txIn != nil && isV2InputType(inputType) {
+			if v2Fields[inputType] {
+				return ErrDuplicateKey
+			}
+			if keydata != nil {
+				return ErrInvalidKeydata
+			}
+
+			err := pi.readV2Field(inputType, value, txIn)
+			if err != nil {
+				return err
+			}
+			v2Fields[inputType] = true
+
+			continue
+		}
+
+		switch inputType {
 
 		case NonWitnessUtxoType:
 			if pi.NonWitnessUtxo != nil {
@@ -230,11 +261,21 @@ func (pi *PInput) deserialize(r io.Reader) error {
 		}
 	}
 
+	// The outpoint being spent is mandatory in a version 2 packet.
+	if txIn != nil &&
+		(!v2Fields[PreviousTxidType] || !v2Fields[OutputIndexType]) {
+
+		return ErrInvalidPsbtFormat
+	}
+
 	return nil
 }
 
 // serialize attempts to serialize the target PInput into the passed io.Writer.
-func (pi *PInput) serialize(w io.Writer) error {
+// For a version 2 packet txIn is the corresponding transaction input, whose
+// outpoint and sequence are written along with the input; for a version 0
+// packet it is nil.
+func (pi *PInput) serialize(w io.Writer, txIn *wire.TxIn) error {
 
 	if !pi.IsSane() {
 		return ErrInvalidPsbtFormat
@@ -348,6 +389,12 @@ func (pi *PInput) serialize(w io.Writer) error {
 		}
 	}
 
+	if txIn != nil {
+		if err := pi.serializeV2Fields(w, txIn); err != nil {
+			return err
+		}
+	}
+
 	// Unknown is a special case; we don't have a key type, only a key and
 	// a value field
 	for _, kv := range pi.Unknowns {
@@ -359,3 +406,116 @@ func (pi *PInput) serialize(w io.Writer) error {
 
 	return nil
 }
+
+// isV2InputType returns true if the given input type is one of the fields
+// that describe the transaction input in a version 2 packet.
+func isV2InputType(inputType InputType) bool {
+	switch inputType {
+	case PreviousTxidType, OutputIndexType, SequenceType,
+		RequiredTimeLocktimeType, RequiredHeightLocktimeType:
+
+		return true
+	}
+
+	return false
+}
+
+// readV2Field parses the value of a version 2 input field into either the
+// passed transaction input or the locktime requirements of the PInput.
+func (pi *PInput) readV2Field(inputType InputType, value []byte,
+	txIn *wire.TxIn) error {
+
+	if inputType == PreviousTxidType {
+		if len(value) != chainhash.HashSize {
+			return ErrInvalidPsbtFormat
+		}
+		copy(txIn.PreviousOutPoint.Hash[:], value)
+
+		return nil
+	}
+
+	// All of the remaining fields are 32-bit unsigned integers.
+	if len(value) != 4 {
+		return ErrInvalidPsbtFormat
+	}
+	v := binary.LittleEndian.Uint32(value)
+
+	switch inputType {
+	case OutputIndexType:
+		txIn.PreviousOutPoint.Index = v
+
+	case SequenceType:
+		txIn.Sequence = v
+
+	case RequiredTimeLocktimeType:
+		if v < txscript.LockTimeThreshold {
+			return ErrInvalidLockTime
+		}
+		pi.RequiredTimeLocktime = v
+
+	case RequiredHeightLocktimeType:
+		if v == 0 || v >= txscript.LockTimeThreshold {
+			return ErrInvalidLockTime
+		}
+		pi.RequiredHeightLocktime = v
+	}
+
+	return nil
+}
+
+// serializeV2Fields writes out the fields that describe the transaction input
+// of a version 2 packet, along with the locktime requirements of the input.
+func (pi *PInput) serializeV2Fields(w io.Writer, txIn *wire.TxIn) error {
+	err := serializeKVPairWithType(
+		w, uint8(PreviousTxidType), nil, txIn.PreviousOutPoint.Hash[:],
+	)
+	if err != nil {
+		return err
+	}
+
+	var indexBytes [4]byte
+	binary.LittleEndian.PutUint32(indexBytes[:], txIn.PreviousOutPoint.Index)
+	err = serializeKVPairWithType(
+		w, uint8(OutputIndexType), nil, indexBytes[:],
+	)
+	if err != nil {
+		return err
+	}
+
+	// The sequence defaults to the maximum value when absent, so it's only
+	// written out if it differs.
+	if txIn.Sequence != wire.MaxTxInSequenceNum {
+		var seqBytes [4]byte
+		binary.LittleEndian.PutUint32(seqBytes[:], txIn.Sequence)
+		err := serializeKVPairWithType(
+			w, uint8(SequenceType), nil, seqBytes[:],
+		)
+		if err != nil {
+			return err
+		}
+	}
+
+	if pi.RequiredTimeLocktime != 0 {
+		var lockTime [4]byte
+		binary.LittleEndian.PutUint32(lockTime[:], pi.RequiredTimeLocktime)
+		err := serializeKVPairWithType(
+			w, uint8(RequiredTimeLocktimeType), nil, lockTime[:],
+		)
+		if err != nil {
+			return err
+		}
+	}
+
+	if pi.RequiredHeightLocktime != 0 {
+		var lockTime [4]byte
+		binary.LittleEndian.PutUint32(lockTime[:], pi.RequiredHeightLocktime)
+		err := serializeKVPairWithType(
+			w, uint8(RequiredHeightLocktimeType), nil, lockTime[:],
+		)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
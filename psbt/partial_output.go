@@ -2,6 +2,7 @@ package psbt
 
 import (
 	"bytes"
+	"encoding/binary"
 	"io"
 	"sort"
 
@@ -29,7 +30,11 @@ func NewPsbtOutput(redeemScript []byte, witnessScript []byte,
 }
 
 // deserialize attempts to recode a new POutput from the passed io.Reader.
-func (po *POutput) deserialize(r io.Reader) error {
+// For a version 2 packet txOut must be non-nil and receives the amount and
+// script described by the output; for a version 0 packet it must be nil, in
+// which case the version 2 fields are rejected.
+func (po *POutput) deserialize(r io.Reader, txOut *wire.TxOut) error {
+	var amountFound, scriptFound bool
 	for {
 		keyint, keydata, err := getKey(r)
 		if err != nil {
@@ -91,18 +96,56 @@ func (po *POutput) deserialize(r io.Reader) error {
 				},
 			)
 
+		case AmountOutputType:
+			if txOut == nil {
+				return ErrInvalidPsbtFormat
+			}
+			if amountFound {
+				return ErrDuplicateKey
+			}
+			if keydata != nil {
+				return ErrInvalidKeydata
+			}
+			if len(value) != 8 {
+				return ErrInvalidPsbtFormat
+			}
+
+			txOut.Value = int64(binary.LittleEndian.Uint64(value))
+			amountFound = true
+
+		case ScriptOutputType:
+			if txOut == nil {
+				return ErrInvalidPsbtFormat
+			}
+			if scriptFound {
+				return ErrDuplicateKey
+			}
+			if keydata != nil {
+				return ErrInvalidKeydata
+			}
+
+			txOut.PkScript = value
+			scriptFound = true
+
 		default:
 			// Unknown type is allowed for inputs but not outputs.
 			return ErrInvalidPsbtFormat
 		}
 	}
 
+	// Both the amount and the script are mandatory in a version 2 packet.
+	if txOut != nil && (!amountFound || !scriptFound) {
+		return ErrInvalidPsbtFormat
+	}
+
 	return nil
 }
 
 // serialize attempts to write out the target POutput into the passed
-// io.Writer.
-func (po *POutput) serialize(w io.Writer) error {
+// io.Writer.  For a version 2 packet txOut is the corresponding transaction
+// output, whose amount and script are written along with the output; for a
+// version 0 packet it is nil.
+func (po *POutput) serialize(w io.Writer, txOut *wire.TxOut) error {
 	if po.RedeemScript != nil {
 		err := serializeKVPairWithType(
 			w, uint8(RedeemScriptOutputType), nil, po.RedeemScript,
@@ -135,5 +178,23 @@ func (po *POutput) serialize(w io.Writer) error {
 		}
 	}
 
+	if txOut != nil {
+		var amount [8]byte
+		binary.LittleEndian.PutUint64(amount[:], uint64(txOut.Value))
+		err := serializeKVPairWithType(
+			w, uint8(AmountOutputType), nil, amount[:],
+		)
+		if err != nil {
+			return err
+		}
+
+		err = serializeKVPairWithType(
+			w, uint8(ScriptOutputType), nil, txOut.PkScript,
+		)
+		if err != nil {
+			return err
+		}
+	}
+
 	return nil
 }
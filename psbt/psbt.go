@@ -10,9 +10,10 @@ package psbt
 import (
 	"bytes"
 	"encoding/base64"
+	"encoding/binary"
 	"errors"
-
 	"io"
+	"sort"
 
 	"github.com/monasuite/monad/wire"
 )
@@ -37,6 +38,21 @@ const MaxPsbtValueLength = 4000000
 // deserialize from the wire. Anything more will return ErrInvalidKeydata.
 const MaxPsbtKeyLength = 10000
 
+// MaxPsbtVersion is the highest PSBT version number this package is able to
+// parse and serialize.
+const MaxPsbtVersion = 2
+
+const (
+	// PsbtVersion0 is the original PSBT version defined in BIP 174, which
+	// carries the whole unsigned transaction in the global section.
+	PsbtVersion0 = 0
+
+	// PsbtVersion2 is the PSBT version defined in BIP 370, in which the
+	// inputs and outputs carry their own transaction data so they can be
+	// added incrementally.
+	PsbtVersion2 = 2
+)
+
 var (
 
 	// ErrInvalidPsbtFormat is a generic error for any situation in which a
@@ -102,6 +118,37 @@ var (
 	// scriptwitness given is not supported by this codebase, or is otherwise
 	// not valid.
 	ErrUnsupportedScriptType = errors.New("Unsupported script type")
+
+	// ErrUnsupportedPsbtVersion indicates that the global version field of
+	// a PSBT specifies a version this package doesn't support.
+	ErrUnsupportedPsbtVersion = errors.New("Unsupported PSBT version")
+
+	// ErrInvalidLockTime indicates that the required locktimes of the
+	// inputs of a version 2 PSBT can't be satisfied by a single
+	// transaction locktime, or that a required locktime is out of range.
+	ErrInvalidLockTime = errors.New("Invalid PSBT locktime requirements")
+
+	// ErrInputsNotModifiable indicates that an input can't be added to a
+	// version 2 PSBT because its modifiable flags don't allow it.
+	ErrInputsNotModifiable = errors.New("PSBT inputs are not modifiable")
+
+	// ErrOutputsNotModifiable indicates that an output can't be added to a
+	// version 2 PSBT because its modifiable flags don't allow it.
+	ErrOutputsNotModifiable = errors.New("PSBT outputs are not modifiable")
+
+	// ErrDuplicateInput indicates that an input spending an outpoint that
+	// is already spent by another input of the PSBT was added.
+	ErrDuplicateInput = errors.New("PSBT already spends the outpoint")
+
+	// ErrInvalidXPub indicates that a global xpub field does not contain
+	// an extended public key whose depth matches its derivation path.
+	ErrInvalidXPub = errors.New("Invalid global xpub")
+
+	// ErrNotConvertible indicates that a version 2 PSBT can't be
+	// converted to version 0 because it sets fields with no version 0
+	// representation, which the conversion would drop.
+	ErrNotConvertible = errors.New("PSBT fields have no version 0 " +
+		"representation")
 )
 
 // Unknown is a struct encapsulating a key-value pair for which the key type is
@@ -120,6 +167,23 @@ type Packet struct {
 	// UnsignedTx is the decoded unsigned transaction for this PSBT.
 	UnsignedTx *wire.MsgTx // Deserialization of unsigned tx
 
+	// XPubs are the extended public keys of the wallet(s) that created
+	// this PSBT, along with their origin information.  Signers can use
+	// them to recognize inputs and outputs that belong to the wallet.
+	XPubs []*XPub
+
+	// Version is the PSBT version number of this packet.  A packet without
+	// a version field is version 0.
+	Version uint32
+
+	// FallbackLockTime is the locktime used for the transaction of a
+	// version 2 packet when none of its inputs require a locktime.
+	FallbackLockTime uint32
+
+	// TxModifiable holds the modification flags of a version 2 packet,
+	// which determine whether inputs and outputs may still be added.
+	TxModifiable TxModifiableFlags
+
 	// Inputs contains all the information needed to properly sign this
 	// target input within the above transaction.
 	Inputs []PInput
@@ -167,11 +231,256 @@ func NewFromUnsignedTx(tx *wire.MsgTx) (*Packet, error) {
 	return &retPsbt, nil
 }
 
+// supportedVersion returns true if the given PSBT version can be parsed and
+// serialized by this package.
+func supportedVersion(version uint32) bool {
+	return version == PsbtVersion0 || version == PsbtVersion2
+}
+
+// globalFields holds the global key-value pairs of a PSBT while it is being
+// parsed.  Since the fields may appear in any order, the PSBT version is only
+// known once the whole global section has been read.
+type globalFields struct {
+	unsignedTx       *wire.MsgTx
+	xPubs            []*XPub
+	version          uint32
+	versionFound     bool
+	txVersion        *int32
+	fallbackLockTime *uint32
+	inputCount       *uint64
+	outputCount      *uint64
+	txModifiable     *TxModifiableFlags
+	unknowns         []Unknown
+}
+
+// readGlobals parses the global section of a PSBT, up to and including the
+// separator.
+func readGlobals(r io.Reader) (*globalFields, error) {
+	var g globalFields
+	for {
+		keyint, keydata, err := getKey(r)
+		if err != nil {
+			return nil, ErrInvalidPsbtFormat
+		}
+		if keyint == -1 {
+			break
+		}
+
+		value, err := wire.ReadVarBytes(
+			r, 0, MaxPsbtValueLength, "PSBT value",
+		)
+		if err != nil {
+			return nil, err
+		}
+
+		switch GlobalType(keyint) {
+		case UnsignedTxType:
+			if g.unsignedTx != nil {
+				return nil, ErrDuplicateKey
+			}
+			if keydata != nil {
+				return nil, ErrInvalidKeydata
+			}
+
+			g.unsignedTx, err = readUnsignedTx(value)
+			if err != nil {
+				return nil, err
+			}
+
+		case XpubType:
+			xPub, err := readXPub(keydata, value)
+			if err != nil {
+				return nil, err
+			}
+
+			// Duplicate keys are not allowed.
+			for _, x := range g.xPubs {
+				if bytes.Equal(
+					EncodeExtendedKey(x.ExtendedKey), keydata,
+				) {
+					return nil, ErrDuplicateKey
+				}
+			}
+
+			g.xPubs = append(g.xPubs, xPub)
+
+		case TxVersionType:
+			if g.txVersion != nil {
+				return nil, ErrDuplicateKey
+			}
+			if keydata != nil {
+				return nil, ErrInvalidKeydata
+			}
+			if len(value) != 4 {
+				return nil, ErrInvalidPsbtFormat
+			}
+
+			txVersion := int32(binary.LittleEndian.Uint32(value))
+			g.txVersion = &txVersion
+
+		case FallbackLocktimeType:
+			if g.fallbackLockTime != nil {
+				return nil, ErrDuplicateKey
+			}
+			if keydata != nil {
+				return nil, ErrInvalidKeydata
+			}
+			if len(value) != 4 {
+				return nil, ErrInvalidPsbtFormat
+			}
+
+			lockTime := binary.LittleEndian.Uint32(value)
+			g.fallbackLockTime = &lockTime
+
+		case InputCountType:
+			if g.inputCount != nil {
+				return nil, ErrDuplicateKey
+			}
+			if keydata != nil {
+				return nil, ErrInvalidKeydata
+			}
+
+			g.inputCount, err = readCompactSize(value)
+			if err != nil {
+				return nil, err
+			}
+
+		case OutputCountType:
+			if g.outputCount != nil {
+				return nil, ErrDuplicateKey
+			}
+			if keydata != nil {
+				return nil, ErrInvalidKeydata
+			}
+
+			g.outputCount, err = readCompactSize(value)
+			if err != nil {
+				return nil, err
+			}
+
+		case TxModifiableType:
+			if g.txModifiable != nil {
+				return nil, ErrDuplicateKey
+			}
+			if keydata != nil {
+				return nil, ErrInvalidKeydata
+			}
+			if len(value) != 1 {
+				return nil, ErrInvalidPsbtFormat
+			}
+
+			flags := TxModifiableFlags(value[0])
+			g.txModifiable = &flags
+
+		case VersionType:
+			if g.versionFound {
+				return nil, ErrDuplicateKey
+			}
+			if keydata != nil {
+				return nil, ErrInvalidKeydata
+			}
+
+			// The version must be a 32-bit unsigned integer, and we
+			// only accept the versions we know how to handle.
+			if len(value) != 4 {
+				return nil, ErrInvalidPsbtFormat
+			}
+			g.version = binary.LittleEndian.Uint32(value)
+			if !supportedVersion(g.version) {
+				return nil, ErrUnsupportedPsbtVersion
+			}
+			g.versionFound = true
+
+		default:
+			keyintanddata := []byte{byte(keyint)}
+			keyintanddata = append(keyintanddata, keydata...)
+
+			newUnknown := Unknown{
+				Key:   keyintanddata,
+				Value: value,
+			}
+			g.unknowns = append(g.unknowns, newUnknown)
+		}
+	}
+
+	// Finally make sure the set of fields present matches the version.
+	switch g.version {
+	case PsbtVersion0:
+		// Version 0 requires the unsigned transaction and excludes all
+		// of the fields introduced with version 2.
+		if g.unsignedTx == nil || g.txVersion != nil ||
+			g.fallbackLockTime != nil || g.inputCount != nil ||
+			g.outputCount != nil || g.txModifiable != nil {
+
+			return nil, ErrInvalidPsbtFormat
+		}
+
+	case PsbtVersion2:
+		// Version 2 excludes the unsigned transaction, as it is
+		// described by the remaining fields instead.  The transaction
+		// version must be at least 2.
+		if g.unsignedTx != nil || g.txVersion == nil ||
+			g.inputCount == nil || g.outputCount == nil {
+
+			return nil, ErrInvalidPsbtFormat
+		}
+		if *g.txVersion < 2 {
+			return nil, ErrInvalidPsbtFormat
+		}
+	}
+
+	return &g, nil
+}
+
+// readUnsignedTx decodes the unsigned transaction of a version 0 PSBT and
+// makes sure it doesn't carry any signature data.
+func readUnsignedTx(value []byte) (*wire.MsgTx, error) {
+	msgTx := wire.NewMsgTx(2)
+	err := msgTx.Deserialize(bytes.NewReader(value))
+	if err != nil {
+		// If there are no inputs in this yet incomplete transaction,
+		// the wire package still incorrectly assumes it's encoded in
+		// the witness format. We can fix this by just trying the non-
+		// witness encoding too. If that also fails, it's probably an
+		// invalid transaction.
+		msgTx = wire.NewMsgTx(2)
+		err2 := msgTx.DeserializeNoWitness(bytes.NewReader(value))
+
+		// If the second attempt also failed, something else is wrong
+		// and it probably makes more sense to return the original
+		// error instead of the error from the workaround.
+		if err2 != nil {
+			return nil, err
+		}
+	}
+	if !validateUnsignedTX(msgTx) {
+		return nil, ErrInvalidRawTxSigned
+	}
+
+	return msgTx, nil
+}
+
+// readCompactSize decodes a value consisting of exactly one compact size
+// unsigned integer.
+func readCompactSize(value []byte) (*uint64, error) {
+	r := bytes.NewReader(value)
+	count, err := wire.ReadVarInt(r, 0)
+	if err != nil || r.Len() != 0 {
+		return nil, ErrInvalidPsbtFormat
+	}
+
+	return &count, nil
+}
+
 // NewFromRawBytes returns a new instance of a Packet struct created by reading
 // from a byte slice. If the format is invalid, an error is returned. If the
 // argument b64 is true, the passed byte slice is decoded from base64 encoding
 // before processing.
 //
+// Both version 0 and version 2 packets are accepted.  For version 2 packets
+// the UnsignedTx field is assembled from the per-input and per-output
+// transaction fields, with the locktime determined as described in BIP 370.
+//
 // NOTE: To create a Packet from one's own data, rather than reading in a
 // serialization from a counterparty, one should use a psbt.New.
 func NewFromRawBytes(r io.Reader, b64 bool) (*Packet, error) {
@@ -194,81 +503,24 @@ func NewFromRawBytes(r io.Reader, b64 bool) (*Packet, error) {
 		return nil, ErrInvalidMagicBytes
 	}
 
-	// Next we parse the GLOBAL section.  There is currently only 1 known
-	// key type, UnsignedTx.  We insist this exists first; unknowns are
-	// allowed, but only after.
-	keyint, keydata, err := getKey(r)
+	// Next we parse the GLOBAL section, which tells us the version of the
+	// packet and with that how the inputs and outputs are described.
+	globals, err := readGlobals(r)
 	if err != nil {
 		return nil, err
 	}
-	if GlobalType(keyint) != UnsignedTxType || keydata != nil {
-		return nil, ErrInvalidPsbtFormat
-	}
 
-	// Now that we've verified the global type is present, we'll decode it
-	// into a proper unsigned transaction, and validate it.
-	value, err := wire.ReadVarBytes(
-		r, 0, MaxPsbtValueLength, "PSBT value",
-	)
-	if err != nil {
-		return nil, err
+	if globals.version == PsbtVersion2 {
+		return readV2Body(r, globals)
 	}
-	msgTx := wire.NewMsgTx(2)
-	err = msgTx.Deserialize(bytes.NewReader(value))
-	if err != nil {
-		// If there are no inputs in this yet incomplete transaction,
-		// the wire package still incorrectly assumes it's encoded in
-		// the witness format. We can fix this by just trying the non-
-		// witness encoding too. If that also fails, it's probably an
-		// invalid transaction.
-		msgTx = wire.NewMsgTx(2)
-		err2 := msgTx.DeserializeNoWitness(bytes.NewReader(value))
 
-		// If the second attempt also failed, something else is wrong
-		// and it probably makes more sense to return the original
-		// error instead of the error from the workaround.
-		if err2 != nil {
-			return nil, err
-		}
-	}
-	if !validateUnsignedTX(msgTx) {
-		return nil, ErrInvalidRawTxSigned
-	}
-
-	// Next we parse any unknowns that may be present, making sure that we
-	// break at the separator.
-	var unknownSlice []Unknown
-	for {
-		keyint, keydata, err := getKey(r)
-		if err != nil {
-			return nil, ErrInvalidPsbtFormat
-		}
-		if keyint == -1 {
-			break
-		}
-
-		value, err := wire.ReadVarBytes(
-			r, 0, MaxPsbtValueLength, "PSBT value",
-		)
-		if err != nil {
-			return nil, err
-		}
-
-		keyintanddata := []byte{byte(keyint)}
-		keyintanddata = append(keyintanddata, keydata...)
-
-		newUnknown := Unknown{
-			Key:   keyintanddata,
-			Value: value,
-		}
-		unknownSlice = append(unknownSlice, newUnknown)
-	}
+	msgTx := globals.unsignedTx
 
 	// Next we parse the INPUT section.
 	inSlice := make([]PInput, len(msgTx.TxIn))
 	for i := range msgTx.TxIn {
 		input := PInput{}
-		err = input.deserialize(r)
+		err = input.deserialize(r, nil)
 		if err != nil {
 			return nil, err
 		}
@@ -280,7 +532,7 @@ func NewFromRawBytes(r io.Reader, b64 bool) (*Packet, error) {
 	outSlice := make([]POutput, len(msgTx.TxOut))
 	for i := range msgTx.TxOut {
 		output := POutput{}
-		err = output.deserialize(r)
+		err = output.deserialize(r, nil)
 		if err != nil {
 			return nil, err
 		}
@@ -291,9 +543,10 @@ func NewFromRawBytes(r io.Reader, b64 bool) (*Packet, error) {
 	// Populate the new Packet object
 	newPsbt := Packet{
 		UnsignedTx: msgTx,
+		XPubs:      globals.xPubs,
 		Inputs:     inSlice,
 		Outputs:    outSlice,
-		Unknowns:   unknownSlice,
+		Unknowns:   globals.unknowns,
 	}
 
 	// Extended sanity checking is applied here to make sure the
@@ -315,22 +568,75 @@ func (p *Packet) Serialize(w io.Writer) error {
 		return err
 	}
 
-	// Next we prep to write out the unsigned transaction by first
-	// serializing it into an intermediate buffer.
-	serializedTx := bytes.NewBuffer(
-		make([]byte, 0, p.UnsignedTx.SerializeSize()),
-	)
-	if err := p.UnsignedTx.Serialize(serializedTx); err != nil {
-		return err
+	// A version 2 packet describes its transaction through separate
+	// global, input and output fields instead of a single unsigned
+	// transaction.
+	v2 := p.Version == PsbtVersion2
+	if v2 {
+		err := VerifyInputOutputLen(p, true, true)
+		if err != nil {
+			return err
+		}
+		if err := p.serializeV2Globals(w); err != nil {
+			return err
+		}
+	} else {
+		// Next we prep to write out the unsigned transaction by first
+		// serializing it into an intermediate buffer.
+		serializedTx := bytes.NewBuffer(
+			make([]byte, 0, p.UnsignedTx.SerializeSize()),
+		)
+		if err := p.UnsignedTx.Serialize(serializedTx); err != nil {
+			return err
+		}
+
+		// Now that we have the serialized transaction, we'll write it
+		// out to the proper global type.
+		err := serializeKVPairWithType(
+			w, uint8(UnsignedTxType), nil, serializedTx.Bytes(),
+		)
+		if err != nil {
+			return err
+		}
 	}
 
-	// Now that we have the serialized transaction, we'll write it out to
-	// the proper global type.
-	err := serializeKVPairWithType(
-		w, uint8(UnsignedTxType), nil, serializedTx.Bytes(),
-	)
-	if err != nil {
-		return err
+	// The global xpubs follow, ordered by their serialized extended key.
+	// A copy is sorted so the packet isn't modified.
+	xPubs := make([]*XPub, len(p.XPubs))
+	copy(xPubs, p.XPubs)
+	sort.Sort(XPubSorter(xPubs))
+	for _, xPub := range xPubs {
+		err := serializeKVPairWithType(
+			w, uint8(XpubType), EncodeExtendedKey(xPub.ExtendedKey),
+			SerializeBIP32Derivation(
+				xPub.MasterKeyFingerprint, xPub.Bip32Path,
+			),
+		)
+		if err != nil {
+			return err
+		}
+	}
+
+	// A version of zero is implied by the absence of the version field, so
+	// we only write it out for later versions.
+	if p.Version != 0 {
+		var versionBytes [4]byte
+		binary.LittleEndian.PutUint32(versionBytes[:], p.Version)
+
+		err := serializeKVPairWithType(
+			w, uint8(VersionType), nil, versionBytes[:],
+		)
+		if err != nil {
+			return err
+		}
+	}
+
+	// Unknowns, including any proprietary global fields, are written out
+	// as is.
+	for _, kv := range p.Unknowns {
+		if err := serializeKVpair(w, kv.Key, kv.Value); err != nil {
+			return err
+		}
 	}
 
 	// With that our global section is done, so we'll write out the
@@ -340,8 +646,13 @@ func (p *Packet) Serialize(w io.Writer) error {
 		return err
 	}
 
-	for _, pInput := range p.Inputs {
-		err := pInput.serialize(w)
+	for i, pInput := range p.Inputs {
+		var txIn *wire.TxIn
+		if v2 {
+			txIn = p.UnsignedTx.TxIn[i]
+		}
+
+		err := pInput.serialize(w, txIn)
 		if err != nil {
 			return err
 		}
@@ -351,8 +662,13 @@ func (p *Packet) Serialize(w io.Writer) error {
 		}
 	}
 
-	for _, pOutput := range p.Outputs {
-		err := pOutput.serialize(w)
+	for i, pOutput := range p.Outputs {
+		var txOut *wire.TxOut
+		if v2 {
+			txOut = p.UnsignedTx.TxOut[i]
+		}
+
+		err := pOutput.serialize(w, txOut)
 		if err != nil {
 			return err
 		}
@@ -365,6 +681,169 @@ func (p *Packet) Serialize(w io.Writer) error {
 	return nil
 }
 
+// serializeV2Globals writes out the global fields that describe the
+// transaction of a version 2 packet: the transaction version, the fallback
+// locktime, the input and output counts and the modification flags.
+func (p *Packet) serializeV2Globals(w io.Writer) error {
+	var txVersion [4]byte
+	binary.LittleEndian.PutUint32(txVersion[:], uint32(p.UnsignedTx.Version))
+	err := serializeKVPairWithType(w, uint8(TxVersionType), nil, txVersion[:])
+	if err != nil {
+		return err
+	}
+
+	if p.FallbackLockTime != 0 {
+		var lockTime [4]byte
+		binary.LittleEndian.PutUint32(lockTime[:], p.FallbackLockTime)
+		err := serializeKVPairWithType(
+			w, uint8(FallbackLocktimeType), nil, lockTime[:],
+		)
+		if err != nil {
+			return err
+		}
+	}
+
+	var inputCount bytes.Buffer
+	err = wire.WriteVarInt(&inputCount, 0, uint64(len(p.UnsignedTx.TxIn)))
+	if err != nil {
+		return err
+	}
+	err = serializeKVPairWithType(
+		w, uint8(InputCountType), nil, inputCount.Bytes(),
+	)
+	if err != nil {
+		return err
+	}
+
+	var outputCount bytes.Buffer
+	err = wire.WriteVarInt(&outputCount, 0, uint64(len(p.UnsignedTx.TxOut)))
+	if err != nil {
+		return err
+	}
+	err = serializeKVPairWithType(
+		w, uint8(OutputCountType), nil, outputCount.Bytes(),
+	)
+	if err != nil {
+		return err
+	}
+
+	if p.TxModifiable != 0 {
+		return serializeKVPairWithType(
+			w, uint8(TxModifiableType), nil,
+			[]byte{byte(p.TxModifiable)},
+		)
+	}
+
+	return nil
+}
+
+// readV2Body parses the input and output sections of a version 2 packet
+// whose global section has already been read, and assembles the unsigned
+// transaction from the per-input and per-output fields.
+func readV2Body(r io.Reader, globals *globalFields) (*Packet, error) {
+	msgTx := wire.NewMsgTx(*globals.txVersion)
+
+	// The counts are untrusted, so we only allocate as we go rather than
+	// up front.
+	var inSlice []PInput
+	for i := uint64(0); i < *globals.inputCount; i++ {
+		txIn := &wire.TxIn{Sequence: wire.MaxTxInSequenceNum}
+		input := PInput{}
+		if err := input.deserialize(r, txIn); err != nil {
+			return nil, err
+		}
+
+		msgTx.AddTxIn(txIn)
+		inSlice = append(inSlice, input)
+	}
+
+	var outSlice []POutput
+	for i := uint64(0); i < *globals.outputCount; i++ {
+		txOut := &wire.TxOut{}
+		output := POutput{}
+		if err := output.deserialize(r, txOut); err != nil {
+			return nil, err
+		}
+
+		msgTx.AddTxOut(txOut)
+		outSlice = append(outSlice, output)
+	}
+
+	newPsbt := Packet{
+		UnsignedTx: msgTx,
+		XPubs:      globals.xPubs,
+		Version:    globals.version,
+		Inputs:     inSlice,
+		Outputs:    outSlice,
+		Unknowns:   globals.unknowns,
+	}
+	if globals.fallbackLockTime != nil {
+		newPsbt.FallbackLockTime = *globals.fallbackLockTime
+	}
+	if globals.txModifiable != nil {
+		newPsbt.TxModifiable = *globals.txModifiable
+	}
+
+	// The locktime of the transaction follows from the requirements of
+	// the individual inputs.
+	lockTime, err := newPsbt.DetermineLockTime()
+	if err != nil {
+		return nil, err
+	}
+	msgTx.LockTime = lockTime
+
+	if err = newPsbt.SanityCheck(); err != nil {
+		return nil, err
+	}
+
+	return &newPsbt, nil
+}
+
+// DetermineLockTime computes the locktime of the transaction of a version 2
+// packet as described in BIP 370.  If no input requires a locktime, the
+// fallback locktime is used.  Otherwise a height based locktime is chosen if
+// every input with requirements accepts one, and a time based locktime if
+// not; the result is the largest locktime of that kind required by any input.
+// ErrInvalidLockTime is returned if the requirements can't all be met.
+func (p *Packet) DetermineLockTime() (uint32, error) {
+	var (
+		constrained        bool
+		heightOK, timeOK   = true, true
+		maxHeight, maxTime uint32
+	)
+	for _, pInput := range p.Inputs {
+		if pInput.RequiredHeightLocktime == 0 &&
+			pInput.RequiredTimeLocktime == 0 {
+
+			continue
+		}
+		constrained = true
+
+		if pInput.RequiredHeightLocktime == 0 {
+			heightOK = false
+		} else if pInput.RequiredHeightLocktime > maxHeight {
+			maxHeight = pInput.RequiredHeightLocktime
+		}
+
+		if pInput.RequiredTimeLocktime == 0 {
+			timeOK = false
+		} else if pInput.RequiredTimeLocktime > maxTime {
+			maxTime = pInput.RequiredTimeLocktime
+		}
+	}
+
+	switch {
+	case !constrained:
+		return p.FallbackLockTime, nil
+	case heightOK:
+		return maxHeight, nil
+	case timeOK:
+		return maxTime, nil
+	default:
+		return 0, ErrInvalidLockTime
+	}
+}
+
 // B64Encode returns the base64 encoding of the serialization of
 // the current PSBT, or an error if the encoding fails.
 func (p *Packet) B64Encode() (string, error) {
@@ -397,6 +876,45 @@ func (p *Packet) SanityCheck() error {
 		return ErrInvalidRawTxSigned
 	}
 
+	if !supportedVersion(p.Version) {
+		return ErrUnsupportedPsbtVersion
+	}
+
+	if p.Version == PsbtVersion2 {
+		// The transaction of a version 2 packet must be at least
+		// version 2 and its locktime must follow from the input
+		// requirements.
+		if p.UnsignedTx.Version < 2 {
+			return ErrInvalidPsbtFormat
+		}
+		lockTime, err := p.DetermineLockTime()
+		if err != nil {
+			return err
+		}
+		if lockTime != p.UnsignedTx.LockTime {
+			return ErrInvalidLockTime
+		}
+	} else {
+		// None of the version 2 fields may be set on a version 0
+		// packet, since they can't be serialized.
+		if p.FallbackLockTime != 0 || p.TxModifiable != 0 {
+			return ErrInvalidPsbtFormat
+		}
+		for _, pInput := range p.Inputs {
+			if pInput.RequiredTimeLocktime != 0 ||
+				pInput.RequiredHeightLocktime != 0 {
+
+				return ErrInvalidPsbtFormat
+			}
+		}
+	}
+
+	for _, xPub := range p.XPubs {
+		if !xPub.checkValid() {
+			return ErrInvalidXPub
+		}
+	}
+
 	for _, tin := range p.Inputs {
 		if !tin.IsSane() {
 			return ErrInvalidPsbtFormat
@@ -405,3 +923,28 @@ func (p *Packet) SanityCheck() error {
 
 	return nil
 }
+
+// readXPub parses the key data and value of a global xpub field into an XPub,
+// making sure the extended key is public and matches the derivation path.
+func readXPub(keydata, value []byte) (*XPub, error) {
+	extendedKey, err := DecodeExtendedKey(keydata)
+	if err != nil {
+		return nil, ErrInvalidKeydata
+	}
+
+	master, derivationPath, err := readXPubDerivation(value)
+	if err != nil {
+		return nil, err
+	}
+
+	xPub := &XPub{
+		ExtendedKey:          extendedKey,
+		MasterKeyFingerprint: master,
+		Bip32Path:            derivationPath,
+	}
+	if !xPub.checkValid() {
+		return nil, ErrInvalidXPub
+	}
+
+	return xPub, nil
+}
@@ -9,13 +9,16 @@ import (
 	"encoding/base64"
 	"encoding/binary"
 	"encoding/hex"
+	"reflect"
 	"testing"
 
 	"github.com/davecgh/go-spew/spew"
+	"github.com/monasuite/monad/chaincfg"
 	"github.com/monasuite/monad/chaincfg/chainhash"
 	"github.com/monasuite/monad/txscript"
 	"github.com/monasuite/monad/wire"
 	"github.com/monasuite/monautil"
+	"github.com/monasuite/monautil/hdkeychain"
 )
 
 // Test vectors from:
@@ -1446,3 +1449,798 @@ func TestWitnessForNonWitnessUtxo(t *testing.T) {
 		t.Fatalf("unable to extract funding TX: %v", err)
 	}
 }
+
+// newTestXPubPacket creates a packet spending a single input to a single
+// output that global fields can be attached to.
+func newTestXPubPacket(t *testing.T) *Packet {
+	t.Helper()
+
+	packet, err := New(
+		[]*wire.OutPoint{{Index: 1}},
+		[]*wire.TxOut{{Value: 1000, PkScript: []byte{txscript.OP_TRUE}}},
+		2, 0, []uint32{wire.MaxTxInSequenceNum},
+	)
+	if err != nil {
+		t.Fatalf("unable to create packet: %v", err)
+	}
+
+	return packet
+}
+
+// rawPacketWithGlobals serializes the given packet, which must have exactly
+// one input and one output without any data, while inserting the passed raw
+// key-value pairs after the unsigned transaction in the global section.
+func rawPacketWithGlobals(t *testing.T, packet *Packet,
+	globals ...[]byte) []byte {
+
+	t.Helper()
+
+	var tx bytes.Buffer
+	if err := packet.UnsignedTx.Serialize(&tx); err != nil {
+		t.Fatalf("unable to serialize tx: %v", err)
+	}
+
+	var b bytes.Buffer
+	b.Write(psbtMagic[:])
+	err := serializeKVPairWithType(&b, uint8(UnsignedTxType), nil, tx.Bytes())
+	if err != nil {
+		t.Fatalf("unable to serialize tx field: %v", err)
+	}
+	for _, global := range globals {
+		b.Write(global)
+	}
+
+	// Global, input and output separators.
+	b.Write([]byte{0x00, 0x00, 0x00})
+
+	return b.Bytes()
+}
+
+// TestGlobalXPubsAndVersion tests that global xpubs and the version field can
+// be added through the Updater, and that they survive a serialization round
+// trip in the expected order.
+func TestGlobalXPubsAndVersion(t *testing.T) {
+	seed := bytes.Repeat([]byte{0x42}, hdkeychain.RecommendedSeedLen)
+	master, err := hdkeychain.NewMaster(seed, &chaincfg.MainNetParams)
+	if err != nil {
+		t.Fatalf("unable to create master key: %v", err)
+	}
+	masterPub, err := master.Neuter()
+	if err != nil {
+		t.Fatalf("unable to neuter master key: %v", err)
+	}
+
+	path := []uint32{
+		hdkeychain.HardenedKeyStart + 48,
+		hdkeychain.HardenedKeyStart + 22,
+		hdkeychain.HardenedKeyStart + 0,
+	}
+	account := master
+	for _, index := range path {
+		account, err = account.Derive(index)
+		if err != nil {
+			t.Fatalf("unable to derive account key: %v", err)
+		}
+	}
+	accountPub, err := account.Neuter()
+	if err != nil {
+		t.Fatalf("unable to neuter account key: %v", err)
+	}
+
+	const fingerprint = 0xd90c6a4f
+
+	packet := newTestXPubPacket(t)
+	updater, err := NewUpdater(packet)
+	if err != nil {
+		t.Fatalf("unable to create updater: %v", err)
+	}
+
+	// Private keys and paths not matching the depth must be rejected.
+	err = updater.AddXPub(account, fingerprint, path)
+	if err != ErrInvalidXPub {
+		t.Fatalf("expected ErrInvalidXPub for private key, got %v", err)
+	}
+	err = updater.AddXPub(accountPub, fingerprint, path[:2])
+	if err != ErrInvalidXPub {
+		t.Fatalf("expected ErrInvalidXPub for short path, got %v", err)
+	}
+
+	if err := updater.AddXPub(accountPub, fingerprint, path); err != nil {
+		t.Fatalf("unable to add account xpub: %v", err)
+	}
+	if err := updater.AddXPub(masterPub, fingerprint, nil); err != nil {
+		t.Fatalf("unable to add master xpub: %v", err)
+	}
+	err = updater.AddXPub(accountPub, fingerprint, path)
+	if err != ErrDuplicateKey {
+		t.Fatalf("expected ErrDuplicateKey, got %v", err)
+	}
+
+	if err := updater.SetVersion(MaxPsbtVersion + 1); err != ErrUnsupportedPsbtVersion {
+		t.Fatalf("expected ErrUnsupportedPsbtVersion, got %v", err)
+	}
+	if err := updater.SetVersion(0); err != nil {
+		t.Fatalf("unable to set version: %v", err)
+	}
+
+	var b bytes.Buffer
+	if err := packet.Serialize(&b); err != nil {
+		t.Fatalf("unable to serialize packet: %v", err)
+	}
+	serialized := b.Bytes()
+
+	// Serializing must not reorder the xpubs of the packet itself.
+	if packet.XPubs[0].ExtendedKey.String() != accountPub.String() {
+		t.Fatalf("serialization reordered the xpubs of the packet")
+	}
+
+	parsed, err := NewFromRawBytes(bytes.NewReader(serialized), false)
+	if err != nil {
+		t.Fatalf("unable to parse packet: %v", err)
+	}
+	if len(parsed.XPubs) != 2 {
+		t.Fatalf("expected 2 xpubs, got %d", len(parsed.XPubs))
+	}
+	if parsed.Version != 0 {
+		t.Fatalf("expected version 0, got %d", parsed.Version)
+	}
+
+	// The master key has depth 0 and therefore sorts before the account
+	// key in the serialization.
+	if parsed.XPubs[0].ExtendedKey.String() != masterPub.String() ||
+		len(parsed.XPubs[0].Bip32Path) != 0 {
+
+		t.Fatalf("unexpected first xpub: %v", spew.Sdump(parsed.XPubs[0]))
+	}
+	xPub := parsed.XPubs[1]
+	if xPub.ExtendedKey.String() != accountPub.String() {
+		t.Fatalf("expected xpub %v, got %v", accountPub,
+			xPub.ExtendedKey)
+	}
+	if xPub.MasterKeyFingerprint != fingerprint {
+		t.Fatalf("expected fingerprint %x, got %x", fingerprint,
+			xPub.MasterKeyFingerprint)
+	}
+	if !reflect.DeepEqual(xPub.Bip32Path, path) {
+		t.Fatalf("expected path %v, got %v", path, xPub.Bip32Path)
+	}
+
+	var b2 bytes.Buffer
+	if err := parsed.Serialize(&b2); err != nil {
+		t.Fatalf("unable to re-serialize packet: %v", err)
+	}
+	if !bytes.Equal(serialized, b2.Bytes()) {
+		t.Fatalf("serialization mismatch after round trip")
+	}
+}
+
+// TestReadInvalidGlobalFields tests that malformed global xpub and version
+// fields are rejected, and that a version field is preserved when present.
+func TestReadInvalidGlobalFields(t *testing.T) {
+	seed := bytes.Repeat([]byte{0x42}, hdkeychain.RecommendedSeedLen)
+	master, err := hdkeychain.NewMaster(seed, &chaincfg.MainNetParams)
+	if err != nil {
+		t.Fatalf("unable to create master key: %v", err)
+	}
+	child, err := master.Derive(hdkeychain.HardenedKeyStart)
+	if err != nil {
+		t.Fatalf("unable to derive child key: %v", err)
+	}
+	childPub, err := child.Neuter()
+	if err != nil {
+		t.Fatalf("unable to neuter child key: %v", err)
+	}
+
+	kv := func(keyType GlobalType, keydata, value []byte) []byte {
+		var b bytes.Buffer
+		err := serializeKVPairWithType(&b, uint8(keyType), keydata, value)
+		if err != nil {
+			t.Fatalf("unable to serialize kv pair: %v", err)
+		}
+		return b.Bytes()
+	}
+	versionBytes := func(version uint32) []byte {
+		var v [4]byte
+		binary.LittleEndian.PutUint32(v[:], version)
+		return v[:]
+	}
+
+	xPubKey := EncodeExtendedKey(childPub)
+	validPath := SerializeBIP32Derivation(
+		1, []uint32{hdkeychain.HardenedKeyStart},
+	)
+	tooLongPath := SerializeBIP32Derivation(
+		1, []uint32{hdkeychain.HardenedKeyStart, 1},
+	)
+
+	tests := []struct {
+		name    string
+		globals [][]byte
+		err     error
+	}{{
+		name:    "valid xpub",
+		globals: [][]byte{kv(XpubType, xPubKey, validPath)},
+	}, {
+		name:    "explicit version 0",
+		globals: [][]byte{kv(VersionType, nil, versionBytes(0))},
+	}, {
+		name:    "xpub with truncated key",
+		globals: [][]byte{kv(XpubType, xPubKey[:77], validPath)},
+		err:     ErrInvalidKeydata,
+	}, {
+		name:    "xpub with private key",
+		globals: [][]byte{kv(XpubType, EncodeExtendedKey(child), validPath)},
+		err:     ErrInvalidXPub,
+	}, {
+		name:    "xpub with path not matching depth",
+		globals: [][]byte{kv(XpubType, xPubKey, tooLongPath)},
+		err:     ErrInvalidXPub,
+	}, {
+		name: "duplicate xpub",
+		globals: [][]byte{
+			kv(XpubType, xPubKey, validPath),
+			kv(XpubType, xPubKey, validPath),
+		},
+		err: ErrDuplicateKey,
+	}, {
+		name:    "unsupported version",
+		globals: [][]byte{kv(VersionType, nil, versionBytes(1))},
+		err:     ErrUnsupportedPsbtVersion,
+	}, {
+		name:    "version with key data",
+		globals: [][]byte{kv(VersionType, []byte{0x01}, versionBytes(0))},
+		err:     ErrInvalidKeydata,
+	}, {
+		name:    "version of wrong length",
+		globals: [][]byte{kv(VersionType, nil, []byte{0x00})},
+		err:     ErrInvalidPsbtFormat,
+	}, {
+		name: "duplicate version",
+		globals: [][]byte{
+			kv(VersionType, nil, versionBytes(0)),
+			kv(VersionType, nil, versionBytes(0)),
+		},
+		err: ErrDuplicateKey,
+	}, {
+		name:    "version 0 with tx version",
+		globals: [][]byte{kv(TxVersionType, nil, versionBytes(2))},
+		err:     ErrInvalidPsbtFormat,
+	}, {
+		name:    "version 0 with modifiable flags",
+		globals: [][]byte{kv(TxModifiableType, nil, []byte{0x03})},
+		err:     ErrInvalidPsbtFormat,
+	}, {
+		name: "version 2 with unsigned tx",
+		globals: [][]byte{
+			kv(VersionType, nil, versionBytes(2)),
+			kv(TxVersionType, nil, versionBytes(2)),
+			kv(InputCountType, nil, []byte{0x01}),
+			kv(OutputCountType, nil, []byte{0x01}),
+		},
+		err: ErrInvalidPsbtFormat,
+	}}
+
+	packet := newTestXPubPacket(t)
+	for _, test := range tests {
+		raw := rawPacketWithGlobals(t, packet, test.globals...)
+		_, err := NewFromRawBytes(bytes.NewReader(raw), false)
+		if err != test.err {
+			t.Fatalf("%s: expected error %v, got %v", test.name,
+				test.err, err)
+		}
+	}
+}
+
+// v2KV serializes a single key-value pair with the given key type for use in
+// the raw version 2 packets built by rawV2Packet.
+func v2KV(t *testing.T, keyType uint8, value []byte) []byte {
+	t.Helper()
+
+	var b bytes.Buffer
+	if err := serializeKVPairWithType(&b, keyType, nil, value); err != nil {
+		t.Fatalf("unable to serialize kv pair: %v", err)
+	}
+
+	return b.Bytes()
+}
+
+// le32 returns the little endian encoding of the passed value.
+func le32(v uint32) []byte {
+	var b [4]byte
+	binary.LittleEndian.PutUint32(b[:], v)
+	return b[:]
+}
+
+// le64 returns the little endian encoding of the passed value.
+func le64(v uint64) []byte {
+	var b [8]byte
+	binary.LittleEndian.PutUint64(b[:], v)
+	return b[:]
+}
+
+// rawV2Packet assembles a raw version 2 PSBT from the passed key-value pairs
+// of the global section and of each input and output map.  The version, the
+// transaction version and the counts are prepended to the global section.
+func rawV2Packet(t *testing.T, txVersion uint32, globals [][]byte,
+	inputs, outputs [][][]byte) []byte {
+
+	t.Helper()
+
+	var b bytes.Buffer
+	b.Write(psbtMagic[:])
+	b.Write(v2KV(t, uint8(TxVersionType), le32(txVersion)))
+	b.Write(v2KV(t, uint8(InputCountType), []byte{byte(len(inputs))}))
+	b.Write(v2KV(t, uint8(OutputCountType), []byte{byte(len(outputs))}))
+	b.Write(v2KV(t, uint8(VersionType), le32(PsbtVersion2)))
+	for _, kv := range globals {
+		b.Write(kv)
+	}
+	b.WriteByte(0x00)
+
+	for _, section := range append(inputs, outputs...) {
+		for _, kv := range section {
+			b.Write(kv)
+		}
+		b.WriteByte(0x00)
+	}
+
+	return b.Bytes()
+}
+
+// TestPsbtV2RoundTrip checks that a version 2 packet assembled with a
+// Constructor survives serialization and parsing, and that the transaction
+// is derived from the per-input and per-output fields.
+func TestPsbtV2RoundTrip(t *testing.T) {
+	packet, err := NewV2(
+		2, 100, TxModifiableInputs|TxModifiableOutputs,
+	)
+	if err != nil {
+		t.Fatalf("unable to create packet: %v", err)
+	}
+	constructor, err := NewConstructor(packet)
+	if err != nil {
+		t.Fatalf("unable to create constructor: %v", err)
+	}
+
+	prevOut1 := wire.OutPoint{Hash: chainhash.Hash{0x01}, Index: 3}
+	prevOut2 := wire.OutPoint{Hash: chainhash.Hash{0x02}, Index: 0}
+	err = constructor.AddInput(
+		prevOut1, wire.MaxTxInSequenceNum, &PInput{
+			WitnessUtxo: &wire.TxOut{
+				Value: 5000, PkScript: []byte{txscript.OP_TRUE},
+			},
+		},
+	)
+	if err != nil {
+		t.Fatalf("unable to add input: %v", err)
+	}
+
+	// Without locktime requirements the fallback locktime applies.
+	if packet.UnsignedTx.LockTime != 100 {
+		t.Fatalf("expected fallback locktime 100, got %d",
+			packet.UnsignedTx.LockTime)
+	}
+
+	err = constructor.AddInput(prevOut2, 0xfffffffd, &PInput{
+		RequiredHeightLocktime: 500,
+		RequiredTimeLocktime:   1600000000,
+	})
+	if err != nil {
+		t.Fatalf("unable to add input: %v", err)
+	}
+	err = constructor.AddOutput(
+		&wire.TxOut{Value: 4000, PkScript: []byte{txscript.OP_TRUE}},
+		nil,
+	)
+	if err != nil {
+		t.Fatalf("unable to add output: %v", err)
+	}
+
+	// An input accepting both kinds of locktimes makes the height based
+	// one win.
+	if packet.UnsignedTx.LockTime != 500 {
+		t.Fatalf("expected locktime 500, got %d",
+			packet.UnsignedTx.LockTime)
+	}
+
+	var b bytes.Buffer
+	if err := packet.Serialize(&b); err != nil {
+		t.Fatalf("unable to serialize packet: %v", err)
+	}
+	parsed, err := NewFromRawBytes(bytes.NewReader(b.Bytes()), false)
+	if err != nil {
+		t.Fatalf("unable to parse packet: %v", err)
+	}
+
+	if !reflect.DeepEqual(parsed.UnsignedTx, packet.UnsignedTx) {
+		t.Fatalf("transaction mismatch: got %v, want %v",
+			spew.Sdump(parsed.UnsignedTx),
+			spew.Sdump(packet.UnsignedTx))
+	}
+	if parsed.Version != PsbtVersion2 || parsed.FallbackLockTime != 100 ||
+		parsed.TxModifiable != packet.TxModifiable {
+
+		t.Fatalf("global fields mismatch: got %v", spew.Sdump(parsed))
+	}
+	if parsed.Inputs[1].RequiredHeightLocktime != 500 ||
+		parsed.Inputs[1].RequiredTimeLocktime != 1600000000 {
+
+		t.Fatalf("locktime requirements mismatch: got %v",
+			spew.Sdump(parsed.Inputs[1]))
+	}
+
+	var b2 bytes.Buffer
+	if err := parsed.Serialize(&b2); err != nil {
+		t.Fatalf("unable to serialize packet: %v", err)
+	}
+	if !bytes.Equal(b.Bytes(), b2.Bytes()) {
+		t.Fatalf("serialization mismatch: got %x, want %x",
+			b2.Bytes(), b.Bytes())
+	}
+}
+
+// TestReadInvalidPsbtV2 checks that malformed version 2 packets are rejected.
+func TestReadInvalidPsbtV2(t *testing.T) {
+	txid := v2KV(t, uint8(PreviousTxidType), bytes.Repeat([]byte{1}, 32))
+	index := v2KV(t, uint8(OutputIndexType), le32(0))
+	amount := v2KV(t, uint8(AmountOutputType), le64(1000))
+	script := v2KV(t, uint8(ScriptOutputType), []byte{txscript.OP_TRUE})
+	height := func(h uint32) []byte {
+		return v2KV(t, uint8(RequiredHeightLocktimeType), le32(h))
+	}
+	timeLock := func(h uint32) []byte {
+		return v2KV(t, uint8(RequiredTimeLocktimeType), le32(h))
+	}
+	txidN := func(n byte) []byte {
+		return v2KV(
+			t, uint8(PreviousTxidType), bytes.Repeat([]byte{n}, 32),
+		)
+	}
+
+	tests := []struct {
+		name      string
+		txVersion uint32
+		globals   [][]byte
+		inputs    [][][]byte
+		outputs   [][][]byte
+		err       error
+	}{{
+		name:      "valid",
+		txVersion: 2,
+		inputs:    [][][]byte{{txid, index}},
+		outputs:   [][][]byte{{amount, script}},
+	}, {
+		name:      "tx version 1",
+		txVersion: 1,
+		inputs:    [][][]byte{{txid, index}},
+		outputs:   [][][]byte{{amount, script}},
+		err:       ErrInvalidPsbtFormat,
+	}, {
+		name:      "input without txid",
+		txVersion: 2,
+		inputs:    [][][]byte{{index}},
+		outputs:   [][][]byte{{amount, script}},
+		err:       ErrInvalidPsbtFormat,
+	}, {
+		name:      "input with duplicate index",
+		txVersion: 2,
+		inputs:    [][][]byte{{txid, index, index}},
+		outputs:   [][][]byte{{amount, script}},
+		err:       ErrDuplicateKey,
+	}, {
+		name:      "output without script",
+		txVersion: 2,
+		inputs:    [][][]byte{{txid, index}},
+		outputs:   [][][]byte{{amount}},
+		err:       ErrInvalidPsbtFormat,
+	}, {
+		name:      "height locktime above threshold",
+		txVersion: 2,
+		inputs:    [][][]byte{{txid, index, height(500000000)}},
+		outputs:   [][][]byte{{amount, script}},
+		err:       ErrInvalidLockTime,
+	}, {
+		name:      "time locktime below threshold",
+		txVersion: 2,
+		inputs:    [][][]byte{{txid, index, timeLock(100)}},
+		outputs:   [][][]byte{{amount, script}},
+		err:       ErrInvalidLockTime,
+	}, {
+		name:      "incompatible locktimes",
+		txVersion: 2,
+		inputs: [][][]byte{
+			{txidN(1), index, height(100)},
+			{txidN(2), index, timeLock(500000001)},
+		},
+		outputs: [][][]byte{{amount, script}},
+		err:     ErrInvalidLockTime,
+	}, {
+		name:      "missing input count",
+		txVersion: 2,
+		globals: [][]byte{
+			v2KV(t, uint8(InputCountType), []byte{0x01}),
+		},
+		inputs:  [][][]byte{{txid, index}},
+		outputs: [][][]byte{{amount, script}},
+		err:     ErrDuplicateKey,
+	}}
+
+	for _, test := range tests {
+		raw := rawV2Packet(
+			t, test.txVersion, test.globals, test.inputs,
+			test.outputs,
+		)
+		_, err := NewFromRawBytes(bytes.NewReader(raw), false)
+		if err != test.err {
+			t.Errorf("%s: expected error %v, got %v", test.name,
+				test.err, err)
+		}
+	}
+}
+
+// TestReadV0WithV2InputKeys checks that version 0 packets keep input keys
+// that only have a meaning in version 2 as unknowns.
+func TestReadV0WithV2InputKeys(t *testing.T) {
+	packet := newTestXPubPacket(t)
+	packet.Inputs[0].Unknowns = []*Unknown{{
+		Key:   []byte{byte(SequenceType)},
+		Value: le32(1),
+	}}
+
+	var b bytes.Buffer
+	if err := packet.Serialize(&b); err != nil {
+		t.Fatalf("unable to serialize packet: %v", err)
+	}
+	parsed, err := NewFromRawBytes(bytes.NewReader(b.Bytes()), false)
+	if err != nil {
+		t.Fatalf("unable to parse packet: %v", err)
+	}
+
+	if !reflect.DeepEqual(parsed.Inputs[0].Unknowns,
+		packet.Inputs[0].Unknowns) {
+
+		t.Fatalf("unknowns mismatch: got %v",
+			spew.Sdump(parsed.Inputs[0].Unknowns))
+	}
+	if parsed.UnsignedTx.TxIn[0].Sequence != wire.MaxTxInSequenceNum {
+		t.Fatalf("sequence was modified by unknown field")
+	}
+}
+
+// TestConstructorModifiable checks that the Constructor honors the modifiable
+// flags of a packet and that signatures clear them as described in BIP 370.
+func TestConstructorModifiable(t *testing.T) {
+	if _, err := NewConstructor(newTestXPubPacket(t)); err == nil {
+		t.Fatalf("expected constructor to reject version 0 packet")
+	}
+
+	packet, err := NewV2(2, 0, 0)
+	if err != nil {
+		t.Fatalf("unable to create packet: %v", err)
+	}
+	constructor, err := NewConstructor(packet)
+	if err != nil {
+		t.Fatalf("unable to create constructor: %v", err)
+	}
+
+	prevOut := wire.OutPoint{Hash: chainhash.Hash{0x01}}
+	err = constructor.AddInput(prevOut, wire.MaxTxInSequenceNum, nil)
+	if err != ErrInputsNotModifiable {
+		t.Fatalf("expected ErrInputsNotModifiable, got %v", err)
+	}
+	err = constructor.AddOutput(&wire.TxOut{Value: 1}, nil)
+	if err != ErrOutputsNotModifiable {
+		t.Fatalf("expected ErrOutputsNotModifiable, got %v", err)
+	}
+
+	packet.TxModifiable = TxModifiableInputs | TxModifiableOutputs
+	err = constructor.AddInput(prevOut, wire.MaxTxInSequenceNum, nil)
+	if err != nil {
+		t.Fatalf("unable to add input: %v", err)
+	}
+	err = constructor.AddInput(prevOut, wire.MaxTxInSequenceNum, nil)
+	if err != ErrDuplicateInput {
+		t.Fatalf("expected ErrDuplicateInput, got %v", err)
+	}
+
+	// Once an input is signed, an input changing the locktime can't be
+	// added anymore, while one that doesn't change it still can.
+	packet.Inputs[0].PartialSigs = []*PartialSig{{}}
+	prevOut.Index++
+	err = constructor.AddInput(
+		prevOut, wire.MaxTxInSequenceNum,
+		&PInput{RequiredHeightLocktime: 10},
+	)
+	if err != ErrInvalidLockTime {
+		t.Fatalf("expected ErrInvalidLockTime, got %v", err)
+	}
+	if len(packet.Inputs) != 1 || len(packet.UnsignedTx.TxIn) != 1 {
+		t.Fatalf("failed input was added to the packet")
+	}
+	err = constructor.AddInput(prevOut, wire.MaxTxInSequenceNum, nil)
+	if err != nil {
+		t.Fatalf("unable to add input: %v", err)
+	}
+
+	tests := []struct {
+		sigHashType txscript.SigHashType
+		expected    TxModifiableFlags
+	}{{
+		sigHashType: txscript.SigHashAll,
+		expected:    0,
+	}, {
+		sigHashType: txscript.SigHashNone,
+		expected:    TxModifiableOutputs,
+	}, {
+		sigHashType: txscript.SigHashSingle,
+		expected:    TxModifiableSigHashSingle,
+	}, {
+		sigHashType: txscript.SigHashAll | txscript.SigHashAnyOneCanPay,
+		expected:    TxModifiableInputs,
+	}, {
+		sigHashType: txscript.SigHashNone | txscript.SigHashAnyOneCanPay,
+		expected:    TxModifiableInputs | TxModifiableOutputs,
+	}, {
+		sigHashType: txscript.SigHashSingle |
+			txscript.SigHashAnyOneCanPay,
+		expected: TxModifiableInputs | TxModifiableSigHashSingle,
+	}}
+
+	for _, test := range tests {
+		p := Packet{
+			TxModifiable: TxModifiableInputs | TxModifiableOutputs,
+		}
+		p.updateModifiable(test.sigHashType)
+		if p.TxModifiable != test.expected {
+			t.Errorf("sighash %v: expected flags %03b, got %03b",
+				test.sigHashType, test.expected, p.TxModifiable)
+		}
+	}
+}
+
+// TestConvertV0V2 checks that converting the valid test vectors to version 2
+// and back is lossless.
+func TestConvertV0V2(t *testing.T) {
+	for i, v := range validPsbtHex {
+		raw, err := hex.DecodeString(v)
+		if err != nil {
+			t.Fatalf("unable to decode hex: %v", err)
+		}
+		packet, err := NewFromRawBytes(bytes.NewReader(raw), false)
+		if err != nil {
+			t.Fatalf("unable to parse psbt: %v", err)
+		}
+
+		var original bytes.Buffer
+		if err := packet.Serialize(&original); err != nil {
+			t.Fatalf("unable to serialize psbt: %v", err)
+		}
+
+		// Unknown input fields of the types that version 2 uses for
+		// the transaction input prevent the conversion.
+		convertible := packet.UnsignedTx.Version >= 2
+		for _, pInput := range packet.Inputs {
+			for _, kv := range pInput.Unknowns {
+				if isV2InputType(InputType(kv.Key[0])) {
+					convertible = false
+				}
+			}
+		}
+
+		v2, err := ConvertToV2(packet)
+		if !convertible {
+			if err != ErrInvalidPsbtFormat {
+				t.Fatalf("vector %d: expected ErrInvalidPsbtFormat, "+
+					"got %v", i, err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Fatalf("vector %d: unable to convert: %v", i, err)
+		}
+		if packet.Version != PsbtVersion0 {
+			t.Fatalf("vector %d: conversion modified original", i)
+		}
+
+		// The version 2 packet must survive a round trip of its own.
+		var b bytes.Buffer
+		if err := v2.Serialize(&b); err != nil {
+			t.Fatalf("vector %d: unable to serialize: %v", i, err)
+		}
+		parsed, err := NewFromRawBytes(bytes.NewReader(b.Bytes()), false)
+		if err != nil {
+			t.Fatalf("vector %d: unable to parse v2: %v", i, err)
+		}
+
+		v0, err := ConvertToV0(parsed)
+		if err != nil {
+			t.Fatalf("vector %d: unable to convert: %v", i, err)
+		}
+		var converted bytes.Buffer
+		if err := v0.Serialize(&converted); err != nil {
+			t.Fatalf("vector %d: unable to serialize: %v", i, err)
+		}
+		if !bytes.Equal(original.Bytes(), converted.Bytes()) {
+			t.Fatalf("vector %d: round trip mismatch: got %x, "+
+				"want %x", i, converted.Bytes(), original.Bytes())
+		}
+	}
+
+	// A version 0 packet can't carry the version 2 output fields to the
+	// conversion, as they fail to parse.
+	for _, outputType := range []OutputType{AmountOutputType, ScriptOutputType} {
+		var b bytes.Buffer
+		if err := newTestXPubPacket(t).Serialize(&b); err != nil {
+			t.Fatalf("unable to serialize packet: %v", err)
+		}
+
+		// Insert the field before the separator of the last output.
+		raw := b.Bytes()
+		field := append([]byte{1, byte(outputType), 8}, make([]byte, 8)...)
+		raw = append(raw[:len(raw)-1:len(raw)-1], append(field, 0)...)
+
+		_, err := NewFromRawBytes(bytes.NewReader(raw), false)
+		if err != ErrInvalidPsbtFormat {
+			t.Fatalf("output type %d: expected ErrInvalidPsbtFormat, "+
+				"got %v", outputType, err)
+		}
+	}
+
+	// Converting a version 2 packet to version 0 and back is lossless,
+	// and fields without a version 0 representation prevent it.
+	v2, err := ConvertToV2(newTestXPubPacket(t))
+	if err != nil {
+		t.Fatalf("unable to convert: %v", err)
+	}
+	v0, err := ConvertToV0(v2)
+	if err != nil {
+		t.Fatalf("unable to convert: %v", err)
+	}
+	back, err := ConvertToV2(v0)
+	if err != nil {
+		t.Fatalf("unable to convert: %v", err)
+	}
+	if !reflect.DeepEqual(back, v2) {
+		t.Fatalf("round trip mismatch: got %v, want %v",
+			spew.Sdump(back), spew.Sdump(v2))
+	}
+
+	lossy := []struct {
+		name   string
+		modify func(p *Packet)
+	}{
+		{"modifiable", func(p *Packet) {
+			p.TxModifiable = TxModifiableInputs
+		}},
+		{"required time", func(p *Packet) {
+			p.Inputs[0].RequiredTimeLocktime = 500000000
+			p.UnsignedTx.LockTime = 500000000
+		}},
+		{"required height", func(p *Packet) {
+			p.Inputs[0].RequiredHeightLocktime = 100
+			p.UnsignedTx.LockTime = 100
+		}},
+	}
+	for _, test := range lossy {
+		p := copyPacket(v2)
+		test.modify(p)
+		if _, err := ConvertToV0(p); err != ErrNotConvertible {
+			t.Fatalf("%s: expected ErrNotConvertible, got %v",
+				test.name, err)
+		}
+	}
+
+	// The Updater converts packets in place when the version changes.
+	packet := newTestXPubPacket(t)
+	packet.UnsignedTx.LockTime = 42
+	updater, err := NewUpdater(packet)
+	if err != nil {
+		t.Fatalf("unable to create updater: %v", err)
+	}
+	if err := updater.SetVersion(PsbtVersion2); err != nil {
+		t.Fatalf("unable to set version: %v", err)
+	}
+	if packet.Version != PsbtVersion2 || packet.FallbackLockTime != 42 {
+		t.Fatalf("packet not converted: %v", spew.Sdump(packet))
+	}
+}
@@ -0,0 +1,184 @@
+// Copyright (c) 2026 The monasuite developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package psbt
+
+import (
+	"sync"
+
+	"github.com/monasuite/monad/txscript"
+	"github.com/monasuite/monad/wire"
+)
+
+// ScriptFinalizer builds the data satisfying a particular kind of script when
+// an input is finalized.  The Finalizer takes care of how that data is
+// placed in the signature script or witness of the input, including pushing
+// the redeem script of P2SH inputs and appending the witness script of P2WSH
+// inputs, so a ScriptFinalizer only deals with the script itself.
+type ScriptFinalizer interface {
+	// MatchScript returns true if the finalizer knows how to satisfy the
+	// passed script.  The script is the witness script of a P2WSH input,
+	// the redeem script of a P2SH input that isn't nested P2WSH, and the
+	// output script otherwise; for P2WPKH inputs that is the witness
+	// program.
+	MatchScript(script []byte) bool
+
+	// BuildWitness returns the stack items satisfying the script, with
+	// the bottom of the stack first, from the partial signatures and other
+	// data of the input.  For legacy inputs the items are pushed by the
+	// signature script, for witness inputs they form the witness.
+	// ErrNotFinalizable should be returned if the input lacks data the
+	// script requires.
+	BuildWitness(pInput *PInput, script []byte) (wire.TxWitness, error)
+}
+
+// scriptFinalizerFuncs implements ScriptFinalizer through a pair of functions.
+type scriptFinalizerFuncs struct {
+	match func(script []byte) bool
+	build func(pInput *PInput, script []byte) (wire.TxWitness, error)
+}
+
+// MatchScript calls the matcher function of the finalizer.
+func (f *scriptFinalizerFuncs) MatchScript(script []byte) bool {
+	return f.match(script)
+}
+
+// BuildWitness calls the witness builder function of the finalizer.
+func (f *scriptFinalizerFuncs) BuildWitness(pInput *PInput,
+	script []byte) (wire.TxWitness, error) {
+
+	return f.build(pInput, script)
+}
+
+// NewScriptFinalizer returns a ScriptFinalizer that uses the passed script
+// template matcher and witness builder functions to implement the methods of
+// the interface.
+func NewScriptFinalizer(match func(script []byte) bool,
+	build func(pInput *PInput, script []byte) (wire.TxWitness,
+		error)) ScriptFinalizer {
+
+	return &scriptFinalizerFuncs{match: match, build: build}
+}
+
+var (
+	// registeredFinalizers holds the finalizers added through
+	// RegisterFinalizer, the most recently registered first.
+	registeredFinalizers []ScriptFinalizer
+
+	// finalizersMtx protects registeredFinalizers.
+	finalizersMtx sync.RWMutex
+
+	// builtinFinalizers are the finalizers for the standard script types,
+	// which are consulted after all registered ones.
+	builtinFinalizers = []ScriptFinalizer{
+		&pubKeyHashFinalizer{},
+		&multiSigFinalizer{},
+	}
+)
+
+// RegisterFinalizer adds a finalizer for a custom kind of script, such as an
+// HTLC or a timelocked vault script.  Finalizers are consulted in the reverse
+// order of registration, and all of them before the built-in finalizers for
+// P2PKH, P2WPKH and multisig scripts, so a registered finalizer may also
+// replace the handling of a standard script.
+func RegisterFinalizer(f ScriptFinalizer) {
+	finalizersMtx.Lock()
+	defer finalizersMtx.Unlock()
+
+	registeredFinalizers = append(
+		[]ScriptFinalizer{f}, registeredFinalizers...,
+	)
+}
+
+// buildFinalStack returns the stack items satisfying the passed script, using
+// the first finalizer that matches it.  ErrUnsupportedScriptType is returned
+// if no finalizer matches.
+func buildFinalStack(pInput *PInput, script []byte) (wire.TxWitness, error) {
+	// The partial signatures must all use the sighash type the input asks
+	// for, whichever finalizer ends up using them.
+	for _, ps := range pInput.PartialSigs {
+		if !checkSigHashFlags(ps.Signature, pInput) {
+			return nil, ErrInvalidSigHashFlags
+		}
+	}
+
+	finalizersMtx.RLock()
+	finalizers := make(
+		[]ScriptFinalizer, 0,
+		len(registeredFinalizers)+len(builtinFinalizers),
+	)
+	finalizers = append(finalizers, registeredFinalizers...)
+	finalizersMtx.RUnlock()
+	finalizers = append(finalizers, builtinFinalizers...)
+
+	for _, f := range finalizers {
+		if f.MatchScript(script) {
+			return f.BuildWitness(pInput, script)
+		}
+	}
+
+	return nil, ErrUnsupportedScriptType
+}
+
+// pubKeyHashFinalizer finalizes P2PKH and P2WPKH scripts, which are satisfied
+// by a single signature followed by the public key.
+type pubKeyHashFinalizer struct{}
+
+// MatchScript returns true for P2PKH scripts and P2WPKH witness programs.
+func (f *pubKeyHashFinalizer) MatchScript(script []byte) bool {
+	return txscript.IsPayToWitnessPubKeyHash(script) ||
+		txscript.GetScriptClass(script) == txscript.PubKeyHashTy
+}
+
+// BuildWitness returns the signature and public key of the only partial
+// signature of the input.
+func (f *pubKeyHashFinalizer) BuildWitness(pInput *PInput,
+	script []byte) (wire.TxWitness, error) {
+
+	if len(pInput.PartialSigs) != 1 {
+		return nil, ErrNotFinalizable
+	}
+	ps := pInput.PartialSigs[0]
+
+	return wire.TxWitness{ps.Signature, ps.PubKey}, nil
+}
+
+// multiSigFinalizer finalizes bare multisig scripts, which are satisfied by
+// the signatures in the order of their public keys in the script.
+type multiSigFinalizer struct{}
+
+// MatchScript returns true for multisig scripts.
+func (f *multiSigFinalizer) MatchScript(script []byte) bool {
+	return txscript.GetScriptClass(script) == txscript.MultiSigTy
+}
+
+// BuildWitness returns the ordered signatures, preceded by the empty element
+// that OP_CHECKMULTISIG pops in excess.
+func (f *multiSigFinalizer) BuildWitness(pInput *PInput,
+	script []byte) (wire.TxWitness, error) {
+
+	if len(pInput.PartialSigs) == 0 {
+		return nil, ErrNotFinalizable
+	}
+
+	var (
+		pubKeys [][]byte
+		sigs    [][]byte
+	)
+	for _, ps := range pInput.PartialSigs {
+		pubKeys = append(pubKeys, ps.PubKey)
+		sigs = append(sigs, ps.Signature)
+	}
+
+	orderedSigs, err := extractKeyOrderFromScript(script, pubKeys, sigs)
+	if err != nil {
+		return nil, err
+	}
+
+	stack := make(wire.TxWitness, 0, len(orderedSigs)+1)
+	stack = append(stack, nil)
+	stack = append(stack, orderedSigs...)
+
+	return stack, nil
+}
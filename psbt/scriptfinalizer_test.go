@@ -0,0 +1,229 @@
+// Copyright (c) 2026 The monasuite developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package psbt
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"testing"
+
+	"github.com/monasuite/monad/btcec"
+	"github.com/monasuite/monad/chaincfg/chainhash"
+	"github.com/monasuite/monad/txscript"
+	"github.com/monasuite/monad/wire"
+)
+
+// hashLockScript returns a script that can be spent with a signature of
+// pubKey and the preimage of hash.
+func hashLockScript(t *testing.T, hash, pubKey []byte) []byte {
+	t.Helper()
+
+	script, err := txscript.NewScriptBuilder().
+		AddOp(txscript.OP_SHA256).AddData(hash).
+		AddOp(txscript.OP_EQUALVERIFY).
+		AddData(pubKey).AddOp(txscript.OP_CHECKSIG).
+		Script()
+	if err != nil {
+		t.Fatalf("unable to build script: %v", err)
+	}
+
+	return script
+}
+
+// saveRegisteredFinalizers returns a function restoring the currently
+// registered finalizers, to be deferred by tests registering their own.
+func saveRegisteredFinalizers() func() {
+	finalizersMtx.Lock()
+	saved := registeredFinalizers
+	finalizersMtx.Unlock()
+
+	return func() {
+		finalizersMtx.Lock()
+		registeredFinalizers = saved
+		finalizersMtx.Unlock()
+	}
+}
+
+func TestRegisterFinalizer(t *testing.T) {
+	defer saveRegisteredFinalizers()()
+
+	privKey, err := btcec.NewPrivateKey(btcec.S256())
+	if err != nil {
+		t.Fatalf("unable to create key: %v", err)
+	}
+	pubKey := privKey.PubKey().SerializeCompressed()
+
+	preimage := bytes.Repeat([]byte{0x42}, 32)
+	hash := sha256.Sum256(preimage)
+	witnessScript := hashLockScript(t, hash[:], pubKey)
+
+	scriptHash := sha256.Sum256(witnessScript)
+	pkScript, err := txscript.NewScriptBuilder().
+		AddOp(txscript.OP_0).AddData(scriptHash[:]).Script()
+	if err != nil {
+		t.Fatalf("unable to build output script: %v", err)
+	}
+
+	packet, err := New(
+		[]*wire.OutPoint{{Hash: chainhash.Hash{0x01}, Index: 0}},
+		[]*wire.TxOut{{Value: 9000, PkScript: []byte{txscript.OP_TRUE}}},
+		2, 0, []uint32{wire.MaxTxInSequenceNum},
+	)
+	if err != nil {
+		t.Fatalf("unable to create packet: %v", err)
+	}
+	utxo := &wire.TxOut{Value: 10000, PkScript: pkScript}
+	packet.Inputs[0].WitnessUtxo = utxo
+	packet.Inputs[0].WitnessScript = witnessScript
+
+	sig, err := txscript.RawTxInWitnessSignature(
+		packet.UnsignedTx, txscript.NewTxSigHashes(packet.UnsignedTx),
+		0, utxo.Value, witnessScript, txscript.SigHashAll, privKey,
+	)
+	if err != nil {
+		t.Fatalf("unable to sign input: %v", err)
+	}
+	packet.Inputs[0].PartialSigs = []*PartialSig{{
+		PubKey: pubKey, Signature: sig,
+	}}
+
+	// Without a finalizer for the script the input can't be finalized.
+	if err := Finalize(packet, 0); err != ErrUnsupportedScriptType {
+		t.Fatalf("expected ErrUnsupportedScriptType, got %v", err)
+	}
+
+	RegisterFinalizer(NewScriptFinalizer(
+		func(script []byte) bool {
+			return bytes.Equal(script, witnessScript)
+		},
+		func(pInput *PInput, script []byte) (wire.TxWitness, error) {
+			if len(pInput.PartialSigs) != 1 {
+				return nil, ErrNotFinalizable
+			}
+			return wire.TxWitness{
+				pInput.PartialSigs[0].Signature, preimage,
+			}, nil
+		},
+	))
+
+	if err := Finalize(packet, 0); err != nil {
+		t.Fatalf("unable to finalize input: %v", err)
+	}
+
+	tx, err := Extract(packet)
+	if err != nil {
+		t.Fatalf("unable to extract tx: %v", err)
+	}
+	witness := tx.TxIn[0].Witness
+	if len(witness) != 3 || !bytes.Equal(witness[2], witnessScript) {
+		t.Fatalf("witness script not appended: %x", witness)
+	}
+
+	vm, err := txscript.NewEngine(
+		pkScript, tx, 0, txscript.StandardVerifyFlags, nil, nil,
+		utxo.Value,
+	)
+	if err != nil {
+		t.Fatalf("unable to create engine: %v", err)
+	}
+	if err := vm.Execute(); err != nil {
+		t.Fatalf("final witness doesn't satisfy the script: %v", err)
+	}
+}
+
+func TestRegisterFinalizerOrder(t *testing.T) {
+	defer saveRegisteredFinalizers()()
+
+	matchAll := func([]byte) bool { return true }
+	build := func(item byte) func(*PInput, []byte) (wire.TxWitness,
+		error) {
+
+		return func(*PInput, []byte) (wire.TxWitness, error) {
+			return wire.TxWitness{{item}}, nil
+		}
+	}
+
+	// Registered finalizers take precedence over the built-in ones, the
+	// most recently registered first.
+	RegisterFinalizer(NewScriptFinalizer(matchAll, build(1)))
+	RegisterFinalizer(NewScriptFinalizer(matchAll, build(2)))
+
+	pInput := &PInput{}
+	stack, err := buildFinalStack(pInput, []byte{txscript.OP_TRUE})
+	if err != nil {
+		t.Fatalf("unable to build stack: %v", err)
+	}
+	if len(stack) != 1 || !bytes.Equal(stack[0], []byte{2}) {
+		t.Fatalf("unexpected stack %x", stack)
+	}
+}
+
+func TestRegisterFinalizerWithoutSignatures(t *testing.T) {
+	defer saveRegisteredFinalizers()()
+
+	// The script is spent with the preimage alone, so the input carries no
+	// partial signatures.
+	preimage := bytes.Repeat([]byte{0x42}, 32)
+	hash := sha256.Sum256(preimage)
+	witnessScript, err := txscript.NewScriptBuilder().
+		AddOp(txscript.OP_SHA256).AddData(hash[:]).
+		AddOp(txscript.OP_EQUAL).
+		Script()
+	if err != nil {
+		t.Fatalf("unable to build script: %v", err)
+	}
+
+	scriptHash := sha256.Sum256(witnessScript)
+	pkScript, err := txscript.NewScriptBuilder().
+		AddOp(txscript.OP_0).AddData(scriptHash[:]).Script()
+	if err != nil {
+		t.Fatalf("unable to build output script: %v", err)
+	}
+
+	packet, err := New(
+		[]*wire.OutPoint{{Hash: chainhash.Hash{0x01}, Index: 0}},
+		[]*wire.TxOut{{Value: 9000, PkScript: []byte{txscript.OP_TRUE}}},
+		2, 0, []uint32{wire.MaxTxInSequenceNum},
+	)
+	if err != nil {
+		t.Fatalf("unable to create packet: %v", err)
+	}
+	utxo := &wire.TxOut{Value: 10000, PkScript: pkScript}
+	packet.Inputs[0].WitnessUtxo = utxo
+	packet.Inputs[0].WitnessScript = witnessScript
+
+	_, err = MaybeFinalize(packet, 0)
+	if err != ErrUnsupportedScriptType {
+		t.Fatalf("expected ErrUnsupportedScriptType, got %v", err)
+	}
+
+	RegisterFinalizer(NewScriptFinalizer(
+		func(script []byte) bool {
+			return bytes.Equal(script, witnessScript)
+		},
+		func(*PInput, []byte) (wire.TxWitness, error) {
+			return wire.TxWitness{preimage}, nil
+		},
+	))
+
+	if err := MaybeFinalizeAll(packet); err != nil {
+		t.Fatalf("unable to finalize inputs: %v", err)
+	}
+
+	tx, err := Extract(packet)
+	if err != nil {
+		t.Fatalf("unable to extract tx: %v", err)
+	}
+	vm, err := txscript.NewEngine(
+		pkScript, tx, 0, txscript.StandardVerifyFlags, nil, nil,
+		utxo.Value,
+	)
+	if err != nil {
+		t.Fatalf("unable to create engine: %v", err)
+	}
+	if err := vm.Execute(); err != nil {
+		t.Fatalf("final witness doesn't satisfy the script: %v", err)
+	}
+}
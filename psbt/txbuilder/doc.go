@@ -0,0 +1,39 @@
+// Copyright (c) 2026 The monasuite developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+/*
+Package txbuilder builds unsigned transactions as PSBTs, funded from a set of
+coins.
+
+Overview
+
+A Builder pays a list of outputs from coins chosen by any coinset.CoinSelector,
+at a fee rate.  The fee is computed from the largest size the transaction may
+have once it is signed, and the coin selector is asked for more coins until
+the selected coins also pay the fee of spending them.  Change is paid to an
+address returned by a ChangeSource, which is only asked for an address when
+the transaction has change, and change too small to be relayed is left to the
+fee instead.
+
+The result is a psbt.Packet with the UTXO information signers need: the
+WitnessUtxo of coins paying to witness programs, or to P2SH scripts the
+caller declares as nesting one, and the NonWitnessUtxo of other coins, looked
+up through a PrevTxSource.  Inputs and outputs are
+sorted as described in BIP 69, and the change output can be moved to a random
+position afterwards.
+
+Signing
+
+The packet is signed by the usual PSBT signers, such as psbt.KeySigner, then
+finalized and extracted:
+
+	built, err := builder.Build(outputs, coins)
+	...
+	_, err = signer.Sign(built.Packet)
+	...
+	err = psbt.MaybeFinalizeAll(built.Packet)
+	...
+	tx, err := psbt.Extract(built.Packet)
+*/
+package txbuilder
@@ -0,0 +1,61 @@
+// Copyright (c) 2026 The monasuite developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package txbuilder_test
+
+import (
+	"fmt"
+
+	"github.com/monasuite/monad/txscript"
+	"github.com/monasuite/monad/wire"
+	"github.com/monasuite/monautil"
+	"github.com/monasuite/monautil/coinset"
+	"github.com/monasuite/monautil/psbt/txbuilder"
+)
+
+// This example demonstrates how to build a transaction paying an address from
+// a P2WPKH coin, with change paid back to the wallet.
+func ExampleBuilder_Build() {
+	// The coin is an output of 1 MONA paying to a P2WPKH address of the
+	// wallet.
+	walletAddr := p2wpkhAddress(newKey(1))
+	pkScript, err := txscript.PayToAddrScript(walletAddr)
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	prevTx := wire.NewMsgTx(wire.TxVersion)
+	prevTx.AddTxIn(&wire.TxIn{})
+	prevTx.AddTxOut(wire.NewTxOut(1e8, pkScript))
+	coins := []coinset.Coin{
+		&coinset.SimpleCoin{Tx: monautil.NewTx(prevTx), TxNumConfs: 6},
+	}
+
+	builder := &txbuilder.Builder{
+		Selector: &coinset.MinNumberCoinSelector{MaxInputs: 10},
+		FeeRate:  monautil.FeeRatePerKVByte(100000),
+		ChangeSource: func() (monautil.Address, error) {
+			return walletAddr, nil
+		},
+	}
+	built, err := builder.Build([]*txbuilder.Output{
+		{Address: p2wpkhAddress(newKey(2)), Amount: 5e7},
+	}, coins)
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+
+	tx := built.Packet.UnsignedTx
+	fmt.Println("Inputs:", len(tx.TxIn))
+	fmt.Println("Outputs:", len(tx.TxOut))
+	fmt.Println("Fee:", built.Fee)
+	fmt.Println("Change:", monautil.Amount(tx.TxOut[built.ChangeIndex].Value))
+
+	// Output:
+	// Inputs: 1
+	// Outputs: 2
+	// Fee: 0.000141 MONA
+	// Change: 0.499859 MONA
+}
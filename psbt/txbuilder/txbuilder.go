@@ -0,0 +1,552 @@
+// Copyright (c) 2026 The monasuite developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package txbuilder
+
+import (
+	"errors"
+	"fmt"
+	"math/rand"
+
+	"github.com/monasuite/monad/chaincfg/chainhash"
+	"github.com/monasuite/monad/txscript"
+	"github.com/monasuite/monad/wire"
+	"github.com/monasuite/monautil"
+	"github.com/monasuite/monautil/coinset"
+	"github.com/monasuite/monautil/policy"
+	"github.com/monasuite/monautil/psbt"
+	"github.com/monasuite/monautil/txsizes"
+)
+
+const (
+	// DefaultTxVersion is the version of the built transactions when
+	// Builder.TxVersion is zero.
+	DefaultTxVersion = 2
+
+	// baseTxSize is the size of the version and locktime of a
+	// transaction.
+	baseTxSize = 4 + 4
+
+	// witnessHeaderSize is the size of the marker and flag bytes of a
+	// transaction with witness data.
+	witnessHeaderSize = 2
+)
+
+var (
+	// ErrNoOutputs describes an error in which a transaction without any
+	// outputs is to be built.
+	ErrNoOutputs = errors.New("transaction has no outputs")
+
+	// ErrNoSelector describes an error in which a Builder has no coin
+	// selector.
+	ErrNoSelector = errors.New("no coin selector")
+
+	// ErrNoChangeSource describes an error in which a transaction needs a
+	// change output but the Builder has no change source.
+	ErrNoChangeSource = errors.New("transaction needs change but no " +
+		"change source is set")
+
+	// ErrPrevTxMismatch describes an error in which the previous
+	// transaction returned for a coin doesn't contain the coin.
+	ErrPrevTxMismatch = errors.New("previous transaction doesn't " +
+		"match the coin")
+)
+
+// OutputAmountError describes an output whose amount is negative or exceeds
+// monautil.MaxSatoshi, alone or added to the amounts of the outputs before
+// it.
+type OutputAmountError struct {
+	// Index is the index of the output in the outputs passed to Build.
+	Index int
+
+	// Amount is the amount of the output.
+	Amount monautil.Amount
+}
+
+// Error satisfies the error interface and prints human-readable errors.
+func (e *OutputAmountError) Error() string {
+	return fmt.Sprintf("output %d of %v is out of range", e.Index, e.Amount)
+}
+
+// DustOutputError describes an output too small to be relayed, given the
+// minimum relay fee of the Builder.
+type DustOutputError struct {
+	// Index is the index of the output in the outputs passed to Build.
+	Index int
+
+	// Amount is the amount of the output.
+	Amount monautil.Amount
+}
+
+// Error satisfies the error interface and prints human-readable errors.
+func (e *DustOutputError) Error() string {
+	return fmt.Sprintf("output %d of %v is dust", e.Index, e.Amount)
+}
+
+// MissingPrevTxError describes a coin which can't be spent with witness data,
+// so the whole previous transaction is needed to sign for it, but it couldn't
+// be looked up.
+type MissingPrevTxError struct {
+	// OutPoint is the outpoint of the coin.
+	OutPoint wire.OutPoint
+
+	// Err is the error returned by the PrevTxSource, or nil if the
+	// Builder has none.
+	Err error
+}
+
+// Error satisfies the error interface and prints human-readable errors.
+func (e *MissingPrevTxError) Error() string {
+	if e.Err == nil {
+		return fmt.Sprintf("previous transaction of %v is needed",
+			e.OutPoint)
+	}
+	return fmt.Sprintf("previous transaction of %v is needed: %v",
+		e.OutPoint, e.Err)
+}
+
+// Unwrap returns the error of the PrevTxSource.
+func (e *MissingPrevTxError) Unwrap() error {
+	return e.Err
+}
+
+// Output is a payment made by a built transaction.
+type Output struct {
+	Address monautil.Address
+	Amount  monautil.Amount
+}
+
+// ChangeSource returns the address change is paid to.  It is only called for
+// transactions that have a change output.
+type ChangeSource func() (monautil.Address, error)
+
+// PrevTxSource returns the transaction with the passed hash.  It is used to
+// fill in the NonWitnessUtxo of the inputs.
+type PrevTxSource func(hash *chainhash.Hash) (*wire.MsgTx, error)
+
+// NestedWitnessFunc returns whether a P2SH coin nests a witness program, such
+// as a P2SH-P2WPKH coin, so it is spent with witness data.
+type NestedWitnessFunc func(coinset.Coin) bool
+
+// Builder builds unsigned transactions paying a set of outputs from coins
+// chosen by a coin selector, at a fee rate, and returns them as PSBTs ready to
+// be signed.  Change is paid to an address of the change source, unless it
+// would be dust, in which case it is left to the fee.  Inputs and outputs are
+// ordered as described in BIP 69.
+//
+// The builder adds the fees of the transaction to the value the coin selector
+// is asked for, and asks again for more when the fees of the selected inputs
+// aren't paid.  Selectors based on effective values, such as
+// coinset.MinWasteCoinSelector, already account for the fees of the inputs,
+// so they should be configured with the same fee rate and input weights, and
+// with zero BaseWeight and ChangeWeight.
+type Builder struct {
+	// Selector chooses the coins spent by the transaction.
+	Selector coinset.CoinSelector
+
+	// FeeRate is the fee rate paid by the transaction.  The fee is
+	// computed from the largest size the transaction may have once it is
+	// signed, so the fee rate is never undershot.
+	FeeRate monautil.FeeRatePerKVByte
+
+	// ChangeSource returns the address of the change output.
+	ChangeSource ChangeSource
+
+	// ChangeScriptSize is the size of the public key script of the change
+	// outputs, used to estimate the fee before the change address is
+	// known.  The size of a P2WPKH script is used when it is zero.
+	ChangeScriptSize int
+
+	// InputWeight returns the weight of the input spending a coin.
+	// coinset.ScriptInputWeight is used when it is nil.
+	InputWeight coinset.InputWeightFunc
+
+	// PrevTxs looks up the previous transactions of the coins to fill in
+	// the NonWitnessUtxo of the inputs.  It is required for coins which
+	// aren't spent with witness data, and optional otherwise.
+	PrevTxs PrevTxSource
+
+	// IsNestedWitness returns whether a P2SH coin nests a witness
+	// program, in which case its input is given a WitnessUtxo and its
+	// previous transaction is optional.  P2SH coins are assumed not to
+	// nest one when it is nil, so their previous transactions are
+	// required.
+	IsNestedWitness NestedWitnessFunc
+
+	// MinRelayFee is the minimum relay fee rate, which determines the
+	// outputs that are dust.  policy.DefaultMinRelayFee is used when it is
+	// zero.
+	MinRelayFee monautil.FeeRatePerKVByte
+
+	// TxVersion is the version of the transaction.  DefaultTxVersion is
+	// used when it is zero.
+	TxVersion int32
+
+	// LockTime is the locktime of the transaction.
+	LockTime uint32
+
+	// SignalRBF sets the sequence numbers of the inputs to signal that
+	// the transaction may be replaced, as described in BIP 125.
+	SignalRBF bool
+
+	// RandomizeChange moves the change output to a random position after
+	// the outputs are sorted, so it can't be told apart from the payments
+	// by its position.
+	RandomizeChange bool
+
+	// Rand is the source of randomness used to place the change output,
+	// or nil for the default source.
+	Rand *rand.Rand
+}
+
+// BuiltTx is an unsigned transaction built by a Builder.
+type BuiltTx struct {
+	// Packet is the transaction as a PSBT, with the UTXO information of
+	// every input.
+	Packet *psbt.Packet
+
+	// InputValue is the total value of the inputs.
+	InputValue monautil.Amount
+
+	// Fee is the fee paid by the transaction.
+	Fee monautil.Amount
+
+	// ChangeIndex is the index of the change output, or -1 if the
+	// transaction has none.
+	ChangeIndex int
+}
+
+// Build builds a transaction paying the outputs from the coins.  The error of
+// the coin selector is returned when the coins can't pay for the outputs and
+// the fee, and an OutputAmountError or a DustOutputError when an output
+// can't be paid.
+func (b *Builder) Build(outputs []*Output, coins []coinset.Coin) (*BuiltTx,
+	error) {
+
+	if len(outputs) == 0 {
+		return nil, ErrNoOutputs
+	}
+	if b.Selector == nil {
+		return nil, ErrNoSelector
+	}
+
+	txOuts := make([]*wire.TxOut, len(outputs))
+	var outputValue monautil.Amount
+	for i, out := range outputs {
+		pkScript, err := monautil.PayToAddrScript(out.Address)
+		if err != nil {
+			return nil, err
+		}
+		txOuts[i] = wire.NewTxOut(int64(out.Amount), pkScript)
+		outputValue, err = outputValue.Add(out.Amount)
+		if err != nil || out.Amount < 0 {
+			return nil, &OutputAmountError{Index: i, Amount: out.Amount}
+		}
+		if b.isDust(txOuts[i]) {
+			return nil, &DustOutputError{Index: i, Amount: out.Amount}
+		}
+	}
+
+	changeScriptSize := b.ChangeScriptSize
+	if changeScriptSize == 0 {
+		changeScriptSize = txsizes.P2WPKHPkScriptSize
+	}
+
+	// Ask the selector for the value of the outputs and the fee, until the
+	// selected coins pay the fee for spending them as well.  The fee asked
+	// for grows on every pass, so the passes end once enough coins are
+	// selected or the selector runs out of them.
+	target := outputValue + b.FeeRate.FeeForWeight(
+		b.estimateWeight(nil, txOuts, changeScriptSize),
+	)
+	var (
+		selected   []coinset.Coin
+		inputValue monautil.Amount
+	)
+	for {
+		selection, err := b.Selector.CoinSelect(target, coins)
+		if err != nil {
+			return nil, err
+		}
+		selected = selection.Coins()
+		inputValue = 0
+		for _, c := range selected {
+			inputValue += c.Value()
+		}
+
+		fee := b.FeeRate.FeeForWeight(
+			b.estimateWeight(selected, txOuts, -1),
+		)
+		if inputValue >= outputValue+fee {
+			break
+		}
+
+		next := outputValue + b.FeeRate.FeeForWeight(
+			b.estimateWeight(selected, txOuts, changeScriptSize),
+		)
+		if next <= target {
+			// The selector returned less than it was asked for.
+			return nil, coinset.ErrCoinsNoSelectionAvailable
+		}
+		target = next
+	}
+
+	// Add a change output if the value left after the fee with change
+	// isn't dust.  The change address is only requested now, and the fee
+	// is computed with the size of its actual script.
+	changeIndex := -1
+	if b.FeeRate.FeeForWeight(b.estimateWeight(selected, txOuts,
+		changeScriptSize)) < inputValue-outputValue {
+
+		changeOut, err := b.changeOutput(selected, txOuts, inputValue-
+			outputValue)
+		if err != nil {
+			return nil, err
+		}
+		if changeOut != nil {
+			txOuts = append(txOuts, changeOut)
+			changeIndex = len(txOuts) - 1
+		}
+	}
+
+	packet, err := b.newPacket(selected, txOuts)
+	if err != nil {
+		return nil, err
+	}
+
+	// Sort the inputs and outputs, keeping track of the change output by
+	// its identity.
+	var changeOut *wire.TxOut
+	if changeIndex >= 0 {
+		changeOut = txOuts[changeIndex]
+	}
+	if err := psbt.InPlaceSort(packet); err != nil {
+		return nil, err
+	}
+	if changeOut != nil {
+		for i, txOut := range packet.UnsignedTx.TxOut {
+			if txOut == changeOut {
+				changeIndex = i
+				break
+			}
+		}
+		if b.RandomizeChange {
+			changeIndex = b.moveChange(packet, changeIndex)
+		}
+	}
+
+	var txOutValue monautil.Amount
+	for _, txOut := range packet.UnsignedTx.TxOut {
+		txOutValue += monautil.Amount(txOut.Value)
+	}
+
+	return &BuiltTx{
+		Packet:      packet,
+		InputValue:  inputValue,
+		Fee:         inputValue - txOutValue,
+		ChangeIndex: changeIndex,
+	}, nil
+}
+
+// changeOutput returns the change output of a transaction spending the coins
+// to the outputs, where excess is the value of the coins above the outputs, or
+// nil if the change would be dust.
+func (b *Builder) changeOutput(coins []coinset.Coin, txOuts []*wire.TxOut,
+	excess monautil.Amount) (*wire.TxOut, error) {
+
+	if b.ChangeSource == nil {
+		return nil, ErrNoChangeSource
+	}
+	addr, err := b.ChangeSource()
+	if err != nil {
+		return nil, err
+	}
+	pkScript, err := monautil.PayToAddrScript(addr)
+	if err != nil {
+		return nil, err
+	}
+
+	fee := b.FeeRate.FeeForWeight(
+		b.estimateWeight(coins, txOuts, len(pkScript)),
+	)
+	changeOut := wire.NewTxOut(int64(excess-fee), pkScript)
+	if excess <= fee || b.isDust(changeOut) {
+		return nil, nil
+	}
+
+	return changeOut, nil
+}
+
+// newPacket returns the PSBT of the transaction spending the coins to the
+// outputs, with the UTXO information of the inputs.
+func (b *Builder) newPacket(coins []coinset.Coin,
+	txOuts []*wire.TxOut) (*psbt.Packet, error) {
+
+	version := b.TxVersion
+	if version == 0 {
+		version = DefaultTxVersion
+	}
+
+	// Inputs with the largest sequence number don't enforce the locktime,
+	// so a smaller one is used when there is a locktime.
+	sequence := uint32(wire.MaxTxInSequenceNum)
+	switch {
+	case b.SignalRBF:
+		sequence = wire.MaxTxInSequenceNum - 2
+	case b.LockTime != 0:
+		sequence = wire.MaxTxInSequenceNum - 1
+	}
+
+	inputs := make([]*wire.OutPoint, len(coins))
+	sequences := make([]uint32, len(coins))
+	for i, c := range coins {
+		inputs[i] = wire.NewOutPoint(c.Hash(), c.Index())
+		sequences[i] = sequence
+	}
+	packet, err := psbt.New(inputs, txOuts, version, b.LockTime, sequences)
+	if err != nil {
+		return nil, err
+	}
+
+	for i, c := range coins {
+		prevTx, err := b.prevTx(c)
+		if err != nil {
+			return nil, err
+		}
+
+		pInput := &packet.Inputs[i]
+		pInput.NonWitnessUtxo = prevTx
+		if b.isWitnessCoin(c) {
+			pInput.WitnessUtxo = wire.NewTxOut(
+				int64(c.Value()), c.PkScript(),
+			)
+		}
+	}
+
+	return packet, nil
+}
+
+// prevTx returns the previous transaction of the coin, or nil if it isn't
+// needed and can't be looked up.
+func (b *Builder) prevTx(c coinset.Coin) (*wire.MsgTx, error) {
+	op := wire.OutPoint{Hash: *c.Hash(), Index: c.Index()}
+	if b.PrevTxs == nil {
+		if b.isWitnessCoin(c) {
+			return nil, nil
+		}
+		return nil, &MissingPrevTxError{OutPoint: op}
+	}
+
+	prevTx, err := b.PrevTxs(c.Hash())
+	if err != nil || prevTx == nil {
+		if b.isWitnessCoin(c) {
+			return nil, nil
+		}
+		return nil, &MissingPrevTxError{OutPoint: op, Err: err}
+	}
+
+	// The transaction must actually contain the coin, as signers rely on
+	// it for the value being spent.
+	if prevTx.TxHash() != op.Hash || int(op.Index) >= len(prevTx.TxOut) {
+		return nil, ErrPrevTxMismatch
+	}
+	coinOut := wire.NewTxOut(int64(c.Value()), c.PkScript())
+	if !psbt.TxOutsEqual(prevTx.TxOut[op.Index], coinOut) {
+		return nil, ErrPrevTxMismatch
+	}
+
+	return prevTx, nil
+}
+
+// isWitnessCoin returns whether the coin is spent with witness data.  See
+// IsWitnessCoin.
+func (b *Builder) isWitnessCoin(c coinset.Coin) bool {
+	return IsWitnessCoin(c, b.IsNestedWitness)
+}
+
+// IsWitnessCoin returns whether the coin is spent with witness data, so the
+// WitnessUtxo of its input is enough to sign for it.  P2SH coins are only
+// spent with witness data if isNested says so, and never if it is nil.
+func IsWitnessCoin(c coinset.Coin, isNested NestedWitnessFunc) bool {
+	pkScript := c.PkScript()
+	if txscript.IsPayToScriptHash(pkScript) {
+		return isNested != nil && isNested(c)
+	}
+	return txscript.IsWitnessProgram(pkScript)
+}
+
+// estimateWeight returns the largest weight of the transaction spending the
+// coins to the outputs.  See EstimateWeight.
+func (b *Builder) estimateWeight(coins []coinset.Coin, txOuts []*wire.TxOut,
+	changeScriptSize int) int64 {
+
+	return EstimateWeight(coins, txOuts, changeScriptSize, b.InputWeight)
+}
+
+// EstimateWeight returns the largest weight of the transaction spending the
+// coins to the outputs once it is signed, along with a change output with a
+// public key script of the passed size unless it is negative.  The weights of
+// the inputs are returned by inputWeight, or by coinset.ScriptInputWeight if
+// it is nil.  The marker and flag bytes are always counted, along with an
+// empty witness for every input, so the estimate never falls short whatever
+// the inputs are.
+func EstimateWeight(coins []coinset.Coin, txOuts []*wire.TxOut,
+	changeScriptSize int, inputWeight coinset.InputWeightFunc) int64 {
+
+	numOutputs := len(txOuts)
+	size := baseTxSize
+	for _, txOut := range txOuts {
+		size += txOut.SerializeSize()
+	}
+	if changeScriptSize >= 0 {
+		numOutputs++
+		size += txsizes.OutputSize(changeScriptSize)
+	}
+	size += wire.VarIntSerializeSize(uint64(len(coins))) +
+		wire.VarIntSerializeSize(uint64(numOutputs))
+
+	if inputWeight == nil {
+		inputWeight = coinset.ScriptInputWeight
+	}
+	weight := int64(size*monautil.WitnessScaleFactor + witnessHeaderSize)
+	for _, c := range coins {
+		weight += inputWeight(c) + 1
+	}
+
+	return weight
+}
+
+// isDust returns whether the output is too small to be relayed at the minimum
+// relay fee of the builder.  See policy.IsDust.
+func (b *Builder) isDust(txOut *wire.TxOut) bool {
+	minRelayFee := b.MinRelayFee
+	if minRelayFee == 0 {
+		minRelayFee = policy.DefaultMinRelayFee
+	}
+	return policy.IsDust(txOut, minRelayFee)
+}
+
+// moveChange moves the change output at the passed index to a random position
+// and returns the new index.
+func (b *Builder) moveChange(packet *psbt.Packet, changeIndex int) int {
+	n := len(packet.UnsignedTx.TxOut)
+	var newIndex int
+	if b.Rand == nil {
+		newIndex = rand.Intn(n)
+	} else {
+		newIndex = b.Rand.Intn(n)
+	}
+
+	txOuts, pOutputs := packet.UnsignedTx.TxOut, packet.Outputs
+	changeOut, changePOut := txOuts[changeIndex], pOutputs[changeIndex]
+	for i := changeIndex; i < newIndex; i++ {
+		txOuts[i], pOutputs[i] = txOuts[i+1], pOutputs[i+1]
+	}
+	for i := changeIndex; i > newIndex; i-- {
+		txOuts[i], pOutputs[i] = txOuts[i-1], pOutputs[i-1]
+	}
+	txOuts[newIndex], pOutputs[newIndex] = changeOut, changePOut
+
+	return newIndex
+}
@@ -0,0 +1,572 @@
+// Copyright (c) 2026 The monasuite developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package txbuilder_test
+
+import (
+	"bytes"
+	"errors"
+	"math/rand"
+	"sort"
+	"testing"
+
+	"github.com/monasuite/monad/btcec"
+	"github.com/monasuite/monad/chaincfg/chainhash"
+	"github.com/monasuite/monad/txscript"
+	"github.com/monasuite/monad/wire"
+	"github.com/monasuite/monautil"
+	"github.com/monasuite/monautil/coinset"
+	"github.com/monasuite/monautil/psbt"
+	"github.com/monasuite/monautil/psbt/internal/testwallet"
+	"github.com/monasuite/monautil/psbt/txbuilder"
+)
+
+// testFeeRate is the fee rate of the transactions built by the tests, 100
+// watanabe per virtual byte.
+const testFeeRate = monautil.FeeRatePerKVByte(100000)
+
+// testSelector is the coin selector of the tests, which selects coins in
+// order until their value is enough.
+var testSelector = &coinset.MinIndexCoinSelector{MaxInputs: 10}
+
+// The fixtures of the tests are shared with the tests of the other packages
+// building transactions.
+var (
+	newKey        = testwallet.NewKey
+	p2wpkhAddress = testwallet.P2WPKHAddress
+	p2pkhAddress  = testwallet.P2PKHAddress
+	payment       = testwallet.Payment
+	net           = testwallet.Net
+)
+
+// changeSource returns a change source of the address of the passed key,
+// counting the calls made to it.
+func changeSource(key *btcec.PrivateKey, calls *int) txbuilder.ChangeSource {
+	return func() (monautil.Address, error) {
+		*calls++
+		return p2wpkhAddress(key), nil
+	}
+}
+
+// checkBuiltTx checks that the built transaction pays the outputs at the fee
+// rate, is sorted, carries the UTXO information of its inputs, and can be
+// signed into a valid transaction.
+func checkBuiltTx(t *testing.T, w *testwallet.Wallet,
+	outputs []*txbuilder.Output, changeAddr monautil.Address,
+	built *txbuilder.BuiltTx) {
+
+	t.Helper()
+
+	p := built.Packet
+	tx := p.UnsignedTx
+
+	// Every payment must be made, along with the change output if any.
+	var outputValue monautil.Amount
+	wantOuts := len(outputs)
+	for _, out := range outputs {
+		outputValue += out.Amount
+	}
+	if built.ChangeIndex >= 0 {
+		wantOuts++
+		changeScript, _ := txscript.PayToAddrScript(changeAddr)
+		changeOut := tx.TxOut[built.ChangeIndex]
+		if !bytes.Equal(changeOut.PkScript, changeScript) {
+			t.Errorf("output %d isn't the change output",
+				built.ChangeIndex)
+		}
+		outputValue += monautil.Amount(changeOut.Value)
+	}
+	if len(tx.TxOut) != wantOuts {
+		t.Errorf("transaction has %d outputs, want %d", len(tx.TxOut),
+			wantOuts)
+	}
+	if built.InputValue-outputValue != built.Fee {
+		t.Errorf("fee is %v, want %v", built.Fee,
+			built.InputValue-outputValue)
+	}
+
+	// Inputs must be sorted, as must be outputs unless the change output
+	// was moved.
+	if !sort.SliceIsSorted(tx.TxIn, func(i, j int) bool {
+		return tx.TxIn[i].PreviousOutPoint.String() <
+			tx.TxIn[j].PreviousOutPoint.String()
+	}) {
+		t.Errorf("inputs aren't sorted")
+	}
+
+	var inputValue monautil.Amount
+	for i, pInput := range p.Inputs {
+		op := tx.TxIn[i].PreviousOutPoint
+		prevTx := w.Txs[op.Hash]
+		if prevTx == nil {
+			t.Fatalf("input %d spends unknown coin %v", i, op)
+		}
+		prevOut := prevTx.TxOut[op.Index]
+		inputValue += monautil.Amount(prevOut.Value)
+
+		switch {
+		case txscript.IsWitnessProgram(prevOut.PkScript):
+			if pInput.WitnessUtxo == nil ||
+				!psbt.TxOutsEqual(pInput.WitnessUtxo, prevOut) {
+
+				t.Errorf("input %d has wrong witness utxo", i)
+			}
+		case pInput.NonWitnessUtxo != prevTx:
+			t.Errorf("input %d has wrong non-witness utxo", i)
+		}
+	}
+	if inputValue != built.InputValue {
+		t.Errorf("input value is %v, want %v", built.InputValue,
+			inputValue)
+	}
+
+	// The fee must pay the fee rate for the signed transaction, with at
+	// most a few virtual bytes to spare for the estimate.
+	signed, err := w.Sign(built.Packet)
+	if err != nil {
+		t.Fatalf("unable to sign transaction: %v", err)
+	}
+	vsize := monautil.NewTx(signed).VirtualSize()
+	minFee := testFeeRate.FeeForVSize(vsize)
+	maxFee := testFeeRate.FeeForVSize(vsize + 2 + int64(len(tx.TxIn)))
+	if built.Fee < minFee {
+		t.Errorf("fee %v for %d vbytes is below %v", built.Fee, vsize,
+			minFee)
+	}
+	if built.ChangeIndex >= 0 && built.Fee > maxFee {
+		t.Errorf("fee %v for %d vbytes is above %v", built.Fee, vsize,
+			maxFee)
+	}
+}
+
+// TestBuild tests building transactions from coins.
+func TestBuild(t *testing.T) {
+	changeKey := newKey(200)
+	changeAddr := p2wpkhAddress(changeKey)
+
+	tests := []struct {
+		name       string
+		legacy     bool
+		coins      []monautil.Amount
+		outputs    []*txbuilder.Output
+		rbf        bool
+		lockTime   uint32
+		wantInputs int
+		wantChange bool
+		wantSeq    uint32
+	}{
+		{
+			name:       "single input with change",
+			coins:      []monautil.Amount{1e8},
+			outputs:    []*txbuilder.Output{payment(100, 5e7)},
+			wantInputs: 1,
+			wantChange: true,
+			wantSeq:    wire.MaxTxInSequenceNum,
+		},
+		{
+			name:  "multiple outputs and inputs",
+			coins: []monautil.Amount{3e7, 4e7, 5e7},
+			outputs: []*txbuilder.Output{
+				payment(100, 5e7),
+				payment(101, 1e7),
+				payment(102, 1e6),
+			},
+			wantInputs: 2,
+			wantChange: true,
+			wantSeq:    wire.MaxTxInSequenceNum,
+		},
+		{
+			// The first coin pays the payment and the fee without
+			// inputs, but not the fee of spending it.
+			name:       "second pass for input fees",
+			coins:      []monautil.Amount{5e7 + 8000, 1e6},
+			outputs:    []*txbuilder.Output{payment(100, 5e7)},
+			wantInputs: 2,
+			wantChange: true,
+			wantSeq:    wire.MaxTxInSequenceNum,
+		},
+		{
+			// The change after the fee would be dust, so it is
+			// left to the fee.
+			name:       "dust change",
+			coins:      []monautil.Amount{5e7 + 14000},
+			outputs:    []*txbuilder.Output{payment(100, 5e7)},
+			wantInputs: 1,
+			wantChange: false,
+			wantSeq:    wire.MaxTxInSequenceNum,
+		},
+		{
+			name:       "legacy inputs",
+			legacy:     true,
+			coins:      []monautil.Amount{2e7, 4e7},
+			outputs:    []*txbuilder.Output{payment(100, 5e7)},
+			wantInputs: 2,
+			wantChange: true,
+			wantSeq:    wire.MaxTxInSequenceNum,
+		},
+		{
+			name:       "replaceable",
+			coins:      []monautil.Amount{1e8},
+			outputs:    []*txbuilder.Output{payment(100, 5e7)},
+			rbf:        true,
+			wantInputs: 1,
+			wantChange: true,
+			wantSeq:    wire.MaxTxInSequenceNum - 2,
+		},
+		{
+			name:       "locktime",
+			coins:      []monautil.Amount{1e8},
+			outputs:    []*txbuilder.Output{payment(100, 5e7)},
+			lockTime:   500000,
+			wantInputs: 1,
+			wantChange: true,
+			wantSeq:    wire.MaxTxInSequenceNum - 1,
+		},
+	}
+
+	for _, test := range tests {
+		w := testwallet.New(1, test.legacy, test.coins...)
+		changeCalls := 0
+		b := &txbuilder.Builder{
+			Selector:     testSelector,
+			FeeRate:      testFeeRate,
+			ChangeSource: changeSource(changeKey, &changeCalls),
+			PrevTxs:      w.PrevTx,
+			LockTime:     test.lockTime,
+			SignalRBF:    test.rbf,
+		}
+
+		built, err := b.Build(test.outputs, w.Coins)
+		if err != nil {
+			t.Errorf("%s: unexpected error: %v", test.name, err)
+			continue
+		}
+
+		tx := built.Packet.UnsignedTx
+		if len(tx.TxIn) != test.wantInputs {
+			t.Errorf("%s: spends %d inputs, want %d", test.name,
+				len(tx.TxIn), test.wantInputs)
+		}
+		if (built.ChangeIndex >= 0) != test.wantChange {
+			t.Errorf("%s: change index %d, want change %v",
+				test.name, built.ChangeIndex, test.wantChange)
+		}
+		if test.wantChange != (changeCalls == 1) {
+			t.Errorf("%s: change source called %d times",
+				test.name, changeCalls)
+		}
+		if tx.Version != txbuilder.DefaultTxVersion ||
+			tx.LockTime != test.lockTime {
+
+			t.Errorf("%s: version %d and locktime %d", test.name,
+				tx.Version, tx.LockTime)
+		}
+		for i, txIn := range tx.TxIn {
+			if txIn.Sequence != test.wantSeq {
+				t.Errorf("%s: input %d has sequence %x, want %x",
+					test.name, i, txIn.Sequence, test.wantSeq)
+			}
+		}
+
+		// Outputs are sorted when the change output isn't moved.
+		if !sort.SliceIsSorted(tx.TxOut, func(i, j int) bool {
+			return tx.TxOut[i].Value < tx.TxOut[j].Value
+		}) {
+			t.Errorf("%s: outputs aren't sorted", test.name)
+		}
+
+		checkBuiltTx(t, w, test.outputs, changeAddr, built)
+	}
+}
+
+// TestBuildRandomizeChange tests that the change output is moved to every
+// position when change positions are randomized.
+func TestBuildRandomizeChange(t *testing.T) {
+	changeKey := newKey(200)
+	changeAddr := p2wpkhAddress(changeKey)
+	outputs := []*txbuilder.Output{
+		payment(100, 1e6),
+		payment(101, 2e6),
+		payment(102, 3e6),
+	}
+	w := testwallet.New(1, false, 1e8)
+
+	changeCalls := 0
+	b := &txbuilder.Builder{
+		Selector:        testSelector,
+		FeeRate:         testFeeRate,
+		ChangeSource:    changeSource(changeKey, &changeCalls),
+		RandomizeChange: true,
+		Rand:            rand.New(rand.NewSource(1)),
+	}
+
+	seen := make(map[int]bool)
+	for i := 0; i < 50; i++ {
+		built, err := b.Build(outputs, w.Coins)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		seen[built.ChangeIndex] = true
+
+		// The payments must still be sorted among themselves.
+		tx := built.Packet.UnsignedTx
+		var values []int64
+		for i, txOut := range tx.TxOut {
+			if i != built.ChangeIndex {
+				values = append(values, txOut.Value)
+			}
+		}
+		if !sort.SliceIsSorted(values, func(i, j int) bool {
+			return values[i] < values[j]
+		}) {
+			t.Errorf("payments aren't sorted: %v", values)
+		}
+		if len(built.Packet.Outputs) != len(tx.TxOut) {
+			t.Fatalf("packet has %d outputs for %d tx outputs",
+				len(built.Packet.Outputs), len(tx.TxOut))
+		}
+
+		checkBuiltTx(t, w, outputs, changeAddr, built)
+	}
+	for i := 0; i <= len(outputs); i++ {
+		if !seen[i] {
+			t.Errorf("change output never placed at %d", i)
+		}
+	}
+}
+
+// TestBuildP2SHCoins tests that P2SH coins are only given a WitnessUtxo
+// instead of requiring their previous transaction when they are declared to
+// nest a witness program.
+func TestBuildP2SHCoins(t *testing.T) {
+	changeKey := newKey(200)
+	calls := 0
+
+	// The coin pays to a P2SH-P2WPKH script.
+	witnessScript, _ := txscript.PayToAddrScript(p2wpkhAddress(newKey(1)))
+	p2sh, _ := monautil.NewAddressScriptHash(witnessScript, net)
+	pkScript, _ := txscript.PayToAddrScript(p2sh)
+	msgTx := wire.NewMsgTx(wire.TxVersion)
+	msgTx.AddTxIn(&wire.TxIn{})
+	msgTx.AddTxOut(wire.NewTxOut(1e8, pkScript))
+	coins := []coinset.Coin{&coinset.SimpleCoin{
+		Tx:         monautil.NewTx(msgTx),
+		TxNumConfs: 6,
+	}}
+	prevTxs := func(*chainhash.Hash) (*wire.MsgTx, error) {
+		return msgTx, nil
+	}
+	nested := func(coinset.Coin) bool {
+		return true
+	}
+	outputs := []*txbuilder.Output{payment(100, 5e7)}
+
+	b := &txbuilder.Builder{
+		Selector:     testSelector,
+		FeeRate:      testFeeRate,
+		ChangeSource: changeSource(changeKey, &calls),
+	}
+	_, err := b.Build(outputs, coins)
+	var prevErr *txbuilder.MissingPrevTxError
+	if !errors.As(err, &prevErr) {
+		t.Fatalf("got error %v, want MissingPrevTxError", err)
+	}
+
+	tests := []struct {
+		name            string
+		prevTxs         txbuilder.PrevTxSource
+		isNested        txbuilder.NestedWitnessFunc
+		wantWitnessUtxo bool
+		wantPrevTx      bool
+	}{
+		{"undeclared", prevTxs, nil, false, true},
+		{"nested", nil, nested, true, false},
+		{"nested with previous transaction", prevTxs, nested, true, true},
+	}
+
+	for _, test := range tests {
+		b.PrevTxs = test.prevTxs
+		b.IsNestedWitness = test.isNested
+		built, err := b.Build(outputs, coins)
+		if err != nil {
+			t.Errorf("%s: unexpected error: %v", test.name, err)
+			continue
+		}
+
+		pInput := built.Packet.Inputs[0]
+		if (pInput.WitnessUtxo != nil) != test.wantWitnessUtxo {
+			t.Errorf("%s: got witness utxo %v, want %v", test.name,
+				pInput.WitnessUtxo != nil, test.wantWitnessUtxo)
+		}
+		if (pInput.NonWitnessUtxo != nil) != test.wantPrevTx {
+			t.Errorf("%s: got previous transaction %v, want %v",
+				test.name, pInput.NonWitnessUtxo != nil,
+				test.wantPrevTx)
+		}
+	}
+}
+
+// TestBuildErrors tests the errors returned when a transaction can't be built.
+func TestBuildErrors(t *testing.T) {
+	changeKey := newKey(200)
+	w := testwallet.New(1, false, 1e8)
+	legacy := testwallet.New(1, true, 1e8)
+	calls := 0
+
+	tests := []struct {
+		name    string
+		builder *txbuilder.Builder
+		coins   []coinset.Coin
+		outputs []*txbuilder.Output
+		check   func(error) bool
+	}{
+		{
+			name: "no outputs",
+			builder: &txbuilder.Builder{
+				Selector: testSelector,
+			},
+			coins: w.Coins,
+			check: func(err error) bool {
+				return err == txbuilder.ErrNoOutputs
+			},
+		},
+		{
+			name:    "no selector",
+			builder: &txbuilder.Builder{},
+			coins:   w.Coins,
+			outputs: []*txbuilder.Output{payment(100, 5e7)},
+			check: func(err error) bool {
+				return err == txbuilder.ErrNoSelector
+			},
+		},
+		{
+			name: "negative output",
+			builder: &txbuilder.Builder{
+				Selector: testSelector,
+			},
+			coins: w.Coins,
+			outputs: []*txbuilder.Output{
+				payment(100, 5e7),
+				payment(101, -1),
+			},
+			check: func(err error) bool {
+				var amountErr *txbuilder.OutputAmountError
+				return errors.As(err, &amountErr) &&
+					amountErr.Index == 1 && amountErr.Amount == -1
+			},
+		},
+		{
+			name: "output above max",
+			builder: &txbuilder.Builder{
+				Selector: testSelector,
+			},
+			coins: w.Coins,
+			outputs: []*txbuilder.Output{
+				payment(100, monautil.MaxSatoshi+1),
+			},
+			check: func(err error) bool {
+				var amountErr *txbuilder.OutputAmountError
+				return errors.As(err, &amountErr) &&
+					amountErr.Index == 0
+			},
+		},
+		{
+			name: "outputs above max",
+			builder: &txbuilder.Builder{
+				Selector: testSelector,
+			},
+			coins: w.Coins,
+			outputs: []*txbuilder.Output{
+				payment(100, monautil.MaxSatoshi),
+				payment(101, 1000),
+			},
+			check: func(err error) bool {
+				var amountErr *txbuilder.OutputAmountError
+				return errors.As(err, &amountErr) &&
+					amountErr.Index == 1
+			},
+		},
+		{
+			name: "dust output",
+			builder: &txbuilder.Builder{
+				Selector: testSelector,
+			},
+			coins: w.Coins,
+			outputs: []*txbuilder.Output{
+				payment(100, 5e7),
+				payment(101, 293),
+			},
+			check: func(err error) bool {
+				var dustErr *txbuilder.DustOutputError
+				return errors.As(err, &dustErr) &&
+					dustErr.Index == 1 && dustErr.Amount == 293
+			},
+		},
+		{
+			name: "insufficient funds",
+			builder: &txbuilder.Builder{
+				Selector: testSelector,
+				FeeRate:  testFeeRate,
+			},
+			coins:   w.Coins,
+			outputs: []*txbuilder.Output{payment(100, 1e8)},
+			check: func(err error) bool {
+				return err == coinset.ErrCoinsNoSelectionAvailable
+			},
+		},
+		{
+			name: "no change source",
+			builder: &txbuilder.Builder{
+				Selector: testSelector,
+				FeeRate:  testFeeRate,
+			},
+			coins:   w.Coins,
+			outputs: []*txbuilder.Output{payment(100, 5e7)},
+			check: func(err error) bool {
+				return err == txbuilder.ErrNoChangeSource
+			},
+		},
+		{
+			name: "missing previous transaction",
+			builder: &txbuilder.Builder{
+				Selector:     testSelector,
+				FeeRate:      testFeeRate,
+				ChangeSource: changeSource(changeKey, &calls),
+			},
+			coins:   legacy.Coins,
+			outputs: []*txbuilder.Output{payment(100, 5e7)},
+			check: func(err error) bool {
+				var prevErr *txbuilder.MissingPrevTxError
+				return errors.As(err, &prevErr) &&
+					prevErr.OutPoint.Hash ==
+						*legacy.Coins[0].Hash()
+			},
+		},
+		{
+			name: "mismatched previous transaction",
+			builder: &txbuilder.Builder{
+				Selector:     testSelector,
+				FeeRate:      testFeeRate,
+				ChangeSource: changeSource(changeKey, &calls),
+				PrevTxs: func(*chainhash.Hash) (*wire.MsgTx,
+					error) {
+
+					return wire.NewMsgTx(wire.TxVersion), nil
+				},
+			},
+			coins:   legacy.Coins,
+			outputs: []*txbuilder.Output{payment(100, 5e7)},
+			check: func(err error) bool {
+				return err == txbuilder.ErrPrevTxMismatch
+			},
+		},
+	}
+
+	for _, test := range tests {
+		_, err := test.builder.Build(test.outputs, test.coins)
+		if !test.check(err) {
+			t.Errorf("%s: unexpected error: %v", test.name, err)
+		}
+	}
+}
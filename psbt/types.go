@@ -30,6 +30,34 @@ const (
 	// extended public key.
 	XpubType GlobalType = 1
 
+	// TxVersionType is an empty key ({0x02}) that houses the 32-bit little
+	// endian signed integer representing the version number of the
+	// transaction being created. It is required in PSBT version 2 and must
+	// be omitted in version 0.
+	TxVersionType GlobalType = 2
+
+	// FallbackLocktimeType is an empty key ({0x03}) that houses the 32-bit
+	// little endian unsigned integer representing the transaction locktime
+	// to use if no inputs specify a required locktime. It must be omitted
+	// in PSBT version 0.
+	FallbackLocktimeType GlobalType = 3
+
+	// InputCountType is an empty key ({0x04}) that houses the compact size
+	// unsigned integer representing the number of inputs in this PSBT. It
+	// is required in PSBT version 2 and must be omitted in version 0.
+	InputCountType GlobalType = 4
+
+	// OutputCountType is an empty key ({0x05}) that houses the compact size
+	// unsigned integer representing the number of outputs in this PSBT. It
+	// is required in PSBT version 2 and must be omitted in version 0.
+	OutputCountType GlobalType = 5
+
+	// TxModifiableType is an empty key ({0x06}) that houses an 8-bit
+	// little endian unsigned integer used as a bitfield for the
+	// modification flags of the transaction. It must be omitted in PSBT
+	// version 0.
+	TxModifiableType GlobalType = 6
+
 	// VersionType houses the global version number of this PSBT. There is
 	// no key (only contains the byte type), then the value if omitted, is
 	// assumed to be zero.
@@ -114,6 +142,45 @@ const (
 	// scripts necessary for the input to pass validation.
 	FinalScriptWitnessType InputType = 8
 
+	// PreviousTxidType is an empty key ({0x0e}).
+	//
+	// The value is the 32 byte txid of the previous transaction whose
+	// output at OutputIndexType is being spent. It is required in PSBT
+	// version 2 and must be omitted in version 0.
+	PreviousTxidType InputType = 0x0e
+
+	// OutputIndexType is an empty key ({0x0f}).
+	//
+	// The value is the 32-bit little endian integer representing the index
+	// of the output being spent in the transaction with the txid of
+	// PreviousTxidType. It is required in PSBT version 2 and must be
+	// omitted in version 0.
+	OutputIndexType InputType = 0x0f
+
+	// SequenceType is an empty key ({0x10}).
+	//
+	// The value is the 32-bit little endian unsigned integer for the
+	// sequence number of this input. If omitted, the sequence number is
+	// assumed to be the final sequence number 0xffffffff. It must be
+	// omitted in PSBT version 0.
+	SequenceType InputType = 0x10
+
+	// RequiredTimeLocktimeType is an empty key ({0x11}).
+	//
+	// The value is the 32-bit little endian unsigned integer greater than
+	// or equal to 500000000 representing the minimum Unix timestamp that
+	// this input requires to be set as the transaction's lock time. It
+	// must be omitted in PSBT version 0.
+	RequiredTimeLocktimeType InputType = 0x11
+
+	// RequiredHeightLocktimeType is an empty key ({0x12}).
+	//
+	// The value is the 32-bit little endian unsigned integer greater than
+	// 0 and less than 500000000 representing the minimum block height that
+	// this input requires to be set as the transaction's lock time. It
+	// must be omitted in PSBT version 0.
+	RequiredHeightLocktimeType InputType = 0x12
+
 	// ProprietaryInputType is a custom type for use by devs.
 	//
 	// The key ({0xFC}|<prefix>|{subtype}|{key data}), is a Variable length
@@ -138,7 +205,7 @@ const (
 	// The value is the witness script of this input, if it has one.
 	WitnessScriptOutputType OutputType = 1
 
-	j // Bip32DerivationOutputType is used to communicate derivation information
+	// Bip32DerivationOutputType is used to communicate derivation information
 	// needed to spend this output. The key is ({0x02}|{public key}).
 	//
 	// The value is master key fingerprint concatenated with the derivation
@@ -146,4 +213,37 @@ const (
 	// little endian unsigned integer indexes concatenated with each other.
 	// Public keys are those needed to spend this output.
 	Bip32DerivationOutputType OutputType = 2
+
+	// AmountOutputType is an empty key ({0x03}).
+	//
+	// The value is the 64-bit signed little endian integer representing
+	// the output's amount in satoshis. It is required in PSBT version 2
+	// and must be omitted in version 0.
+	AmountOutputType OutputType = 3
+
+	// ScriptOutputType is an empty key ({0x04}).
+	//
+	// The value is the script for this output, also known as the
+	// scriptPubKey. It is required in PSBT version 2 and must be omitted
+	// in version 0.
+	ScriptOutputType OutputType = 4
+)
+
+// TxModifiableFlags is the bitfield housed in the TxModifiableType global
+// field of a version 2 PSBT.
+type TxModifiableFlags uint8
+
+const (
+	// TxModifiableInputs indicates that inputs may be added to or removed
+	// from the transaction.
+	TxModifiableInputs TxModifiableFlags = 1 << 0
+
+	// TxModifiableOutputs indicates that outputs may be added to or
+	// removed from the transaction.
+	TxModifiableOutputs TxModifiableFlags = 1 << 1
+
+	// TxModifiableSigHashSingle indicates that the transaction has a
+	// SIGHASH_SINGLE signature, so the pairing of inputs and outputs with
+	// the same index must be preserved when modifying it.
+	TxModifiableSigHashSingle TxModifiableFlags = 1 << 2
 )
@@ -17,6 +17,7 @@ import (
 	"github.com/monasuite/monad/txscript"
 	"github.com/monasuite/monad/wire"
 	"github.com/monasuite/monautil"
+	"github.com/monasuite/monautil/hdkeychain"
 )
 
 // Updater encapsulates the role 'Updater' as specified in BIP174; it accepts
@@ -216,6 +217,14 @@ func (p *Updater) addPartialSignature(inIndex int, sig []byte,
 		p.Upsbt.Inputs[inIndex].PartialSigs, &partialSig,
 	)
 
+	// The signature commits to parts of the transaction which may no
+	// longer be modified in a version 2 packet.
+	if p.Upsbt.Version == PsbtVersion2 {
+		p.Upsbt.updateModifiable(
+			txscript.SigHashType(sig[len(sig)-1]),
+		)
+	}
+
 	if err := p.Upsbt.SanityCheck(); err != nil {
 		return err
 	}
@@ -375,3 +384,91 @@ func (p *Updater) AddOutWitnessScript(witnessScript []byte,
 
 	return nil
 }
+
+// AddXPub takes an extended public key, the master key fingerprint as defined
+// in BIP32 and the BIP32 path of the extended key as a slice of uint32 values,
+// and adds them to the global section of the PSBT.  The length of the path
+// must match the depth of the extended key.
+//
+// NOTE: This can be called multiple times to add several xpubs, such as the
+// ones of all cosigners of a multisig wallet.  An error is returned if
+// addition of this key-value pair to the Psbt fails.
+func (p *Updater) AddXPub(xPub *hdkeychain.ExtendedKey,
+	masterKeyFingerprint uint32, bip32Path []uint32) error {
+
+	newXPub := XPub{
+		ExtendedKey:          xPub,
+		MasterKeyFingerprint: masterKeyFingerprint,
+		Bip32Path:            bip32Path,
+	}
+
+	if !newXPub.checkValid() {
+		return ErrInvalidXPub
+	}
+
+	// Don't allow duplicate keys
+	serializedKey := EncodeExtendedKey(xPub)
+	for _, x := range p.Upsbt.XPubs {
+		if bytes.Equal(EncodeExtendedKey(x.ExtendedKey), serializedKey) {
+			return ErrDuplicateKey
+		}
+	}
+
+	p.Upsbt.XPubs = append(p.Upsbt.XPubs, &newXPub)
+
+	if err := p.Upsbt.SanityCheck(); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// SetVersion sets the global PSBT version number of the packet, converting it
+// with ConvertToV0 or ConvertToV2 if the version changes.  An error is
+// returned if the version is not supported by this package.
+func (p *Updater) SetVersion(version uint32) error {
+	var (
+		converted *Packet
+		err       error
+	)
+	switch {
+	case version == p.Upsbt.Version:
+		return p.Upsbt.SanityCheck()
+	case version == PsbtVersion0:
+		converted, err = ConvertToV0(p.Upsbt)
+	case version == PsbtVersion2:
+		converted, err = ConvertToV2(p.Upsbt)
+	default:
+		return ErrUnsupportedPsbtVersion
+	}
+	if err != nil {
+		return err
+	}
+
+	*p.Upsbt = *converted
+
+	return nil
+}
+
+// sigHashMask is the mask of the sighash type bits which select the outputs a
+// signature commits to, leaving out the SIGHASH_ANYONECANPAY modifier.
+const sigHashMask = 0x1f
+
+// updateModifiable clears the modifiable flags of a version 2 packet that a
+// new signature with the given sighash type commits to, as described in BIP
+// 370.  Unless the signature uses SIGHASH_ANYONECANPAY no more inputs may be
+// added, and unless it uses SIGHASH_NONE no more outputs may be added.
+func (p *Packet) updateModifiable(sigHashType txscript.SigHashType) {
+	if sigHashType&txscript.SigHashAnyOneCanPay == 0 {
+		p.TxModifiable &^= TxModifiableInputs
+	}
+
+	switch sigHashType & sigHashMask {
+	case txscript.SigHashNone:
+	case txscript.SigHashSingle:
+		p.TxModifiable &^= TxModifiableOutputs
+		p.TxModifiable |= TxModifiableSigHashSingle
+	default:
+		p.TxModifiable &^= TxModifiableOutputs
+	}
+}
@@ -33,20 +33,6 @@ func WriteTxWitness(w io.Writer, wit [][]byte) error {
 	return nil
 }
 
-// writePKHWitness writes a witness for a p2wkh spending input
-func writePKHWitness(sig []byte, pub []byte) ([]byte, error) {
-	var (
-		buf          bytes.Buffer
-		witnessItems = [][]byte{sig, pub}
-	)
-
-	if err := WriteTxWitness(&buf, witnessItems); err != nil {
-		return nil, err
-	}
-
-	return buf.Bytes(), nil
-}
-
 // checkIsMultisigScript is a utility function to check whether a given
 // redeemscript fits the standard multisig template used in all P2SH based
 // multisig, given a set of pubkeys for redemption.
@@ -143,43 +129,6 @@ func extractKeyOrderFromScript(script []byte, expectedPubkeys [][]byte,
 	return sortedSigs, nil
 }
 
-// getMultisigScriptWitness creates a full psbt serialized Witness field for
-// the transaction, given the public keys and signatures to be appended. This
-// function will only accept witnessScripts of the type M of N multisig. This
-// is used for both p2wsh and nested p2wsh multisig cases.
-func getMultisigScriptWitness(witnessScript []byte, pubKeys [][]byte,
-	sigs [][]byte) ([]byte, error) {
-
-	// First using the script as a guide, we'll properly order the sigs
-	// according to how their corresponding pubkeys appear in the
-	// witnessScript.
-	orderedSigs, err := extractKeyOrderFromScript(
-		witnessScript, pubKeys, sigs,
-	)
-	if err != nil {
-		return nil, err
-	}
-
-	// Now that we know the proper order, we'll append each of the
-	// signatures into a new witness stack, then top it off with the
-	// witness script at the end, prepending the nil as we need the extra
-	// pop..
-	witnessElements := make(wire.TxWitness, 0, len(sigs)+2)
-	witnessElements = append(witnessElements, nil)
-	for _, os := range orderedSigs {
-		witnessElements = append(witnessElements, os)
-	}
-	witnessElements = append(witnessElements, witnessScript)
-
-	// Now that we have the full witness stack, we'll serialize it in the
-	// expected format, and return the final bytes.
-	var buf bytes.Buffer
-	if err = WriteTxWitness(&buf, witnessElements); err != nil {
-		return nil, err
-	}
-	return buf.Bytes(), nil
-}
-
 // checkSigHashFlags compares the sighash flag byte on a signature with the
 // value expected according to any PsbtInSighashType field in this section of
 // the PSBT, and returns true if they match, false otherwise.
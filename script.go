@@ -0,0 +1,395 @@
+// Copyright (c) 2026 The monasuite developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package monautil
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/monasuite/monad/chaincfg"
+)
+
+// These are the opcodes used by the standard output scripts.  They are
+// defined here rather than taken from txscript, which depends on this
+// package.
+const (
+	op0             = 0x00
+	opPushData1     = 0x4c
+	opPushData2     = 0x4d
+	opPushData4     = 0x4e
+	op1             = 0x51
+	op16            = 0x60
+	opReturn        = 0x6a
+	opDup           = 0x76
+	opEqual         = 0x87
+	opEqualVerify   = 0x88
+	opHash160       = 0xa9
+	opCheckSig      = 0xac
+	opCheckMultiSig = 0xae
+)
+
+// ErrUnsupportedScriptAddress describes an error in which an output script
+// can't be built for an address, as its type is unknown or it is nil.
+var ErrUnsupportedScriptAddress = errors.New("unsupported address type")
+
+// ScriptClass is an enumeration for the standard forms of output scripts.
+type ScriptClass byte
+
+// Classes of output script.
+const (
+	// NonStandardTy is a script of none of the forms below.
+	NonStandardTy ScriptClass = iota
+
+	// PubKeyTy is a pay-to-pubkey (P2PK) script.
+	PubKeyTy
+
+	// PubKeyHashTy is a pay-to-pubkey-hash (P2PKH) script.
+	PubKeyHashTy
+
+	// ScriptHashTy is a pay-to-script-hash (P2SH) script.
+	ScriptHashTy
+
+	// WitnessV0PubKeyHashTy is a pay-to-witness-pubkey-hash (P2WPKH)
+	// script.
+	WitnessV0PubKeyHashTy
+
+	// WitnessV0ScriptHashTy is a pay-to-witness-script-hash (P2WSH)
+	// script.
+	WitnessV0ScriptHashTy
+
+	// WitnessV1PlusTy is a witness program of version 1 through 16, such
+	// as a pay-to-taproot (P2TR) script.
+	WitnessV1PlusTy
+
+	// MultiSigTy is a bare multisig script.
+	MultiSigTy
+
+	// NullDataTy is a provably unspendable OP_RETURN script carrying
+	// data.
+	NullDataTy
+)
+
+// scriptClassStrings houses the human-readable strings which describe each
+// script class.
+var scriptClassStrings = map[ScriptClass]string{
+	NonStandardTy:         "nonstandard",
+	PubKeyTy:              "pubkey",
+	PubKeyHashTy:          "pubkeyhash",
+	ScriptHashTy:          "scripthash",
+	WitnessV0PubKeyHashTy: "witness_v0_keyhash",
+	WitnessV0ScriptHashTy: "witness_v0_scripthash",
+	WitnessV1PlusTy:       "witness_v1plus",
+	MultiSigTy:            "multisig",
+	NullDataTy:            "nulldata",
+}
+
+// String returns the ScriptClass as a human-readable name.
+func (c ScriptClass) String() string {
+	if s, ok := scriptClassStrings[c]; ok {
+		return s
+	}
+	return fmt.Sprintf("Unknown ScriptClass (%d)", byte(c))
+}
+
+// PayToAddrScript returns the canonical output script paying to the address.
+// Pay-to-pubkey addresses are paid to with a P2PK script using the
+// serialization format of the address.
+func PayToAddrScript(addr Address) ([]byte, error) {
+	switch addr := addr.(type) {
+	case *AddressPubKeyHash:
+		if addr == nil {
+			break
+		}
+		script := []byte{opDup, opHash160, 20}
+		script = append(script, addr.hash[:]...)
+		return append(script, opEqualVerify, opCheckSig), nil
+
+	case *AddressScriptHash:
+		if addr == nil {
+			break
+		}
+		script := []byte{opHash160, 20}
+		script = append(script, addr.hash[:]...)
+		return append(script, opEqual), nil
+
+	case *AddressPubKey:
+		if addr == nil {
+			break
+		}
+		pubKey := addr.ScriptAddress()
+		script := append([]byte{byte(len(pubKey))}, pubKey...)
+		return append(script, opCheckSig), nil
+
+	case *AddressWitnessPubKeyHash:
+		if addr == nil {
+			break
+		}
+		return witnessProgramScript(0, addr.WitnessProgram()), nil
+
+	case *AddressWitnessScriptHash:
+		if addr == nil {
+			break
+		}
+		return witnessProgramScript(0, addr.WitnessProgram()), nil
+
+	case *AddressTaproot:
+		if addr == nil {
+			break
+		}
+		return witnessProgramScript(addr.WitnessVersion(),
+			addr.WitnessProgram()), nil
+
+	case *AddressWitnessProgram:
+		if addr == nil {
+			break
+		}
+		return witnessProgramScript(addr.WitnessVersion(),
+			addr.WitnessProgram()), nil
+	}
+
+	return nil, ErrUnsupportedScriptAddress
+}
+
+// witnessProgramScript returns the output script of the witness program of
+// the passed version.
+func witnessProgramScript(version byte, program []byte) []byte {
+	versionOp := byte(op0)
+	if version != 0 {
+		versionOp = op1 + version - 1
+	}
+	script := make([]byte, 0, len(program)+2)
+	script = append(script, versionOp, byte(len(program)))
+	return append(script, program...)
+}
+
+// GetScriptClass returns the class of the output script.
+func GetScriptClass(pkScript []byte) ScriptClass {
+	class, _ := classify(pkScript)
+	return class
+}
+
+// ExtractPkScriptAddrs returns the class of the output script, the addresses
+// it pays to on the passed network and the number of signatures required to
+// spend it.  Pay-to-pubkey and bare multisig scripts pay to the addresses of
+// their public keys, omitting the keys which aren't valid points.  Null data
+// and nonstandard scripts pay to no address, and spending witness programs of
+// unknown versions requires no signatures.
+//
+// An error is only returned when an address can't be created for the script,
+// in which case the class is still returned.
+func ExtractPkScriptAddrs(pkScript []byte,
+	net *chaincfg.Params) (ScriptClass, []Address, int, error) {
+
+	class, pushes := classify(pkScript)
+
+	var addr Address
+	var err error
+	switch class {
+	case PubKeyTy:
+		addr, err = NewAddressPubKey(pushes[0], net)
+		if err != nil {
+			return class, nil, 1, nil
+		}
+		return class, []Address{addr}, 1, nil
+
+	case PubKeyHashTy:
+		addr, err = NewAddressPubKeyHash(pkScript[3:23], net)
+
+	case ScriptHashTy:
+		addr, err = NewAddressScriptHashFromHash(pkScript[2:22], net)
+
+	case WitnessV0PubKeyHashTy:
+		addr, err = NewAddressWitnessPubKeyHash(pkScript[2:], net)
+
+	case WitnessV0ScriptHashTy:
+		addr, err = NewAddressWitnessScriptHash(pkScript[2:], net)
+
+	case WitnessV1PlusTy:
+		version := pkScript[0] - op1 + 1
+		if version == 1 && len(pkScript) == 34 {
+			addr, err = NewAddressTaproot(pkScript[2:], net)
+			if err != nil {
+				return class, nil, 0, err
+			}
+			return class, []Address{addr}, 1, nil
+		}
+		addr, err = NewAddressWitnessProgram(version, pkScript[2:], net)
+		if err != nil {
+			return class, nil, 0, err
+		}
+		return class, []Address{addr}, 0, nil
+
+	case MultiSigTy:
+		numSigs := int(pkScript[0] - op1 + 1)
+		addrs := make([]Address, 0, len(pushes))
+		for _, pubKey := range pushes {
+			pubKeyAddr, err := NewAddressPubKey(pubKey, net)
+			if err == nil {
+				addrs = append(addrs, pubKeyAddr)
+			}
+		}
+		return class, addrs, numSigs, nil
+
+	default:
+		return class, nil, 0, nil
+	}
+
+	if err != nil {
+		return class, nil, 0, err
+	}
+	return class, []Address{addr}, 1, nil
+}
+
+// classify returns the class of the output script, along with the public keys
+// of pay-to-pubkey and bare multisig scripts.
+func classify(script []byte) (ScriptClass, [][]byte) {
+	switch {
+	case len(script) == 25 && script[0] == opDup &&
+		script[1] == opHash160 && script[2] == 20 &&
+		script[23] == opEqualVerify && script[24] == opCheckSig:
+
+		return PubKeyHashTy, nil
+
+	case len(script) == 23 && script[0] == opHash160 && script[1] == 20 &&
+		script[22] == opEqual:
+
+		return ScriptHashTy, nil
+
+	case len(script) > 0 && script[len(script)-1] == opCheckSig &&
+		len(script)-2 == int(script[0]) && isPubKey(script[1:len(script)-1]):
+
+		return PubKeyTy, [][]byte{script[1 : len(script)-1]}
+	}
+
+	if version, program, ok := witnessProgram(script); ok {
+		switch {
+		case version != 0:
+			return WitnessV1PlusTy, nil
+		case len(program) == 20:
+			return WitnessV0PubKeyHashTy, nil
+		case len(program) == 32:
+			return WitnessV0ScriptHashTy, nil
+		}
+		return NonStandardTy, nil
+	}
+
+	if len(script) > 0 && script[0] == opReturn {
+		if _, ok := scriptPushes(script[1:]); ok {
+			return NullDataTy, nil
+		}
+		return NonStandardTy, nil
+	}
+
+	if pubKeys := multiSigPubKeys(script); pubKeys != nil {
+		return MultiSigTy, pubKeys
+	}
+
+	return NonStandardTy, nil
+}
+
+// witnessProgram returns the version and program of the script if it is a
+// witness program: a version opcode followed by a single direct push of 2 to
+// 40 bytes.
+func witnessProgram(script []byte) (byte, []byte, bool) {
+	if len(script) < 4 || len(script) > 42 {
+		return 0, nil, false
+	}
+	if script[0] != op0 && (script[0] < op1 || script[0] > op16) {
+		return 0, nil, false
+	}
+	if int(script[1]) != len(script)-2 {
+		return 0, nil, false
+	}
+
+	var version byte
+	if script[0] != op0 {
+		version = script[0] - op1 + 1
+	}
+	return version, script[2:], true
+}
+
+// multiSigPubKeys returns the public keys of the script if it is a bare
+// multisig script, requiring m of n signatures with 1 <= m <= n <= 16.
+func multiSigPubKeys(script []byte) [][]byte {
+	if len(script) < 3 || script[len(script)-1] != opCheckMultiSig {
+		return nil
+	}
+	numSigsOp, numPubKeysOp := script[0], script[len(script)-2]
+	if numSigsOp < op1 || numSigsOp > op16 ||
+		numPubKeysOp < op1 || numPubKeysOp > op16 ||
+		numSigsOp > numPubKeysOp {
+
+		return nil
+	}
+
+	pubKeys, ok := scriptPushes(script[1 : len(script)-2])
+	if !ok || len(pubKeys) != int(numPubKeysOp-op1+1) {
+		return nil
+	}
+	for _, pubKey := range pubKeys {
+		if !isPubKey(pubKey) {
+			return nil
+		}
+	}
+	return pubKeys
+}
+
+// isPubKey returns whether the data has the size and prefix of a compressed,
+// uncompressed or hybrid public key.  Whether it is a point on the curve isn't
+// checked.
+func isPubKey(data []byte) bool {
+	switch len(data) {
+	case 33:
+		return data[0] == 0x02 || data[0] == 0x03
+	case 65:
+		return data[0] == 0x04 || data[0] == 0x06 || data[0] == 0x07
+	}
+	return false
+}
+
+// scriptPushes returns the data pushed by the script, and whether the script
+// only pushes data, without ending in the middle of a push.  Small integers
+// are pushed as their opcode.
+func scriptPushes(script []byte) ([][]byte, bool) {
+	var pushes [][]byte
+	for len(script) > 0 {
+		op := script[0]
+		script = script[1:]
+
+		var size int
+		switch {
+		case op < opPushData1:
+			size = int(op)
+
+		case op == opPushData1 && len(script) >= 1:
+			size = int(script[0])
+			script = script[1:]
+
+		case op == opPushData2 && len(script) >= 2:
+			size = int(script[0]) | int(script[1])<<8
+			script = script[2:]
+
+		case op == opPushData4 && len(script) >= 4:
+			size = int(uint32(script[0]) | uint32(script[1])<<8 |
+				uint32(script[2])<<16 | uint32(script[3])<<24)
+			script = script[4:]
+
+		case op > opPushData4 && op <= op16:
+			// OP_1NEGATE, OP_RESERVED and the small integers.
+			pushes = append(pushes, []byte{op})
+			continue
+
+		default:
+			return nil, false
+		}
+
+		if size < 0 || size > len(script) {
+			return nil, false
+		}
+		pushes = append(pushes, script[:size])
+		script = script[size:]
+	}
+	return pushes, true
+}
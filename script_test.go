@@ -0,0 +1,306 @@
+// Copyright (c) 2026 The monasuite developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package monautil_test
+
+import (
+	"bytes"
+	"encoding/hex"
+	"testing"
+
+	"github.com/monasuite/monad/chaincfg"
+	"github.com/monasuite/monautil"
+)
+
+// decodeHex decodes the passed hex string and panics on failure.  It must
+// only be called with hard-coded values.
+func decodeHex(s string) []byte {
+	b, err := hex.DecodeString(s)
+	if err != nil {
+		panic("invalid hex in source file: " + s)
+	}
+	return b
+}
+
+const (
+	// scriptPubKeyComp and scriptPubKeyUncomp are the compressed and
+	// uncompressed serializations of the generator point.
+	scriptPubKeyComp = "0279be667ef9dcbbac55a06295ce870b07029bfcdb2dce28d9" +
+		"59f2815b16f81798"
+	scriptPubKeyUncomp = "0479be667ef9dcbbac55a06295ce870b07029bfcdb2dce2" +
+		"8d959f2815b16f81798483ada7726a3c4655da4fbfc0e1108a8fd17b448a685" +
+		"54199c47d08ffb10d4b8"
+
+	// scriptPubKeyOffCurve has the prefix of a compressed public key, but
+	// is not a point on the curve.
+	scriptPubKeyOffCurve = "0200000000000000000000000000000000000000000000" +
+		"00000000000000000005"
+
+	hash20 = "0102030405060708090a0b0c0d0e0f1011121314"
+	hash32 = "0102030405060708090a0b0c0d0e0f10" +
+		"1112131415161718191a1b1c1d1e1f20"
+)
+
+// TestPayToAddrScript ensures the canonical output scripts of addresses are
+// built, and that the addresses are extracted back from them.
+func TestPayToAddrScript(t *testing.T) {
+	net := &chaincfg.MainNetParams
+
+	p2pkh, _ := monautil.NewAddressPubKeyHash(decodeHex(hash20), net)
+	p2sh, _ := monautil.NewAddressScriptHashFromHash(decodeHex(hash20), net)
+	p2pkComp, _ := monautil.NewAddressPubKey(decodeHex(scriptPubKeyComp), net)
+	p2pkUncomp, _ := monautil.NewAddressPubKey(
+		decodeHex(scriptPubKeyUncomp), net)
+	p2wpkh, _ := monautil.NewAddressWitnessPubKeyHash(decodeHex(hash20), net)
+	p2wsh, _ := monautil.NewAddressWitnessScriptHash(decodeHex(hash32), net)
+	p2tr, _ := monautil.NewAddressTaproot(decodeHex(hash32), net)
+	v16, _ := monautil.NewAddressWitnessProgram(16, decodeHex(hash20), net)
+
+	tests := []struct {
+		name    string
+		addr    monautil.Address
+		script  string
+		class   monautil.ScriptClass
+		reqSigs int
+	}{
+		{"p2pkh", p2pkh, "76a914" + hash20 + "88ac",
+			monautil.PubKeyHashTy, 1},
+		{"p2sh", p2sh, "a914" + hash20 + "87",
+			monautil.ScriptHashTy, 1},
+		{"p2pk compressed", p2pkComp, "21" + scriptPubKeyComp + "ac",
+			monautil.PubKeyTy, 1},
+		{"p2pk uncompressed", p2pkUncomp,
+			"41" + scriptPubKeyUncomp + "ac", monautil.PubKeyTy, 1},
+		{"p2wpkh", p2wpkh, "0014" + hash20,
+			monautil.WitnessV0PubKeyHashTy, 1},
+		{"p2wsh", p2wsh, "0020" + hash32,
+			monautil.WitnessV0ScriptHashTy, 1},
+		{"p2tr", p2tr, "5120" + hash32, monautil.WitnessV1PlusTy, 1},
+		{"witness v16", v16, "6014" + hash20,
+			monautil.WitnessV1PlusTy, 0},
+	}
+
+	for _, test := range tests {
+		script, err := monautil.PayToAddrScript(test.addr)
+		if err != nil {
+			t.Errorf("%s: unexpected error: %v", test.name, err)
+			continue
+		}
+		want := decodeHex(test.script)
+		if !bytes.Equal(script, want) {
+			t.Errorf("%s: got script %x, want %x", test.name,
+				script, want)
+			continue
+		}
+
+		class, addrs, reqSigs, err := monautil.ExtractPkScriptAddrs(
+			script, net)
+		if err != nil {
+			t.Errorf("%s: unexpected extract error: %v", test.name,
+				err)
+			continue
+		}
+		if class != test.class {
+			t.Errorf("%s: got class %v, want %v", test.name, class,
+				test.class)
+		}
+		if reqSigs != test.reqSigs {
+			t.Errorf("%s: got %d required signatures, want %d",
+				test.name, reqSigs, test.reqSigs)
+		}
+		if len(addrs) != 1 || addrs[0].String() != test.addr.String() {
+			t.Errorf("%s: got addresses %v, want %v", test.name,
+				addrs, test.addr)
+		}
+	}
+}
+
+// TestPayToAddrScriptErrors ensures no script is built for unsupported and
+// nil addresses.
+func TestPayToAddrScriptErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		addr monautil.Address
+	}{
+		{"nil", nil},
+		{"nil p2pkh", (*monautil.AddressPubKeyHash)(nil)},
+		{"nil p2sh", (*monautil.AddressScriptHash)(nil)},
+		{"nil p2pk", (*monautil.AddressPubKey)(nil)},
+		{"nil p2wpkh", (*monautil.AddressWitnessPubKeyHash)(nil)},
+		{"nil p2wsh", (*monautil.AddressWitnessScriptHash)(nil)},
+		{"nil p2tr", (*monautil.AddressTaproot)(nil)},
+		{"nil witness program", (*monautil.AddressWitnessProgram)(nil)},
+	}
+
+	for _, test := range tests {
+		_, err := monautil.PayToAddrScript(test.addr)
+		if err != monautil.ErrUnsupportedScriptAddress {
+			t.Errorf("%s: got error %v, want %v", test.name, err,
+				monautil.ErrUnsupportedScriptAddress)
+		}
+	}
+}
+
+// TestExtractPkScriptAddrs ensures scripts without a single address are
+// classified and their addresses extracted.
+func TestExtractPkScriptAddrs(t *testing.T) {
+	net := &chaincfg.MainNetParams
+
+	tests := []struct {
+		name    string
+		script  string
+		class   monautil.ScriptClass
+		addrs   []string
+		reqSigs int
+	}{
+		{
+			name: "1 of 2 multisig",
+			script: "51" + "21" + scriptPubKeyComp + "41" +
+				scriptPubKeyUncomp + "52ae",
+			class:   monautil.MultiSigTy,
+			addrs:   []string{scriptPubKeyComp, scriptPubKeyUncomp},
+			reqSigs: 1,
+		},
+		{
+			name: "2 of 2 multisig with key off the curve",
+			script: "52" + "21" + scriptPubKeyComp + "21" +
+				scriptPubKeyOffCurve + "52ae",
+			class:   monautil.MultiSigTy,
+			addrs:   []string{scriptPubKeyComp},
+			reqSigs: 2,
+		},
+		{
+			name:    "p2pk off the curve",
+			script:  "21" + scriptPubKeyOffCurve + "ac",
+			class:   monautil.PubKeyTy,
+			reqSigs: 1,
+		},
+		{
+			name:   "null data",
+			script: "6a0568656c6c6f",
+			class:  monautil.NullDataTy,
+		},
+		{
+			name:   "null data with small integers",
+			script: "6a4c020102" + "51" + "60",
+			class:  monautil.NullDataTy,
+		},
+		{
+			name:   "bare op_return",
+			script: "6a",
+			class:  monautil.NullDataTy,
+		},
+		{
+			name:   "op_return followed by opcode",
+			script: "6a0568656c6c6fac",
+			class:  monautil.NonStandardTy,
+		},
+		{
+			name:   "op_return with truncated push",
+			script: "6a0568656c6c",
+			class:  monautil.NonStandardTy,
+		},
+		{
+			name:   "empty",
+			script: "",
+			class:  monautil.NonStandardTy,
+		},
+		{
+			name:   "op_true",
+			script: "51",
+			class:  monautil.NonStandardTy,
+		},
+		{
+			name:   "witness v0 of invalid size",
+			script: "0018" + hash20 + "01020304",
+			class:  monautil.NonStandardTy,
+		},
+		{
+			name:   "witness program too long",
+			script: "5129" + hash32 + "010203040506070809",
+			class:  monautil.NonStandardTy,
+		},
+		{
+			name:   "multisig requiring more signatures than keys",
+			script: "52" + "21" + scriptPubKeyComp + "51ae",
+			class:  monautil.NonStandardTy,
+		},
+		{
+			name:   "multisig with wrong key count",
+			script: "51" + "21" + scriptPubKeyComp + "52ae",
+			class:  monautil.NonStandardTy,
+		},
+		{
+			name:   "multisig with invalid key",
+			script: "51" + "14" + hash20 + "51ae",
+			class:  monautil.NonStandardTy,
+		},
+		{
+			name:   "p2pk with invalid prefix",
+			script: "21" + "05" + scriptPubKeyComp[2:] + "ac",
+			class:  monautil.NonStandardTy,
+		},
+	}
+
+	for _, test := range tests {
+		script := decodeHex(test.script)
+		if class := monautil.GetScriptClass(script); class != test.class {
+			t.Errorf("%s: got class %v, want %v", test.name, class,
+				test.class)
+		}
+
+		class, addrs, reqSigs, err := monautil.ExtractPkScriptAddrs(
+			script, net)
+		if err != nil {
+			t.Errorf("%s: unexpected error: %v", test.name, err)
+			continue
+		}
+		if class != test.class {
+			t.Errorf("%s: extracted class %v, want %v", test.name,
+				class, test.class)
+		}
+		if reqSigs != test.reqSigs {
+			t.Errorf("%s: got %d required signatures, want %d",
+				test.name, reqSigs, test.reqSigs)
+		}
+		if len(addrs) != len(test.addrs) {
+			t.Errorf("%s: got %d addresses, want %d", test.name,
+				len(addrs), len(test.addrs))
+			continue
+		}
+		for i, addr := range addrs {
+			if addr.String() != test.addrs[i] {
+				t.Errorf("%s: address %d is %v, want %v",
+					test.name, i, addr, test.addrs[i])
+			}
+		}
+	}
+}
+
+// TestScriptClassStringer tests the stringized output for the ScriptClass
+// type.
+func TestScriptClassStringer(t *testing.T) {
+	tests := []struct {
+		in   monautil.ScriptClass
+		want string
+	}{
+		{monautil.NonStandardTy, "nonstandard"},
+		{monautil.PubKeyTy, "pubkey"},
+		{monautil.PubKeyHashTy, "pubkeyhash"},
+		{monautil.ScriptHashTy, "scripthash"},
+		{monautil.WitnessV0PubKeyHashTy, "witness_v0_keyhash"},
+		{monautil.WitnessV0ScriptHashTy, "witness_v0_scripthash"},
+		{monautil.WitnessV1PlusTy, "witness_v1plus"},
+		{monautil.MultiSigTy, "multisig"},
+		{monautil.NullDataTy, "nulldata"},
+		{0xff, "Unknown ScriptClass (255)"},
+	}
+
+	for i, test := range tests {
+		if got := test.in.String(); got != test.want {
+			t.Errorf("String #%d: got %q, want %q", i, got,
+				test.want)
+		}
+	}
+}
@@ -26,6 +26,8 @@ type Tx struct {
 	txHash        *chainhash.Hash // Cached transaction hash
 	txHashWitness *chainhash.Hash // Cached transaction witness hash
 	txHasWitness  *bool           // If the transaction has witness data
+	txStripped    *int64          // Cached size without witness data
+	txWeight      *int64          // Cached transaction weight
 	txIndex       int             // Position within a block or TxIndexUnknown
 }
 
@@ -79,6 +81,41 @@ func (t *Tx) HasWitness() bool {
 	return hasWitness
 }
 
+// StrippedSize returns the serialized size of the transaction without any
+// witness data.  This is equivalent to calling SerializeSizeStripped on the
+// underlying wire.MsgTx, however it caches the result so subsequent calls are
+// more efficient.
+func (t *Tx) StrippedSize() int64 {
+	if t.txStripped != nil {
+		return *t.txStripped
+	}
+
+	size := int64(t.msgTx.SerializeSizeStripped())
+	t.txStripped = &size
+	return size
+}
+
+// Weight returns the weight of the transaction, which is its stripped size
+// scaled by the witness scale factor plus the size of its witness data.  The
+// result is cached so subsequent calls are more efficient.
+func (t *Tx) Weight() int64 {
+	if t.txWeight != nil {
+		return *t.txWeight
+	}
+
+	weight := t.StrippedSize()*(WitnessScaleFactor-1) +
+		int64(t.msgTx.SerializeSize())
+	t.txWeight = &weight
+	return weight
+}
+
+// VirtualSize returns the virtual size of the transaction, which is its
+// weight divided by the witness scale factor and rounded up.  Fee rates per
+// virtual byte apply to this size.
+func (t *Tx) VirtualSize() int64 {
+	return WeightToVSize(t.Weight())
+}
+
 // Index returns the saved index of the transaction within a block.  This value
 // will be TxIndexUnknown if it hasn't already explicitly been set.
 func (t *Tx) Index() int {
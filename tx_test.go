@@ -134,3 +134,39 @@ func TestTxWitnessHash(t *testing.T) {
 		}
 	}
 }
+
+// TestTxSizes tests the StrippedSize(), Weight() and VirtualSize() methods.
+func TestTxSizes(t *testing.T) {
+	tests := []struct {
+		name     string
+		msgTx    int
+		stripped int64
+		weight   int64
+		vsize    int64
+	}{
+		{"witness", 0, 135, 549, 138},
+		{"no witness", 1, 259, 1036, 259},
+		{"no witness 2", 3, 225, 900, 225},
+	}
+
+	for _, test := range tests {
+		tx := monautil.NewTx(Block100000.Transactions[test.msgTx])
+
+		// Request the sizes multiple times to ensure the cached values
+		// are consistent.
+		for i := 0; i < 2; i++ {
+			if size := tx.StrippedSize(); size != test.stripped {
+				t.Errorf("%s: StrippedSize: got %d, want %d",
+					test.name, size, test.stripped)
+			}
+			if weight := tx.Weight(); weight != test.weight {
+				t.Errorf("%s: Weight: got %d, want %d", test.name,
+					weight, test.weight)
+			}
+			if vsize := tx.VirtualSize(); vsize != test.vsize {
+				t.Errorf("%s: VirtualSize: got %d, want %d",
+					test.name, vsize, test.vsize)
+			}
+		}
+	}
+}
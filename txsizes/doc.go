@@ -0,0 +1,20 @@
+// Copyright (c) 2026 The monasuite developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+/*
+Package txsizes estimates the size of transactions before they are signed.
+
+Overview
+
+Coin selection and fee bumping need the size of a transaction to compute its
+fee, but the size of a transaction is only known once its inputs are signed.
+This package predicts the weight and virtual size of a signed transaction from
+how each input is spent, which is one of P2PKH, P2SH-P2WPKH, P2WPKH or P2WSH
+m-of-n multisig, and the public key scripts of its outputs.
+
+The estimates assume every signature has the largest DER encoding, so they may
+exceed the size of the signed transaction by a few bytes but never fall short
+of it.  A fee computed from the estimate therefore always meets the fee rate.
+*/
+package txsizes
@@ -0,0 +1,36 @@
+// Copyright (c) 2026 The monasuite developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package txsizes_test
+
+import (
+	"fmt"
+
+	"github.com/monasuite/monad/wire"
+	"github.com/monasuite/monautil"
+	"github.com/monasuite/monautil/txsizes"
+)
+
+// This example demonstrates estimating the fee of a transaction spending a
+// P2WPKH and a 2-of-3 multisig input to a payment and a change output before
+// it is signed.
+func ExampleEstimateVirtualSize() {
+	inputs := []txsizes.Input{
+		{Type: txsizes.P2WPKH},
+		txsizes.NewMultiSigInput(2, 3),
+	}
+	outputs := []*wire.TxOut{
+		wire.NewTxOut(1e8, make([]byte, txsizes.P2PKHPkScriptSize)),
+		wire.NewTxOut(5e7, make([]byte, txsizes.P2WPKHPkScriptSize)),
+	}
+
+	vsize := txsizes.EstimateVirtualSize(inputs, outputs)
+	feeRate := monautil.FeeRatePerKVByte(100000)
+	fmt.Println("Virtual size:", vsize)
+	fmt.Println("Fee:", feeRate.FeeForVSize(vsize))
+
+	// Output:
+	// Virtual size: 249
+	// Fee: 0.000249 MONA
+}
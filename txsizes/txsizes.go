@@ -0,0 +1,228 @@
+// Copyright (c) 2026 The monasuite developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package txsizes
+
+import (
+	"github.com/monasuite/monad/wire"
+	"github.com/monasuite/monautil"
+)
+
+const (
+	// MaxSigSize is the largest size of a DER encoded signature with its
+	// sighash type appended.  Estimates use it for every signature so they
+	// are never smaller than the signed transaction.
+	MaxSigSize = 73
+
+	// PubKeySize is the size of a compressed public key.
+	PubKeySize = 33
+
+	// P2PKHPkScriptSize is the size of a P2PKH output script:
+	// OP_DUP OP_HASH160 OP_DATA_20 <20 bytes> OP_EQUALVERIFY OP_CHECKSIG.
+	P2PKHPkScriptSize = 1 + 1 + 1 + 20 + 1 + 1
+
+	// P2SHPkScriptSize is the size of a P2SH output script:
+	// OP_HASH160 OP_DATA_20 <20 bytes> OP_EQUAL.
+	P2SHPkScriptSize = 1 + 1 + 20 + 1
+
+	// P2WPKHPkScriptSize is the size of a P2WPKH output script:
+	// OP_0 OP_DATA_20 <20 bytes>.
+	P2WPKHPkScriptSize = 1 + 1 + 20
+
+	// P2WSHPkScriptSize is the size of a P2WSH output script:
+	// OP_0 OP_DATA_32 <32 bytes>.
+	P2WSHPkScriptSize = 1 + 1 + 32
+
+	// P2PKHSigScriptSize is the size of the signature script spending a
+	// P2PKH output: OP_DATA_73 <signature> OP_DATA_33 <public key>.
+	P2PKHSigScriptSize = 1 + MaxSigSize + 1 + PubKeySize
+
+	// NestedP2WPKHSigScriptSize is the size of the signature script
+	// spending a P2SH-P2WPKH output, which pushes the P2WPKH script:
+	// OP_DATA_22 <P2WPKH script>.
+	NestedP2WPKHSigScriptSize = 1 + P2WPKHPkScriptSize
+
+	// P2WPKHWitnessSize is the size of the witness spending a P2WPKH
+	// output: the number of items, then the length prefixed signature and
+	// public key.
+	P2WPKHWitnessSize = 1 + 1 + MaxSigSize + 1 + PubKeySize
+
+	// baseInputSize is the size of an input without its signature script
+	// or its length: the previous outpoint and the sequence number.
+	baseInputSize = 32 + 4 + 4
+
+	// baseTxSize is the size of a transaction without its inputs, outputs
+	// and their counts: the version and the lock time.
+	baseTxSize = 4 + 4
+
+	// witnessHeaderSize is the size of the marker and flag bytes of
+	// transactions with witness data.
+	witnessHeaderSize = 2
+)
+
+// InputType identifies how an input is spent, which determines the size of
+// its signature script and witness.
+type InputType uint8
+
+// These constants define the input types the estimator understands.  All
+// public keys are assumed to be compressed.
+const (
+	// P2PKH is an input spending a pay-to-pubkey-hash output.
+	P2PKH InputType = iota
+
+	// NestedP2WPKH is an input spending a P2SH output whose redeem script
+	// is a P2WPKH script.
+	NestedP2WPKH
+
+	// P2WPKH is an input spending a pay-to-witness-pubkey-hash output.
+	P2WPKH
+
+	// P2WSHMultiSig is an input spending a P2WSH output whose witness
+	// script is an m-of-n OP_CHECKMULTISIG script.
+	P2WSHMultiSig
+)
+
+// String returns the InputType as a human-readable name.
+func (t InputType) String() string {
+	switch t {
+	case P2PKH:
+		return "p2pkh"
+	case NestedP2WPKH:
+		return "p2sh-p2wpkh"
+	case P2WPKH:
+		return "p2wpkh"
+	case P2WSHMultiSig:
+		return "p2wsh-multisig"
+	default:
+		return "unknown"
+	}
+}
+
+// Input describes an input to estimate the size of.
+type Input struct {
+	// Type is how the input is spent.
+	Type InputType
+
+	// RequiredSigs and NumKeys are the m and n of P2WSHMultiSig inputs,
+	// where 1 <= m <= n <= 20.  They are ignored for other types.
+	RequiredSigs int
+	NumKeys      int
+}
+
+// NewMultiSigInput returns an input spending a P2WSH output with an m-of-n
+// multisig witness script.
+func NewMultiSigInput(m, n int) Input {
+	return Input{Type: P2WSHMultiSig, RequiredSigs: m, NumKeys: n}
+}
+
+// smallIntSize returns the size of the opcode pushing n in a script, which is
+// a single byte small integer opcode for n up to 16.
+func smallIntSize(n int) int {
+	if n <= 16 {
+		return 1
+	}
+	return 2
+}
+
+// MultiSigScriptSize returns the size of an m-of-n multisig script with
+// compressed public keys:
+// OP_m <OP_DATA_33 <public key>>... OP_n OP_CHECKMULTISIG.
+func MultiSigScriptSize(m, n int) int {
+	return smallIntSize(m) + n*(1+PubKeySize) + smallIntSize(n) + 1
+}
+
+// SigScriptSize returns the size of the signature script of the input,
+// without its length prefix.
+func (in Input) SigScriptSize() int {
+	switch in.Type {
+	case P2PKH:
+		return P2PKHSigScriptSize
+	case NestedP2WPKH:
+		return NestedP2WPKHSigScriptSize
+	default:
+		return 0
+	}
+}
+
+// WitnessSize returns the size of the witness of the input, including the
+// number of its items, or zero for inputs without witness data.
+func (in Input) WitnessSize() int {
+	switch in.Type {
+	case NestedP2WPKH, P2WPKH:
+		return P2WPKHWitnessSize
+
+	case P2WSHMultiSig:
+		// The witness holds the empty item consumed by the
+		// OP_CHECKMULTISIG bug, the signatures and the witness script.
+		m := in.RequiredSigs
+		scriptSize := MultiSigScriptSize(m, in.NumKeys)
+		return wire.VarIntSerializeSize(uint64(m+2)) + 1 +
+			m*(1+MaxSigSize) +
+			wire.VarIntSerializeSize(uint64(scriptSize)) + scriptSize
+
+	default:
+		return 0
+	}
+}
+
+// HasWitness returns whether the input is spent with witness data.
+func (in Input) HasWitness() bool {
+	return in.WitnessSize() != 0
+}
+
+// Size returns the size of the input without its witness.
+func (in Input) Size() int {
+	sigScriptSize := in.SigScriptSize()
+	return baseInputSize +
+		wire.VarIntSerializeSize(uint64(sigScriptSize)) + sigScriptSize
+}
+
+// Weight returns the weight the input adds to a transaction, which is its
+// size scaled by the witness scale factor plus the size of its witness.
+func (in Input) Weight() int64 {
+	return int64(in.Size()*monautil.WitnessScaleFactor + in.WitnessSize())
+}
+
+// OutputSize returns the size of an output with a public key script of the
+// passed size.
+func OutputSize(pkScriptSize int) int {
+	return 8 + wire.VarIntSerializeSize(uint64(pkScriptSize)) + pkScriptSize
+}
+
+// EstimateWeight returns the weight of the transaction spending the inputs to
+// the outputs once it is signed.  Every signature is assumed to have the
+// largest size, so the estimate is never less than the actual weight.
+func EstimateWeight(inputs []Input, outputs []*wire.TxOut) int64 {
+	size := baseTxSize + wire.VarIntSerializeSize(uint64(len(inputs))) +
+		wire.VarIntSerializeSize(uint64(len(outputs)))
+	for _, out := range outputs {
+		size += OutputSize(len(out.PkScript))
+	}
+
+	witnessSize, hasWitness := 0, false
+	for _, in := range inputs {
+		size += in.Size()
+		witnessSize += in.WitnessSize()
+		hasWitness = hasWitness || in.HasWitness()
+	}
+
+	// Transactions with witness data have the marker and flag bytes, and
+	// every input without witness data has an empty witness.
+	if hasWitness {
+		witnessSize += witnessHeaderSize
+		for _, in := range inputs {
+			if !in.HasWitness() {
+				witnessSize++
+			}
+		}
+	}
+
+	return int64(size*monautil.WitnessScaleFactor + witnessSize)
+}
+
+// EstimateVirtualSize returns the virtual size of the transaction spending the
+// inputs to the outputs once it is signed.  See EstimateWeight.
+func EstimateVirtualSize(inputs []Input, outputs []*wire.TxOut) int64 {
+	return monautil.WeightToVSize(EstimateWeight(inputs, outputs))
+}
@@ -0,0 +1,181 @@
+// Copyright (c) 2026 The monasuite developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package txsizes_test
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/monasuite/monad/txscript"
+	"github.com/monasuite/monad/wire"
+	"github.com/monasuite/monautil"
+	"github.com/monasuite/monautil/txsizes"
+)
+
+// push returns a script pushing n bytes of data.
+func push(n int) []byte {
+	script, _ := txscript.NewScriptBuilder().
+		AddData(bytes.Repeat([]byte{0x02}, n)).Script()
+	return script
+}
+
+// signedTxIn returns an input as it looks once it is signed with signatures of
+// the largest size.
+func signedTxIn(t *testing.T, in txsizes.Input) *wire.TxIn {
+	sig := bytes.Repeat([]byte{0x30}, txsizes.MaxSigSize)
+	pubKey := bytes.Repeat([]byte{0x02}, txsizes.PubKeySize)
+
+	txIn := wire.NewTxIn(&wire.OutPoint{}, nil, nil)
+	switch in.Type {
+	case txsizes.P2PKH:
+		txIn.SignatureScript = append(push(txsizes.MaxSigSize),
+			push(txsizes.PubKeySize)...)
+
+	case txsizes.NestedP2WPKH:
+		txIn.SignatureScript = push(txsizes.P2WPKHPkScriptSize)
+		txIn.Witness = wire.TxWitness{sig, pubKey}
+
+	case txsizes.P2WPKH:
+		txIn.Witness = wire.TxWitness{sig, pubKey}
+
+	case txsizes.P2WSHMultiSig:
+		builder := txscript.NewScriptBuilder().
+			AddInt64(int64(in.RequiredSigs))
+		for i := 0; i < in.NumKeys; i++ {
+			builder.AddData(pubKey)
+		}
+		script, err := builder.AddInt64(int64(in.NumKeys)).
+			AddOp(txscript.OP_CHECKMULTISIG).Script()
+		if err != nil {
+			t.Fatalf("unable to build multisig script: %v", err)
+		}
+
+		txIn.Witness = wire.TxWitness{nil}
+		for i := 0; i < in.RequiredSigs; i++ {
+			txIn.Witness = append(txIn.Witness, sig)
+		}
+		txIn.Witness = append(txIn.Witness, script)
+	}
+
+	return txIn
+}
+
+// TestEstimate ensures the estimated sizes match the sizes of transactions
+// signed with signatures of the largest size.
+func TestEstimate(t *testing.T) {
+	p2pkhOut := wire.NewTxOut(1e8, make([]byte, txsizes.P2PKHPkScriptSize))
+	p2wpkhOut := wire.NewTxOut(1e8, make([]byte, txsizes.P2WPKHPkScriptSize))
+	p2wshOut := wire.NewTxOut(1e8, make([]byte, txsizes.P2WSHPkScriptSize))
+	p2pkh := txsizes.Input{Type: txsizes.P2PKH}
+	nested := txsizes.Input{Type: txsizes.NestedP2WPKH}
+	p2wpkh := txsizes.Input{Type: txsizes.P2WPKH}
+
+	manyInputs := make([]txsizes.Input, 300)
+	for i := range manyInputs {
+		manyInputs[i] = p2wpkh
+	}
+
+	tests := []struct {
+		name    string
+		inputs  []txsizes.Input
+		outputs []*wire.TxOut
+		vsize   int64
+	}{
+		{
+			name:    "1 p2pkh to 1 p2pkh",
+			inputs:  []txsizes.Input{p2pkh},
+			outputs: []*wire.TxOut{p2pkhOut},
+			vsize:   193,
+		},
+		{
+			name:    "1 p2wpkh to 2 p2wpkh",
+			inputs:  []txsizes.Input{p2wpkh},
+			outputs: []*wire.TxOut{p2wpkhOut, p2wpkhOut},
+			vsize:   141,
+		},
+		{
+			name:    "1 p2sh-p2wpkh to 1 p2pkh",
+			inputs:  []txsizes.Input{nested},
+			outputs: []*wire.TxOut{p2pkhOut},
+			vsize:   136,
+		},
+		{
+			name:    "mixed inputs",
+			inputs:  []txsizes.Input{p2pkh, nested, p2wpkh},
+			outputs: []*wire.TxOut{p2wshOut, p2wpkhOut},
+		},
+		{
+			name:    "2-of-3 multisig",
+			inputs:  []txsizes.Input{txsizes.NewMultiSigInput(2, 3)},
+			outputs: []*wire.TxOut{p2wshOut},
+		},
+		{
+			name: "15-of-20 multisig and p2pkh",
+			inputs: []txsizes.Input{
+				txsizes.NewMultiSigInput(15, 20), p2pkh,
+			},
+			outputs: []*wire.TxOut{p2wpkhOut},
+		},
+		{
+			name:    "many inputs",
+			inputs:  manyInputs,
+			outputs: []*wire.TxOut{p2pkhOut},
+		},
+		{
+			name:   "no outputs",
+			inputs: []txsizes.Input{p2wpkh},
+		},
+	}
+
+	for _, test := range tests {
+		msgTx := wire.NewMsgTx(wire.TxVersion)
+		for _, in := range test.inputs {
+			msgTx.AddTxIn(signedTxIn(t, in))
+		}
+		for _, out := range test.outputs {
+			msgTx.AddTxOut(out)
+		}
+		tx := monautil.NewTx(msgTx)
+
+		weight := txsizes.EstimateWeight(test.inputs, test.outputs)
+		if weight != tx.Weight() {
+			t.Errorf("%s: EstimateWeight: got %d, want %d",
+				test.name, weight, tx.Weight())
+		}
+		vsize := txsizes.EstimateVirtualSize(test.inputs, test.outputs)
+		if vsize != tx.VirtualSize() {
+			t.Errorf("%s: EstimateVirtualSize: got %d, want %d",
+				test.name, vsize, tx.VirtualSize())
+		}
+		if test.vsize != 0 && vsize != test.vsize {
+			t.Errorf("%s: EstimateVirtualSize: got %d, want %d",
+				test.name, vsize, test.vsize)
+		}
+	}
+}
+
+// TestInputWeight ensures the weight of each input matches the weight it
+// adds to a transaction with witness data.
+func TestInputWeight(t *testing.T) {
+	p2wpkh := txsizes.Input{Type: txsizes.P2WPKH}
+	out := wire.NewTxOut(1e8, make([]byte, txsizes.P2WPKHPkScriptSize))
+	outputs := []*wire.TxOut{out}
+
+	inputs := []txsizes.Input{
+		{Type: txsizes.NestedP2WPKH},
+		p2wpkh,
+		txsizes.NewMultiSigInput(2, 3),
+	}
+	base := txsizes.EstimateWeight([]txsizes.Input{p2wpkh}, outputs)
+	for _, in := range inputs {
+		weight := txsizes.EstimateWeight(
+			[]txsizes.Input{p2wpkh, in}, outputs,
+		)
+		if in.Weight() != weight-base {
+			t.Errorf("%v: got weight %d, want %d", in.Type,
+				in.Weight(), weight-base)
+		}
+	}
+}
@@ -0,0 +1,280 @@
+// Copyright (c) 2026 The monasuite developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package monautil
+
+// References:
+//   [BIP21]: URI Scheme
+//   https://github.com/bitcoin/bips/blob/master/bip-0021.mediawiki
+
+import (
+	"errors"
+	"net/url"
+	"sort"
+	"strings"
+
+	"github.com/btcsuite/golangcrypto/ripemd160"
+	"github.com/monasuite/monad/chaincfg"
+	"github.com/monasuite/monautil/base58"
+	"github.com/shopspring/decimal"
+)
+
+// URIScheme is the scheme of payment URIs.
+const URIScheme = "monacoin"
+
+// requiredParamPrefix is the prefix of the names of URI parameters which the
+// parser must understand.
+const requiredParamPrefix = "req-"
+
+var (
+	// ErrInvalidURIScheme describes an error in which a payment URI does
+	// not start with the monacoin: scheme.
+	ErrInvalidURIScheme = errors.New("URI scheme is not " + URIScheme)
+
+	// ErrInvalidURIAmount describes an error in which the amount of a
+	// payment URI is not a decimal number of monacoin with at most eight
+	// decimal places, or exceeds MaxSatoshi.
+	ErrInvalidURIAmount = errors.New("invalid URI amount")
+
+	// ErrMalformedURIParam describes an error in which a parameter of a
+	// payment URI is not properly percent-encoded or lacks a name.
+	ErrMalformedURIParam = errors.New("malformed URI parameter")
+
+	// ErrDuplicateURIParam describes an error in which a parameter occurs
+	// more than once in a payment URI.
+	ErrDuplicateURIParam = errors.New("duplicate URI parameter")
+
+	// ErrUnknownRequiredURIParam describes an error in which a payment URI
+	// holds a req- parameter which the caller doesn't understand.
+	ErrUnknownRequiredURIParam = errors.New("unknown required URI " +
+		"parameter")
+)
+
+// URI is a payment URI of the form
+// monacoin:<address>?amount=<amount>&label=<label>&message=<message>, as
+// specified by [BIP21].
+type URI struct {
+	// Address is the address to pay to.
+	Address Address
+
+	// Amount is the requested amount, or zero if none was requested.
+	Amount Amount
+
+	// Label is a label for the address, such as the name of the
+	// recipient, or empty if none was given.
+	Label string
+
+	// Message describes the payment to the user, or is empty if none was
+	// given.
+	Message string
+
+	// Params holds the other parameters of the URI by name, including
+	// the req- parameters the caller declared as understood when parsing.
+	Params map[string]string
+}
+
+// ParseURI parses a payment URI and validates its address for the passed
+// network, returning ErrWrongNet for an address of another network.  The
+// amount is parsed as a decimal number, so it is converted to an Amount
+// without rounding errors.
+//
+// Parameters whose name starts with req- must be understood by the caller,
+// so the URI is rejected with ErrUnknownRequiredURIParam unless their names
+// are passed in knownRequired.  Known required parameters and all other
+// parameters are kept in the Params field.
+func ParseURI(uri string, net *chaincfg.Params,
+	knownRequired ...string) (*URI, error) {
+
+	// The scheme is case insensitive.
+	prefix := URIScheme + ":"
+	if len(uri) < len(prefix) ||
+		!strings.EqualFold(uri[:len(prefix)], prefix) {
+
+		return nil, ErrInvalidURIScheme
+	}
+	uri = uri[len(prefix):]
+
+	addrPart, query := uri, ""
+	if i := strings.IndexByte(uri, '?'); i >= 0 {
+		addrPart, query = uri[:i], uri[i+1:]
+	}
+	addr, err := DecodeAddress(addrPart, net)
+	if err != nil {
+		if isRegisteredNetAddress(addrPart) {
+			return nil, ErrWrongNet
+		}
+		return nil, err
+	}
+	if !addr.IsForNet(net) {
+		return nil, ErrWrongNet
+	}
+
+	result := &URI{Address: addr, Params: make(map[string]string)}
+	seen := make(map[string]bool)
+	for _, param := range strings.Split(query, "&") {
+		if param == "" {
+			continue
+		}
+
+		name, value, err := parseURIParam(param)
+		if err != nil {
+			return nil, err
+		}
+		if seen[name] {
+			return nil, ErrDuplicateURIParam
+		}
+		seen[name] = true
+
+		switch name {
+		case "amount":
+			result.Amount, err = parseURIAmount(value)
+			if err != nil {
+				return nil, err
+			}
+
+		case "label":
+			result.Label = value
+
+		case "message":
+			result.Message = value
+
+		default:
+			if strings.HasPrefix(name, requiredParamPrefix) &&
+				!containsString(knownRequired, name) {
+
+				return nil, ErrUnknownRequiredURIParam
+			}
+			result.Params[name] = value
+		}
+	}
+
+	return result, nil
+}
+
+// isRegisteredNetAddress returns whether the passed string is a valid
+// P2PKH, P2SH or segwit address of any registered network, which DecodeAddress
+// rejects when it isn't of the network it decodes for.
+func isRegisteredNetAddress(addr string) bool {
+	oneIndex := strings.LastIndexByte(addr, '1')
+	if oneIndex > 1 && chaincfg.IsBech32SegwitPrefix(addr[:oneIndex+1]) {
+		_, _, err := decodeSegWitAddress(addr)
+		return err == nil
+	}
+
+	decoded, netID, err := base58.CheckDecode(addr)
+	if err != nil || len(decoded) != ripemd160.Size {
+		return false
+	}
+	return chaincfg.IsPubKeyHashAddrID(netID) ||
+		chaincfg.IsScriptHashAddrID(netID)
+}
+
+// parseURIParam splits a URI parameter of the form name=value into its
+// percent-decoded name and value.
+func parseURIParam(param string) (string, string, error) {
+	rawName, rawValue := param, ""
+	if i := strings.IndexByte(param, '='); i >= 0 {
+		rawName, rawValue = param[:i], param[i+1:]
+	}
+
+	// Plus signs are kept as they are rather than decoded as spaces, as
+	// [BIP21] URIs follow RFC 3986.
+	name, err := url.PathUnescape(rawName)
+	if err != nil || name == "" {
+		return "", "", ErrMalformedURIParam
+	}
+	value, err := url.PathUnescape(rawValue)
+	if err != nil {
+		return "", "", ErrMalformedURIParam
+	}
+
+	return name, value, nil
+}
+
+// parseURIAmount parses the amount parameter of a URI, a decimal number of
+// monacoin such as 1.5.
+func parseURIAmount(s string) (Amount, error) {
+	// Only plain decimal numbers are allowed, without signs or exponents.
+	digits := 0
+	for i, c := range s {
+		switch {
+		case c >= '0' && c <= '9':
+			digits++
+		case c == '.' && !strings.ContainsRune(s[:i], '.'):
+		default:
+			return 0, ErrInvalidURIAmount
+		}
+	}
+	if digits == 0 {
+		return 0, ErrInvalidURIAmount
+	}
+
+	d, err := decimal.NewFromString(s)
+	if err != nil || !d.Equal(d.Round(8)) {
+		return 0, ErrInvalidURIAmount
+	}
+	amount, err := NewAmount(d)
+	if err != nil || amount > MaxSatoshi {
+		return 0, ErrInvalidURIAmount
+	}
+
+	return amount, nil
+}
+
+// containsString returns whether s is one of the passed strings.
+func containsString(strs []string, s string) bool {
+	for _, str := range strs {
+		if str == s {
+			return true
+		}
+	}
+	return false
+}
+
+// escapeURIParam percent-encodes a URI parameter name or value.  Spaces are
+// encoded as %20 rather than plus signs, which [BIP21] URIs don't decode.
+func escapeURIParam(s string) string {
+	return strings.Replace(url.QueryEscape(s), "+", "%20", -1)
+}
+
+// String returns the URI in the form
+// monacoin:<address>?amount=<amount>&label=<label>&message=<message>,
+// followed by the other parameters sorted by name.  Parameters that are empty
+// are left out.
+func (u *URI) String() string {
+	var b strings.Builder
+	b.WriteString(URIScheme)
+	b.WriteByte(':')
+	b.WriteString(u.Address.EncodeAddress())
+
+	sep := byte('?')
+	writeParam := func(name, value string) {
+		b.WriteByte(sep)
+		b.WriteString(escapeURIParam(name))
+		b.WriteByte('=')
+		b.WriteString(escapeURIParam(value))
+		sep = '&'
+	}
+
+	if u.Amount != 0 {
+		writeParam("amount", u.Amount.ToDecimalBTC().String())
+	}
+	if u.Label != "" {
+		writeParam("label", u.Label)
+	}
+	if u.Message != "" {
+		writeParam("message", u.Message)
+	}
+
+	names := make([]string, 0, len(u.Params))
+	for name := range u.Params {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		writeParam(name, u.Params[name])
+	}
+
+	return b.String()
+}
@@ -0,0 +1,261 @@
+// Copyright (c) 2026 The monasuite developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package monautil_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/monasuite/monad/chaincfg"
+	"github.com/monasuite/monautil"
+)
+
+// TestParseURI ensures payment URIs are parsed into their address, amount,
+// label, message and other parameters, and that invalid URIs are rejected
+// with the expected errors.
+func TestParseURI(t *testing.T) {
+	tests := []struct {
+		name          string
+		uri           string
+		net           *chaincfg.Params
+		knownRequired []string
+		addr          string
+		amount        monautil.Amount
+		label         string
+		message       string
+		params        map[string]string
+		err           error
+	}{
+		{
+			name:   "address only",
+			uri:    "monacoin:M9vQFWksNwMShpHKZJqDdMPFjkyGDRtxyn",
+			net:    &chaincfg.MainNetParams,
+			addr:   "M9vQFWksNwMShpHKZJqDdMPFjkyGDRtxyn",
+			params: map[string]string{},
+		},
+		{
+			name:   "upper case scheme",
+			uri:    "MONACOIN:M9vQFWksNwMShpHKZJqDdMPFjkyGDRtxyn?amount=1.5",
+			net:    &chaincfg.MainNetParams,
+			addr:   "M9vQFWksNwMShpHKZJqDdMPFjkyGDRtxyn",
+			amount: 150000000,
+			params: map[string]string{},
+		},
+		{
+			name:    "all parameters",
+			uri:     "monacoin:MXCeTRYF62fdUtHT8CJujoaVEduGf5hQP6?amount=0.00000001&label=Luke%20Jr&message=Donation%20for%20project%20xyz&foo=bar",
+			net:     &chaincfg.MainNetParams,
+			addr:    "MXCeTRYF62fdUtHT8CJujoaVEduGf5hQP6",
+			amount:  1,
+			label:   "Luke Jr",
+			message: "Donation for project xyz",
+			params:  map[string]string{"foo": "bar"},
+		},
+		{
+			name:   "plus sign kept",
+			uri:    "monacoin:M9vQFWksNwMShpHKZJqDdMPFjkyGDRtxyn?label=a+b",
+			net:    &chaincfg.MainNetParams,
+			addr:   "M9vQFWksNwMShpHKZJqDdMPFjkyGDRtxyn",
+			label:  "a+b",
+			params: map[string]string{},
+		},
+		{
+			name:   "bech32 address",
+			uri:    "monacoin:mona1qvzvkjn4q3nszqxrv3nraga2r822xjty3q96530?amount=20.3",
+			net:    &chaincfg.MainNetParams,
+			addr:   "mona1qvzvkjn4q3nszqxrv3nraga2r822xjty3q96530",
+			amount: 2030000000,
+			params: map[string]string{},
+		},
+		{
+			name:          "known required parameter",
+			uri:           "monacoin:M9vQFWksNwMShpHKZJqDdMPFjkyGDRtxyn?req-somethingyoudontunderstand=50",
+			net:           &chaincfg.MainNetParams,
+			knownRequired: []string{"req-somethingyoudontunderstand"},
+			addr:          "M9vQFWksNwMShpHKZJqDdMPFjkyGDRtxyn",
+			params:        map[string]string{"req-somethingyoudontunderstand": "50"},
+		},
+		{
+			name: "unknown required parameter",
+			uri:  "monacoin:M9vQFWksNwMShpHKZJqDdMPFjkyGDRtxyn?req-somethingyoudontunderstand=50&req-somethingelseyoudontget=999",
+			net:  &chaincfg.MainNetParams,
+			err:  monautil.ErrUnknownRequiredURIParam,
+		},
+		{
+			name: "wrong scheme",
+			uri:  "bitcoin:M9vQFWksNwMShpHKZJqDdMPFjkyGDRtxyn",
+			net:  &chaincfg.MainNetParams,
+			err:  monautil.ErrInvalidURIScheme,
+		},
+		{
+			name: "wrong network",
+			uri:  "monacoin:mrX9vMRYLfVy1BnZbc5gZjuyaqH3ZW2ZHz",
+			net:  &chaincfg.MainNetParams,
+			err:  monautil.ErrWrongNet,
+		},
+		{
+			name: "bech32 address of wrong network",
+			uri:  "monacoin:tmona1qqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqe36fyt",
+			net:  &chaincfg.MainNetParams,
+			err:  monautil.ErrWrongNet,
+		},
+		{
+			name: "mainnet address on testnet",
+			uri:  "monacoin:M9vQFWksNwMShpHKZJqDdMPFjkyGDRtxyn",
+			net:  &chaincfg.TestNet4Params,
+			err:  monautil.ErrWrongNet,
+		},
+		{
+			name: "too many decimals",
+			uri:  "monacoin:M9vQFWksNwMShpHKZJqDdMPFjkyGDRtxyn?amount=0.000000001",
+			net:  &chaincfg.MainNetParams,
+			err:  monautil.ErrInvalidURIAmount,
+		},
+		{
+			name: "negative amount",
+			uri:  "monacoin:M9vQFWksNwMShpHKZJqDdMPFjkyGDRtxyn?amount=-1",
+			net:  &chaincfg.MainNetParams,
+			err:  monautil.ErrInvalidURIAmount,
+		},
+		{
+			name: "exponent amount",
+			uri:  "monacoin:M9vQFWksNwMShpHKZJqDdMPFjkyGDRtxyn?amount=1e3",
+			net:  &chaincfg.MainNetParams,
+			err:  monautil.ErrInvalidURIAmount,
+		},
+		{
+			name: "two dots",
+			uri:  "monacoin:M9vQFWksNwMShpHKZJqDdMPFjkyGDRtxyn?amount=1.2.3",
+			net:  &chaincfg.MainNetParams,
+			err:  monautil.ErrInvalidURIAmount,
+		},
+		{
+			name: "amount above max",
+			uri:  "monacoin:M9vQFWksNwMShpHKZJqDdMPFjkyGDRtxyn?amount=105120000.00000001",
+			net:  &chaincfg.MainNetParams,
+			err:  monautil.ErrInvalidURIAmount,
+		},
+		{
+			name: "duplicate parameter",
+			uri:  "monacoin:M9vQFWksNwMShpHKZJqDdMPFjkyGDRtxyn?label=a&label=b",
+			net:  &chaincfg.MainNetParams,
+			err:  monautil.ErrDuplicateURIParam,
+		},
+		{
+			name: "bad escape",
+			uri:  "monacoin:M9vQFWksNwMShpHKZJqDdMPFjkyGDRtxyn?label=%zz",
+			net:  &chaincfg.MainNetParams,
+			err:  monautil.ErrMalformedURIParam,
+		},
+	}
+
+	for _, test := range tests {
+		uri, err := monautil.ParseURI(test.uri, test.net, test.knownRequired...)
+		if test.err != nil {
+			if err != test.err {
+				t.Errorf("%s: got error %v, want %v", test.name, err,
+					test.err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s: unexpected error: %v", test.name, err)
+			continue
+		}
+
+		if uri.Address.EncodeAddress() != test.addr {
+			t.Errorf("%s: got address %s, want %s", test.name,
+				uri.Address.EncodeAddress(), test.addr)
+		}
+		if uri.Amount != test.amount {
+			t.Errorf("%s: got amount %v, want %v", test.name,
+				int64(uri.Amount), int64(test.amount))
+		}
+		if uri.Label != test.label {
+			t.Errorf("%s: got label %q, want %q", test.name,
+				uri.Label, test.label)
+		}
+		if uri.Message != test.message {
+			t.Errorf("%s: got message %q, want %q", test.name,
+				uri.Message, test.message)
+		}
+		if !reflect.DeepEqual(uri.Params, test.params) {
+			t.Errorf("%s: got params %v, want %v", test.name,
+				uri.Params, test.params)
+		}
+	}
+}
+
+// TestURIString ensures URIs are built with their parameters escaped and in
+// a stable order, and that the built URIs parse back to the same values.
+func TestURIString(t *testing.T) {
+	addr, err := monautil.DecodeAddress(
+		"M9vQFWksNwMShpHKZJqDdMPFjkyGDRtxyn", &chaincfg.MainNetParams,
+	)
+	if err != nil {
+		t.Fatalf("DecodeAddress: unexpected error: %v", err)
+	}
+
+	tests := []struct {
+		name string
+		uri  monautil.URI
+		want string
+	}{
+		{
+			name: "address only",
+			uri:  monautil.URI{Address: addr},
+			want: "monacoin:M9vQFWksNwMShpHKZJqDdMPFjkyGDRtxyn",
+		},
+		{
+			name: "amount",
+			uri:  monautil.URI{Address: addr, Amount: 150000000},
+			want: "monacoin:M9vQFWksNwMShpHKZJqDdMPFjkyGDRtxyn?amount=1.5",
+		},
+		{
+			name: "all parameters",
+			uri: monautil.URI{
+				Address: addr,
+				Amount:  1,
+				Label:   "モナ & co",
+				Message: "a+b=c",
+				Params: map[string]string{
+					"req-x": "1",
+					"foo":   "bar baz",
+				},
+			},
+			want: "monacoin:M9vQFWksNwMShpHKZJqDdMPFjkyGDRtxyn?" +
+				"amount=0.00000001&label=%E3%83%A2%E3%83%8A%20%26%20co&" +
+				"message=a%2Bb%3Dc&foo=bar%20baz&req-x=1",
+		},
+	}
+
+	for _, test := range tests {
+		s := test.uri.String()
+		if s != test.want {
+			t.Errorf("%s: got %s, want %s", test.name, s, test.want)
+			continue
+		}
+
+		uri, err := monautil.ParseURI(s, &chaincfg.MainNetParams, "req-x")
+		if err != nil {
+			t.Errorf("%s: ParseURI: unexpected error: %v", test.name,
+				err)
+			continue
+		}
+		if uri.Amount != test.uri.Amount || uri.Label != test.uri.Label ||
+			uri.Message != test.uri.Message {
+
+			t.Errorf("%s: round trip got %+v, want %+v", test.name,
+				uri, test.uri)
+		}
+		for name, value := range test.uri.Params {
+			if uri.Params[name] != value {
+				t.Errorf("%s: round trip got param %s=%q, want "+
+					"%q", test.name, name, uri.Params[name],
+					value)
+			}
+		}
+	}
+}